@@ -0,0 +1,99 @@
+package base
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// FitterContext is implemented by estimators whose Fit can be aborted with a context.
+// FitContext returns ctx.Err() when ctx is cancelled or its deadline is exceeded, the estimator must then be fitted again
+// before use. progress is reported to the ProgressCallback of ctx, if any (see WithProgress)
+type FitterContext interface {
+	FitContext(ctx context.Context, X, Y *mat.Dense) error
+}
+
+// Progress is the state of a fit reported to a ProgressCallback.
+// Estimator is the reporting estimator. Epoch counts epochs, iterations, splits or candidates depending on the reporter,
+// Epochs is their number if known, else 0. Loss is the training loss and ValidationScore the score on held out data,
+// both are NaN when the reporter has none. Elapsed is the time since the start of the fit
+type Progress struct {
+	Estimator       interface{}
+	Epoch, Epochs   int
+	Loss            float64
+	ValidationScore float64
+	Elapsed         time.Duration
+}
+
+// ProgressCallback receives the progress of fits. OnProgress may be called concurrently by parallel fits
+type ProgressCallback interface {
+	OnProgress(p Progress)
+}
+
+// ProgressFunc is a func implementing ProgressCallback
+type ProgressFunc func(p Progress)
+
+// OnProgress calls f
+func (f ProgressFunc) OnProgress(p Progress) { f(p) }
+
+type progressKey struct{}
+
+// WithProgress returns a copy of ctx carrying callback, to which FitContext methods report their progress
+func WithProgress(ctx context.Context, callback ProgressCallback) context.Context {
+	return context.WithValue(ctx, progressKey{}, callback)
+}
+
+// ReportProgress sends p to the ProgressCallback of ctx. it does nothing if ctx is nil or has no callback
+func ReportProgress(ctx context.Context, p Progress) {
+	if ctx == nil {
+		return
+	}
+	if callback, ok := ctx.Value(progressKey{}).(ProgressCallback); ok && callback != nil {
+		callback.OnProgress(p)
+	}
+}
+
+// ContextErr returns ctx.Err(), or nil if ctx is nil
+func ContextErr(ctx context.Context) error {
+	if ctx == nil {
+		return nil
+	}
+	return ctx.Err()
+}
+
+// ContextRecorder is an optimize.Recorder for gonum/optimize based fits: it stops the optimization with Context.Err()
+// when Context is done and reports each major iteration of Estimator to the ProgressCallback of Context.
+// Recorder, if not nil, records too
+type ContextRecorder struct {
+	Context   context.Context
+	Estimator interface{}
+	Epochs    int
+	Recorder  optimize.Recorder
+	start     time.Time
+}
+
+// Init initializes Recorder
+func (r *ContextRecorder) Init() error {
+	r.start = time.Now()
+	if r.Recorder != nil {
+		return r.Recorder.Init()
+	}
+	return nil
+}
+
+// Record returns Context.Err() if Context is done, else reports major iterations and calls Recorder
+func (r *ContextRecorder) Record(loc *optimize.Location, op optimize.Operation, stats *optimize.Stats) error {
+	if err := ContextErr(r.Context); err != nil {
+		return err
+	}
+	if op&optimize.MajorIteration != 0 {
+		ReportProgress(r.Context, Progress{Estimator: r.Estimator, Epoch: stats.MajorIterations, Epochs: r.Epochs, Loss: loc.F, ValidationScore: math.NaN(), Elapsed: time.Since(r.start)})
+	}
+	if r.Recorder != nil {
+		return r.Recorder.Record(loc, op, stats)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+func TestContextRecorder(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var progress []Progress
+	ctx = WithProgress(ctx, ProgressFunc(func(p Progress) { progress = append(progress, p) }))
+	r := &ContextRecorder{Context: ctx, Estimator: "estimator", Epochs: 10}
+	if err := r.Init(); err != nil {
+		t.Fatal(err)
+	}
+	loc := &optimize.Location{F: 2}
+	if err := r.Record(loc, optimize.MajorIteration, &optimize.Stats{MajorIterations: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Record(loc, optimize.FuncEvaluation, &optimize.Stats{MajorIterations: 1}); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if err := r.Record(loc, optimize.MajorIteration, &optimize.Stats{MajorIterations: 2}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if len(progress) != 1 {
+		t.Fatalf("expected one major iteration reported, got %d", len(progress))
+	}
+	if p := progress[0]; p.Estimator != "estimator" || p.Epoch != 1 || p.Epochs != 10 || p.Loss != 2 || !math.IsNaN(p.ValidationScore) {
+		t.Errorf("unexpected progress %+v", p)
+	}
+	// no callback, no context
+	ReportProgress(context.Background(), Progress{})
+	ReportProgress(nil, Progress{})
+	if err := ContextErr(nil); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,213 @@
+package base
+
+import (
+	"gonum.org/v1/gonum/blas/blas32"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Dense32 is a dense matrix of float32, using half the memory of a *mat.Dense.
+// it implements mat.Matrix so it can be passed where a mat.Matrix is expected, its elements being
+// converted to float64 by At. estimators accepting a *Dense32 keep it in float32 and convert the rows
+// they compute on to float64
+type Dense32 struct {
+	mat blas32.General
+}
+
+// NewDense32 returns a r×c *Dense32 on data, row major. data is allocated if nil
+func NewDense32(r, c int, data []float32) *Dense32 {
+	if r <= 0 || c <= 0 {
+		panic(mat.ErrZeroLength)
+	}
+	if data == nil {
+		data = make([]float32, r*c)
+	}
+	if len(data) != r*c {
+		panic(mat.ErrShape)
+	}
+	return &Dense32{mat: blas32.General{Rows: r, Cols: c, Stride: c, Data: data}}
+}
+
+// Dense32Of returns a float32 copy of a
+func Dense32Of(a mat.Matrix) *Dense32 {
+	r, c := a.Dims()
+	m := NewDense32(r, c, nil)
+	for i := 0; i < r; i++ {
+		row := m.RawRowView(i)
+		if a32, ok := a.(*Dense32); ok {
+			copy(row, a32.RawRowView(i))
+			continue
+		}
+		if d, ok := a.(mat.RawRowViewer); ok {
+			for j, v := range d.RawRowView(i) {
+				row[j] = float32(v)
+			}
+			continue
+		}
+		for j := range row {
+			row[j] = float32(a.At(i, j))
+		}
+	}
+	return m
+}
+
+// Dims for Dense32
+func (m *Dense32) Dims() (r, c int) { return m.mat.Rows, m.mat.Cols }
+
+// At returns the element i,j converted to float64
+func (m *Dense32) At(i, j int) float64 {
+	if uint(i) >= uint(m.mat.Rows) {
+		panic(mat.ErrRowAccess)
+	}
+	if uint(j) >= uint(m.mat.Cols) {
+		panic(mat.ErrColAccess)
+	}
+	return float64(m.mat.Data[i*m.mat.Stride+j])
+}
+
+// Set sets the element i,j to float32(v)
+func (m *Dense32) Set(i, j int, v float64) {
+	if uint(i) >= uint(m.mat.Rows) {
+		panic(mat.ErrRowAccess)
+	}
+	if uint(j) >= uint(m.mat.Cols) {
+		panic(mat.ErrColAccess)
+	}
+	m.mat.Data[i*m.mat.Stride+j] = float32(v)
+}
+
+// T for Dense32
+func (m *Dense32) T() mat.Matrix { return MatTranspose{Matrix: m} }
+
+// RawMatrix returns the underlying blas32.General
+func (m *Dense32) RawMatrix() blas32.General { return m.mat }
+
+// RawRowView returns the elements of row i, sharing the storage of m
+func (m *Dense32) RawRowView(i int) []float32 {
+	if uint(i) >= uint(m.mat.Rows) {
+		panic(mat.ErrRowAccess)
+	}
+	return m.mat.Data[i*m.mat.Stride : i*m.mat.Stride+m.mat.Cols]
+}
+
+// RowView returns row i as a mat.Vector sharing the storage of m
+func (m *Dense32) RowView(i int) mat.Vector { return vector32(m.RawRowView(i)) }
+
+// SliceRows returns the rows i to k-1 of m, sharing its storage
+func (m *Dense32) SliceRows(i, k int) *Dense32 {
+	if i < 0 || k > m.mat.Rows || i >= k {
+		panic(mat.ErrIndexOutOfRange)
+	}
+	g := m.mat
+	g.Rows = k - i
+	g.Data = m.mat.Data[i*m.mat.Stride : (k-1)*m.mat.Stride+m.mat.Cols]
+	return &Dense32{mat: g}
+}
+
+// TakeRows returns a copy of the rows of m in the order of rows
+func (m *Dense32) TakeRows(rows []int) *Dense32 {
+	out := NewDense32(len(rows), m.mat.Cols, nil)
+	for k, i := range rows {
+		copy(out.RawRowView(k), m.RawRowView(i))
+	}
+	return out
+}
+
+// SwapRows swaps the rows i and j of m
+func (m *Dense32) SwapRows(i, j int) {
+	ri, rj := m.RawRowView(i), m.RawRowView(j)
+	for k := range ri {
+		ri[k], rj[k] = rj[k], ri[k]
+	}
+}
+
+// ToDense returns a float64 copy of m
+func (m *Dense32) ToDense() *mat.Dense {
+	out := mat.NewDense(m.mat.Rows, m.mat.Cols, nil)
+	for i := 0; i < m.mat.Rows; i++ {
+		row := out.RawRowView(i)
+		for j, v := range m.RawRowView(i) {
+			row[j] = float64(v)
+		}
+	}
+	return out
+}
+
+// MulTo puts m×b into dst, accumulating in float64. dst is allocated if empty
+func (m *Dense32) MulTo(dst *mat.Dense, b mat.Matrix) {
+	br, bc := b.Dims()
+	if br != m.mat.Cols {
+		panic(mat.ErrShape)
+	}
+	bd := mat.DenseCopyOf(b)
+	prepareDst(dst, m.mat.Rows, bc)
+	Parallelize(-1, m.mat.Rows, func(th, start, end int) {
+		for i := start; i < end; i++ {
+			drow := dst.RawRowView(i)
+			for o := range drow {
+				drow[o] = 0
+			}
+			for j, v := range m.RawRowView(i) {
+				if v == 0 {
+					continue
+				}
+				for o, bv := range bd.RawRowView(j) {
+					drow[o] += float64(v) * bv
+				}
+			}
+		}
+	})
+}
+
+// TMulTo puts mᵀ×b into dst, accumulating in float64. dst is allocated if empty
+func (m *Dense32) TMulTo(dst *mat.Dense, b mat.Matrix) {
+	br, bc := b.Dims()
+	if br != m.mat.Rows {
+		panic(mat.ErrShape)
+	}
+	bd := mat.DenseCopyOf(b)
+	prepareDst(dst, m.mat.Cols, bc)
+	dst.Zero()
+	for i := 0; i < m.mat.Rows; i++ {
+		brow := bd.RawRowView(i)
+		for j, v := range m.RawRowView(i) {
+			if v == 0 {
+				continue
+			}
+			drow := dst.RawRowView(j)
+			for o, bv := range brow {
+				drow[o] += float64(v) * bv
+			}
+		}
+	}
+}
+
+// prepareDst allocates an empty dst to r×c and panics if a non empty one has other dimensions
+func prepareDst(dst *mat.Dense, r, c int) {
+	if dst.IsZero() {
+		*dst = *mat.NewDense(r, c, nil)
+		return
+	}
+	if dr, dc := dst.Dims(); dr != r || dc != c {
+		panic(mat.ErrShape)
+	}
+}
+
+// GobEncode for gob
+func (m *Dense32) GobEncode() ([]byte, error) { return GobEncodeFields(m.mat) }
+
+// GobDecode for gob
+func (m *Dense32) GobDecode(b []byte) error { return GobDecodeFields(b, &m.mat) }
+
+// vector32 is a row of a Dense32 as a mat.Vector
+type vector32 []float32
+
+func (v vector32) Dims() (r, c int) { return len(v), 1 }
+func (v vector32) At(i, j int) float64 {
+	if j != 0 {
+		panic(mat.ErrColAccess)
+	}
+	return float64(v[i])
+}
+func (v vector32) AtVec(i int) float64 { return float64(v[i]) }
+func (v vector32) Len() int            { return len(v) }
+func (v vector32) T() mat.Matrix       { return mat.TransposeVec{Vector: v} }
@@ -0,0 +1,62 @@
+package base
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func ExampleDense32() {
+	X := Dense32Of(mat.NewDense(3, 2, []float64{1, 2, 3, 4, 5, 6}))
+	fmt.Printf("%g\n", mat.Formatted(X.SliceRows(1, 3)))
+	Y := &mat.Dense{}
+	X.MulTo(Y, mat.NewDense(2, 1, []float64{1, -1}))
+	fmt.Printf("%g\n", mat.Formatted(Y.T()))
+	// Output:
+	// ⎡3  4⎤
+	// ⎣5  6⎦
+	// [-1  -1  -1]
+}
+
+func TestDense32(t *testing.T) {
+	Xd := mat.NewDense(4, 3, []float64{1, 0, 2, -1, 3, 0, 0, .5, 1, 2, 2, -2})
+	X := Dense32Of(Xd)
+	if !mat.Equal(X, Xd) || !mat.Equal(X.ToDense(), Xd) {
+		t.Errorf("expected %g, got %g", mat.Formatted(Xd), mat.Formatted(X))
+	}
+	B := mat.NewDense(3, 2, []float64{1, 2, 3, 4, 5, 6})
+	expected, actual := &mat.Dense{}, &mat.Dense{}
+	expected.Mul(Xd, B)
+	X.MulTo(actual, B)
+	if !mat.Equal(expected, actual) {
+		t.Errorf("MulTo: expected %g, got %g", mat.Formatted(expected), mat.Formatted(actual))
+	}
+	C := mat.NewDense(4, 2, []float64{1, 2, 3, 4, 5, 6, 7, 8})
+	expected, actual = &mat.Dense{}, &mat.Dense{}
+	expected.Mul(Xd.T(), C)
+	X.TMulTo(actual, C)
+	if !mat.Equal(expected, actual) {
+		t.Errorf("TMulTo: expected %g, got %g", mat.Formatted(expected), mat.Formatted(actual))
+	}
+	if !mat.Equal(X.TakeRows([]int{3, 0}), mat.NewDense(2, 3, []float64{2, 2, -2, 1, 0, 2})) {
+		t.Errorf("unexpected TakeRows %g", mat.Formatted(X.TakeRows([]int{3, 0})))
+	}
+	if d := mat.Norm(X.RowView(1), 2); d != mat.Norm(Xd.RowView(1), 2) {
+		t.Errorf("unexpected RowView norm %g", d)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(X); err != nil {
+		t.Fatal(err)
+	}
+	decoded := &Dense32{}
+	if err := gob.NewDecoder(&buf).Decode(decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !mat.Equal(decoded, X) {
+		t.Errorf("expected %g after gob, got %g", mat.Formatted(X), mat.Formatted(decoded))
+	}
+}
@@ -0,0 +1,164 @@
+package base
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// FitterE is implemented by estimators able to report Fit failures as errors instead of panicking
+type FitterE interface {
+	FitE(X, Y *mat.Dense) error
+}
+
+// PredicterE is implemented by estimators able to report Predict failures as errors instead of panicking
+type PredicterE interface {
+	PredictE(X, Y *mat.Dense) error
+}
+
+// TransformerE is implemented by transformers able to report Transform failures as errors instead of panicking
+type TransformerE interface {
+	FitterE
+	TransformE(X, Y *mat.Dense) (Xout, Yout *mat.Dense, err error)
+}
+
+// Estimator is the error returning contract of regressors, classifiers and clusterers
+type Estimator interface {
+	FitterE
+	PredicterE
+}
+
+// NotFittedError is returned when an estimator is used before being fitted
+type NotFittedError struct {
+	Estimator interface{}
+}
+
+func (e *NotFittedError) Error() string {
+	return fmt.Sprintf("%T is not fitted yet. call Fit before using this estimator", e.Estimator)
+}
+
+// ShapeError is returned when matrices passed to an estimator have inconsistent dimensions
+type ShapeError struct {
+	Op  string
+	Msg string
+}
+
+func (e *ShapeError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Op, e.Msg)
+}
+
+// ValueError is returned when matrices passed to an estimator contain invalid values, NaN or infinity for example
+type ValueError struct {
+	Op  string
+	Msg string
+}
+
+func (e *ValueError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Op, e.Msg)
+}
+
+// ParamError is returned when an hyperparameter has an invalid value
+type ParamError struct {
+	Estimator interface{}
+	Param     string
+	Value     interface{}
+	Msg       string
+}
+
+func (e *ParamError) Error() string {
+	s := fmt.Sprintf("invalid value %#v for %s", e.Value, e.Param)
+	if e.Estimator != nil {
+		s = fmt.Sprintf("%T: %s", e.Estimator, s)
+	}
+	if e.Msg != "" {
+		s += ": " + e.Msg
+	}
+	return s
+}
+
+// ConvergenceError is returned when an iterative estimator stopped before convergence.
+// the estimator is fitted and usable but its results may be inaccurate
+type ConvergenceError struct {
+	Estimator interface{}
+	NIter     int
+	Msg       string
+}
+
+func (e *ConvergenceError) Error() string {
+	s := fmt.Sprintf("%T did not converge after %d iterations", e.Estimator, e.NIter)
+	if e.Msg != "" {
+		s += ": " + e.Msg
+	}
+	return s
+}
+
+// Recover calls f and returns the recovered panic, if any, as an error.
+// typed errors used as panic values are returned unchanged
+func Recover(f func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok {
+				err = e
+			} else {
+				err = fmt.Errorf("%v", r)
+			}
+		}
+	}()
+	f()
+	return
+}
+
+// CheckXY returns a *ShapeError if X is nil or empty or if Y is not nil and has not the same number of rows as X,
+// and a *ValueError if X contains NaN or infinite values
+func CheckXY(op string, X, Y *mat.Dense) error {
+	if err := CheckXYDims(op, X, Y); err != nil {
+		return err
+	}
+	return CheckFinite(op, X)
+}
+
+// CheckXYDims is CheckXY without the NaN and infinity check, for estimators accepting missing values
+func CheckXYDims(op string, X, Y *mat.Dense) error {
+	if X == nil || X.IsZero() {
+		return &ShapeError{Op: op, Msg: "X is nil or empty"}
+	}
+	if Y != nil && !Y.IsZero() {
+		rx, _ := X.Dims()
+		ry, _ := Y.Dims()
+		if rx != ry {
+			return &ShapeError{Op: op, Msg: fmt.Sprintf("X has %d rows but Y has %d", rx, ry)}
+		}
+	}
+	return nil
+}
+
+// CheckFitXY is CheckXY for Fit methods where Y is required
+func CheckFitXY(op string, X, Y *mat.Dense) error {
+	if Y == nil || Y.IsZero() {
+		return &ShapeError{Op: op, Msg: "Y is nil or empty"}
+	}
+	return CheckXY(op, X, Y)
+}
+
+// CheckNFeatures returns a *ShapeError if X has not nFeatures columns
+func CheckNFeatures(op string, X mat.Matrix, nFeatures int) error {
+	_, c := X.Dims()
+	if c != nFeatures {
+		return &ShapeError{Op: op, Msg: fmt.Sprintf("X has %d features but estimator was fitted with %d", c, nFeatures)}
+	}
+	return nil
+}
+
+// CheckFinite returns a *ValueError if X contains NaN or infinite values
+func CheckFinite(op string, X mat.Matrix) error {
+	r, c := X.Dims()
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			if v := X.At(i, j); math.IsNaN(v) || math.IsInf(v, 0) {
+				return &ValueError{Op: op, Msg: fmt.Sprintf("X contains %g at %d,%d", v, i, j)}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package base
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestRecover(t *testing.T) {
+	if err := Recover(func() {}); err != nil {
+		t.Errorf("expected nil, got %s", err)
+	}
+	perr := &ParamError{Param: "Alpha", Value: -1.}
+	err := Recover(func() { panic(perr) })
+	var target *ParamError
+	if !errors.As(err, &target) || target != perr {
+		t.Errorf("expected %v, got %v", perr, err)
+	}
+	err = Recover(func() { panic("boom") })
+	if err == nil || err.Error() != "boom" {
+		t.Errorf("expected boom, got %v", err)
+	}
+}
+
+func TestCheckXY(t *testing.T) {
+	X, Y := mat.NewDense(3, 2, nil), mat.NewDense(2, 1, nil)
+	var shapeErr *ShapeError
+	if err := CheckXY("op", &mat.Dense{}, nil); !errors.As(err, &shapeErr) {
+		t.Errorf("expected ShapeError for empty X, got %v", err)
+	}
+	if err := CheckXY("op", X, nil); err != nil {
+		t.Errorf("expected nil for nil Y, got %v", err)
+	}
+	if err := CheckXY("op", X, Y); !errors.As(err, &shapeErr) {
+		t.Errorf("expected ShapeError for rows mismatch, got %v", err)
+	}
+	if err := CheckFitXY("op", X, nil); !errors.As(err, &shapeErr) {
+		t.Errorf("expected ShapeError for nil Y, got %v", err)
+	}
+	if err := CheckNFeatures("op", X, 3); !errors.As(err, &shapeErr) {
+		t.Errorf("expected ShapeError for features mismatch, got %v", err)
+	}
+	X.Set(2, 1, math.NaN())
+	var valueErr *ValueError
+	if err := CheckXY("op", X, nil); !errors.As(err, &valueErr) || err.Error() != "op: X contains NaN at 2,1" {
+		t.Errorf("expected ValueError for NaN, got %v", err)
+	}
+	if err := CheckXYDims("op", X, nil); err != nil {
+		t.Errorf("expected nil for NaN with CheckXYDims, got %v", err)
+	}
+}
+
+func ExampleParamError() {
+	fmt.Println(&ParamError{Param: "Solver", Value: "foo", Msg: "NewSolver called with unknown name"})
+	fmt.Println(&NotFittedError{Estimator: &Classifier{}})
+	fmt.Println(&ShapeError{Op: "Predict", Msg: "X has 3 features but estimator was fitted with 2"})
+	// Output:
+	// invalid value "foo" for Solver: NewSolver called with unknown name
+	// *base.Classifier is not fitted yet. call Fit before using this estimator
+	// Predict: X has 3 features but estimator was fitted with 2
+}
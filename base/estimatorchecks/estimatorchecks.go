@@ -0,0 +1,393 @@
+// Package estimatorchecks runs generic checks against any estimator of this module: predict before fit,
+// clone independence, fit idempotence, shapes handling, nil Y, Save/Load round trip and NaN rejection.
+//
+// estimators differ in what their Fit and Predict return (KMeans.Predict returns nothing, SVC.Predict a
+// base.Transformer, LinearRegression.Predict a base.Regressor), so the checks only use the error returning
+// methods FitE, PredictE and TransformE. estimators drawing random numbers must have a seeded RandomState
+package estimatorchecks
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Estimator is the contract checked: a clonable base.Transformer reporting Fit failures as errors, which predicts
+// with PredictE (regressors, classifiers, clusterers) or transforms with TransformE (transformers)
+type Estimator interface {
+	base.Transformer
+	base.TransformerCloner
+	base.FitterE
+}
+
+// Check is a named generic check. Func returns nil if estimator passes the check
+type Check struct {
+	Name string
+	Func func(estimator Estimator, X, Y *mat.Dense) error
+}
+
+// Checks are the checks run by CheckEstimator
+var Checks = []Check{
+	{Name: "PredictBeforeFit", Func: CheckPredictBeforeFit},
+	{Name: "CloneIndependence", Func: CheckCloneIndependence},
+	{Name: "FitIdempotent", Func: CheckFitIdempotent},
+	{Name: "Shapes", Func: CheckShapes},
+	{Name: "NilY", Func: CheckNilY},
+	{Name: "SaveLoad", Func: CheckSaveLoad},
+	{Name: "NaNRejected", Func: CheckNaNRejected},
+}
+
+// Except returns Checks without the checks named names
+func Except(names ...string) []Check {
+	checks := make([]Check, 0, len(Checks))
+	for _, check := range Checks {
+		excepted := false
+		for _, name := range names {
+			excepted = excepted || check.Name == name
+		}
+		if !excepted {
+			checks = append(checks, check)
+		}
+	}
+	return checks
+}
+
+// CheckError lists the checks failed by an estimator
+type CheckError struct {
+	Estimator interface{}
+	Failures  []error
+}
+
+func (e *CheckError) Error() string {
+	s := fmt.Sprintf("%T failed %d checks", e.Estimator, len(e.Failures))
+	for _, failure := range e.Failures {
+		s += "\n" + failure.Error()
+	}
+	return s
+}
+
+// CheckEstimator runs checks (Checks if none is given) against clones of estimator, which must not be fitted.
+// Y is the Y passed to FitE and gives the shape of predictions, a nSamples×1 Y for clusterers.
+// it returns a *CheckError listing the failed checks, or nil. panics are reported as failures
+func CheckEstimator(estimator Estimator, X, Y *mat.Dense, checks ...Check) error {
+	if len(checks) == 0 {
+		checks = Checks
+	}
+	var failures []error
+	for _, check := range checks {
+		var err error
+		if perr := base.Recover(func() { err = check.Func(estimator, X, Y) }); perr != nil {
+			err = fmt.Errorf("panic: %v", perr)
+		}
+		if err != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", check.Name, err))
+		}
+	}
+	if failures != nil {
+		return &CheckError{Estimator: estimator, Failures: failures}
+	}
+	return nil
+}
+
+// CheckPredictBeforeFit checks that PredictE and TransformE of an unfitted clone return a *base.NotFittedError.
+// stateless transformers may also transform without error
+func CheckPredictBeforeFit(estimator Estimator, X, Y *mat.Dense) error {
+	e, err := clone(estimator)
+	if err != nil {
+		return err
+	}
+	_, err = output(e, X, Y)
+	if _, predicter := e.(base.PredicterE); err == nil && !predicter {
+		return nil
+	}
+	return expect(err, new(*base.NotFittedError), "predict before Fit")
+}
+
+// CheckCloneIndependence checks that fitting a clone of a fitted estimator changes neither the predictions of the
+// fitted estimator nor the unfitted state of estimator
+func CheckCloneIndependence(estimator Estimator, X, Y *mat.Dense) error {
+	_, errBefore := output(estimator, X, Y)
+	fitted, err := clone(estimator)
+	if err != nil {
+		return err
+	}
+	if err := fit(fitted, X, Y); err != nil {
+		return err
+	}
+	expected, err := output(fitted, X, Y)
+	if err != nil {
+		return err
+	}
+	refitted, err := clone(fitted)
+	if err != nil {
+		return err
+	}
+	if err := fit(refitted, otherX(X), Y); err != nil {
+		return err
+	}
+	actual, err := output(fitted, X, Y)
+	if err != nil {
+		return err
+	}
+	if !equalOutputs(expected, actual) {
+		return errors.New("fitting a clone changed the predictions of the fitted estimator")
+	}
+	var notFitted *base.NotFittedError
+	if _, errAfter := output(estimator, X, Y); errors.As(errBefore, &notFitted) && !errors.As(errAfter, &notFitted) {
+		return errors.New("fitting a clone fitted the estimator")
+	}
+	return nil
+}
+
+// CheckFitIdempotent checks that fitting X,Y gives the same predictions whatever the previous fits
+func CheckFitIdempotent(estimator Estimator, X, Y *mat.Dense) error {
+	e, err := clone(estimator)
+	if err != nil {
+		return err
+	}
+	if err := fit(e, X, Y); err != nil {
+		return err
+	}
+	expected, err := output(e, X, Y)
+	if err != nil {
+		return err
+	}
+	if err := fit(e, otherX(X), Y); err != nil {
+		return err
+	}
+	if err := fit(e, X, Y); err != nil {
+		return err
+	}
+	actual, err := output(e, X, Y)
+	if err != nil {
+		return err
+	}
+	if !equalOutputs(expected, actual) {
+		return errors.New("a second fit with the same X,Y gave different predictions")
+	}
+	return nil
+}
+
+// CheckShapes checks that an empty X, a Y not matching X (for estimators requiring Y) and a X with an unexpected
+// number of features are reported as *base.ShapeError, and that predictions have the rows of X and the columns of Y
+func CheckShapes(estimator Estimator, X, Y *mat.Dense) error {
+	nSamples, nFeatures := X.Dims()
+	_, nOutputs := Y.Dims()
+	e, err := clone(estimator)
+	if err != nil {
+		return err
+	}
+	if err := expect(e.FitE(&mat.Dense{}, Y), new(*base.ShapeError), "Fit with an empty X"); err != nil {
+		return err
+	}
+	e, err = clone(estimator)
+	if err != nil {
+		return err
+	}
+	if e.FitE(mat.DenseCopyOf(X), nil) != nil {
+		Yshort := mat.DenseCopyOf(Y.Slice(0, nSamples-1, 0, nOutputs))
+		if err := expect(e.FitE(mat.DenseCopyOf(X), Yshort), new(*base.ShapeError), "Fit with a Y shorter than X"); err != nil {
+			return err
+		}
+	}
+	e, err = clone(estimator)
+	if err != nil {
+		return err
+	}
+	if err := fit(e, X, Y); err != nil {
+		return err
+	}
+	out, err := output(e, X, Y)
+	if err != nil {
+		return err
+	}
+	predicter, isPredicter := e.(base.PredicterE)
+	if r, c := out[0].Dims(); r != nSamples || (isPredicter && c != nOutputs) {
+		return fmt.Errorf("expected %d rows and %d columns, got %d,%d", nSamples, nOutputs, r, c)
+	}
+	Xwide := mat.NewDense(nSamples, nFeatures+1, nil)
+	Xwide.Slice(0, nSamples, 0, nFeatures).(*mat.Dense).Copy(X)
+	if _, err := output(e, Xwide, Y); err != nil || isPredicter {
+		if err := expect(err, new(*base.ShapeError), "predict with an additional feature"); err != nil {
+			return err
+		}
+	}
+	if isPredicter {
+		err := predicter.PredictE(mat.DenseCopyOf(X), mat.NewDense(nSamples-1, nOutputs, nil))
+		if err := expect(err, new(*base.ShapeError), "PredictE with a Y shorter than X"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckNilY checks that FitE with a nil Y either returns a *base.ShapeError or fits a usable estimator
+func CheckNilY(estimator Estimator, X, Y *mat.Dense) error {
+	e, err := clone(estimator)
+	if err != nil {
+		return err
+	}
+	if err := e.FitE(mat.DenseCopyOf(X), nil); err != nil {
+		return expect(err, new(*base.ShapeError), "Fit with a nil Y")
+	}
+	if _, err := output(e, X, Y); err != nil {
+		return fmt.Errorf("estimator fitted with a nil Y is not usable: %w", err)
+	}
+	return nil
+}
+
+// CheckSaveLoad checks that an estimator loaded by base.Load predicts as the fitted estimator saved by base.Save
+func CheckSaveLoad(estimator Estimator, X, Y *mat.Dense) error {
+	e, err := clone(estimator)
+	if err != nil {
+		return err
+	}
+	if err := fit(e, X, Y); err != nil {
+		return err
+	}
+	expected, err := output(e, X, Y)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := base.Save(&buf, e); err != nil {
+		return err
+	}
+	loaded, err := base.Load(&buf)
+	if err != nil {
+		return err
+	}
+	loadedEstimator, ok := loaded.(Estimator)
+	if !ok || reflect.TypeOf(loaded) != reflect.TypeOf(e) {
+		return fmt.Errorf("loaded a %T, expected a %T", loaded, e)
+	}
+	actual, err := output(loadedEstimator, X, Y)
+	if err != nil {
+		return err
+	}
+	if !equalOutputs(expected, actual) {
+		return errors.New("the loaded estimator predicts differently")
+	}
+	return nil
+}
+
+// CheckNaNRejected checks that a X containing NaN is reported as a *base.ValueError by FitE, PredictE and TransformE
+func CheckNaNRejected(estimator Estimator, X, Y *mat.Dense) error {
+	XNaN := mat.DenseCopyOf(X)
+	XNaN.Set(0, 0, math.NaN())
+	e, err := clone(estimator)
+	if err != nil {
+		return err
+	}
+	if err := expect(e.FitE(XNaN, Y), new(*base.ValueError), "Fit with a NaN in X"); err != nil {
+		return err
+	}
+	if err := fit(e, X, Y); err != nil {
+		return err
+	}
+	_, err = output(e, XNaN, Y)
+	return expect(err, new(*base.ValueError), "predict with a NaN in X")
+}
+
+// RegressionData returns a X of 40 samples and 3 features and a Y of 2 outputs, linear in X with a small noise
+func RegressionData() (X, Y *mat.Dense) {
+	rnd := rand.New(rand.NewSource(7))
+	X, Y = mat.NewDense(40, 3, nil), mat.NewDense(40, 2, nil)
+	for i := 0; i < 40; i++ {
+		x := X.RawRowView(i)
+		for j := range x {
+			x[j] = rnd.NormFloat64()
+		}
+		Y.Set(i, 0, 1+x[0]+2*x[1]-x[2]+.1*rnd.NormFloat64())
+		Y.Set(i, 1, -1+x[0]-x[1]+.1*rnd.NormFloat64())
+	}
+	return
+}
+
+// ClassificationData returns a X of 40 samples and 2 features in 2 blobs, and Y, the 0 or 1 class of each sample
+func ClassificationData() (X, Y *mat.Dense) {
+	rnd := rand.New(rand.NewSource(7))
+	X, Y = mat.NewDense(40, 2, nil), mat.NewDense(40, 1, nil)
+	for i := 0; i < 40; i++ {
+		class := float64(i % 2)
+		X.Set(i, 0, 2*class-1+.5*rnd.NormFloat64())
+		X.Set(i, 1, 1-2*class+.5*rnd.NormFloat64())
+		Y.Set(i, 0, class)
+	}
+	return
+}
+
+// clone returns estimator.Clone() as an Estimator
+func clone(estimator Estimator) (Estimator, error) {
+	c, ok := estimator.Clone().(Estimator)
+	if !ok || reflect.TypeOf(c) != reflect.TypeOf(estimator) {
+		return nil, fmt.Errorf("Clone returned a %T, expected a %T", c, estimator)
+	}
+	return c, nil
+}
+
+// fit fits estimator with copies of X,Y. a *base.ConvergenceError is not a failure, the estimator is usable
+func fit(estimator Estimator, X, Y *mat.Dense) error {
+	err := estimator.FitE(mat.DenseCopyOf(X), mat.DenseCopyOf(Y))
+	var convergenceErr *base.ConvergenceError
+	if err != nil && !errors.As(err, &convergenceErr) {
+		return fmt.Errorf("Fit: %w", err)
+	}
+	return nil
+}
+
+// output returns the predictions of estimator for X with the columns of Y, or X and Y transformed by estimator.
+// X and Y are copied as some transformers work in place
+func output(estimator Estimator, X, Y *mat.Dense) ([]*mat.Dense, error) {
+	nSamples, _ := X.Dims()
+	_, nOutputs := Y.Dims()
+	switch e := estimator.(type) {
+	case base.PredicterE:
+		Ypred := mat.NewDense(nSamples, nOutputs, nil)
+		return []*mat.Dense{Ypred}, e.PredictE(mat.DenseCopyOf(X), Ypred)
+	case base.TransformerE:
+		Xout, Yout, err := e.TransformE(mat.DenseCopyOf(X), mat.DenseCopyOf(Y))
+		return []*mat.Dense{Xout, Yout}, err
+	}
+	return nil, fmt.Errorf("%T implements neither PredictE nor TransformE", estimator)
+}
+
+// otherX returns 2X+1 with reversed rows
+func otherX(X *mat.Dense) *mat.Dense {
+	nSamples, nFeatures := X.Dims()
+	other := mat.NewDense(nSamples, nFeatures, nil)
+	other.Apply(func(i, j int, _ float64) float64 { return 2*X.At(nSamples-1-i, j) + 1 }, other)
+	return other
+}
+
+func equalOutputs(a, b []*mat.Dense) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] == nil || b[i] == nil {
+			if a[i] != b[i] {
+				return false
+			}
+			continue
+		}
+		if !mat.EqualApprox(a[i], b[i], 1e-9*math.Max(1, mat.Norm(a[i], math.Inf(1)))) {
+			return false
+		}
+	}
+	return true
+}
+
+// expect returns nil if err is a target, a **base.ShapeError for example, else an error about what
+func expect(err error, target interface{}, what string) error {
+	if err != nil && errors.As(err, target) {
+		return nil
+	}
+	return fmt.Errorf("%s: expected a %s, got %v", what, reflect.TypeOf(target).Elem(), err)
+}
@@ -0,0 +1,117 @@
+package estimatorchecks
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// meanRegressor predicts the mean of Y plus the sum of the features of X minus their means.
+// if Shared is set, a refit updates XMean in place, so that it is shared with clones
+type meanRegressor struct {
+	Shared      bool
+	XMean, Mean []float64
+}
+
+func init() {
+	base.Register(&meanRegressor{})
+}
+
+func (m *meanRegressor) Clone() base.Transformer {
+	clone := *m
+	return &clone
+}
+
+func (m *meanRegressor) Fit(X, Y *mat.Dense) base.Transformer {
+	if err := m.FitE(X, Y); err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func (m *meanRegressor) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckFitXY("meanRegressor.Fit", X, Y); err != nil {
+		return err
+	}
+	nSamples, nFeatures := X.Dims()
+	_, nOutputs := Y.Dims()
+	if !m.Shared || len(m.XMean) != nFeatures {
+		m.XMean = make([]float64, nFeatures)
+	}
+	for j := range m.XMean {
+		m.XMean[j] = mat.Sum(X.ColView(j)) / float64(nSamples)
+	}
+	m.Mean = make([]float64, nOutputs)
+	for o := range m.Mean {
+		m.Mean[o] = mat.Sum(Y.ColView(o)) / float64(nSamples)
+	}
+	return nil
+}
+
+func (m *meanRegressor) PredictE(X, Y *mat.Dense) error {
+	if m.Mean == nil {
+		return &base.NotFittedError{Estimator: m}
+	}
+	if err := base.CheckXY("meanRegressor.Predict", X, Y); err != nil {
+		return err
+	}
+	if err := base.CheckNFeatures("meanRegressor.Predict", X, len(m.XMean)); err != nil {
+		return err
+	}
+	Y.Apply(func(i, o int, _ float64) float64 {
+		y := m.Mean[o]
+		for j, xmean := range m.XMean {
+			y += X.At(i, j) - xmean
+		}
+		return y
+	}, Y)
+	return nil
+}
+
+func (m *meanRegressor) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
+	if err := m.PredictE(X, Y); err != nil {
+		panic(err)
+	}
+	return X, Y
+}
+
+func ExampleCheckEstimator() {
+	X, Y := RegressionData()
+	fmt.Println(CheckEstimator(&meanRegressor{}, X, Y))
+	// Output:
+	// <nil>
+}
+
+func TestCheckEstimator(t *testing.T) {
+	X, Y := RegressionData()
+	err := CheckEstimator(&meanRegressor{Shared: true}, X, Y)
+	var checkErr *CheckError
+	if !errors.As(err, &checkErr) {
+		t.Fatalf("expected a *CheckError, got %v", err)
+	}
+	if len(checkErr.Failures) != 1 || !strings.HasPrefix(checkErr.Failures[0].Error(), "CloneIndependence: ") {
+		t.Errorf("expected a CloneIndependence failure, got %v", err)
+	}
+	if err := CheckEstimator(&meanRegressor{Shared: true}, X, Y, Except("CloneIndependence")...); err != nil {
+		t.Error(err)
+	}
+	if len(Except("SaveLoad", "NaNRejected")) != len(Checks)-2 {
+		t.Error("Except did not remove SaveLoad and NaNRejected")
+	}
+}
+
+func TestClassificationData(t *testing.T) {
+	X, Y := ClassificationData()
+	nSamples, nFeatures := X.Dims()
+	if nSamples != 40 || nFeatures != 2 {
+		t.Errorf("expected a 40×2 X, got %d×%d", nSamples, nFeatures)
+	}
+	ones := mat.Sum(Y)
+	if ones == 0 || ones == 40 {
+		t.Errorf("expected 2 classes, got %g ones", ones)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 	"math"
-	"math/rand"
 
 	"gonum.org/v1/gonum/blas/blas64"
 	"gonum.org/v1/gonum/mat"
@@ -322,12 +321,18 @@ func MatRowStr(X mat.Matrix, i int) string {
 
 // MatShuffle shuffles the rows of X and Y matrices
 func MatShuffle(X, Y *mat.Dense) {
+	MatShuffleRandomState(X, Y, nil)
+}
+
+// MatShuffleRandomState is MatShuffle drawing from randomState (see CheckRandomState)
+func MatShuffleRandomState(X, Y *mat.Dense, randomState *RandomState) {
+	randomState = CheckRandomState(randomState)
 	nSamples, nFeatures := X.Dims()
 	_, nOutputs := Y.Dims()
 	Xrowi := make([]float64, nFeatures, nFeatures)
 	Yrowi := make([]float64, nOutputs, nOutputs)
 	for i := nSamples - 1; i > 0; i-- {
-		j := rand.Intn(i + 1)
+		j := randomState.Intn(i + 1)
 		copy(Xrowi, X.RawRowView(i))
 		X.SetRow(i, X.RawRowView(j))
 		X.SetRow(j, Xrowi)
@@ -18,3 +18,22 @@ func TestMatrixFirstColumnRemoved(t *testing.T) {
 		t.Errorf("Theta1.At(0,0):%g expected:%g", Theta1.At(0, 0), 2.)
 	}
 }
+
+func TestMatShuffle(t *testing.T) {
+	X := mat.NewDense(20, 1, nil)
+	for i := 0; i < 20; i++ {
+		X.Set(i, 0, float64(i))
+	}
+	Y := mat.DenseCopyOf(X)
+	MatShuffle(X, Y)
+	if !mat.Equal(X, Y) {
+		t.Errorf("expected X and Y rows shuffled together, got %g and %g", mat.Col(nil, 0, X), mat.Col(nil, 0, Y))
+	}
+	// a seed gives the same shuffle
+	X1, Y1 := mat.DenseCopyOf(X), mat.DenseCopyOf(Y)
+	MatShuffleRandomState(X, Y, NewRandomState(7))
+	MatShuffleRandomState(X1, Y1, NewRandomState(7))
+	if !mat.Equal(X, X1) || !mat.Equal(X, Y) {
+		t.Errorf("expected the same shuffle for a seed, got %g and %g", mat.Col(nil, 0, X), mat.Col(nil, 0, X1))
+	}
+}
@@ -35,7 +35,7 @@ var Solvers = map[string]OptimCreator{
 func NewSolver(name string) OptimCreator {
 	s, ok := Solvers[name]
 	if !ok {
-		panic(fmt.Errorf("NewSolver called with unknown name %s", name))
+		panic(&ParamError{Param: "Solver", Value: name, Msg: "NewSolver called with unknown name"})
 	}
 	return s
 }
@@ -0,0 +1,231 @@
+package base
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+)
+
+// ParamsSeparator separates nested estimator names in parameter keys, as in "stepname__Field"
+const ParamsSeparator = "__"
+
+// Params is implemented by estimators which resolve their parameters themselves (a pipeline for example).
+// other estimators are handled by GetParams and SetParams using their exported fields
+type Params interface {
+	GetParams() map[string]interface{}
+	SetParams(params map[string]interface{}) error
+}
+
+// GetParams returns the parameters of estimator.
+// for estimators not implementing Params, these are the exported fields, including the promoted ones.
+// parameters of nested estimators (fields implementing Transformer) are also returned with a "Field__" prefix
+func GetParams(estimator interface{}) map[string]interface{} {
+	if p, ok := estimator.(Params); ok {
+		return p.GetParams()
+	}
+	params := make(map[string]interface{})
+	v := reflect.Indirect(reflect.ValueOf(estimator))
+	if v.Kind() != reflect.Struct {
+		return params
+	}
+	depths := make(map[string]int)
+	var walk func(v reflect.Value, depth int)
+	walk = func(v reflect.Value, depth int) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf, fv := t.Field(i), v.Field(i)
+			if sf.Anonymous && fv.Kind() == reflect.Struct {
+				walk(fv, depth+1)
+				continue
+			}
+			if sf.PkgPath != "" {
+				continue
+			}
+			if d, ok := depths[sf.Name]; ok && d <= depth {
+				continue
+			}
+			depths[sf.Name] = depth
+			params[sf.Name] = fv.Interface()
+		}
+	}
+	walk(v, 0)
+	for name, value := range params {
+		if nested, ok := value.(Transformer); ok && !isNil(nested) {
+			for k, v := range GetParams(nested) {
+				params[name+ParamsSeparator+k] = v
+			}
+		}
+	}
+	return params
+}
+
+// SetParams sets the parameters of estimator, which must be a pointer for estimators not implementing Params.
+// values are converted to the field type when possible: ints and floats between numeric kinds,
+// []interface{} to typed slices. "Field__Sub" keys set parameter Sub of the estimator in Field, which is replaced by
+// a modified clone if it is a TransformerCloner
+func SetParams(estimator interface{}, params map[string]interface{}) error {
+	if p, ok := estimator.(Params); ok {
+		return p.SetParams(params)
+	}
+	v := reflect.ValueOf(estimator)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("SetParams: %T is not a pointer to a struct", estimator)
+	}
+	v = v.Elem()
+	nested := make(map[string]map[string]interface{})
+	for k, value := range params {
+		if i := strings.Index(k, ParamsSeparator); i >= 0 {
+			name := k[:i]
+			if nested[name] == nil {
+				nested[name] = make(map[string]interface{})
+			}
+			nested[name][k[i+len(ParamsSeparator):]] = value
+			continue
+		}
+		field := v.FieldByName(k)
+		if !field.IsValid() || !field.CanSet() {
+			return &ParamError{Estimator: estimator, Param: k, Value: value, Msg: fmt.Sprintf("no field %s in %T", k, estimator)}
+		}
+		if err := setValue(field, value); err != nil {
+			return &ParamError{Estimator: estimator, Param: k, Value: value, Msg: err.Error()}
+		}
+	}
+	for name, nestedParams := range nested {
+		field := v.FieldByName(name)
+		if !field.IsValid() || !field.CanInterface() {
+			return &ParamError{Estimator: estimator, Param: name, Value: nestedParams, Msg: fmt.Sprintf("no field %s in %T", name, estimator)}
+		}
+		sub := field.Interface()
+		if field.Kind() == reflect.Struct {
+			sub = field.Addr().Interface()
+		}
+		if isNil(sub) {
+			return &ParamError{Estimator: estimator, Param: name, Value: nestedParams, Msg: "nested estimator is nil"}
+		}
+		// a nested estimator may be shared with the clones of estimator: its clone is modified and stored instead
+		if cloner, ok := sub.(TransformerCloner); ok && field.Kind() != reflect.Struct && field.CanSet() {
+			if clone := reflect.ValueOf(cloner.Clone()); clone.Type().AssignableTo(field.Type()) {
+				if err := SetParams(clone.Interface(), nestedParams); err != nil {
+					return err
+				}
+				field.Set(clone)
+				continue
+			}
+		}
+		if err := SetParams(sub, nestedParams); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// setValue sets field to value, converting it if needed
+func setValue(field reflect.Value, value interface{}) error {
+	t := field.Type()
+	if value == nil {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func:
+			field.Set(reflect.Zero(t))
+			return nil
+		}
+		return fmt.Errorf("can't set %s to nil", t)
+	}
+	v := reflect.ValueOf(value)
+	if v.Type().AssignableTo(t) {
+		field.Set(v)
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		f, ok := toFloat(v)
+		if !ok {
+			break
+		}
+		switch t.Kind() {
+		case reflect.Float32, reflect.Float64:
+			field.SetFloat(f)
+			return nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if f != math.Trunc(f) || field.OverflowInt(int64(f)) {
+				return fmt.Errorf("%v is not a valid %s", value, t)
+			}
+			field.SetInt(int64(f))
+			return nil
+		default:
+			if f != math.Trunc(f) || f < 0 || field.OverflowUint(uint64(f)) {
+				return fmt.Errorf("%v is not a valid %s", value, t)
+			}
+			field.SetUint(uint64(f))
+			return nil
+		}
+	case reflect.Slice:
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			break
+		}
+		s := reflect.MakeSlice(t, v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if err := setValue(s.Index(i), v.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		field.Set(s)
+		return nil
+	case reflect.Ptr:
+		if t == reflect.TypeOf((*RandomState)(nil)) {
+			// allow setting RandomState from a seed or a *rand.Rand
+			if r, ok := value.(*rand.Rand); ok {
+				field.Set(reflect.ValueOf(NewRandomStateFromRand(r)))
+				return nil
+			}
+			seed, ok := v.Interface().(int64)
+			if f, isNumber := toFloat(v); !ok && isNumber && f == math.Trunc(f) {
+				seed, ok = int64(f), true
+			}
+			if !ok {
+				break
+			}
+			field.Set(reflect.ValueOf(NewRandomState(seed)))
+			return nil
+		}
+		// allow setting *int64 and the like from a value
+		elem := reflect.New(t.Elem())
+		if err := setValue(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+	if v.Type().ConvertibleTo(t) && v.Kind() == t.Kind() {
+		field.Set(v.Convert(t))
+		return nil
+	}
+	return fmt.Errorf("can't set %s to %T", t, value)
+}
+
+func toFloat(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
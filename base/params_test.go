@@ -0,0 +1,93 @@
+package base
+
+import (
+	"errors"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type paramsTestEmbedded struct {
+	Alpha float64
+	NIter int
+}
+
+type paramsTestEstimator struct {
+	paramsTestEmbedded
+	Shuffle          bool
+	HiddenLayerSizes []int
+	Kernel           interface{}
+	RandomState      *RandomState
+	Inner            *paramsTestEstimator
+	unexported       int
+}
+
+func (m *paramsTestEstimator) Fit(X, Y *mat.Dense) Transformer { return m }
+func (m *paramsTestEstimator) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
+	return X, Y
+}
+func (m *paramsTestEstimator) Clone() Transformer {
+	clone := *m
+	return &clone
+}
+
+func TestSetParams(t *testing.T) {
+	m := &paramsTestEstimator{Inner: &paramsTestEstimator{}}
+	err := SetParams(m, map[string]interface{}{
+		"Alpha":            1,
+		"NIter":            10.,
+		"Shuffle":          true,
+		"HiddenLayerSizes": []interface{}{5, 3.},
+		"Kernel":           "rbf",
+		"RandomState":      7,
+		"Inner__Alpha":     .5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Alpha != 1 || m.NIter != 10 || !m.Shuffle || len(m.HiddenLayerSizes) != 2 || m.HiddenLayerSizes[1] != 3 || m.Kernel != "rbf" || m.RandomState == nil || m.Inner.Alpha != .5 {
+		t.Errorf("unexpected %#v", m)
+	}
+	if seed, ok := m.RandomState.Seed(); !ok || seed != 7 {
+		t.Errorf("RandomState: got seed %d,%v", seed, ok)
+	}
+	var paramErr *ParamError
+	for _, params := range []map[string]interface{}{
+		{"NIter": 1.5},
+		{"Shuffle": 1},
+		{"unexported": 1},
+		{"Missing": 1},
+		{"Inner__Inner__Alpha": 1},
+	} {
+		if err := SetParams(m, params); !errors.As(err, &paramErr) {
+			t.Errorf("%v: expected ParamError, got %v", params, err)
+		}
+	}
+}
+
+func TestSetParamsNestedClone(t *testing.T) {
+	// shallow copies share Inner, setting a nested parameter must not change the others
+	m := &paramsTestEstimator{Inner: &paramsTestEstimator{}}
+	clone1, clone2 := m.Clone().(*paramsTestEstimator), m.Clone().(*paramsTestEstimator)
+	if err := SetParams(clone1, map[string]interface{}{"Inner__Alpha": .1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetParams(clone2, map[string]interface{}{"Inner__Alpha": 5}); err != nil {
+		t.Fatal(err)
+	}
+	if m.Inner.Alpha != 0 || clone1.Inner.Alpha != .1 || clone2.Inner.Alpha != 5 {
+		t.Errorf("expected Alpha 0, .1 and 5, got %g, %g and %g", m.Inner.Alpha, clone1.Inner.Alpha, clone2.Inner.Alpha)
+	}
+}
+
+func TestGetParams(t *testing.T) {
+	m := &paramsTestEstimator{paramsTestEmbedded: paramsTestEmbedded{Alpha: 2}, Inner: &paramsTestEstimator{}}
+	m.Inner.NIter = 3
+	params := GetParams(m)
+	if params["Alpha"] != 2. || params["Inner__NIter"] != 3 {
+		t.Errorf("unexpected %v", params)
+	}
+	if _, ok := params["unexported"]; ok {
+		t.Error("unexported field returned")
+	}
+}
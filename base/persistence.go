@@ -0,0 +1,162 @@
+package base
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// SaveFormatVersion is the version of the format written by Save.
+// Load refuses streams written with a greater version
+const SaveFormatVersion = 1
+
+// Restorer is implemented by estimators holding runtime state which is not saved (funcs, kernels, distances...)
+// and must be rebuilt after Load. composite estimators must call Restore on their children
+type Restorer interface {
+	Restore() error
+}
+
+var registry = struct {
+	sync.RWMutex
+	types map[string]reflect.Type
+}{types: make(map[string]reflect.Type)}
+
+// Register makes the concrete type of estimator loadable by Load, including when it is nested in another estimator
+// (a pipeline step for example). packages of this module register their estimators in an init func,
+// user defined estimators must be registered before being saved or loaded
+func Register(estimator Transformer) {
+	gob.Register(estimator)
+	registry.Lock()
+	defer registry.Unlock()
+	registry.types[typeName(estimator)] = reflect.TypeOf(estimator)
+}
+
+// Registered returns a new zero estimator of the registered type named name (as printed by %T), or nil
+func Registered(name string) Transformer {
+	registry.RLock()
+	defer registry.RUnlock()
+	t, ok := registry.types[name]
+	if !ok {
+		return nil
+	}
+	if t.Kind() == reflect.Ptr {
+		return reflect.New(t.Elem()).Interface().(Transformer)
+	}
+	return reflect.Zero(t).Interface().(Transformer)
+}
+
+func typeName(estimator interface{}) string {
+	return fmt.Sprintf("%T", estimator)
+}
+
+type saveHeader struct {
+	Version int
+	Type    string
+}
+
+type savePayload struct {
+	Estimator Transformer
+}
+
+// Save writes estimator to w in a versioned gob stream. estimator type must be registered with Register
+func Save(w io.Writer, estimator Transformer) error {
+	name := typeName(estimator)
+	if Registered(name) == nil {
+		return fmt.Errorf("save: %s is not registered", name)
+	}
+	enc := gob.NewEncoder(w)
+	if err := enc.Encode(saveHeader{Version: SaveFormatVersion, Type: name}); err != nil {
+		return fmt.Errorf("save %s: %w", name, err)
+	}
+	if err := enc.Encode(savePayload{Estimator: estimator}); err != nil {
+		return fmt.Errorf("save %s: %w", name, err)
+	}
+	return nil
+}
+
+// Load reads an estimator written by Save. its type (and the type of nested estimators) must be registered.
+// Restore is called on the loaded estimator if it implements Restorer
+func Load(r io.Reader) (Transformer, error) {
+	dec := gob.NewDecoder(r)
+	var header saveHeader
+	if err := dec.Decode(&header); err != nil {
+		return nil, fmt.Errorf("load: %w", err)
+	}
+	if header.Version > SaveFormatVersion {
+		return nil, fmt.Errorf("load %s: format version %d is newer than %d", header.Type, header.Version, SaveFormatVersion)
+	}
+	if Registered(header.Type) == nil {
+		return nil, fmt.Errorf("load: %s is not registered. import its package", header.Type)
+	}
+	var payload savePayload
+	if err := dec.Decode(&payload); err != nil {
+		return nil, fmt.Errorf("load %s: %w", header.Type, err)
+	}
+	if restorer, ok := payload.Estimator.(Restorer); ok {
+		if err := restorer.Restore(); err != nil {
+			return nil, fmt.Errorf("load %s: %w", header.Type, err)
+		}
+	}
+	return payload.Estimator, nil
+}
+
+// LoadInto is Load for a known estimator type. estimator must be a pointer to the type which was saved
+func LoadInto(r io.Reader, estimator Transformer) error {
+	loaded, err := Load(r)
+	if err != nil {
+		return err
+	}
+	dst, src := reflect.ValueOf(estimator), reflect.ValueOf(loaded)
+	if dst.Type() != src.Type() || dst.Kind() != reflect.Ptr {
+		return fmt.Errorf("load: cannot load %s into %s", src.Type(), dst.Type())
+	}
+	dst.Elem().Set(src.Elem())
+	return nil
+}
+
+// GobEncodeFields gob encodes v. it's a helper for GobEncode methods of types encoding a subset of their fields
+func GobEncodeFields(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(v)
+	return buf.Bytes(), err
+}
+
+// GobDecodeFields decodes into v a buffer encoded by GobEncodeFields
+func GobDecodeFields(b []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(b)).Decode(v)
+}
+
+// activations have no fields. these methods only make them usable with gob in interface fields
+
+// GobEncode for gob
+func (Identity) GobEncode() ([]byte, error) { return []byte{}, nil }
+
+// GobDecode for gob
+func (*Identity) GobDecode([]byte) error { return nil }
+
+// GobEncode for gob
+func (Logistic) GobEncode() ([]byte, error) { return []byte{}, nil }
+
+// GobDecode for gob
+func (*Logistic) GobDecode([]byte) error { return nil }
+
+// GobEncode for gob
+func (ReLU) GobEncode() ([]byte, error) { return []byte{}, nil }
+
+// GobDecode for gob
+func (*ReLU) GobDecode([]byte) error { return nil }
+
+// GobEncode for gob
+func (Tanh) GobEncode() ([]byte, error) { return []byte{}, nil }
+
+// GobDecode for gob
+func (*Tanh) GobDecode([]byte) error { return nil }
+
+func init() {
+	for _, activation := range Activations {
+		gob.Register(activation)
+	}
+}
@@ -0,0 +1,52 @@
+package base
+
+import (
+	"bytes"
+	"encoding/gob"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type persistenceTestEstimator struct {
+	Coef     *mat.Dense
+	Restored bool
+}
+
+func (m *persistenceTestEstimator) Fit(X, Y *mat.Dense) Transformer { return m }
+func (m *persistenceTestEstimator) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
+	return X, Y
+}
+func (m *persistenceTestEstimator) Restore() error { m.Restored = true; return nil }
+
+type unregisteredTestEstimator struct{ persistenceTestEstimator }
+
+func TestSaveLoad(t *testing.T) {
+	Register(&persistenceTestEstimator{})
+	m := &persistenceTestEstimator{Coef: mat.NewDense(2, 1, []float64{1, 2})}
+	var buf bytes.Buffer
+	if err := Save(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+	loaded := &persistenceTestEstimator{}
+	if err := LoadInto(&buf, loaded); err != nil {
+		t.Fatal(err)
+	}
+	if !mat.Equal(m.Coef, loaded.Coef) || !loaded.Restored {
+		t.Errorf("unexpected loaded estimator %#v", loaded)
+	}
+
+	if err := Save(&buf, &unregisteredTestEstimator{}); err == nil || !strings.Contains(err.Error(), "not registered") {
+		t.Errorf("expected not registered error, got %v", err)
+	}
+
+	buf.Reset()
+	enc := gob.NewEncoder(&buf)
+	if err := enc.Encode(saveHeader{Version: SaveFormatVersion + 1, Type: "*base.persistenceTestEstimator"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(&buf); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("expected version error, got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package base
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math/rand"
+	"sync"
+)
+
+// RandomState is a source of random numbers safe for concurrent use.
+// it is either a seed, created by NewRandomState, or a generator, wrapping a *rand.Rand (NewRandomStateFromRand)
+// or returned by CheckRandomState.
+// estimators, splitters and generators take a *RandomState field and call CheckRandomState when they start:
+// with a seed they draw the same numbers on each call, so two fits with the same seed are identical,
+// with a generator successive calls draw different numbers, and with nil they are seeded from math/rand.
+// a seed is saved by Save, a generator is not
+type RandomState struct {
+	seed   int64
+	seeded bool
+	r      *rand.Rand
+}
+
+// lockedSource is a rand.Source64 safe for concurrent use
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source64
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
+
+// randSource is a rand.Source64 drawing from a *rand.Rand
+type randSource struct{ r *rand.Rand }
+
+func (s randSource) Int63() int64    { return s.r.Int63() }
+func (s randSource) Uint64() uint64  { return s.r.Uint64() }
+func (s randSource) Seed(seed int64) { s.r.Seed(seed) }
+
+func newLockedRand(src rand.Source64) *rand.Rand {
+	return rand.New(&lockedSource{src: src})
+}
+
+// NewRandomState returns a RandomState seeded with seed
+func NewRandomState(seed int64) *RandomState {
+	return &RandomState{seed: seed, seeded: true, r: newLockedRand(rand.NewSource(seed).(rand.Source64))}
+}
+
+// NewRandomStateFromRand returns a generator RandomState drawing from r.
+// r must not be used elsewhere concurrently
+func NewRandomStateFromRand(r *rand.Rand) *RandomState {
+	return &RandomState{r: newLockedRand(randSource{r})}
+}
+
+// CheckRandomState returns the generator to use for rs: a new generator seeded from math/rand if rs is nil,
+// a new generator seeded with the seed of rs if rs is a seed, else rs itself
+func CheckRandomState(rs *RandomState) *RandomState {
+	switch {
+	case rs == nil:
+		return &RandomState{r: newLockedRand(rand.NewSource(rand.Int63()).(rand.Source64))}
+	case rs.seeded:
+		return &RandomState{r: newLockedRand(rand.NewSource(rs.seed).(rand.Source64))}
+	default:
+		return rs
+	}
+}
+
+// Seed returns the seed of rs and true if rs is a seed, else 0 and false
+func (rs *RandomState) Seed() (int64, bool) {
+	return rs.seed, rs.seeded
+}
+
+// Spawn returns n independent generators whose seeds are drawn in sequence from rs.
+// parallel jobs each drawing from their own generator give the same results whatever the number of threads
+func (rs *RandomState) Spawn(n int) []*RandomState {
+	rs = CheckRandomState(rs)
+	children := make([]*RandomState, n)
+	for i := range children {
+		children[i] = &RandomState{r: newLockedRand(rand.NewSource(rs.Int63()).(rand.Source64))}
+	}
+	return children
+}
+
+// Rand returns the *rand.Rand rs draws from, for APIs taking a *rand.Rand. its Read method is not safe for concurrent use
+func (rs *RandomState) Rand() *rand.Rand { return rs.r }
+
+// Int63 returns a non-negative pseudo-random 63-bit integer as an int64
+func (rs *RandomState) Int63() int64 { return rs.r.Int63() }
+
+// Int63n returns, as an int64, a non-negative pseudo-random number in [0,n)
+func (rs *RandomState) Int63n(n int64) int64 { return rs.r.Int63n(n) }
+
+// Intn returns, as an int, a non-negative pseudo-random number in [0,n)
+func (rs *RandomState) Intn(n int) int { return rs.r.Intn(n) }
+
+// Float64 returns, as a float64, a pseudo-random number in [0.0,1.0)
+func (rs *RandomState) Float64() float64 { return rs.r.Float64() }
+
+// NormFloat64 returns a normally distributed float64 with mean 0 and standard deviation 1
+func (rs *RandomState) NormFloat64() float64 { return rs.r.NormFloat64() }
+
+// ExpFloat64 returns an exponentially distributed float64 with rate parameter 1
+func (rs *RandomState) ExpFloat64() float64 { return rs.r.ExpFloat64() }
+
+// Perm returns, as a slice of n ints, a pseudo-random permutation of the integers [0,n)
+func (rs *RandomState) Perm(n int) []int { return rs.r.Perm(n) }
+
+// Shuffle pseudo-randomizes the order of elements using swap
+func (rs *RandomState) Shuffle(n int, swap func(i, j int)) { rs.r.Shuffle(n, swap) }
+
+type gobRandomState struct {
+	Seed   int64
+	Seeded bool
+}
+
+// GobEncode saves the seed of rs. the state of a generator is not saved
+func (rs *RandomState) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(gobRandomState{Seed: rs.seed, Seeded: rs.seeded})
+	return buf.Bytes(), err
+}
+
+// GobDecode restores a seed saved by GobEncode. a saved generator is restored as a new generator seeded from math/rand
+func (rs *RandomState) GobDecode(data []byte) error {
+	var g gobRandomState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&g); err != nil {
+		return err
+	}
+	if g.Seeded {
+		*rs = *NewRandomState(g.Seed)
+	} else {
+		*rs = *CheckRandomState(nil)
+	}
+	return nil
+}
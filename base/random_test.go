@@ -0,0 +1,76 @@
+package base
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func ExampleCheckRandomState() {
+	seed := NewRandomState(7)
+	a, b := CheckRandomState(seed), CheckRandomState(seed)
+	fmt.Println(a.Intn(1000) == b.Intn(1000))
+	generator := NewRandomStateFromRand(rand.New(rand.NewSource(7)))
+	fmt.Println(CheckRandomState(generator) == generator)
+	// Output:
+	// true
+	// true
+}
+
+func TestRandomStateSpawn(t *testing.T) {
+	draw := func(threads int) []float64 {
+		children := NewRandomState(3).Spawn(8)
+		out := make([]float64, len(children))
+		Parallelize(threads, len(children), func(th, start, end int) {
+			for i := start; i < end; i++ {
+				out[i] = children[i].NormFloat64()
+			}
+		})
+		return out
+	}
+	sequential, parallel := draw(1), draw(4)
+	for i := range sequential {
+		if sequential[i] != parallel[i] {
+			t.Fatalf("child %d: %g != %g", i, sequential[i], parallel[i])
+		}
+		if i > 0 && sequential[i] == sequential[i-1] {
+			t.Errorf("children %d and %d drew the same number", i-1, i)
+		}
+	}
+}
+
+func TestRandomStateConcurrent(t *testing.T) {
+	rs := CheckRandomState(NewRandomState(1))
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				rs.Float64()
+				rs.Perm(5)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRandomStateGob(t *testing.T) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(NewRandomState(42)); err != nil {
+		t.Fatal(err)
+	}
+	loaded := &RandomState{}
+	if err := gob.NewDecoder(&buf).Decode(loaded); err != nil {
+		t.Fatal(err)
+	}
+	if seed, ok := loaded.Seed(); !ok || seed != 42 {
+		t.Errorf("got seed %d,%v", seed, ok)
+	}
+	if CheckRandomState(loaded).Int63() != CheckRandomState(NewRandomState(42)).Int63() {
+		t.Errorf("loaded seed draws differently")
+	}
+}
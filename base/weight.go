@@ -0,0 +1,172 @@
+package base
+
+import (
+	"encoding/gob"
+	"fmt"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// FitterWeighted is implemented by estimators able to weigh each sample in their fit.
+// FitWeighted is FitE with a weight per row of X. a nil sampleWeight weighs all samples 1, and integer weights are
+// equivalent to repeating the rows of X and Y
+type FitterWeighted interface {
+	FitWeighted(X, Y *mat.Dense, sampleWeight []float64) error
+}
+
+// CheckSampleWeight returns a *ShapeError if sampleWeight is not nil and has not one element per row of X,
+// and a *ParamError if it has a negative or NaN weight or if all weights are zero
+func CheckSampleWeight(op string, X mat.Matrix, sampleWeight []float64) error {
+	if sampleWeight == nil {
+		return nil
+	}
+	if NSamples, _ := X.Dims(); len(sampleWeight) != NSamples {
+		return &ShapeError{Op: op, Msg: fmt.Sprintf("sampleWeight has %d elements but X has %d rows", len(sampleWeight), NSamples)}
+	}
+	sum := 0.
+	for i, w := range sampleWeight {
+		if w < 0 || math.IsNaN(w) {
+			return &ParamError{Param: fmt.Sprintf("sampleWeight[%d]", i), Value: w, Msg: "must be >= 0"}
+		}
+		sum += w
+	}
+	if sum == 0 {
+		return &ParamError{Param: "sampleWeight", Value: sampleWeight, Msg: "weights sum to zero"}
+	}
+	return nil
+}
+
+// RepeatRows returns the rows of X repeated sampleWeight[i] times, sampleWeight being rounded to integers.
+// it is the unweighted equivalent of a weighted fit, used to check FitWeighted implementations
+func RepeatRows(X *mat.Dense, sampleWeight []float64) *mat.Dense {
+	_, NFeatures := X.Dims()
+	data := make([]float64, 0)
+	for i, w := range sampleWeight {
+		for k := 0; k < int(math.Round(w)); k++ {
+			data = append(data, X.RawRowView(i)...)
+		}
+	}
+	return mat.NewDense(len(data)/NFeatures, NFeatures, data)
+}
+
+// ComputeClassWeight returns the weight of each of classes for the labels y.
+// classWeight is nil for a weight of 1, "balanced" for len(y)/(len(classes)*count of the class in y),
+// or a map[float64]float64 from a class to its weight, classes absent from the map weighing 1
+func ComputeClassWeight(classWeight interface{}, classes, y []float64) ([]float64, error) {
+	weights := make([]float64, len(classes))
+	for c := range weights {
+		weights[c] = 1
+	}
+	switch cw := classWeight.(type) {
+	case nil:
+	case string:
+		if cw != "balanced" {
+			return nil, &ParamError{Param: "ClassWeight", Value: cw, Msg: "must be \"balanced\" or a map[float64]float64"}
+		}
+		index := make(map[float64]int, len(classes))
+		for c, class := range classes {
+			index[class] = c
+		}
+		counts := make([]float64, len(classes))
+		for _, label := range y {
+			c, ok := index[label]
+			if !ok {
+				return nil, &ParamError{Param: "y", Value: label, Msg: "label not in classes"}
+			}
+			counts[c]++
+		}
+		for c, count := range counts {
+			if count == 0 {
+				return nil, &ParamError{Param: "ClassWeight", Value: cw, Msg: fmt.Sprintf("class %g has no sample", classes[c])}
+			}
+			weights[c] = float64(len(y)) / (float64(len(classes)) * count)
+		}
+	case map[float64]float64:
+		index := make(map[float64]int, len(classes))
+		for c, class := range classes {
+			index[class] = c
+		}
+		for class, w := range cw {
+			c, ok := index[class]
+			if !ok {
+				return nil, &ParamError{Param: "ClassWeight", Value: cw, Msg: fmt.Sprintf("class %g not in classes", class)}
+			}
+			weights[c] = w
+		}
+	default:
+		return nil, &ParamError{Param: "ClassWeight", Value: classWeight, Msg: "must be nil, \"balanced\" or a map[float64]float64"}
+	}
+	return weights, nil
+}
+
+// ComputeSampleWeight returns the weight of each row of Y given classWeight (see ComputeClassWeight).
+// a single column Y holds the labels. with several columns, Y is a one-hot or one-vs-rest indicator matrix
+// and the class of a row is the index of its largest column
+func ComputeSampleWeight(classWeight interface{}, Y *mat.Dense) ([]float64, error) {
+	NSamples, NOutputs := Y.Dims()
+	y := make([]float64, NSamples)
+	var classes []float64
+	if NOutputs == 1 {
+		mat.Col(y, 0, Y)
+		seen := make(map[float64]bool)
+		for _, label := range y {
+			if !seen[label] {
+				seen[label] = true
+				classes = append(classes, label)
+			}
+		}
+		sort.Float64s(classes)
+	} else {
+		classes = make([]float64, NOutputs)
+		for c := range classes {
+			classes[c] = float64(c)
+		}
+		for i := range y {
+			best := 0
+			for c, v := range Y.RawRowView(i) {
+				if v > Y.At(i, best) {
+					best = c
+				}
+			}
+			y[i] = float64(best)
+		}
+	}
+	weights, err := ComputeClassWeight(classWeight, classes, y)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[float64]int, len(classes))
+	for c, class := range classes {
+		index[class] = c
+	}
+	sampleWeight := make([]float64, NSamples)
+	for i, label := range y {
+		sampleWeight[i] = weights[index[label]]
+	}
+	return sampleWeight, nil
+}
+
+// ApplyClassWeight returns sampleWeight multiplied by the class weights of the rows of Y (see ComputeSampleWeight).
+// it returns sampleWeight unchanged when classWeight is nil, so that a nil sampleWeight keeps unweighted fits unweighted
+func ApplyClassWeight(classWeight interface{}, Y *mat.Dense, sampleWeight []float64) ([]float64, error) {
+	if classWeight == nil {
+		return sampleWeight, nil
+	}
+	weights, err := ComputeSampleWeight(classWeight, Y)
+	if err != nil {
+		return nil, err
+	}
+	for i := range weights {
+		if sampleWeight != nil {
+			weights[i] *= sampleWeight[i]
+		}
+	}
+	return weights, nil
+}
+
+func init() {
+	// ClassWeight fields are interface{} and may hold a map
+	gob.Register(map[float64]float64{})
+}
@@ -0,0 +1,82 @@
+package base
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestCheckSampleWeight(t *testing.T) {
+	X := mat.NewDense(3, 2, nil)
+	if err := CheckSampleWeight("op", X, nil); err != nil {
+		t.Errorf("expected nil for nil sampleWeight, got %v", err)
+	}
+	if err := CheckSampleWeight("op", X, []float64{1, 0, 2.5}); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+	var shapeErr *ShapeError
+	if err := CheckSampleWeight("op", X, []float64{1, 2}); !errors.As(err, &shapeErr) {
+		t.Errorf("expected ShapeError for length mismatch, got %v", err)
+	}
+	var paramErr *ParamError
+	for _, sw := range [][]float64{{1, -1, 1}, {1, math.NaN(), 1}, {0, 0, 0}} {
+		if err := CheckSampleWeight("op", X, sw); !errors.As(err, &paramErr) {
+			t.Errorf("expected ParamError for %v, got %v", sw, err)
+		}
+	}
+}
+
+func ExampleRepeatRows() {
+	X := mat.NewDense(3, 2, []float64{1, 2, 3, 4, 5, 6})
+	fmt.Printf("%g\n", mat.Formatted(RepeatRows(X, []float64{2, 0, 1})))
+	// Output:
+	// ⎡1  2⎤
+	// ⎢1  2⎥
+	// ⎣5  6⎦
+}
+
+func ExampleComputeClassWeight() {
+	y := []float64{0, 0, 0, 1}
+	w, _ := ComputeClassWeight("balanced", []float64{0, 1}, y)
+	fmt.Printf("%.3f\n", w)
+	w, _ = ComputeClassWeight(map[float64]float64{1: 10}, []float64{0, 1}, y)
+	fmt.Printf("%.3f\n", w)
+	// Output:
+	// [0.667 2.000]
+	// [1.000 10.000]
+}
+
+func ExampleComputeSampleWeight() {
+	Y := mat.NewDense(4, 1, []float64{2, 2, 2, 5})
+	sw, _ := ComputeSampleWeight("balanced", Y)
+	fmt.Printf("%.3f\n", sw)
+	Y = mat.NewDense(4, 2, []float64{1, 0, 1, 0, 1, 0, 0, 1})
+	sw, _ = ComputeSampleWeight("balanced", Y)
+	fmt.Printf("%.3f\n", sw)
+	// Output:
+	// [0.667 0.667 0.667 2.000]
+	// [0.667 0.667 0.667 2.000]
+}
+
+func TestComputeClassWeightErrors(t *testing.T) {
+	var paramErr *ParamError
+	for _, cw := range []interface{}{"auto", map[float64]float64{3: 1}, []float64{1, 2}} {
+		if _, err := ComputeClassWeight(cw, []float64{0, 1}, []float64{0, 1}); !errors.As(err, &paramErr) {
+			t.Errorf("expected ParamError for %v, got %v", cw, err)
+		}
+	}
+	if _, err := ComputeClassWeight("balanced", []float64{0, 1}, []float64{0, 0}); !errors.As(err, &paramErr) {
+		t.Errorf("expected ParamError for a class without samples, got %v", err)
+	}
+	sw, err := ApplyClassWeight(nil, mat.NewDense(2, 1, []float64{0, 1}), nil)
+	if err != nil || sw != nil {
+		t.Errorf("expected nil sampleWeight for nil ClassWeight, got %v %v", sw, err)
+	}
+	sw, _ = ApplyClassWeight("balanced", mat.NewDense(3, 1, []float64{0, 0, 1}), []float64{1, 2, 3})
+	if want := []float64{.75, 1.5, 4.5}; fmt.Sprint(sw) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, sw)
+	}
+}
@@ -1,6 +1,7 @@
 package cluster
 
 import (
+	"fmt"
 	"runtime"
 
 	"github.com/pa-m/sklearn/base"
@@ -119,6 +120,41 @@ func (m *DBSCAN) Fit(X, Y *mat.Dense) base.Transformer {
 
 }
 
+// FitE is Fit returning an error instead of panicking. Y is ignored
+func (m *DBSCAN) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckXY("DBSCAN.Fit", X, nil); err != nil {
+		return err
+	}
+	if m.Eps <= 0 {
+		return &base.ParamError{Estimator: m, Param: "Eps", Value: m.Eps, Msg: "must be > 0"}
+	}
+	if NSamples, _ := X.Dims(); m.SampleWeight != nil && len(m.SampleWeight) != NSamples {
+		return &base.ShapeError{Op: "DBSCAN.Fit", Msg: fmt.Sprintf("SampleWeight has %d elements but X has %d rows", len(m.SampleWeight), NSamples)}
+	}
+	return base.Recover(func() { m.Fit(X, Y) })
+}
+
+// FitWeighted is FitE with sampleWeight as SampleWeight. Y is ignored
+func (m *DBSCAN) FitWeighted(X, Y *mat.Dense, sampleWeight []float64) error {
+	m.SampleWeight = sampleWeight
+	return m.FitE(X, Y)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *DBSCAN) PredictE(X, Y *mat.Dense) error {
+	if m.NeighborsModel == nil || m.NeighborsModel.X == nil {
+		return &base.NotFittedError{Estimator: m}
+	}
+	if err := base.CheckXY("DBSCAN.Predict", X, Y); err != nil {
+		return err
+	}
+	_, NFeatures := m.NeighborsModel.X.Dims()
+	if err := base.CheckNFeatures("DBSCAN.Predict", X, NFeatures); err != nil {
+		return err
+	}
+	return base.Recover(func() { m.Predict(X, Y) })
+}
+
 // Predict for DBSCAN
 func (m *DBSCAN) Predict(X, Y *mat.Dense) base.Transformer {
 	return m
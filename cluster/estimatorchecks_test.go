@@ -0,0 +1,16 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/pa-m/sklearn/base/estimatorchecks"
+)
+
+func TestEstimatorChecks(t *testing.T) {
+	X, Y := estimatorchecks.ClassificationData()
+	for _, estimator := range []estimatorchecks.Estimator{&KMeans{NClusters: 2}, NewDBSCAN(nil)} {
+		if err := estimatorchecks.CheckEstimator(estimator, X, Y); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -44,6 +44,12 @@ func (m *KMeans) _nearest(scaledRow mat.Vector) int {
 // Fit compute centroids
 // Y is useless here but we want all classifiers have the same interface. pass nil
 func (m *KMeans) Fit(X, Y *mat.Dense) base.Transformer {
+	m.fit(X, nil)
+	return m
+}
+
+// fit computes the centroids as the means of their samples weighted by sampleWeight, if not nil
+func (m *KMeans) fit(X *mat.Dense, sampleWeight []float64) {
 	NSamples, NFeatures := X.Dims()
 	if NSamples < m.NClusters {
 		panic(fmt.Errorf("NSamples<m.NClusters %d<%d", NSamples, m.NClusters))
@@ -61,6 +67,13 @@ func (m *KMeans) Fit(X, Y *mat.Dense) base.Transformer {
 	}
 	NearestCentroid := make([]int, NSamples)
 	CentroidCount := make([]int, m.NClusters)
+	CentroidWeight := make([]float64, m.NClusters)
+	weight := func(sample int) float64 {
+		if sampleWeight == nil {
+			return 1
+		}
+		return sampleWeight[sample]
+	}
 	epoch := 0
 	changed := true
 	unchangeCount := 0
@@ -74,6 +87,12 @@ func (m *KMeans) Fit(X, Y *mat.Dense) base.Transformer {
 		changed = false
 		// find nearest centroids
 		m.predictInternal(X, NearestCentroid, CentroidCount, &changed)
+		for ic := range CentroidWeight {
+			CentroidWeight[ic] = 0
+		}
+		for sample, ic := range NearestCentroid {
+			CentroidWeight[ic] += weight(sample)
+		}
 		// recompute centroids
 		m.Centroids.Sub(m.Centroids, m.Centroids)
 		var mu sync.Mutex
@@ -81,10 +100,14 @@ func (m *KMeans) Fit(X, Y *mat.Dense) base.Transformer {
 			row := make([]float64, NFeatures, NFeatures)
 			for sample := start; sample < end; sample++ {
 				ic := NearestCentroid[sample]
+				w := weight(sample)
+				if w == 0 {
+					continue
+				}
 				mu.Lock()
 				c := m.Centroids.RowView(ic)
 				mat.Row(row, sample, X)
-				c.(*mat.VecDense).AddScaledVec(c, 1./float64(CentroidCount[ic]), mat.NewVecDense(NFeatures, row))
+				c.(*mat.VecDense).AddScaledVec(c, w/CentroidWeight[ic], mat.NewVecDense(NFeatures, row))
 				mu.Unlock()
 			}
 		})
@@ -94,7 +117,43 @@ func (m *KMeans) Fit(X, Y *mat.Dense) base.Transformer {
 			unchangeCount++
 		}
 	}
-	return m
+}
+
+// FitE is Fit returning an error instead of panicking. Y is ignored
+func (m *KMeans) FitE(X, Y *mat.Dense) error {
+	return m.FitWeighted(X, Y, nil)
+}
+
+// FitWeighted is FitE with centroids computed as the means of their samples weighted by sampleWeight. Y is ignored
+func (m *KMeans) FitWeighted(X, Y *mat.Dense, sampleWeight []float64) error {
+	if err := base.CheckXY("KMeans.Fit", X, nil); err != nil {
+		return err
+	}
+	if m.NClusters <= 0 {
+		return &base.ParamError{Estimator: m, Param: "NClusters", Value: m.NClusters, Msg: "must be > 0"}
+	}
+	if NSamples, _ := X.Dims(); NSamples < m.NClusters {
+		return &base.ParamError{Estimator: m, Param: "NClusters", Value: m.NClusters, Msg: fmt.Sprintf("NSamples<m.NClusters %d<%d", NSamples, m.NClusters)}
+	}
+	if err := base.CheckSampleWeight("KMeans.FitWeighted", X, sampleWeight); err != nil {
+		return err
+	}
+	return base.Recover(func() { m.fit(X, sampleWeight) })
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *KMeans) PredictE(X, Y *mat.Dense) error {
+	if m.Centroids == nil {
+		return &base.NotFittedError{Estimator: m}
+	}
+	if err := base.CheckFitXY("KMeans.Predict", X, Y); err != nil {
+		return err
+	}
+	_, NFeatures := m.Centroids.Dims()
+	if err := base.CheckNFeatures("KMeans.Predict", X, NFeatures); err != nil {
+		return err
+	}
+	return base.Recover(func() { m.Predict(X, Y) })
 }
 
 // Transform for pipeline
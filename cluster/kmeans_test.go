@@ -92,3 +92,15 @@ func ExampleKMeans() {
 	}
 	// Output:
 }
+
+func ExampleKMeans_FitWeighted() {
+	X := mat.NewDense(6, 1, []float64{0, 10, 1, 2, 11, 12})
+	kmeans := &KMeans{NClusters: 2}
+	// weighing 2 as 4 samples moves the first centroid from 1 to (0+1+4*2)/6
+	if err := kmeans.FitWeighted(X, nil, []float64{1, 1, 1, 4, 1, 1}); err != nil {
+		panic(err)
+	}
+	fmt.Printf("%.3f\n", mat.Formatted(kmeans.Centroids.T()))
+	// Output:
+	// [ 1.500  11.000]
+}
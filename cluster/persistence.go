@@ -0,0 +1,56 @@
+package cluster
+
+import (
+	"io"
+	"reflect"
+
+	"github.com/pa-m/sklearn/base"
+)
+
+func init() {
+	base.Register(&KMeans{})
+	base.Register(&DBSCAN{})
+}
+
+// Restore restores Distance after Load
+func (m *KMeans) Restore() error {
+	if m.Distance == nil {
+		m.Distance = EuclideanDistance
+	}
+	return nil
+}
+
+// Save writes the KMeans to w. it fails for a custom Distance, which can't be saved
+func (m *KMeans) Save(w io.Writer) error { return base.Save(w, m) }
+
+// Load reads a KMeans written by Save
+func (m *KMeans) Load(r io.Reader) error { return base.LoadInto(r, m) }
+
+// GobEncode encodes KMeans. it returns a *base.ParamError for a Distance other than EuclideanDistance
+func (m *KMeans) GobEncode() ([]byte, error) {
+	if m.Distance != nil && reflect.ValueOf(m.Distance).Pointer() != reflect.ValueOf(EuclideanDistance).Pointer() {
+		return nil, &base.ParamError{Estimator: m, Param: "Distance", Value: "custom", Msg: "only EuclideanDistance can be saved"}
+	}
+	type kMeans KMeans
+	return base.GobEncodeFields((*kMeans)(m))
+}
+
+// GobDecode decodes a KMeans encoded by GobEncode
+func (m *KMeans) GobDecode(b []byte) error {
+	type kMeans KMeans
+	return base.GobDecodeFields(b, (*kMeans)(m))
+}
+
+// Restore restores NeighborsModel distance after Load
+func (m *DBSCAN) Restore() error {
+	if m.NeighborsModel == nil {
+		return nil
+	}
+	return m.NeighborsModel.Restore()
+}
+
+// Save writes the DBSCAN to w
+func (m *DBSCAN) Save(w io.Writer) error { return base.Save(w, m) }
+
+// Load reads a DBSCAN written by Save
+func (m *DBSCAN) Load(r io.Reader) error { return base.LoadInto(r, m) }
@@ -0,0 +1,42 @@
+package cluster
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"gonum.org/v1/gonum/mat"
+)
+
+func ExampleKMeans_Save() {
+	ds := datasets.LoadIris()
+	NSamples, _ := ds.X.Dims()
+	kmeans := &KMeans{NClusters: 3}
+	kmeans.Fit(ds.X, nil)
+	var buf bytes.Buffer
+	if err := kmeans.Save(&buf); err != nil {
+		fmt.Println(err)
+	}
+	loaded := &KMeans{}
+	if err := loaded.Load(&buf); err != nil {
+		fmt.Println(err)
+	}
+	Ypred, Yloaded := mat.NewDense(NSamples, 1, nil), mat.NewDense(NSamples, 1, nil)
+	kmeans.Predict(ds.X, Ypred)
+	loaded.Predict(ds.X, Yloaded)
+	fmt.Println(mat.Equal(Ypred, Yloaded))
+	// Output:
+	// true
+}
+
+func TestKMeansSaveCustomDistance(t *testing.T) {
+	kmeans := &KMeans{NClusters: 2, Distance: MinkowskiDistance(1)}
+	kmeans.Fit(mat.NewDense(4, 1, []float64{0, 1, 2, 3}), nil)
+	var paramErr *base.ParamError
+	if err := kmeans.Save(&bytes.Buffer{}); !errors.As(err, &paramErr) || paramErr.Param != "Distance" {
+		t.Errorf("expected a *base.ParamError for a custom Distance, got %v", err)
+	}
+}
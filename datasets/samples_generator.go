@@ -3,14 +3,12 @@ package datasets
 import (
 	"math"
 	"math/rand"
-	"runtime"
 	"sort"
 
 	"github.com/pa-m/sklearn/base"
 	"github.com/pa-m/sklearn/preprocessing"
 	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
-	"gonum.org/v1/gonum/stat/distmv"
 )
 
 // MakeRegression Generate a random regression problem
@@ -23,9 +21,9 @@ import (
 // tail_strength : float between 0.0 and 1.0, optional (default=0.5) currently unused
 // shuffle : boolean, optional (default=True)
 // coef : boolean. the coefficients of the underlying linear model are returned regardless its value.
-// random_state : *math.Rand optional (default=nil)
+// random_state : *base.RandomState, *rand.Rand or int64 seed, optional (default=nil)
 func MakeRegression(kwargs map[string]interface{}) (X, y, Coef *mat.Dense) {
-	rnd := func() float64 { return rand.NormFloat64() }
+	var randomState *base.RandomState
 	var nSamples, nFeatures, nInformative, nTargets, Shuffle = 100, 100, 10, 1, true
 	if v, ok := kwargs["n_samples"]; ok {
 		nSamples = v.(int)
@@ -40,8 +38,18 @@ func MakeRegression(kwargs map[string]interface{}) (X, y, Coef *mat.Dense) {
 		nTargets = v.(int)
 	}
 	if v, ok := kwargs["random_state"]; ok {
-		rnd = func() float64 { return v.(*rand.Rand).NormFloat64() }
+		switch rs := v.(type) {
+		case *base.RandomState:
+			randomState = rs
+		case *rand.Rand:
+			randomState = base.NewRandomStateFromRand(rs)
+		case int64:
+			randomState = base.NewRandomState(rs)
+		case int:
+			randomState = base.NewRandomState(int64(rs))
+		}
 	}
+	rnd := base.CheckRandomState(randomState).NormFloat64
 	X = mat.NewDense(nSamples, nFeatures, nil)
 	if !Shuffle {
 		col := make([]float64, nSamples)
@@ -108,13 +116,14 @@ type MakeBlobsConfig struct {
 	ClusterStd  float64
 	CenterBox   []float64
 	Shuffle     bool
-	RandomState *rand.Rand
+	RandomState *base.RandomState
 }
 
 // MakeBlobs Generate isotropic Gaussian blobs for clustering
 // config may be null or preintialised
 // config.Centers may be and int or a mat.Matrix
 // unlinke scikit-learn's make_blob, Shuffle is false by default
+// all numbers are drawn from config.RandomState (see base.CheckRandomState)
 func MakeBlobs(config *MakeBlobsConfig) (X, Y *mat.Dense) {
 	if config == nil {
 		config = &MakeBlobsConfig{}
@@ -144,12 +153,8 @@ func MakeBlobs(config *MakeBlobsConfig) (X, Y *mat.Dense) {
 	if config.CenterBox == nil {
 		config.CenterBox = []float64{-10, 10}
 	}
-	randNormFloat64 := rand.NormFloat64
-	randIntn := rand.Intn
-	if config.RandomState != nil {
-		randNormFloat64 = config.RandomState.NormFloat64
-		randIntn = config.RandomState.Intn
-	}
+	randomState := base.CheckRandomState(config.RandomState)
+	randNormFloat64 := randomState.NormFloat64
 	if randomizeCenters {
 		boxCenter := (config.CenterBox[0] + config.CenterBox[1]) / 2
 		boxRadius := math.Abs(config.CenterBox[1]-config.CenterBox[0]) / 2
@@ -167,25 +172,18 @@ func MakeBlobs(config *MakeBlobsConfig) (X, Y *mat.Dense) {
 
 	X = mat.NewDense(config.NSamples, config.NFeatures, nil)
 	Y = mat.NewDense(config.NSamples, 1, nil)
-	base.Parallelize(runtime.NumCPU(), config.NSamples, func(th, start, end int) {
-		mu := make([]float64, config.NFeatures)
-		sigma := mat.NewSymDense(config.NFeatures, nil)
-
-		for i := 0; i < config.NFeatures; i++ {
-			sigma.SetSym(i, i, 1)
+	// samples are drawn in sequence so that they only depend on RandomState
+	for sample := 0; sample < config.NSamples; sample++ {
+		cluster := randomState.Intn(NCenters)
+		Y.Set(sample, 0, float64(cluster))
+		Xrow := X.RawRowView(sample)
+		for feature := range Xrow {
+			Xrow[feature] = randNormFloat64() * config.ClusterStd
 		}
-		sigma.ScaleSym(config.ClusterStd*config.ClusterStd, sigma)
-		normal, _ := distmv.NewNormal(mu, sigma, nil)
-		_ = normal
-		for sample := start; sample < end; sample++ {
-			cluster := randIntn(NCenters)
-			Y.Set(sample, 0, float64(cluster))
-			normal.Rand(X.RawRowView(sample))
-			floats.Add(X.RawRowView(sample), Centers.RawRowView(cluster))
-		}
-	})
+		floats.Add(Xrow, Centers.RawRowView(cluster))
+	}
 	if config.Shuffle {
-		X, Y = preprocessing.NewShuffler().FitTransform(X, Y)
+		X, Y = (&preprocessing.Shuffler{RandomState: randomState}).FitTransform(X, Y)
 	}
 	return
 }
@@ -2,6 +2,10 @@ package datasets
 
 import (
 	"fmt"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
 )
 
 func ExampleMakeRegression() {
@@ -27,3 +31,14 @@ func ExampleMakeBlobs() {
 	// Output:
 	// rx=100 cx=2 ry=100 cy=1
 }
+
+func TestMakeBlobsRandomState(t *testing.T) {
+	makeBlobs := func() (X, Y *mat.Dense) {
+		return MakeBlobs(&MakeBlobsConfig{NSamples: 50, Centers: 3, Shuffle: true, RandomState: base.NewRandomState(7)})
+	}
+	X1, Y1 := makeBlobs()
+	X2, Y2 := makeBlobs()
+	if !mat.Equal(X1, X2) || !mat.Equal(Y1, Y2) {
+		t.Errorf("blobs with the same RandomState differ")
+	}
+}
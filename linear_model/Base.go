@@ -1,6 +1,7 @@
 package linearmodel
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -11,7 +12,6 @@ import (
 
 	//"gonum.org/v1/gonum/diff/fd"
 	"math"
-	"math/rand"
 	"runtime"
 
 	"gonum.org/v1/gonum/mat"
@@ -56,6 +56,7 @@ type LinearRegression struct {
 }
 
 // RegularizedRegression is a common structure for ElasticNet,Lasso and Ridge
+// RandomState draws the initial coefficients and the shuffles of each epoch
 type RegularizedRegression struct {
 	LinearRegression
 	Solver              string
@@ -63,6 +64,7 @@ type RegularizedRegression struct {
 	Tol, Alpha, L1Ratio float64
 	LossFunction        Loss
 	ActivationFunction  Activation
+	RandomState         *base.RandomState
 	Options             LinFitOptions
 }
 
@@ -83,20 +85,49 @@ func (regr *LinearRegression) Clone() base.Transformer {
 
 // Fit fits Coef for a LinearRegression
 func (regr *LinearRegression) Fit(X0, Y0 *mat.Dense) base.Transformer {
+	regr.fit(X0, Y0, nil)
+	return regr
+}
+
+func (regr *LinearRegression) fit(X0, Y0 *mat.Dense, sampleWeight []float64) {
 	var X, Y, YOffset *mat.Dense
-	X, Y, regr.XOffset, YOffset, regr.XScale = PreprocessData(X0, Y0, regr.FitIntercept, regr.Normalize, nil)
+	X, Y, regr.XOffset, YOffset, regr.XScale = PreprocessData(X0, Y0, regr.FitIntercept, regr.Normalize, weightVec(sampleWeight))
+	if sampleWeight != nil {
+		X, Y = rescaleRows(X, Y, sampleWeight)
+	}
 	// use least squares
 	regr.Coef = &mat.Dense{}
 	regr.Coef.Solve(X, Y)
 	regr.LinearModel.setIntercept(regr.XOffset, YOffset, regr.XScale)
-	return regr
 }
 
 // Fit fits Coef for a LinearRegression
 func (regr *RegularizedRegression) Fit(X0, Y0 *mat.Dense) base.Transformer {
-	var X, Y, YOffset *mat.Dense
-	X, Y, regr.XOffset, YOffset, regr.XScale = PreprocessData(X0, Y0, regr.FitIntercept, regr.Normalize, nil)
+	if err := regr.fit(context.Background(), X0, Y0, nil); err != nil {
+		panic(err)
+	}
+	return regr
+}
+
+// FitContext is FitE stopping between epochs when ctx is done. it reports the loss of each epoch to the
+// ProgressCallback of ctx
+func (regr *RegularizedRegression) FitContext(ctx context.Context, X, Y *mat.Dense) error {
+	if err := regr.checkFit(X, Y); err != nil {
+		return err
+	}
+	var err error
+	if rerr := base.Recover(func() { err = regr.fit(ctx, X, Y, nil) }); rerr != nil {
+		return rerr
+	}
+	return err
+}
+
+func (regr *RegularizedRegression) fit(ctx context.Context, X0 mat.Matrix, Y0 *mat.Dense, sampleWeight []float64) error {
+	var X mat.Matrix
+	var Y, YOffset *mat.Dense
+	X, Y, regr.XOffset, YOffset, regr.XScale = preprocess(X0, Y0, regr.FitIntercept, regr.Normalize, sampleWeight)
 	opt := regr.Options
+	opt.SampleWeight = sampleWeight
 	opt.Tol = regr.Tol
 	opt.Solver = regr.Solver
 	opt.SolverConfigure = regr.SolverConfigure
@@ -104,10 +135,52 @@ func (regr *RegularizedRegression) Fit(X0, Y0 *mat.Dense) base.Transformer {
 	opt.Activation = regr.ActivationFunction
 	opt.Alpha = regr.Alpha
 	opt.L1Ratio = regr.L1Ratio
+	opt.RandomState = regr.RandomState
+	opt.Context, opt.Estimator = ctx, regr
 	res := LinFit(X, Y, &opt)
+	if res.Err != nil {
+		return res.Err
+	}
 	regr.Coef = res.Theta
 	regr.LinearModel.setIntercept(regr.XOffset, YOffset, regr.XScale)
-	return regr
+	return nil
+}
+
+// FitE is Fit returning an error instead of panicking
+func (regr *LinearRegression) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckFitXY("LinearRegression.Fit", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { regr.Fit(X, Y) })
+}
+
+// FitE is Fit returning an error instead of panicking
+func (regr *RegularizedRegression) FitE(X, Y *mat.Dense) error {
+	if err := regr.checkFit(X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { regr.Fit(X, Y) })
+}
+
+func (regr *RegularizedRegression) checkFit(X, Y *mat.Dense) error {
+	if err := base.CheckFitXY("RegularizedRegression.Fit", X, Y); err != nil {
+		return err
+	}
+	if err := checkAlphaL1Ratio(regr, regr.Alpha, regr.L1Ratio); err != nil {
+		return err
+	}
+	if _, ok := base.Solvers[regr.Solver]; regr.Solver != "" && !ok {
+		return &base.ParamError{Estimator: regr, Param: "Solver", Value: regr.Solver}
+	}
+	return nil
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (regr *LinearRegression) PredictE(X, Y *mat.Dense) error {
+	if err := regr.checkPredict(regr, "LinearRegression.Predict", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { regr.Predict(X, Y) })
 }
 
 // Predict predicts y for X using Coef
@@ -135,17 +208,25 @@ func (regr *LinearRegression) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense)
 
 // SGDRegressor base struct
 // should  be named GonumOptimizeRegressor
-// implemented as a per-output optimization of (possibly regularized) square-loss with gonum/optimize methods
+// implemented as a per-output optimization of (possibly regularized) square-loss with gonum/optimize methods.
+// Loss is squared_loss (default), or huber, epsilon_insensitive or squared_epsilon_insensitive which are fitted by
+// stochastic gradient descent with SGDParams, EarlyStopping and WarmStart included. PartialFit runs an epoch of
+// stochastic gradient descent for any Loss
 type SGDRegressor struct {
 	LinearModel
+	SGDParams
 	Tol, Alpha, L1Ratio float
 	NJobs               int
 	Method              optimize.Method
+	Loss                string
+	SGDResult           SGDResult
 }
 
 // NewSGDRegressor creates a *SGDRegressor with defaults
 func NewSGDRegressor() *SGDRegressor {
-	regr := &SGDRegressor{Tol: 1e-4, Alpha: 0.0001, L1Ratio: 0.15, NJobs: 1, Method: &optimize.LBFGS{}}
+	regr := &SGDRegressor{Tol: 1e-4, Alpha: 0.0001, L1Ratio: 0.15, NJobs: 1, Method: &optimize.LBFGS{}, Loss: "squared_loss", SGDParams: SGDParams{
+		Penalty: "l2", MaxIter: 1000, Shuffle: true, Epsilon: .1, LearningRate: "invscaling", Eta0: .01, PowerT: .25, NIterNoChange: 5, ValidationFraction: .1,
+	}}
 	regr.FitIntercept = true
 	//regr.RegressorMixin1.Predicter = regr
 	return regr
@@ -159,8 +240,32 @@ func (regr *SGDRegressor) Clone() base.Transformer {
 
 // Fit learns Coef
 func (regr *SGDRegressor) Fit(X0, y0 *mat.Dense) base.Transformer {
-	var X, Y, YOffset *mat.Dense
-	X, Y, regr.XOffset, YOffset, regr.XScale = PreprocessData(X0, y0, regr.FitIntercept, regr.Normalize, nil)
+	if err := regr.fit(context.Background(), X0, y0, nil); err != nil {
+		panic(err)
+	}
+	return regr
+}
+
+// FitContext is FitE stopping the optimization when ctx is done. it reports the loss of each major iteration
+// to the ProgressCallback of ctx
+func (regr *SGDRegressor) FitContext(ctx context.Context, X, Y *mat.Dense) error {
+	if err := regr.checkFit(X, Y); err != nil {
+		return err
+	}
+	return regr.fitE(ctx, X, Y, nil)
+}
+
+func (regr *SGDRegressor) fit(ctx context.Context, X0 mat.Matrix, y0 *mat.Dense, sampleWeight []float64) error {
+	if regr.Loss != "" && regr.Loss != "squared_loss" {
+		return regr.fitSGD(ctx, X0, y0, sampleWeight, false)
+	}
+	regr.SGDResult = SGDResult{}
+	var X mat.Matrix
+	var Y, YOffset *mat.Dense
+	X, Y, regr.XOffset, YOffset, regr.XScale = preprocess(X0, y0, regr.FitIntercept, regr.Normalize, sampleWeight)
+	if sampleWeight != nil {
+		X, Y = rescaleRows(X.(*mat.Dense), Y, sampleWeight)
+	}
 	// begin use gonum gradientDescent
 	nSamples, nFeatures := X.Dims()
 	_, nOutputs := Y.Dims()
@@ -171,7 +276,7 @@ func (regr *SGDRegressor) Fit(X0, y0 *mat.Dense) base.Transformer {
 		regr.Coef.SetCol(o, coefSlice)
 		tmp := mat.NewDense(nSamples, 1, nil)
 		// e will be sum of squares of errors
-		tmp.Mul(X, regr.Coef.ColView(o))
+		mulTo(tmp, X, regr.Coef.ColView(o))
 		tmp.Sub(tmp, Y.ColView(o))
 		tmp.MulElem(tmp, tmp)
 		e := mat.Sum(tmp) / 2. / float(nSamples)
@@ -214,10 +319,10 @@ func (regr *SGDRegressor) Fit(X0, y0 *mat.Dense) base.Transformer {
 			// X dot Ydiff+ alpha*l1ratio*sign+alpha*(1-l1ratio)*coef
 			tmp := mat.NewDense(nSamples, 1, nil)
 			regr.Coef.SetCol(o, coef)
-			tmp.Mul(X, regr.Coef.ColView(o)) // X dot coef
+			mulTo(tmp, X, regr.Coef.ColView(o)) // X dot coef
 			tmp.Sub(tmp, Y.ColView(o))       // Ydiff
 			gradmat := mat.NewDense(nFeatures, 1, nil)
-			gradmat.Mul(X.T(), tmp) // X dot Ydiff
+			mulTo(gradmat, X.T(), tmp) // X dot Ydiff
 			al1 := regr.Alpha * regr.L1Ratio / float(nSamples)
 			al2 := regr.Alpha * (1. - regr.L1Ratio) / float(nSamples)
 			sgn := func(x float) float {
@@ -254,9 +359,13 @@ func (regr *SGDRegressor) Fit(X0, y0 *mat.Dense) base.Transformer {
 		// printer := NewPrinter()
 		// printer.HeadingInterval = 1
 		// settings.Recorder = printer
+		settings.Recorder = &base.ContextRecorder{Context: ctx, Estimator: regr}
 
 		method := regr.Method
 		res, err := optimize.Minimize(p, initialcoefs, settings, method)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
 		unused(err)
 		//fmt.Printf("res=%s %#v\n", res.Status.String(), res)
 		// if err != nil && err.Error() != "linesearch: no change in location after Linesearcher step" {
@@ -269,7 +378,55 @@ func (regr *SGDRegressor) Fit(X0, y0 *mat.Dense) base.Transformer {
 	// end use gonum gradient gradientDescent
 	regr.setIntercept(regr.XOffset, YOffset, regr.XScale)
 
-	return regr
+	return nil
+}
+
+// FitE is Fit returning an error instead of panicking.
+// a *base.ConvergenceError is returned if the stochastic gradient descent of a Loss other than squared_loss
+// still improved by Tol after MaxIter epochs, the model is still usable
+func (regr *SGDRegressor) FitE(X, Y *mat.Dense) error {
+	if err := regr.checkFit(X, Y); err != nil {
+		return err
+	}
+	return regr.fitE(context.Background(), X, Y, nil)
+}
+
+func (regr *SGDRegressor) fitE(ctx context.Context, X, Y *mat.Dense, sampleWeight []float64) error {
+	var err error
+	if rerr := base.Recover(func() { err = regr.fit(ctx, X, Y, sampleWeight) }); rerr != nil {
+		return rerr
+	}
+	if err == nil && regr.SGDResult.NIter > 0 && !regr.SGDResult.Converged {
+		err = &base.ConvergenceError{Estimator: regr, NIter: regr.SGDResult.NIter, Msg: fmt.Sprintf("the loss still improves by Tol %g, increase MaxIter", regr.Tol)}
+	}
+	return err
+}
+
+func (regr *SGDRegressor) checkFit(X, Y *mat.Dense) error {
+	if err := base.CheckFitXY("SGDRegressor.Fit", X, Y); err != nil {
+		return err
+	}
+	if err := checkAlphaL1Ratio(regr, regr.Alpha, regr.L1Ratio); err != nil {
+		return err
+	}
+	switch regr.Loss {
+	case "", "squared_loss":
+		if regr.Method == nil {
+			return &base.ParamError{Estimator: regr, Param: "Method", Value: regr.Method}
+		}
+		return nil
+	case "huber", "epsilon_insensitive", "squared_epsilon_insensitive":
+		return regr.SGDParams.check(regr, regr.Alpha, regr.L1Ratio)
+	}
+	return &base.ParamError{Estimator: regr, Param: "Loss", Value: regr.Loss, Msg: "must be squared_loss, huber, epsilon_insensitive or squared_epsilon_insensitive"}
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (regr *SGDRegressor) PredictE(X, Y *mat.Dense) error {
+	if err := regr.checkPredict(regr, "SGDRegressor.Predict", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { regr.Predict(X, Y) })
 }
 
 // Predict predicts y from X using Coef
@@ -314,14 +471,23 @@ type LinFitOptions struct {
 	Recorder                            optimize.Recorder
 	PerOutputFit                        bool
 	DisableRegularizationOfFirstFeature bool
+	// Context, if not nil, stops the fit when done. the loss of each epoch of Estimator is reported to its ProgressCallback
+	Context   context.Context
+	Estimator interface{}
+	// RandomState draws the initial Theta and the shuffles of each epoch (see base.CheckRandomState)
+	RandomState *base.RandomState
+	// SampleWeight, if not nil, weighs the loss of each row of X
+	SampleWeight []float64
 }
 
 // LinFitResult is the result or LinFit
+// Err is Context.Err() when the fit was stopped by its Context
 type LinFitResult struct {
 	Converged bool
 	RMSE, J   float64
 	Epoch     int
 	Theta     *mat.Dense
+	Err       error
 }
 
 func initRecorder(recorder optimize.Recorder) (err error) {
@@ -334,7 +500,7 @@ func initRecorder(recorder optimize.Recorder) (err error) {
 }
 
 // LinFit is an internal helper to fit linear regressions
-func LinFit(X, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
+func LinFit(X mat.Matrix, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
 	nSamples, nFeatures := X.Dims()
 	_, nOutputs := Ytrue.Dims()
 	if opts.GOMethodCreator == nil && opts.Solver == "" {
@@ -352,11 +518,12 @@ func LinFit(X, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
 	gradSlice := make([]float64, nFeatures*nOutputs, nFeatures*nOutputs)
 	grad := mat.NewDense(nFeatures, nOutputs, gradSlice)
 
+	randomState := base.CheckRandomState(opts.RandomState)
 	if opts.ThetaInitializer != nil {
 		opts.ThetaInitializer(Theta)
 	} else {
 		Theta.Apply(func(i, j int, v float64) float64 {
-			return 0.01 * rand.Float64()
+			return 0.01 * randomState.Float64()
 		}, Theta)
 	}
 
@@ -377,6 +544,11 @@ func LinFit(X, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
 		opts.Activation = base.Identity{}
 	}
 
+	loss := opts.Loss
+	if opts.SampleWeight != nil {
+		loss = weightedLoss(opts.Loss, opts.SampleWeight)
+	}
+
 	YpredMini := mat.NewDense(miniBatchSize, nOutputs, nil)
 	YdiffMini := mat.NewDense(miniBatchSize, nOutputs, nil)
 
@@ -405,8 +577,25 @@ func LinFit(X, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
 			&optimize.Stats{MajorIterations: epoch, FuncEvaluations: epoch, GradEvaluations: epoch, Runtime: time.Since(start)})
 	}
 	for epoch = 1; epoch <= opts.Epochs && !converged; epoch++ {
-		shuffler := preprocessing.NewShuffler()
-		Xs, Ys := shuffler.FitTransform(X, Ytrue)
+		if err := base.ContextErr(opts.Context); err != nil {
+			return &LinFitResult{RMSE: rmse, J: JBest, Epoch: epoch - 1, Theta: mat.NewDense(nFeatures, nOutputs, thetaSliceBest), Err: err}
+		}
+		var Xs mat.Matrix
+		var Ys *mat.Dense
+		var Ws []float64
+		if Xd, ok := X.(*mat.Dense); ok && opts.SampleWeight == nil {
+			shuffler := &preprocessing.Shuffler{RandomState: randomState}
+			Xs, Ys = shuffler.FitTransform(Xd, Ytrue)
+		} else {
+			perm := randomState.Perm(nSamples)
+			Xs, Ys = takeRows(X, perm), takeRows(Ytrue, perm).(*mat.Dense)
+			if opts.SampleWeight != nil {
+				Ws = make([]float64, nSamples)
+				for k, i := range perm {
+					Ws[k] = opts.SampleWeight[i]
+				}
+			}
+		}
 		for miniBatch := 0; miniBatch*miniBatchSize < nSamples; miniBatch++ {
 			miniBatchStart = miniBatch * miniBatchSize
 			miniBatchEnd := miniBatchStart + miniBatchSize
@@ -414,10 +603,14 @@ func LinFit(X, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
 				miniBatchEnd = nSamples
 			}
 			miniBatchRows := miniBatchEnd - miniBatchStart
+			miniBatchLoss := opts.Loss
+			if Ws != nil {
+				miniBatchLoss = weightedLoss(opts.Loss, Ws[miniBatchStart:miniBatchEnd])
+			}
 
-			J = opts.Loss(
+			J = miniBatchLoss(
 				Ys.Slice(miniBatchStart, miniBatchEnd, 0, nOutputs),
-				Xs.Slice(miniBatchStart, miniBatchEnd, 0, nFeatures),
+				sliceRows(Xs, miniBatchStart, miniBatchEnd),
 				Theta,
 				YpredMini.Slice(0, miniBatchRows, 0, nOutputs).(*mat.Dense),
 				YdiffMini.Slice(0, miniBatchRows, 0, nOutputs).(*mat.Dense),
@@ -425,7 +618,7 @@ func LinFit(X, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
 				opts.Alpha, opts.L1Ratio, nSamples, opts.Activation, opts.DisableRegularizationOfFirstFeature)
 			s.UpdateParams(grad)
 		}
-		J = opts.Loss(
+		J = loss(
 			Ytrue,
 			X,
 			Theta,
@@ -440,6 +633,7 @@ func LinFit(X, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
 		rmse = math.Sqrt(metrics.MeanSquaredError(Ytrue, Ypred, nil, "").At(0, 0))
 
 		converged = math.Sqrt(rmse) < opts.Tol
+		base.ReportProgress(opts.Context, base.Progress{Estimator: opts.Estimator, Epoch: epoch, Epochs: opts.Epochs, Loss: J, ValidationScore: math.NaN(), Elapsed: time.Since(start)})
 		//fmt.Println(epoch, J)
 		if hasRecorder {
 			opts.Recorder.Record(
@@ -454,7 +648,7 @@ func LinFit(X, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
 }
 
 // LinFitGOM fits a regression with a gonum/optimizer Method
-func LinFitGOM(X, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
+func LinFitGOM(X mat.Matrix, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
 	nSamples, nFeatures := X.Dims()
 	_, nOutputs := Ytrue.Dims()
 
@@ -464,6 +658,10 @@ func LinFitGOM(X, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
 	if opts.Activation == nil {
 		opts.Activation = base.Identity{}
 	}
+	loss := opts.Loss
+	if opts.SampleWeight != nil {
+		loss = weightedLoss(opts.Loss, opts.SampleWeight)
+	}
 
 	converged := false
 	if opts.Epochs <= 0 {
@@ -472,6 +670,9 @@ func LinFitGOM(X, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
 	fSettings := func() *optimize.Settings {
 		settings := &optimize.Settings{}
 		settings.Recorder = opts.Recorder
+		if opts.Context != nil {
+			settings.Recorder = &base.ContextRecorder{Context: opts.Context, Estimator: opts.Estimator, Epochs: opts.Epochs, Recorder: opts.Recorder}
+		}
 		settings.GradientThreshold = 1e-12
 		settings.FuncEvaluations = opts.Epochs
 		settings.Concurrent = runtime.NumCPU()
@@ -481,8 +682,9 @@ func LinFitGOM(X, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
 
 	theta := make([]float64, nFeatures*nOutputs, nFeatures*nOutputs)
 	thetaM := mat.NewDense(nFeatures, nOutputs, theta)
+	randomState := base.CheckRandomState(opts.RandomState)
 	for j := 0; j < len(theta); j++ {
-		theta[j] = 0.01 * rand.NormFloat64()
+		theta[j] = 0.01 * randomState.NormFloat64()
 	}
 	var ret *optimize.Result
 	var err error
@@ -503,7 +705,7 @@ func LinFitGOM(X, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
 			thetao := make([]float64, nFeatures, nFeatures)
 			p := optimize.Problem{
 				Func: func(thetao []float64) float64 {
-					J := opts.Loss(Ytrue.ColView(o), X, mat.NewDense(nFeatures, 1, thetao), Ypred, Ydiff, nil, opts.Alpha, opts.L1Ratio, nSamples, opts.Activation, opts.DisableRegularizationOfFirstFeature)
+					J := loss(Ytrue.ColView(o), X, mat.NewDense(nFeatures, 1, thetao), Ypred, Ydiff, nil, opts.Alpha, opts.L1Ratio, nSamples, opts.Activation, opts.DisableRegularizationOfFirstFeature)
 					return J
 				},
 				Grad: func(grad, thetao []float64) []float64 {
@@ -514,7 +716,7 @@ func LinFitGOM(X, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
 						panic("incorrect size of the gradient")
 					}
 
-					opts.Loss(Ytrue.ColView(o), X, mat.NewDense(nFeatures, 1, thetao), Ypred, Ydiff, mat.NewDense(nFeatures, 1, grad), opts.Alpha, opts.L1Ratio, nSamples, opts.Activation, opts.DisableRegularizationOfFirstFeature)
+					loss(Ytrue.ColView(o), X, mat.NewDense(nFeatures, 1, thetao), Ypred, Ydiff, mat.NewDense(nFeatures, 1, grad), opts.Alpha, opts.L1Ratio, nSamples, opts.Activation, opts.DisableRegularizationOfFirstFeature)
 					return grad
 				},
 			}
@@ -544,14 +746,14 @@ func LinFitGOM(X, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
 		p := optimize.Problem{
 			Func: func(theta []float64) float64 {
 
-				J := opts.Loss(Ytrue, X, mat.NewDense(nFeatures, nOutputs, theta), Ypred, Ydiff, nil, opts.Alpha, opts.L1Ratio, nSamples, opts.Activation, opts.DisableRegularizationOfFirstFeature)
+				J := loss(Ytrue, X, mat.NewDense(nFeatures, nOutputs, theta), Ypred, Ydiff, nil, opts.Alpha, opts.L1Ratio, nSamples, opts.Activation, opts.DisableRegularizationOfFirstFeature)
 				return J
 			},
 			Grad: func(grad, theta []float64) []float64 {
 				if grad == nil {
 					grad = make([]float64, len(theta))
 				}
-				opts.Loss(Ytrue, X, mat.NewDense(nFeatures, nOutputs, theta), Ypred, Ydiff, mat.NewDense(nFeatures, nOutputs, grad), opts.Alpha, opts.L1Ratio, nSamples, opts.Activation, opts.DisableRegularizationOfFirstFeature)
+				loss(Ytrue, X, mat.NewDense(nFeatures, nOutputs, theta), Ypred, Ydiff, mat.NewDense(nFeatures, nOutputs, grad), opts.Alpha, opts.L1Ratio, nSamples, opts.Activation, opts.DisableRegularizationOfFirstFeature)
 				return grad
 			},
 		}
@@ -563,16 +765,15 @@ func LinFitGOM(X, Ytrue *mat.Dense, opts *LinFitOptions) *LinFitResult {
 	}
 	//fmt.Printf("ret:%#v\nstatus:%s\n", ret, ret.Status)
 	converged = err == nil
-	return &LinFitResult{Converged: converged, RMSE: rmse, Epoch: epoch, Theta: thetaM}
+	return &LinFitResult{Converged: converged, RMSE: rmse, Epoch: epoch, Theta: thetaM, Err: base.ContextErr(opts.Context)}
 }
 
 var copyStruct = base.CopyStruct
 
 func (regr *LinearModel) setIntercept(XOffset, YOffset, XScale mat.Matrix) {
 	_, nOutputs := regr.Coef.Dims()
-	if regr.Intercept == nil {
-		regr.Intercept = mat.NewDense(1, nOutputs, nil)
-	}
+	// a new Intercept, the previous one may be shared with a clone
+	regr.Intercept = mat.NewDense(1, nOutputs, nil)
 
 	regr.Coef.Apply(func(j, o int, coef float64) float64 { return coef / XScale.At(0, j) }, regr.Coef)
 	if regr.FitIntercept {
@@ -606,9 +807,38 @@ func chkdims(op string, R, X, Y mat.Matrix) {
 	}
 }
 
+// checkPredict returns an error if the model is not fitted or if X,Y are not suitable for prediction
+func (regr *LinearModel) checkPredict(m interface{}, op string, X, Y *mat.Dense) error {
+	if regr.Coef == nil {
+		return &base.NotFittedError{Estimator: m}
+	}
+	if err := base.CheckXY(op, X, Y); err != nil {
+		return err
+	}
+	if Y == nil {
+		return &base.ShapeError{Op: op, Msg: "Y is nil"}
+	}
+	nFeatures, _ := regr.Coef.Dims()
+	return base.CheckNFeatures(op, X, nFeatures)
+}
+
+func checkAlphaL1Ratio(m interface{}, Alpha, L1Ratio float64) error {
+	if Alpha < 0 || math.IsNaN(Alpha) {
+		return &base.ParamError{Estimator: m, Param: "Alpha", Value: Alpha, Msg: "must be >= 0"}
+	}
+	if L1Ratio < 0 || L1Ratio > 1 || math.IsNaN(L1Ratio) {
+		return &base.ParamError{Estimator: m, Param: "L1Ratio", Value: L1Ratio, Msg: "must be in [0,1]"}
+	}
+	return nil
+}
+
 // DecisionFunction fills Y with X dot Coef+Intercept
 func (regr *LinearModel) DecisionFunction(X, Y *mat.Dense) {
-	Y.Mul(X, regr.Coef)
+	regr.decisionFunction(X, Y)
+}
+
+func (regr *LinearModel) decisionFunction(X mat.Matrix, Y *mat.Dense) {
+	mulTo(Y, X, regr.Coef)
 	Y.Apply(func(j int, o int, v float64) float64 {
 
 		return v + regr.Intercept.At(0, o)
@@ -623,6 +853,7 @@ func (regr *LinearModel) Score(X, Y *mat.Dense) float64 {
 }
 
 // PreprocessData center and normalize data
+// when SampleWeight is not nil, X and Y are centered on their means weighted by SampleWeight
 func PreprocessData(X, Y *mat.Dense, FitIntercept, Normalize bool, SampleWeight *mat.VecDense) (Xout, Yout, XOffset, YOffset, XScale *mat.Dense) {
 	Xmat := X.RawMatrix()
 	Ymat := Y.RawMatrix()
@@ -648,7 +879,7 @@ func PreprocessData(X, Y *mat.Dense, FitIntercept, Normalize bool, SampleWeight
 			xcol := X.ColView(feature)
 			mean := 0.
 			if FitIntercept {
-				mean = mat.Sum(xcol) / float64(Xmat.Rows)
+				mean = weightedMean(xcol, SampleWeight)
 
 				for jX, jXout := 0, 0; jX < Xmat.Rows*Xmat.Stride; jX, jXout = jX+Xmat.Stride, jXout+Xoutmat.Stride {
 					Xoutmat.Data[jXout+feature] = Xmat.Data[jX+feature] - mean
@@ -676,7 +907,7 @@ func PreprocessData(X, Y *mat.Dense, FitIntercept, Normalize bool, SampleWeight
 		base.Parallelize(-1, Ymat.Cols, func(th, start, end int) {
 			for output := start; output < end; output++ {
 				ycol := Y.ColView(output)
-				mean := mat.Sum(ycol) / float64(Ymat.Rows)
+				mean := weightedMean(ycol, SampleWeight)
 				YOffsetmat.Data[output] = mean
 				Youtmat := Yout.RawMatrix()
 				for jY, jYout := 0, 0; jY < Ymat.Rows*Ymat.Stride; jY, jYout = jY+Ymat.Stride, jYout+Youtmat.Stride {
@@ -692,3 +923,11 @@ func PreprocessData(X, Y *mat.Dense, FitIntercept, Normalize bool, SampleWeight
 	// 	mat.Formatted(Xout), mat.Formatted(Yout), mat.Formatted(XOffset), mat.Formatted(YOffset), mat.Formatted(XScale))
 	return
 }
+
+// weightedMean returns the mean of v weighted by w, or its mean if w is nil
+func weightedMean(v mat.Vector, w *mat.VecDense) float64 {
+	if w == nil {
+		return mat.Sum(v) / float64(v.Len())
+	}
+	return mat.Dot(v, w) / mat.Sum(w)
+}
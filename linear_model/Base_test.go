@@ -1,6 +1,8 @@
 package linearmodel
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"image/color"
 	"math"
@@ -387,3 +389,80 @@ func _ExampleSetIntercept() {
 	// [10.00  10.00]
 
 }
+
+func TestLinearRegressionErrors(t *testing.T) {
+	p := NewRandomLinearProblem(20, 3, 1)
+	regr := NewLinearRegression()
+	var notFitted *base.NotFittedError
+	if err := regr.PredictE(p.X, mat.NewDense(20, 1, nil)); !errors.As(err, &notFitted) {
+		t.Errorf("expected NotFittedError, got %v", err)
+	}
+	var shapeErr *base.ShapeError
+	if err := regr.FitE(p.X, mat.NewDense(10, 1, nil)); !errors.As(err, &shapeErr) {
+		t.Errorf("expected ShapeError, got %v", err)
+	}
+	if err := regr.FitE(p.X, p.Y); err != nil {
+		t.Fatal(err)
+	}
+	if err := regr.PredictE(mat.NewDense(20, 4, nil), mat.NewDense(20, 1, nil)); !errors.As(err, &shapeErr) {
+		t.Errorf("expected ShapeError, got %v", err)
+	}
+	if err := regr.PredictE(p.X, mat.NewDense(20, 1, nil)); err != nil {
+		t.Error(err)
+	}
+	ridge := NewRidge()
+	ridge.Alpha = -1
+	var paramErr *base.ParamError
+	if err := ridge.FitE(p.X, p.Y); !errors.As(err, &paramErr) || paramErr.Param != "Alpha" {
+		t.Errorf("expected ParamError on Alpha, got %v", err)
+	}
+}
+
+func TestFitContext(t *testing.T) {
+	p := NewRandomLinearProblem(50, 3, 2)
+	// cancelCounter returns a context cancelled by its callback after n reports of estimator
+	cancelCounter := func(n int) (context.Context, *int) {
+		ctx, cancel := context.WithCancel(context.Background())
+		count := new(int)
+		return base.WithProgress(ctx, base.ProgressFunc(func(p base.Progress) {
+			*count++
+			if *count == n {
+				cancel()
+			}
+		})), count
+	}
+
+	ridge := NewRidge()
+	ridge.Solver = "adam"
+	ridge.Options.Epochs = 100
+	ctx, count := cancelCounter(3)
+	if err := ridge.FitContext(ctx, p.X, p.Y); !errors.Is(err, context.Canceled) || *count != 3 {
+		t.Errorf("adam solver: expected cancellation after 3 epochs, got %v after %d", err, *count)
+	}
+	ctx, count = cancelCounter(-1)
+	if err := ridge.FitContext(ctx, p.X, p.Y); err != nil || *count != 100 || ridge.Coef == nil {
+		t.Errorf("adam solver: expected 100 epochs reported, got %v after %d", err, *count)
+	}
+
+	Ycls := mat.NewDense(50, 1, nil)
+	Ycls.Apply(func(i, o int, v float64) float64 { return math.Max(0, math.Copysign(1, p.Y.At(i, 0))) }, Ycls)
+	for _, regr := range []base.FitterContext{NewRidge(), NewSGDRegressor(), NewLogisticRegression()} {
+		ctx, count = cancelCounter(1)
+		Y := p.Y
+		if _, ok := regr.(*LogisticRegression); ok {
+			Y = Ycls
+		}
+		if err := regr.FitContext(ctx, p.X, Y); !errors.Is(err, context.Canceled) || *count != 1 {
+			t.Errorf("%T: expected cancellation, got %v after %d iterations", regr, err, *count)
+		}
+	}
+	sgd := NewSGDRegressor()
+	if err := sgd.FitContext(context.Background(), p.X, p.Y); err != nil || sgd.Score(p.X, p.Y) < .9 {
+		t.Errorf("SGDRegressor: unexpected FitContext result %v", err)
+	}
+	sgd.Alpha = -1
+	var paramErr *base.ParamError
+	if err := sgd.FitContext(context.Background(), p.X, p.Y); !errors.As(err, &paramErr) {
+		t.Errorf("expected ParamError, got %v", err)
+	}
+}
@@ -247,6 +247,25 @@ func (regr *BayesianRidge) Predict2(X, Y, yStd *mat.Dense) base.Regressor {
 	return regr
 }
 
+// FitE is Fit returning an error instead of panicking
+func (regr *BayesianRidge) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckFitXY("BayesianRidge.Fit", X, Y); err != nil {
+		return err
+	}
+	if regr.NIter <= 0 {
+		return &base.ParamError{Estimator: regr, Param: "NIter", Value: regr.NIter, Msg: "must be > 0"}
+	}
+	return base.Recover(func() { regr.Fit(X, Y) })
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (regr *BayesianRidge) PredictE(X, Y *mat.Dense) error {
+	if err := regr.checkPredict(regr, "BayesianRidge.Predict", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { regr.Predict(X, Y) })
+}
+
 // FitTransform is for Pipeline
 func (regr *BayesianRidge) FitTransform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
 	r, c := Y.Dims()
@@ -4,7 +4,9 @@ import (
 	"math"
 	"math/rand"
 
+	"github.com/pa-m/sklearn/sparse"
 	"gonum.org/v1/gonum/blas/blas64"
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
 )
 
@@ -329,3 +331,110 @@ func enetCoordinateDescentMultiTask(w *mat.Dense, l1reg, l2reg float64, X *mat.D
 	}
 	return &CDResult{Gap: gap, Eps: tol, NIter: nIter + 1}
 }
+
+// sparse coordinate descent algorithm for Elastic-Net, for a CSC X centered implicitly with XMean (nil for no centering)
+// v https://github.com/scikit-learn/scikit-learn/blob/a24c8b464d094d2c468a16ea9f8bf8d42d949f84/sklearn/linear_model/cd_fast.pyx sparse_enet_coordinate_descent
+func sparseEnetCoordinateDescent(w *mat.VecDense, alpha, beta float64, X *sparse.CSC, XMean []float64, Y *mat.VecDense, maxIter int, tol float64, rng *rand.Rand, random, positive bool) *CDResult {
+	gap := tol + 1.
+	dwtol := tol
+
+	NSamples, NFeatures := X.Dims()
+	if XMean == nil {
+		XMean = make([]float64, NFeatures)
+	}
+	// # norm_cols_X = ((X - X_mean) ** 2).sum(axis=0)
+	normColsX := make([]float64, NFeatures)
+	for ii := 0; ii < NFeatures; ii++ {
+		col := X.Col(ii)
+		normColsX[ii] = sparse.Norm2(col) - 2*XMean[ii]*floats.Sum(col.Data) + float64(NSamples)*XMean[ii]*XMean[ii]
+	}
+	// # R = Y - np.dot(X - X_mean, w)
+	R := make([]float64, NSamples)
+	for i := range R {
+		R[i] = Y.AtVec(i)
+	}
+	addColumn := func(ii int, scale float64) {
+		// R += scale * (X[:,ii] - X_mean[ii])
+		col := X.Col(ii)
+		for p, i := range col.Indices {
+			R[i] += scale * col.Data[p]
+		}
+		if XMean[ii] != 0 {
+			for i := range R {
+				R[i] -= scale * XMean[ii]
+			}
+		}
+	}
+	colDotR := func(ii int) float64 {
+		// (X[:,ii] - X_mean[ii]) dot R
+		return sparse.DotDense(X.Col(ii), R) - XMean[ii]*floats.Sum(R)
+	}
+	for ii := 0; ii < NFeatures; ii++ {
+		if w.AtVec(ii) != 0 {
+			addColumn(ii, -w.AtVec(ii))
+		}
+	}
+
+	// # tol *= np.dot(y, y)
+	tol *= mat.Dot(Y, Y)
+	var nIter int
+	XtA := make([]float64, NFeatures)
+	for nIter = 0; nIter < maxIter; nIter++ {
+		wmax, dwmax := 0., 0.
+		for fIter := 0; fIter < NFeatures; fIter++ {
+			ii := fIter
+			if random {
+				ii = rng.Intn(NFeatures)
+			}
+			if normColsX[ii] == 0. {
+				continue
+			}
+			wii := w.AtVec(ii)
+			if wii != 0. {
+				addColumn(ii, wii)
+			}
+			tmp := colDotR(ii)
+			if positive && tmp < 0. {
+				w.SetVec(ii, 0)
+			} else {
+				w.SetVec(ii, math.Copysign(math.Max(math.Abs(tmp)-alpha, 0), tmp)/(normColsX[ii]+beta))
+			}
+			if w.AtVec(ii) != 0. {
+				addColumn(ii, -w.AtVec(ii))
+			}
+			dwmax = math.Max(dwmax, math.Abs(w.AtVec(ii)-wii))
+			wmax = math.Max(wmax, math.Abs(w.AtVec(ii)))
+		}
+		if wmax == 0. || dwmax/wmax < dwtol || nIter == maxIter-1 {
+			// # XtA = np.dot((X - X_mean).T, R) - beta * w
+			dualNormXtA := 0.
+			for ii := range XtA {
+				XtA[ii] = colDotR(ii) - beta*w.AtVec(ii)
+				if positive {
+					dualNormXtA = math.Max(dualNormXtA, XtA[ii])
+				} else {
+					dualNormXtA = math.Max(dualNormXtA, math.Abs(XtA[ii]))
+				}
+			}
+			RNorm2 := floats.Dot(R, R)
+			wNorm2 := mat.Dot(w, w)
+			cons := 1.
+			if dualNormXtA > alpha {
+				cons = alpha / dualNormXtA
+				gap = .5 * RNorm2 * (1 + cons*cons)
+			} else {
+				gap = RNorm2
+			}
+			l1norm := blas64.Asum(w.RawVector())
+			RY := 0.
+			for i, r := range R {
+				RY += r * Y.AtVec(i)
+			}
+			gap += alpha*l1norm - cons*RY + .5*beta*(1.+cons*cons)*wNorm2
+			if gap < tol {
+				break
+			}
+		}
+	}
+	return &CDResult{Gap: gap, Eps: tol, NIter: nIter + 1}
+}
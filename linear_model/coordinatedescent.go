@@ -1,6 +1,7 @@
 package linearmodel
 
 import (
+	"fmt"
 	"math"
 	"strings"
 	"sync"
@@ -16,12 +17,13 @@ type CDResult struct {
 }
 
 // ElasticNet is the struct for coordinate descent regularized regressions: ElasticNet,Ridge,Lasso
-// Selection is cyclic or random. defaults to cyclic
+// Selection is cyclic or random. defaults to cyclic. RandomState draws the features with random selection
 type ElasticNet struct {
 	LinearRegression
 	Tol, Alpha, L1Ratio float64
 	MaxIter             int
 	Selection           string
+	RandomState         *base.RandomState
 	WarmStart, Positive bool
 	CDResult            CDResult
 }
@@ -43,30 +45,72 @@ func (regr *ElasticNet) Clone() base.Transformer {
 
 // Fit ElasticNetRegression with coordinate descent
 func (regr *ElasticNet) Fit(X0, Y0 *mat.Dense) base.Transformer {
+	regr.fit(X0, Y0, nil)
+	return regr
+}
+
+func (regr *ElasticNet) fit(X0, Y0 *mat.Dense, sampleWeight []float64) {
 	var X, Y, YOffset *mat.Dense
-	X, Y, regr.XOffset, YOffset, regr.XScale = PreprocessData(X0, Y0, regr.FitIntercept, regr.Normalize, nil)
+	X, Y, regr.XOffset, YOffset, regr.XScale = PreprocessData(X0, Y0, regr.FitIntercept, regr.Normalize, weightVec(sampleWeight))
+	if sampleWeight != nil {
+		X, Y = rescaleRows(X, Y, normalizeWeights(sampleWeight))
+	}
 	NSamples, NFeatures := X.Dims()
 	_, NOutputs := Y.Dims()
 
 	l1reg := regr.Alpha * regr.L1Ratio * float64(NSamples)
 	l2reg := regr.Alpha * (1. - regr.L1Ratio) * float64(NSamples)
-	if !regr.WarmStart {
+	if !regr.WarmStart || regr.Coef == nil {
 		regr.Coef = mat.NewDense(NFeatures, NOutputs, nil)
+	} else {
+		// Coef is updated in place and may be shared with a clone
+		regr.Coef = mat.DenseCopyOf(regr.Coef)
 	}
 	random := strings.EqualFold("random", regr.Selection)
+	rng := base.CheckRandomState(regr.RandomState).Rand()
 	if NOutputs == 1 {
 		y := &mat.VecDense{}
 		w := &mat.VecDense{}
 
 		w.ColViewOf(regr.Coef, 0)
 		y.ColViewOf(Y, 0)
-		regr.CDResult = *enetCoordinateDescent(w, l1reg, l2reg, X, y, regr.MaxIter, regr.Tol, nil, random, regr.Positive)
+		regr.CDResult = *enetCoordinateDescent(w, l1reg, l2reg, X, y, regr.MaxIter, regr.Tol, rng, random, regr.Positive)
 
 	} else {
-		regr.CDResult = *enetCoordinateDescentMultiTask(regr.Coef, l1reg, l2reg, X, Y, regr.MaxIter, regr.Tol, nil, random, regr.Positive)
+		regr.CDResult = *enetCoordinateDescentMultiTask(regr.Coef, l1reg, l2reg, X, Y, regr.MaxIter, regr.Tol, rng, random, regr.Positive)
 	}
 	regr.LinearModel.setIntercept(regr.XOffset, YOffset, regr.XScale)
-	return regr
+}
+
+// FitE is Fit returning an error instead of panicking.
+// a *base.ConvergenceError is returned if the duality gap is above tolerance after MaxIter iterations, the model is still usable
+func (regr *ElasticNet) FitE(X, Y *mat.Dense) error {
+	return regr.fitE("ElasticNet.Fit", X, Y, nil)
+}
+
+func (regr *ElasticNet) fitE(op string, X, Y *mat.Dense, sampleWeight []float64) error {
+	if err := base.CheckFitXY(op, X, Y); err != nil {
+		return err
+	}
+	if err := base.CheckSampleWeight(op, X, sampleWeight); err != nil {
+		return err
+	}
+	if err := checkAlphaL1Ratio(regr, regr.Alpha, regr.L1Ratio); err != nil {
+		return err
+	}
+	if regr.MaxIter <= 0 {
+		return &base.ParamError{Estimator: regr, Param: "MaxIter", Value: regr.MaxIter, Msg: "must be > 0"}
+	}
+	if !strings.EqualFold(regr.Selection, "cyclic") && !strings.EqualFold(regr.Selection, "random") && regr.Selection != "" {
+		return &base.ParamError{Estimator: regr, Param: "Selection", Value: regr.Selection, Msg: "must be cyclic or random"}
+	}
+	if err := base.Recover(func() { regr.fit(X, Y, sampleWeight) }); err != nil {
+		return err
+	}
+	if regr.CDResult.Gap >= regr.CDResult.Eps {
+		return &base.ConvergenceError{Estimator: regr, NIter: regr.CDResult.NIter, Msg: fmt.Sprintf("duality gap %g, tolerance %g", regr.CDResult.Gap, regr.CDResult.Eps)}
+	}
+	return nil
 }
 
 // NewElasticNet creates a *ElasticNet with Alpha=1 and L1Ratio=0.5
@@ -86,7 +130,7 @@ func NewMultiTaskElasticNet() *MultiTaskElasticNet {
 	return regr
 }
 
-//NewLasso creates a *ElasticNetRegression with Alpha=1 and L1Ratio = 1
+// NewLasso creates a *ElasticNetRegression with Alpha=1 and L1Ratio = 1
 func NewLasso() *Lasso {
 	m := NewMultiTaskElasticNet()
 	m.L1Ratio = 1.
@@ -0,0 +1,107 @@
+package linearmodel
+
+import (
+	"context"
+	"math"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// preprocessDense32 is PreprocessData for a *base.Dense32: means and scales are computed in float64
+// and the centered and normalized X is a float32 copy
+func preprocessDense32(X *base.Dense32, Y *mat.Dense, FitIntercept, Normalize bool) (Xout mat.Matrix, Yout, XOffset, YOffset, XScale *mat.Dense) {
+	nSamples, nFeatures := X.Dims()
+	XOffset = mat.NewDense(1, nFeatures, nil)
+	XScale = mat.NewDense(1, nFeatures, nil)
+	offset, scale := XOffset.RawRowView(0), XScale.RawRowView(0)
+	for j := range scale {
+		scale[j] = 1
+	}
+	if !FitIntercept && !Normalize {
+		_, nOutputs := Y.Dims()
+		return X, Y, XOffset, mat.NewDense(1, nOutputs, nil), XScale
+	}
+	if FitIntercept {
+		for i := 0; i < nSamples; i++ {
+			for j, v := range X.RawRowView(i) {
+				offset[j] += float64(v)
+			}
+		}
+		for j := range offset {
+			offset[j] /= float64(nSamples)
+		}
+	}
+	if Normalize {
+		for j := range scale {
+			scale[j] = 0
+		}
+		for i := 0; i < nSamples; i++ {
+			for j, v := range X.RawRowView(i) {
+				d := float64(v) - offset[j]
+				scale[j] += d * d
+			}
+		}
+		for j := range scale {
+			scale[j] = math.Sqrt(scale[j])
+			if scale[j] == 0 {
+				scale[j] = 1
+			}
+		}
+	}
+	X32 := base.NewDense32(nSamples, nFeatures, nil)
+	for i := 0; i < nSamples; i++ {
+		row := X32.RawRowView(i)
+		for j, v := range X.RawRowView(i) {
+			row[j] = float32((float64(v) - offset[j]) / scale[j])
+		}
+	}
+	if FitIntercept {
+		Yout, YOffset = centerY(Y)
+	} else {
+		_, nOutputs := Y.Dims()
+		Yout, YOffset = Y, mat.NewDense(1, nOutputs, nil)
+	}
+	return X32, Yout, XOffset, YOffset, XScale
+}
+
+// FitDense32 is Fit for a float32 X. X is centered and normalized into a float32 copy
+func (regr *RegularizedRegression) FitDense32(X *base.Dense32, Y *mat.Dense) base.Transformer {
+	if err := regr.fit(context.Background(), X, Y, nil); err != nil {
+		panic(err)
+	}
+	return regr
+}
+
+// PredictDense32 is Predict for a float32 X
+func (regr *LinearRegression) PredictDense32(X *base.Dense32, Y *mat.Dense) base.Regressor {
+	regr.decisionFunction(X, Y)
+	return regr
+}
+
+// FitDense32 is Fit for a float32 X. X is centered and normalized into a float32 copy
+func (regr *SGDRegressor) FitDense32(X *base.Dense32, Y *mat.Dense) base.Transformer {
+	if err := regr.fit(context.Background(), X, Y, nil); err != nil {
+		panic(err)
+	}
+	return regr
+}
+
+// PredictDense32 is Predict for a float32 X
+func (regr *SGDRegressor) PredictDense32(X *base.Dense32, Y *mat.Dense) base.Regressor {
+	regr.decisionFunction(X, Y)
+	return regr
+}
+
+// FitDense32 is Fit for a float32 X. X is centered and normalized into a float32 copy
+func (regr *LogisticRegression) FitDense32(X *base.Dense32, Ycls *mat.Dense) base.Transformer {
+	if err := regr.fit(context.Background(), X, Ycls, nil); err != nil {
+		panic(err)
+	}
+	return regr
+}
+
+// PredictDense32 is Predict for a float32 X
+func (regr *LogisticRegression) PredictDense32(X *base.Dense32, Ycls *mat.Dense) {
+	regr.predict(X, Ycls)
+}
@@ -0,0 +1,89 @@
+package linearmodel
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// newDense32Problem returns a X with float32 elements, the same X as a *base.Dense32 and Y=X·coef+1
+func newDense32Problem(nSamples, nFeatures int) (X *mat.Dense, X32 *base.Dense32, Y *mat.Dense) {
+	rnd := rand.New(rand.NewSource(7))
+	X = mat.NewDense(nSamples, nFeatures, nil)
+	X.Apply(func(_, _ int, _ float64) float64 { return float64(float32(rnd.NormFloat64())) }, X)
+	coef := mat.NewDense(nFeatures, 1, nil)
+	coef.Apply(func(j, _ int, _ float64) float64 { return float64(j + 1) }, coef)
+	Y = &mat.Dense{}
+	Y.Mul(X, coef)
+	Y.Apply(func(_, _ int, y float64) float64 { return y + 1 }, Y)
+	return X, base.Dense32Of(X), Y
+}
+
+func TestRegressionDense32(t *testing.T) {
+	X, X32, Y := newDense32Problem(50, 4)
+	type dense32Regressor interface {
+		base.Regressor
+		FitDense32(*base.Dense32, *mat.Dense) base.Transformer
+		PredictDense32(*base.Dense32, *mat.Dense) base.Regressor
+	}
+	newRidge := func() dense32Regressor {
+		m := NewRidge()
+		m.Alpha, m.Normalize = .1, true
+		return m
+	}
+	newSGD := func() dense32Regressor {
+		m := NewSGDRegressor()
+		m.Alpha, m.Method = 0, &optimize.LBFGS{}
+		return m
+	}
+	for _, newRegressor := range []func() dense32Regressor{newRidge, newSGD} {
+		dense, m32 := newRegressor(), newRegressor()
+		dense.Fit(X, Y)
+		m32.FitDense32(X32, Y)
+		Ydense, Y32 := &mat.Dense{}, &mat.Dense{}
+		dense.Predict(X, Ydense)
+		m32.PredictDense32(X32, Y32)
+		if !mat.EqualApprox(Ydense, Y32, 1e-4) {
+			t.Errorf("%T FitDense32 and PredictDense32 differ from Fit and Predict", m32)
+		}
+		Ypred := &mat.Dense{}
+		m32.Predict(X, Ypred)
+		if !mat.EqualApprox(Ypred, Y32, 1e-10) {
+			t.Errorf("%T PredictDense32 differs from Predict", m32)
+		}
+	}
+	// minibatches of a Dense32 are float32 views
+	adam := NewRidge()
+	adam.Alpha, adam.Tol, adam.Solver = 0, 1e-4, "adam"
+	adam.RandomState = base.NewRandomState(7)
+	adam.FitDense32(X32, Y)
+	if score := adam.Score(X, Y); score < .99 {
+		t.Errorf("adam score %g", score)
+	}
+}
+
+func TestLogisticRegressionDense32(t *testing.T) {
+	X, X32, Y := newDense32Problem(60, 3)
+	Y.Apply(func(_, _ int, y float64) float64 {
+		if y > 1 {
+			return 1
+		}
+		return 0
+	}, Y)
+	dense, m32 := NewLogisticRegression(), NewLogisticRegression()
+	dense.Alpha, m32.Alpha = 1, 1
+	dense.Fit(X, Y)
+	m32.FitDense32(X32, Y)
+	if !mat.EqualApprox(dense.Coef, m32.Coef, 1e-3) {
+		t.Errorf("expected coef %g, got %g", mat.Formatted(dense.Coef.T()), mat.Formatted(m32.Coef.T()))
+	}
+	Ydense, Y32 := &mat.Dense{}, &mat.Dense{}
+	m32.Predict(X, Ydense)
+	m32.PredictDense32(X32, Y32)
+	if !mat.EqualApprox(Ydense, Y32, 1e-10) {
+		t.Error("PredictDense32 differs from Predict")
+	}
+}
@@ -0,0 +1,44 @@
+package linearmodel
+
+import (
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/base/estimatorchecks"
+)
+
+func TestEstimatorChecks(t *testing.T) {
+	X, Y := estimatorchecks.RegressionData()
+	ridge := NewRidge()
+	ridge.RandomState = base.NewRandomState(7)
+	pa := NewPassiveAggressiveRegressor()
+	pa.RandomState = base.NewRandomState(7)
+	ransac, theilSen := NewRANSACRegressor(), NewTheilSenRegressor()
+	ransac.RandomState, theilSen.RandomState = base.NewRandomState(7), base.NewRandomState(7)
+	for _, estimator := range []estimatorchecks.Estimator{
+		NewLinearRegression(), ridge, NewSGDRegressor(), NewElasticNet(), NewLasso(), NewBayesianRidge(), pa,
+		NewHuberRegressor(), ransac, theilSen, NewLars(), NewLassoLars(),
+	} {
+		if err := estimatorchecks.CheckEstimator(estimator, X, Y); err != nil {
+			t.Error(err)
+		}
+	}
+	// LassoLarsIC has a single output
+	if err := estimatorchecks.CheckEstimator(NewLassoLarsIC(), X, base.MatDenseSlice(Y, 0, 40, 0, 1)); err != nil {
+		t.Error(err)
+	}
+	Xcls, Ycls := estimatorchecks.ClassificationData()
+	logistic := NewLogisticRegression()
+	logistic.RandomState = base.NewRandomState(7)
+	sgd := NewSGDClassifier()
+	sgd.RandomState = base.NewRandomState(7)
+	perceptron := NewPerceptron()
+	perceptron.RandomState = base.NewRandomState(7)
+	paClassifier := NewPassiveAggressiveClassifier()
+	paClassifier.RandomState = base.NewRandomState(7)
+	for _, estimator := range []estimatorchecks.Estimator{logistic, sgd, perceptron, paClassifier} {
+		if err := estimatorchecks.CheckEstimator(estimator, Xcls, Ycls); err != nil {
+			t.Error(err)
+		}
+	}
+}
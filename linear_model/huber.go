@@ -0,0 +1,249 @@
+package linearmodel
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// HuberRegressor is a linear regression robust to outliers: the residuals r of each output are penalized
+// quadratically below Epsilon times their scale sigma, linearly beyond, sigma being fitted jointly with the weights
+// so that the threshold does not depend on the scale of Y. the objective of each output is
+//
+//	sum_i sigma·(1+H(|r_i|/sigma)) + Alpha·|Coef|²
+//
+// H(z) being z² for z <= Epsilon, else 2·Epsilon·z-Epsilon². it is minimized by L-BFGS.
+// see A. B. Owen, "A robust hybrid of lasso and ridge regression", 2007
+type HuberRegressor struct {
+	LinearModel
+	// Epsilon >= 1 is the residual, in scales, beyond which a sample is an outlier. the default 1.35 keeps 95% of
+	// the efficiency of least squares for gaussian residuals
+	Epsilon, Alpha, Tol float64
+	MaxIter             int
+	// WarmStart makes Fit start from the current Coef, Intercept and Scale
+	WarmStart bool
+	// Scale is the scale sigma of the residuals of each output
+	Scale []float64
+	// Outliers tells the samples whose residual of an output is beyond Epsilon times its Scale
+	Outliers    []bool
+	HuberResult HuberResult
+}
+
+// HuberResult is the result of the L-BFGS minimizations of HuberRegressor
+type HuberResult struct {
+	// NIter is the largest number of iterations of the outputs, Converged is false if the gradient of an
+	// output is still above Tol after MaxIter iterations
+	NIter     int
+	Converged bool
+}
+
+// NewHuberRegressor creates a *HuberRegressor with defaults: Epsilon 1.35, Alpha 1e-4, Tol 1e-5 and MaxIter 100
+func NewHuberRegressor() *HuberRegressor {
+	regr := &HuberRegressor{Epsilon: 1.35, Alpha: 1e-4, Tol: 1e-5, MaxIter: 100}
+	regr.FitIntercept = true
+	return regr
+}
+
+// Clone for HuberRegressor
+func (regr *HuberRegressor) Clone() base.Transformer {
+	clone := *regr
+	return &clone
+}
+
+// Fit learns Coef, Intercept and Scale from X,Y
+func (regr *HuberRegressor) Fit(X, Y *mat.Dense) base.Transformer {
+	if err := regr.fit(context.Background(), X, Y, nil); err != nil {
+		panic(err)
+	}
+	return regr
+}
+
+// FitE is Fit returning an error instead of panicking.
+// a *base.ConvergenceError is returned if the gradient is still above Tol after MaxIter iterations, the model is
+// still usable
+func (regr *HuberRegressor) FitE(X, Y *mat.Dense) error {
+	return regr.fitE(context.Background(), "HuberRegressor.Fit", X, Y, nil)
+}
+
+// FitContext is FitE stopping between iterations when ctx is done. it reports the objective of each iteration
+// to the ProgressCallback of ctx
+func (regr *HuberRegressor) FitContext(ctx context.Context, X, Y *mat.Dense) error {
+	return regr.fitE(ctx, "HuberRegressor.Fit", X, Y, nil)
+}
+
+// FitWeighted is FitE with the loss of each sample weighted by sampleWeight
+func (regr *HuberRegressor) FitWeighted(X, Y *mat.Dense, sampleWeight []float64) error {
+	if err := base.CheckSampleWeight("HuberRegressor.FitWeighted", X, sampleWeight); err != nil {
+		return err
+	}
+	return regr.fitE(context.Background(), "HuberRegressor.FitWeighted", X, Y, sampleWeight)
+}
+
+func (regr *HuberRegressor) fitE(ctx context.Context, op string, X, Y *mat.Dense, sampleWeight []float64) error {
+	if err := regr.checkFit(op, X, Y); err != nil {
+		return err
+	}
+	var err error
+	if rerr := base.Recover(func() { err = regr.fit(ctx, X, Y, sampleWeight) }); rerr != nil {
+		return rerr
+	}
+	if err == nil && !regr.HuberResult.Converged {
+		err = &base.ConvergenceError{Estimator: regr, NIter: regr.HuberResult.NIter, Msg: fmt.Sprintf("the gradient is still above Tol %g, increase MaxIter", regr.Tol)}
+	}
+	return err
+}
+
+func (regr *HuberRegressor) checkFit(op string, X, Y *mat.Dense) error {
+	if err := base.CheckFitXY(op, X, Y); err != nil {
+		return err
+	}
+	if !(regr.Epsilon >= 1) {
+		return &base.ParamError{Estimator: regr, Param: "Epsilon", Value: regr.Epsilon, Msg: "must be >= 1"}
+	}
+	if err := checkAlphaL1Ratio(regr, regr.Alpha, 0); err != nil {
+		return err
+	}
+	if regr.MaxIter <= 0 {
+		return &base.ParamError{Estimator: regr, Param: "MaxIter", Value: regr.MaxIter, Msg: "must be > 0"}
+	}
+	return nil
+}
+
+func (regr *HuberRegressor) fit(ctx context.Context, X, Y *mat.Dense, sampleWeight []float64) error {
+	nSamples, nFeatures := X.Dims()
+	_, nOutputs := Y.Dims()
+	warm := regr.WarmStart && regr.Coef != nil
+	if warm {
+		if err := checkCoefShape("HuberRegressor.Fit", regr.Coef, nFeatures, nOutputs); err != nil {
+			return err
+		}
+	}
+	coef, intercept := mat.NewDense(nFeatures, nOutputs, nil), mat.NewDense(1, nOutputs, nil)
+	scale := make([]float64, nOutputs)
+	result := HuberResult{Converged: true}
+	for o := 0; o < nOutputs; o++ {
+		f := &huberObjective{X: X, y: mat.Col(nil, o, Y), sampleWeight: sampleWeight, epsilon: regr.Epsilon, alpha: regr.Alpha, fitIntercept: regr.FitIntercept}
+		// theta is the weights, the intercept and the log of the scale, which keeps it positive
+		theta := make([]float64, f.nParams())
+		if warm {
+			mat.Col(theta[:nFeatures], o, regr.Coef)
+			if regr.FitIntercept {
+				theta[nFeatures] = regr.Intercept.At(0, o)
+			}
+			theta[len(theta)-1] = math.Log(regr.Scale[o])
+		}
+		theta, nIter, converged, err := lbfgsSolve(ctx, regr, f, theta, regr.MaxIter, regr.Tol)
+		if err != nil {
+			return err
+		}
+		coef.SetCol(o, theta[:nFeatures])
+		if regr.FitIntercept {
+			intercept.Set(0, o, theta[nFeatures])
+		}
+		scale[o] = math.Exp(theta[len(theta)-1])
+		if nIter > result.NIter {
+			result.NIter = nIter
+		}
+		result.Converged = result.Converged && converged
+	}
+	// the weights apply to X itself, not to X centered
+	regr.XOffset, regr.XScale = nil, nil
+	regr.Coef, regr.Intercept, regr.Scale, regr.HuberResult = coef, intercept, scale, result
+	Ypred := &mat.Dense{}
+	regr.DecisionFunction(X, Ypred)
+	regr.Outliers = make([]bool, nSamples)
+	for i := range regr.Outliers {
+		for o := 0; o < nOutputs; o++ {
+			regr.Outliers[i] = regr.Outliers[i] || math.Abs(Y.At(i, o)-Ypred.At(i, o)) > regr.Epsilon*scale[o]
+		}
+	}
+	return nil
+}
+
+// huberObjective is the objective of HuberRegressor for the target y, of parameters the weights, the intercept
+// if fitIntercept, and the log of the scale
+type huberObjective struct {
+	X               *mat.Dense
+	y, sampleWeight []float64
+	epsilon, alpha  float64
+	fitIntercept    bool
+}
+
+func (f *huberObjective) nParams() int {
+	_, nFeatures := f.X.Dims()
+	if f.fitIntercept {
+		return nFeatures + 2
+	}
+	return nFeatures + 1
+}
+
+func (f *huberObjective) lossGrad(theta, grad []float64) float64 {
+	nSamples, nFeatures := f.X.Dims()
+	w := theta[:nFeatures]
+	c := 0.
+	if f.fitIntercept {
+		c = theta[nFeatures]
+	}
+	sigma := math.Exp(theta[len(theta)-1])
+	for j := range grad {
+		grad[j] = 0
+	}
+	loss, dsigma := 0., 0.
+	for i := 0; i < nSamples; i++ {
+		x := f.X.RawRowView(i)
+		sw := 1.
+		if f.sampleWeight != nil {
+			sw = f.sampleWeight[i]
+		}
+		r := f.y[i] - floats.Dot(x, w) - c
+		// dr is the derivative of the loss relatively to r
+		var dr float64
+		if math.Abs(r) > f.epsilon*sigma {
+			loss += sw * (sigma + 2*f.epsilon*math.Abs(r) - sigma*f.epsilon*f.epsilon)
+			dsigma += sw * (1 - f.epsilon*f.epsilon)
+			dr = sw * 2 * f.epsilon * sgn(r)
+		} else {
+			loss += sw * (sigma + r*r/sigma)
+			dsigma += sw * (1 - r*r/(sigma*sigma))
+			dr = sw * 2 * r / sigma
+		}
+		if grad != nil {
+			floats.AddScaled(grad[:nFeatures], -dr, x)
+			if f.fitIntercept {
+				grad[nFeatures] -= dr
+			}
+		}
+	}
+	loss += f.alpha * floats.Dot(w, w)
+	if grad != nil {
+		floats.AddScaled(grad[:nFeatures], 2*f.alpha, w)
+		grad[len(grad)-1] = sigma * dsigma
+	}
+	return loss
+}
+
+// Predict puts into Y the predictions of X
+func (regr *HuberRegressor) Predict(X, Y *mat.Dense) base.Regressor {
+	regr.DecisionFunction(X, Y)
+	return regr
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (regr *HuberRegressor) PredictE(X, Y *mat.Dense) error {
+	if err := regr.checkPredict(regr, "HuberRegressor.Predict", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { regr.Predict(X, Y) })
+}
+
+// Transform is for Pipeline
+func (regr *HuberRegressor) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
+	r, c := Y.Dims()
+	Xout, Yout = X, mat.NewDense(r, c, nil)
+	regr.Predict(X, Yout)
+	return
+}
@@ -0,0 +1,103 @@
+package linearmodel
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+func ExampleHuberRegressor() {
+	X, Y := newOutliersProblem(200)
+	huber, ols := NewHuberRegressor(), NewLinearRegression()
+	if err := huber.FitE(X, Y); err != nil {
+		fmt.Println(err)
+	}
+	ols.Fit(X, Y)
+	// 1 sample out of 10 is an outlier
+	found := 0
+	for i := 0; i < 200; i += 10 {
+		if huber.Outliers[i] {
+			found++
+		}
+	}
+	fmt.Printf("huber coef:%.1f intercept:%.1f outliers found:%d/20\n", mat.Col(nil, 0, huber.Coef), huber.Intercept.At(0, 0), found)
+	fmt.Printf("least squares intercept:%.1f\n", ols.Intercept.At(0, 0))
+	// Output:
+	// huber coef:[2.0 -1.0 0.5] intercept:1.0 outliers found:20/20
+	// least squares intercept:4.1
+}
+
+func TestHuberObjective(t *testing.T) {
+	X, Y := newOutliersProblem(50)
+	for _, fitIntercept := range []bool{true, false} {
+		f := &huberObjective{X: X, y: mat.Col(nil, 0, Y), epsilon: 1.35, alpha: .1, fitIntercept: fitIntercept}
+		theta := []float64{1.5, -.5, .2, .3, .7}[5-f.nParams():]
+		grad := make([]float64, len(theta))
+		f.lossGrad(theta, grad)
+		for j := range theta {
+			h := 1e-6
+			theta[j] += h
+			plus := f.lossGrad(theta, nil)
+			theta[j] -= 2 * h
+			minus := f.lossGrad(theta, nil)
+			theta[j] += h
+			if numeric := (plus - minus) / (2 * h); math.Abs(numeric-grad[j]) > 1e-4*math.Max(1, math.Abs(numeric)) {
+				t.Errorf("intercept %v: gradient %d is %g, numeric derivative %g", fitIntercept, j, grad[j], numeric)
+			}
+		}
+	}
+}
+
+func TestHuberRegressor(t *testing.T) {
+	X, Y := newOutliersProblem(200)
+	// Scale follows the scale of Y, the outliers are the same
+	Y10 := &mat.Dense{}
+	Y10.Scale(10, Y)
+	regr, regr10 := NewHuberRegressor(), NewHuberRegressor()
+	if err := regr.FitE(X, Y); err != nil {
+		t.Fatal(err)
+	}
+	if err := regr10.FitE(X, Y10); err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(regr10.Scale[0]/regr.Scale[0]-10) > .1 {
+		t.Errorf("expected a 10 times larger scale, got %g and %g", regr.Scale[0], regr10.Scale[0])
+	}
+	for i, outlier := range regr.Outliers {
+		if i%10 == 0 && !outlier {
+			t.Errorf("expected sample %d to be an outlier", i)
+		}
+		if outlier != regr10.Outliers[i] {
+			t.Errorf("expected sample %d to be an outlier of both fits", i)
+		}
+	}
+
+	// WarmStart starts from the solution
+	regr.WarmStart = true
+	if err := regr.FitE(X, Y); err != nil || regr.HuberResult.NIter > 2 {
+		t.Errorf("expected a warm start from the solution to converge at once, got %v after %d iterations", err, regr.HuberResult.NIter)
+	}
+
+	for _, params := range []func(*HuberRegressor){
+		func(regr *HuberRegressor) { regr.Epsilon = .5 },
+		func(regr *HuberRegressor) { regr.Alpha = -1 },
+		func(regr *HuberRegressor) { regr.MaxIter = 0 },
+	} {
+		regr := NewHuberRegressor()
+		params(regr)
+		var paramErr *base.ParamError
+		if err := regr.FitE(X, Y); !errors.As(err, &paramErr) {
+			t.Errorf("expected a *base.ParamError, got %v", err)
+		}
+	}
+	regr = NewHuberRegressor()
+	regr.MaxIter = 2
+	var convergenceErr *base.ConvergenceError
+	if err := regr.FitE(X, Y); !errors.As(err, &convergenceErr) {
+		t.Errorf("expected a *base.ConvergenceError after 2 iterations, got %v", err)
+	}
+}
@@ -0,0 +1,532 @@
+package linearmodel
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// LarsResult is the path of a Lars, LassoLars or LassoLarsIC fit, an element per output
+type LarsResult struct {
+	// Alphas are the breakpoints of the path, Active the features of the active set at its end in order of entry,
+	// NIter the number of steps. CoefPath, kept with FitPath, has a column of coefficients of X preprocessed per alpha
+	Alphas   [][]float64
+	Active   [][]int
+	CoefPath []*mat.Dense
+	NIter    []int
+}
+
+// Lars is the least angle regression: starting from zero coefficients, each step adds to the active set the feature
+// most correlated with the residual and moves the coefficients of the active set in the direction equiangular
+// to their features, until another feature is as correlated. it stops after NNonzeroCoefs steps or when all the
+// features are active, the fit is then least squares.
+// see B. Efron, T. Hastie, I. Johnstone, R. Tibshirani, "Least Angle Regression", Annals of Statistics, 2004
+type Lars struct {
+	LinearModel
+	// NNonzeroCoefs is the maximal number of steps, so of features with a nonzero coefficient
+	NNonzeroCoefs int
+	// Positive forces the coefficients to be >= 0, FitPath keeps LarsResult.CoefPath
+	Positive, FitPath bool
+	LarsResult        LarsResult
+}
+
+// NewLars creates a *Lars with defaults: NNonzeroCoefs 500 and FitPath
+func NewLars() *Lars {
+	regr := &Lars{NNonzeroCoefs: 500, FitPath: true}
+	regr.FitIntercept = true
+	return regr
+}
+
+// Clone for Lars
+func (regr *Lars) Clone() base.Transformer {
+	clone := *regr
+	return &clone
+}
+
+// Fit learns Coef and Intercept from X,Y
+func (regr *Lars) Fit(X, Y *mat.Dense) base.Transformer {
+	if err := regr.FitE(X, Y); err != nil {
+		panic(err)
+	}
+	return regr
+}
+
+// FitE is Fit returning an error instead of panicking
+func (regr *Lars) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckFitXY("Lars.Fit", X, Y); err != nil {
+		return err
+	}
+	if regr.NNonzeroCoefs <= 0 {
+		return &base.ParamError{Estimator: regr, Param: "NNonzeroCoefs", Value: regr.NNonzeroCoefs, Msg: "must be > 0"}
+	}
+	return base.Recover(func() {
+		regr.LarsResult = larsFit(&regr.LinearModel, X, Y, "lar", 0, regr.NNonzeroCoefs, regr.Positive, regr.FitPath)
+	})
+}
+
+// Predict puts into Y the predictions of X
+func (regr *Lars) Predict(X, Y *mat.Dense) base.Regressor {
+	regr.DecisionFunction(X, Y)
+	return regr
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (regr *Lars) PredictE(X, Y *mat.Dense) error {
+	if err := regr.checkPredict(regr, "Lars.Predict", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { regr.Predict(X, Y) })
+}
+
+// Transform is for Pipeline
+func (regr *Lars) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
+	r, c := Y.Dims()
+	Xout, Yout = X, mat.NewDense(r, c, nil)
+	regr.Predict(X, Yout)
+	return
+}
+
+// LassoLars is the Lasso fitted by the lasso variant of least angle regression, which drops from the active set a
+// feature whose coefficient crosses zero. the path stops at Alpha, the coefficients minimizing
+//
+//	|Y-X·Coef|²/(2·nSamples) + Alpha·|Coef|₁
+//
+// as those of Lasso, or after MaxIter steps. the whole path costs about a least squares fit, much less than a
+// coordinate descent when there are many more features than samples
+type LassoLars struct {
+	LinearModel
+	Alpha   float64
+	MaxIter int
+	// Positive forces the coefficients to be >= 0, FitPath keeps LarsResult.CoefPath
+	Positive, FitPath bool
+	LarsResult        LarsResult
+}
+
+// NewLassoLars creates a *LassoLars with defaults: Alpha 1, MaxIter 500 and FitPath
+func NewLassoLars() *LassoLars {
+	regr := &LassoLars{Alpha: 1, MaxIter: 500, FitPath: true}
+	regr.FitIntercept = true
+	return regr
+}
+
+// Clone for LassoLars
+func (regr *LassoLars) Clone() base.Transformer {
+	clone := *regr
+	return &clone
+}
+
+// Fit learns Coef and Intercept from X,Y
+func (regr *LassoLars) Fit(X, Y *mat.Dense) base.Transformer {
+	if err := regr.FitE(X, Y); err != nil {
+		panic(err)
+	}
+	return regr
+}
+
+// FitE is Fit returning an error instead of panicking
+func (regr *LassoLars) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckFitXY("LassoLars.Fit", X, Y); err != nil {
+		return err
+	}
+	if err := checkAlphaL1Ratio(regr, regr.Alpha, 1); err != nil {
+		return err
+	}
+	if regr.MaxIter <= 0 {
+		return &base.ParamError{Estimator: regr, Param: "MaxIter", Value: regr.MaxIter, Msg: "must be > 0"}
+	}
+	return base.Recover(func() {
+		regr.LarsResult = larsFit(&regr.LinearModel, X, Y, "lasso", regr.Alpha, regr.MaxIter, regr.Positive, regr.FitPath)
+	})
+}
+
+// Predict puts into Y the predictions of X
+func (regr *LassoLars) Predict(X, Y *mat.Dense) base.Regressor {
+	regr.DecisionFunction(X, Y)
+	return regr
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (regr *LassoLars) PredictE(X, Y *mat.Dense) error {
+	if err := regr.checkPredict(regr, "LassoLars.Predict", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { regr.Predict(X, Y) })
+}
+
+// Transform is for Pipeline
+func (regr *LassoLars) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
+	r, c := Y.Dims()
+	Xout, Yout = X, mat.NewDense(r, c, nil)
+	regr.Predict(X, Yout)
+	return
+}
+
+// LassoLarsIC is a LassoLars whose Alpha is chosen on the lasso path by the Akaike or the Bayes information
+// criterion, the one of least
+//
+//	nSamples·log(2π·σ²) + |y-X·Coef|²/σ² + k·df
+//
+// σ² being the variance of the noise, df the number of nonzero coefficients and k 2 for aic, log(nSamples) for
+// bic. bic selects sparser models. Y must have a single column
+type LassoLarsIC struct {
+	LinearModel
+	// Criterion is aic or bic
+	Criterion string
+	MaxIter   int
+	Positive  bool
+	// NoiseVariance is σ², estimated by least squares if 0, which needs more samples than features
+	NoiseVariance float64
+
+	// Alpha is the alpha of least criterion, Criterions the criterion of each alpha of LarsResult and Variance
+	// the σ² used
+	Alpha      float64
+	Criterions []float64
+	Variance   float64
+	LarsResult LarsResult
+}
+
+// NewLassoLarsIC creates a *LassoLarsIC with defaults: Criterion aic and MaxIter 500
+func NewLassoLarsIC() *LassoLarsIC {
+	regr := &LassoLarsIC{Criterion: "aic", MaxIter: 500}
+	regr.FitIntercept = true
+	return regr
+}
+
+// Clone for LassoLarsIC
+func (regr *LassoLarsIC) Clone() base.Transformer {
+	clone := *regr
+	return &clone
+}
+
+// Fit learns Coef, Intercept and Alpha from X,Y
+func (regr *LassoLarsIC) Fit(X, Y *mat.Dense) base.Transformer {
+	if err := regr.FitE(X, Y); err != nil {
+		panic(err)
+	}
+	return regr
+}
+
+// FitE is Fit returning an error instead of panicking.
+// a *base.ValueError is returned if NoiseVariance is 0 and there are not more samples than features
+func (regr *LassoLarsIC) FitE(X, Y *mat.Dense) error {
+	const op = "LassoLarsIC.Fit"
+	if err := base.CheckFitXY(op, X, Y); err != nil {
+		return err
+	}
+	if _, nOutputs := Y.Dims(); nOutputs != 1 {
+		return &base.ShapeError{Op: op, Msg: fmt.Sprintf("Y has %d columns, expected 1", nOutputs)}
+	}
+	if regr.Criterion != "aic" && regr.Criterion != "bic" {
+		return &base.ParamError{Estimator: regr, Param: "Criterion", Value: regr.Criterion, Msg: "must be aic or bic"}
+	}
+	if regr.MaxIter <= 0 {
+		return &base.ParamError{Estimator: regr, Param: "MaxIter", Value: regr.MaxIter, Msg: "must be > 0"}
+	}
+	if !(regr.NoiseVariance >= 0) {
+		return &base.ParamError{Estimator: regr, Param: "NoiseVariance", Value: regr.NoiseVariance, Msg: "must be >= 0"}
+	}
+	nSamples, nFeatures := X.Dims()
+	if regr.FitIntercept {
+		nFeatures++
+	}
+	if regr.NoiseVariance == 0 && nSamples <= nFeatures {
+		return &base.ValueError{Op: op, Msg: fmt.Sprintf("%d samples are too few to estimate the noise variance of %d weights, set NoiseVariance", nSamples, nFeatures)}
+	}
+	return base.Recover(func() { regr.fit(X, Y) })
+}
+
+func (regr *LassoLarsIC) fit(X, Y *mat.Dense) {
+	var Xc, Yc, YOffset *mat.Dense
+	Xc, Yc, regr.XOffset, YOffset, regr.XScale = PreprocessData(X, Y, regr.FitIntercept, regr.Normalize, nil)
+	nSamples, nFeatures := X.Dims()
+	y := mat.Col(nil, 0, Yc)
+	variance := regr.NoiseVariance
+	if variance == 0 {
+		// the residuals of least squares, of nSamples-nFeatures-1 degrees of freedom with the intercept
+		w := &mat.Dense{}
+		w.Solve(Xc, Yc)
+		residual := &mat.Dense{}
+		residual.Mul(Xc, w)
+		residual.Sub(Yc, residual)
+		dof := nSamples - nFeatures
+		if regr.FitIntercept {
+			dof--
+		}
+		variance = mat.Norm(residual, 2) * mat.Norm(residual, 2) / float64(dof)
+	}
+	k := 2.
+	if regr.Criterion == "bic" {
+		k = math.Log(float64(nSamples))
+	}
+	alphas, active, coefs, nIter := LarsPath(Xc, y, "lasso", 0, regr.MaxIter, regr.Positive)
+	criterions := make([]float64, len(alphas))
+	best := 0
+	residual, coef := make([]float64, nSamples), make([]float64, nFeatures)
+	for a := range alphas {
+		mat.Col(coef, a, coefs)
+		copy(residual, y)
+		df := 0
+		for j, cj := range coef {
+			if math.Abs(cj) > 2.220446049250313e-16 {
+				floats.AddScaled(residual, -cj, mat.Col(nil, j, Xc))
+				df++
+			}
+		}
+		criterions[a] = float64(nSamples)*math.Log(2*math.Pi*variance) + floats.Dot(residual, residual)/variance + k*float64(df)
+		if criterions[a] < criterions[best] {
+			best = a
+		}
+	}
+	regr.Coef = mat.NewDense(nFeatures, 1, mat.Col(nil, best, coefs))
+	regr.setIntercept(regr.XOffset, YOffset, regr.XScale)
+	regr.Alpha, regr.Criterions, regr.Variance = alphas[best], criterions, variance
+	regr.LarsResult = LarsResult{Alphas: [][]float64{alphas}, Active: [][]int{active}, CoefPath: []*mat.Dense{coefs}, NIter: []int{nIter}}
+}
+
+// Predict puts into Y the predictions of X
+func (regr *LassoLarsIC) Predict(X, Y *mat.Dense) base.Regressor {
+	regr.DecisionFunction(X, Y)
+	return regr
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (regr *LassoLarsIC) PredictE(X, Y *mat.Dense) error {
+	if err := regr.checkPredict(regr, "LassoLarsIC.Predict", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { regr.Predict(X, Y) })
+}
+
+// Transform is for Pipeline
+func (regr *LassoLarsIC) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
+	r, c := Y.Dims()
+	Xout, Yout = X, mat.NewDense(r, c, nil)
+	regr.Predict(X, Yout)
+	return
+}
+
+// larsFit fits model to each output of X,Y by LarsPath, the coefficients being those of the end of the path
+func larsFit(model *LinearModel, X, Y *mat.Dense, method string, alphaMin float64, maxIter int, positive, fitPath bool) LarsResult {
+	var Xc, Yc, YOffset *mat.Dense
+	Xc, Yc, model.XOffset, YOffset, model.XScale = PreprocessData(X, Y, model.FitIntercept, model.Normalize, nil)
+	_, nFeatures := X.Dims()
+	_, nOutputs := Y.Dims()
+	result := LarsResult{Alphas: make([][]float64, nOutputs), Active: make([][]int, nOutputs), NIter: make([]int, nOutputs)}
+	if fitPath {
+		result.CoefPath = make([]*mat.Dense, nOutputs)
+	}
+	coef := mat.NewDense(nFeatures, nOutputs, nil)
+	for o := 0; o < nOutputs; o++ {
+		alphas, active, coefs, nIter := LarsPath(Xc, mat.Col(nil, o, Yc), method, alphaMin, maxIter, positive)
+		coef.SetCol(o, mat.Col(nil, len(alphas)-1, coefs))
+		result.Alphas[o], result.Active[o], result.NIter[o] = alphas, active, nIter
+		if fitPath {
+			result.CoefPath[o] = coefs
+		}
+	}
+	model.Coef = coef
+	model.setIntercept(model.XOffset, YOffset, model.XScale)
+	return result
+}
+
+// LarsPath computes the least angle regression or lasso path of X,y. the coefficients are piecewise linear in alpha,
+// the largest correlation of a feature of X with the residual divided by the number of samples: LarsPath returns
+// them at each breakpoint, from alphas[0] where they are all zero down to alphaMin.
+// method is "lar", each step adding a feature to the active set, or "lasso", which also drops a feature whose
+// coefficient crosses zero, so that each column of coefs is the Lasso solution of its alpha. maxIter bounds the
+// number of steps, positive forces the coefficients to be >= 0.
+// active is the active set at the end of the path in order of entry, coefs has a column per alpha.
+// no preprocessing is done here, you must have called PreprocessData before
+func LarsPath(X *mat.Dense, y []float64, method string, alphaMin float64, maxIter int, positive bool) (alphas []float64, active []int, coefs *mat.Dense, nIter int) {
+	if method != "lar" && method != "lasso" {
+		panic(&base.ValueError{Op: "LarsPath", Msg: fmt.Sprintf("method %q must be lar or lasso", method)})
+	}
+	const (
+		eps = 2.220446049250313e-16
+		// tiny, the smallest normal float32, keeps the step ratios finite
+		tiny = 1.1754943508222875e-38
+	)
+	nSamples, nFeatures := X.Dims()
+	// the rows of Xt are the features
+	Xt := mat.DenseCopyOf(X.T())
+	cov := make([]float64, nFeatures)
+	mat.NewVecDense(nFeatures, cov).MulVec(Xt, mat.NewVecDense(nSamples, y))
+	coef := make([]float64, nFeatures)
+	var path [][]float64
+	// isActive tells the features of the active set, ignored those collinear to it
+	isActive, ignored := make([]bool, nFeatures), make([]bool, nFeatures)
+	// signs are the signs of the correlations of the active features, L the Cholesky factor of their Gram matrix
+	var signs []float64
+	var L [][]float64
+	drop := false
+	corr, eqDir := make([]float64, nFeatures), make([]float64, nSamples)
+	for {
+		// cIdx is the inactive feature most correlated with the residual, added unless a feature has just been
+		// dropped. a feature collinear to the active set is ignored for good
+		var cIdx int
+		var c float64
+		var lRow []float64
+		for {
+			cIdx, c = -1, 0.
+			for j, cj := range cov {
+				if !positive {
+					cj = math.Abs(cj)
+				}
+				if !isActive[j] && !ignored[j] && cj > c {
+					cIdx, c = j, cj
+				}
+			}
+			if drop || cIdx < 0 || c/float64(nSamples) <= alphaMin+eps || nIter >= maxIter {
+				break
+			}
+			if lRow = larsCholeskyRow(Xt, L, active, cIdx); lRow != nil {
+				break
+			}
+			ignored[cIdx] = true
+		}
+		alpha := c / float64(nSamples)
+		if alpha <= alphaMin+eps || cIdx < 0 {
+			if cIdx >= 0 && math.Abs(alpha-alphaMin) > eps {
+				if nIter > 0 {
+					// back to the coefficients at alphaMin on the last segment
+					prevAlpha, prev := alphas[nIter-1], path[nIter-1]
+					ss := (prevAlpha - alphaMin) / (prevAlpha - alpha)
+					for j := range coef {
+						coef[j] = prev[j] + ss*(coef[j]-prev[j])
+					}
+				}
+				alpha = alphaMin
+			}
+			alphas, path = append(alphas, alpha), append(path, append([]float64{}, coef...))
+			break
+		}
+		alphas, path = append(alphas, alpha), append(path, append([]float64{}, coef...))
+		if nIter >= maxIter || len(active) >= nFeatures {
+			break
+		}
+		if !drop {
+			sign := 1.
+			if cov[cIdx] < 0 {
+				sign = -1
+			}
+			active, signs, L = append(active, cIdx), append(signs, sign), append(L, lRow)
+			isActive[cIdx] = true
+		}
+
+		// w, of Gram·w = signs, moves the active coefficients with equal correlations of their features
+		w := choleskySolve(L, signs)
+		aa := 1 / math.Sqrt(floats.Dot(w, signs))
+		floats.Scale(aa, w)
+		for i := range eqDir {
+			eqDir[i] = 0
+		}
+		for a, j := range active {
+			floats.AddScaled(eqDir, w[a], Xt.RawRowView(j))
+		}
+		mat.NewVecDense(nFeatures, corr).MulVec(Xt, mat.NewVecDense(nSamples, eqDir))
+		// gamma is the step to the next feature as correlated as the active ones
+		gamma := c / aa
+		for j := range cov {
+			if isActive[j] || ignored[j] {
+				continue
+			}
+			if g := (c - cov[j]) / (aa - corr[j] + tiny); g > 0 && g < gamma {
+				gamma = g
+			}
+			if g := (c + cov[j]) / (aa + corr[j] + tiny); !positive && g > 0 && g < gamma {
+				gamma = g
+			}
+		}
+		// the lasso stops the step where an active coefficient crosses zero
+		drop = false
+		var dropped []bool
+		if method == "lasso" {
+			zMin := math.Inf(1)
+			for a, j := range active {
+				if z := -coef[j] / (w[a] + tiny); z > 0 && z < zMin {
+					zMin = z
+				}
+			}
+			if zMin < gamma {
+				gamma, drop, dropped = zMin, true, make([]bool, len(active))
+				for a, j := range active {
+					dropped[a] = -coef[j]/(w[a]+tiny) == zMin
+				}
+			}
+		}
+		nIter++
+		for a, j := range active {
+			coef[j] += gamma * w[a]
+		}
+		floats.AddScaled(cov, -gamma, corr)
+		if drop {
+			var kept []int
+			var keptSigns []float64
+			for a, j := range active {
+				if dropped[a] {
+					coef[j], isActive[j] = 0, false
+					continue
+				}
+				kept, keptSigns = append(kept, j), append(keptSigns, signs[a])
+			}
+			active, signs, L = kept, keptSigns, nil
+			for a := range active {
+				L = append(L, larsCholeskyRow(Xt, L, active[:a], active[a]))
+			}
+			// the correlations of the dropped features, from the residual
+			residual := append([]float64{}, y...)
+			for _, j := range active {
+				floats.AddScaled(residual, -coef[j], Xt.RawRowView(j))
+			}
+			for j := range cov {
+				if !isActive[j] && !ignored[j] {
+					cov[j] = floats.Dot(Xt.RawRowView(j), residual)
+				}
+			}
+		}
+	}
+	coefs = mat.NewDense(nFeatures, len(path), nil)
+	for k, p := range path {
+		coefs.SetCol(k, p)
+	}
+	return
+}
+
+// larsCholeskyRow returns the row of the Cholesky factor of the Gram matrix of the features active,+j, of factor L
+// for active, or nil if the feature j of Xt is collinear to them
+func larsCholeskyRow(Xt *mat.Dense, L [][]float64, active []int, j int) []float64 {
+	xj := Xt.RawRowView(j)
+	b := make([]float64, len(active)+1)
+	for a, k := range active {
+		b[a] = floats.Dot(Xt.RawRowView(k), xj)
+	}
+	// forward substitution of L·v = b
+	for a := range active {
+		b[a] = (b[a] - floats.Dot(L[a][:a], b[:a])) / L[a][a]
+	}
+	cjj := floats.Dot(xj, xj)
+	diag := cjj - floats.Dot(b[:len(active)], b[:len(active)])
+	if !(diag > 1e-14*cjj) {
+		return nil
+	}
+	b[len(active)] = math.Sqrt(diag)
+	return b
+}
+
+// choleskySolve returns x of L·Lᵀ·x = b, L being lower triangular
+func choleskySolve(L [][]float64, b []float64) []float64 {
+	x := append([]float64{}, b...)
+	for a := range x {
+		x[a] = (x[a] - floats.Dot(L[a][:a], x[:a])) / L[a][a]
+	}
+	for a := len(x) - 1; a >= 0; a-- {
+		for k := a + 1; k < len(x); k++ {
+			x[a] -= L[k][a] * x[k]
+		}
+		x[a] /= L[a][a]
+	}
+	return x
+}
@@ -0,0 +1,225 @@
+package linearmodel
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// newGenomicsProblem returns X of nFeatures gaussian features and Y=1+3·x0-2·x1+1.5·x2 plus a small noise
+func newGenomicsProblem(nSamples, nFeatures int) (X, Y *mat.Dense) {
+	rnd := rand.New(rand.NewSource(7))
+	X, Y = mat.NewDense(nSamples, nFeatures, nil), mat.NewDense(nSamples, 1, nil)
+	for i := 0; i < nSamples; i++ {
+		row := X.RawRowView(i)
+		for j := range row {
+			row[j] = rnd.NormFloat64()
+		}
+		Y.Set(i, 0, 1+3*row[0]-2*row[1]+1.5*row[2]+.1*rnd.NormFloat64())
+	}
+	return
+}
+
+func ExampleLarsPath() {
+	X := mat.NewDense(4, 3, []float64{1, 0, 0, 0, 1, 0, 0, 0, 1, 1, 1, 1})
+	y := []float64{3, -2, 1, 2}
+	X, Y, _, _, _ := PreprocessData(X, mat.NewDense(4, 1, y), true, false, nil)
+	alphas, active, coefs, nIter := LarsPath(X, mat.Col(nil, 0, Y), "lasso", 0, 500, false)
+	fmt.Printf("alphas:%.3f active:%v steps:%d\n", alphas, active, nIter)
+	fmt.Printf("%.3f\n", mat.Formatted(coefs))
+	// Output:
+	// alphas:[0.750 0.500 0.250 0.000] active:[0 1 2] steps:3
+	// ⎡ 0.000   1.000   2.000   3.000⎤
+	// ⎢ 0.000   0.000  -1.000  -2.000⎥
+	// ⎣ 0.000   0.000   0.000   1.000⎦
+}
+
+func ExampleLars() {
+	// 3 informative features out of 200, with 40 samples
+	X, Y := newGenomicsProblem(40, 200)
+	regr := NewLars()
+	regr.NNonzeroCoefs = 6
+	regr.Fit(X, Y)
+	fmt.Printf("active:%v coef:%.1f\n", regr.LarsResult.Active[0], mat.Col(nil, 0, regr.Coef)[:3])
+	// Output:
+	// active:[0 46 1 102 2 60] coef:[3.0 -1.9 1.4]
+}
+
+func ExampleLassoLars() {
+	X, Y := newGenomicsProblem(40, 200)
+	regr := NewLassoLars()
+	regr.Alpha = .1
+	regr.Fit(X, Y)
+	nonzero := 0
+	for _, c := range mat.Col(nil, 0, regr.Coef) {
+		if c != 0 {
+			nonzero++
+		}
+	}
+	fmt.Printf("nonzero:%d coef:%.1f intercept:%.1f\n", nonzero, mat.Col(nil, 0, regr.Coef)[:3], regr.Intercept.At(0, 0))
+	// Output:
+	// nonzero:5 coef:[2.9 -1.8 1.4] intercept:1.0
+}
+
+func ExampleLassoLarsIC() {
+	X, Y := newGenomicsProblem(100, 10)
+	for _, criterion := range []string{"aic", "bic"} {
+		regr := NewLassoLarsIC()
+		regr.Criterion = criterion
+		regr.Fit(X, Y)
+		fmt.Printf("%s alpha:%.3f coef:%.2f\n", criterion, regr.Alpha, mat.Col(nil, 0, regr.Coef))
+	}
+	// Output:
+	// aic alpha:0.010 coef:[2.97 -1.97 1.50 0.00 0.00 0.00 0.00 0.00 0.00 -0.00]
+	// bic alpha:0.012 coef:[2.97 -1.97 1.50 0.00 0.00 0.00 0.00 0.00 0.00 0.00]
+}
+
+func TestLarsPath(t *testing.T) {
+	X, Y := newGenomicsProblem(30, 60)
+	X, Y, _, _, _ = PreprocessData(X, Y, true, false, nil)
+	y := mat.Col(nil, 0, Y)
+	nSamples, nFeatures := X.Dims()
+	for _, positive := range []bool{false, true} {
+		alphas, _, coefs, _ := LarsPath(X, y, "lasso", 0, 500, positive)
+		// each column is the Lasso solution of its alpha: |Xᵀ·r|/nSamples <= alpha, with equality and the sign of
+		// the coefficient for a nonzero one
+		for a, alpha := range alphas {
+			if a > 0 && alpha > alphas[a-1] {
+				t.Errorf("positive %v: alphas are not decreasing: %g", positive, alphas)
+			}
+			coef := mat.Col(nil, a, coefs)
+			residual := &mat.VecDense{}
+			residual.MulVec(X, mat.NewVecDense(nFeatures, coef))
+			residual.SubVec(mat.NewVecDense(nSamples, y), residual)
+			for j, cj := range coef {
+				corr := mat.Dot(X.ColView(j), residual) / float64(nSamples)
+				switch {
+				case positive && cj < 0:
+					t.Errorf("negative coefficient %d at alpha %g", j, alpha)
+				case cj != 0 && math.Abs(corr-alpha*math.Copysign(1, cj)) > 1e-8:
+					t.Errorf("positive %v alpha %g: correlation %d is %g with coefficient %g", positive, alpha, j, corr, cj)
+				case cj == 0 && !positive && math.Abs(corr) > alpha+1e-8, cj == 0 && positive && corr > alpha+1e-8:
+					t.Errorf("positive %v alpha %g: correlation %d is %g for a zero coefficient", positive, alpha, j, corr)
+				}
+			}
+		}
+	}
+	// lar adds a feature per step, until the residual is zero
+	alphas, active, _, nIter := LarsPath(X, y, "lar", 0, 500, false)
+	if nIter != len(active) || nIter != len(alphas)-1 || alphas[len(alphas)-1] != 0 {
+		t.Errorf("expected a feature per step down to alpha 0, got %d steps, active %v, alphas %g", nIter, active, alphas)
+	}
+	// maxIter stops the path
+	if alphas, _, coefs, nIter := LarsPath(X, y, "lar", 0, 2, false); nIter != 2 || len(alphas) != 3 || len(nonZero(mat.Col(nil, 2, coefs))) != 2 {
+		t.Errorf("expected 2 steps, got %d, alphas %g", nIter, alphas)
+	}
+}
+
+// nonZero returns the indices of the nonzero elements of x
+func nonZero(x []float64) []int {
+	var nonzero []int
+	for j, v := range x {
+		if v != 0 {
+			nonzero = append(nonzero, j)
+		}
+	}
+	return nonzero
+}
+
+func TestLassoLars(t *testing.T) {
+	// LassoLars and the coordinate descent Lasso minimize the same objective
+	X, Y := newGenomicsProblem(50, 20)
+	for _, alpha := range []float64{.5, .05, .005} {
+		lars, lasso := NewLassoLars(), NewLasso()
+		lars.Alpha, lasso.Alpha, lasso.Tol = alpha, alpha, 1e-10
+		lars.Fit(X, Y)
+		lasso.Fit(X, Y)
+		if !floats.EqualApprox(mat.Col(nil, 0, lars.Coef), mat.Col(nil, 0, lasso.Coef), 1e-6) || math.Abs(lars.Intercept.At(0, 0)-lasso.Intercept.At(0, 0)) > 1e-6 {
+			t.Errorf("alpha %g: LassoLars coef %.6f, Lasso coef %.6f", alpha, mat.Col(nil, 0, lars.Coef), mat.Col(nil, 0, lasso.Coef))
+		}
+	}
+	// a large Alpha leaves all the coefficients zero
+	lars := NewLassoLars()
+	lars.Alpha = 100
+	lars.FitPath = false
+	if err := lars.FitE(X, Y); err != nil || len(nonZero(mat.Col(nil, 0, lars.Coef))) != 0 || lars.LarsResult.CoefPath != nil {
+		t.Errorf("expected no nonzero coefficient and no path, got %v %g", err, mat.Col(nil, 0, lars.Coef))
+	}
+
+	// Lars with all the features is least squares, with several outputs and Normalize
+	Y2 := mat.NewDense(50, 2, nil)
+	Y2.SetCol(0, mat.Col(nil, 0, Y))
+	Y2.SetCol(1, floats.ScaleTo(make([]float64, 50), -2, mat.Col(nil, 0, Y)))
+	regr, ols := NewLars(), NewLinearRegression()
+	regr.Normalize = true
+	regr.Fit(X, Y2)
+	ols.Fit(X, Y2)
+	if !mat.EqualApprox(regr.Coef, ols.Coef, 1e-8) || !mat.EqualApprox(regr.Intercept, ols.Intercept, 1e-8) {
+		t.Errorf("expected least squares coefficients, got %.4f", mat.Formatted(regr.Coef.T()))
+	}
+	if len(regr.LarsResult.Active) != 2 || len(regr.LarsResult.Active[1]) != 20 {
+		t.Errorf("expected 20 active features for each output, got %v", regr.LarsResult.Active)
+	}
+}
+
+func TestLassoLarsIC(t *testing.T) {
+	X, Y := newGenomicsProblem(40, 200)
+	regr := NewLassoLarsIC()
+	regr.Criterion = "bic"
+	var valueErr *base.ValueError
+	if err := regr.FitE(X, Y); !errors.As(err, &valueErr) {
+		t.Errorf("expected a *base.ValueError with more features than samples, got %v", err)
+	}
+	// with the noise variance known, the informative features are found among 200, bic selecting fewer than aic
+	regr.NoiseVariance = .01
+	if err := regr.FitE(X, Y); err != nil {
+		t.Fatal(err)
+	}
+	aic := NewLassoLarsIC()
+	aic.NoiseVariance = .01
+	aic.Fit(X, Y)
+	nonzero, nonzeroAIC := nonZero(mat.Col(nil, 0, regr.Coef)), nonZero(mat.Col(nil, 0, aic.Coef))
+	if len(nonzero) < 3 || fmt.Sprint(nonzero[:3]) != "[0 1 2]" || len(nonzero) > len(nonzeroAIC) {
+		t.Errorf("expected the features [0 1 2] and fewer for bic, got %v for bic and %v for aic", nonzero, nonzeroAIC)
+	}
+	if len(regr.Criterions) != len(regr.LarsResult.Alphas[0]) || regr.Variance != .01 {
+		t.Errorf("expected a criterion per alpha and the given variance, got %d criterions for %d alphas, variance %g", len(regr.Criterions), len(regr.LarsResult.Alphas[0]), regr.Variance)
+	}
+	best := floats.MinIdx(regr.Criterions)
+	if regr.Alpha != regr.LarsResult.Alphas[0][best] {
+		t.Errorf("expected the alpha of least criterion %g, got %g", regr.LarsResult.Alphas[0][best], regr.Alpha)
+	}
+
+	var shapeErr *base.ShapeError
+	if err := regr.FitE(X, mat.NewDense(40, 2, nil)); !errors.As(err, &shapeErr) {
+		t.Errorf("expected a *base.ShapeError for 2 outputs, got %v", err)
+	}
+	for _, params := range []func(*LassoLarsIC){
+		func(regr *LassoLarsIC) { regr.Criterion = "cv" },
+		func(regr *LassoLarsIC) { regr.MaxIter = 0 },
+		func(regr *LassoLarsIC) { regr.NoiseVariance = -1 },
+	} {
+		regr := NewLassoLarsIC()
+		params(regr)
+		var paramErr *base.ParamError
+		if err := regr.FitE(X, Y); !errors.As(err, &paramErr) {
+			t.Errorf("expected a *base.ParamError, got %v", err)
+		}
+	}
+	for _, regr := range []interface{ FitE(X, Y *mat.Dense) error }{
+		&Lars{NNonzeroCoefs: 0},
+		&LassoLars{Alpha: -1, MaxIter: 10},
+		&LassoLars{Alpha: 1, MaxIter: 0},
+	} {
+		var paramErr *base.ParamError
+		if err := regr.FitE(X, Y); !errors.As(err, &paramErr) {
+			t.Errorf("%T: expected a *base.ParamError, got %v", regr, err)
+		}
+	}
+}
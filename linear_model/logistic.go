@@ -1,21 +1,52 @@
 package linearmodel
 
 import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
 	"github.com/pa-m/sklearn/base"
 	"github.com/pa-m/sklearn/preprocessing"
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
 	"gonum.org/v1/gonum/optimize"
 )
 
-// LogisticRegression WIP
+// LogisticRegression is a logistic regression classifier.
+// Solver is lbfgs, newton-cg, liblinear (coordinate descent), sag or saga, minimizing the log loss plus
+// the penalty scaled by 1/C: Penalty is l2 (default), l1 (liblinear, saga), elasticnet (saga, mixed by L1Ratio) or
+// none. Alpha is used as 1/C when C is 0. the intercept is not penalized.
+// MultiClass is auto, ovr or multinomial: auto is multinomial (softmax) for more than 2 classes except with
+// liblinear, which fits one-vs-rest only. a binary problem is fitted as a single logistic output.
+// the solvers stop at MaxIter iterations (epochs for liblinear, sag and saga) or when Tol is reached,
+// FitE then returns a *base.ConvergenceError if Tol was not reached.
+// Solver may also be "" (default) or a gradient descent solver of base.Solvers, Y is then fitted one-vs-rest by
+// RegularizedRegression with Alpha, L1Ratio and Options, and Penalty, C, MaxIter and MultiClass are not used.
+// ClassWeight is nil, "balanced" or a map[float64]float64 from class labels to weights, see base.ComputeClassWeight
 type LogisticRegression struct {
 	RegularizedRegression
 	LabelBinarizer *preprocessing.LabelBinarizer
+	ClassWeight    interface{}
+	Penalty        string
+	C              float64
+	MaxIter        int
+	MultiClass     string
+	// Classes are the sorted classes of Y, set by the logistic solvers
+	Classes        []float64
+	LogisticResult LogisticResult
+}
+
+// LogisticResult is the solver specific part of a LogisticRegression fit. NIter is the largest number of
+// iterations of the fitted outputs
+type LogisticResult struct {
+	NIter                  int
+	Converged, Multinomial bool
 }
 
 // NewLogisticRegression create and init a *LogisticRegression
 func NewLogisticRegression() *LogisticRegression {
-	regr := &LogisticRegression{}
+	regr := &LogisticRegression{Penalty: "l2", MaxIter: 100, MultiClass: "auto"}
 	//regr.StepSize = 1e-4
 	regr.Tol = 1e-6
 	//regr.Optimizer = base.NewAdamOptimizer()
@@ -63,25 +94,271 @@ func (regr *LogisticRegression) EncodeLabels(Ytrue *mat.Dense) *mat.Dense {
 
 // Fit for LogisticRegression
 func (regr *LogisticRegression) Fit(X, Ycls *mat.Dense) base.Transformer {
+	if err := regr.fit(context.Background(), X, Ycls, nil); err != nil {
+		panic(err)
+	}
+	return regr
+}
+
+// fit encodes Ycls and fits it with sampleWeight multiplied by the ClassWeight of each sample, with the logistic
+// solvers or the RegularizedRegression
+func (regr *LogisticRegression) fit(ctx context.Context, X mat.Matrix, Ycls *mat.Dense, sampleWeight []float64) error {
+	sampleWeight, err := base.ApplyClassWeight(regr.ClassWeight, Ycls, sampleWeight)
+	if err != nil {
+		return err
+	}
+	if logisticSolvers[regr.Solver] {
+		return regr.fitSolver(ctx, X, Ycls, sampleWeight)
+	}
+	regr.Classes, regr.LogisticResult = nil, LogisticResult{}
 	Y := regr.EncodeLabels(Ycls)
-	return regr.RegularizedRegression.Fit(X, Y)
+	return regr.RegularizedRegression.fit(ctx, X, Y, sampleWeight)
+}
+
+// fitSolver fits Coef and Intercept with the logistic solver Solver. a single column Ycls holds classes,
+// a Ycls of several columns is fitted one-vs-rest column by column
+func (regr *LogisticRegression) fitSolver(ctx context.Context, X mat.Matrix, Ycls *mat.Dense, sampleWeight []float64) error {
+	if err := regr.checkSolver(); err != nil {
+		return err
+	}
+	Y := Ycls
+	regr.LabelBinarizer, regr.Classes = nil, nil
+	if _, nOutputs := Ycls.Dims(); nOutputs == 1 {
+		regr.Classes = sortedClasses(Ycls)
+		if len(regr.Classes) < 2 {
+			return &base.ValueError{Op: "LogisticRegression.Fit", Msg: "Y must have at least 2 classes"}
+		}
+		Y = oneHot(Ycls, regr.Classes)
+	}
+	nSamples, nFeatures := X.Dims()
+	_, nOutputs := Y.Dims()
+	multinomial := nOutputs > 1 && regr.Classes != nil && (regr.MultiClass == "multinomial" ||
+		(regr.MultiClass == "auto" || regr.MultiClass == "") && regr.Solver != "liblinear")
+	var Xd *mat.Dense
+	if regr.Solver == "liblinear" || regr.Solver == "sag" || regr.Solver == "saga" {
+		Xd = denseOf(X)
+	}
+	l1, l2 := regr.penalty()
+	regr.Coef, regr.Intercept = mat.NewDense(nFeatures, nOutputs, nil), mat.NewDense(1, nOutputs, nil)
+	regr.LogisticResult = LogisticResult{Converged: true, Multinomial: multinomial}
+	// the problems: a single multinomial one, or a binary one per output
+	problems := []*mat.Dense{Y}
+	if !multinomial {
+		problems = make([]*mat.Dense, nOutputs)
+		for o := range problems {
+			problems[o] = mat.NewDense(nSamples, 1, mat.Col(nil, o, Y))
+		}
+	}
+	for p, Yp := range problems {
+		f := newLogisticLoss(X, Yp, sampleWeight, multinomial, regr.FitIntercept, l1, l2)
+		theta, nIter, converged, err := regr.solve(ctx, f, Xd)
+		if err != nil {
+			return err
+		}
+		W, b := f.params(theta)
+		for o := 0; o < f.nOutputs; o++ {
+			regr.Coef.SetCol(p+o, mat.Col(nil, o, W))
+			if b != nil {
+				regr.Intercept.Set(0, p+o, b[o])
+			}
+		}
+		if nIter > regr.LogisticResult.NIter {
+			regr.LogisticResult.NIter = nIter
+		}
+		regr.LogisticResult.Converged = regr.LogisticResult.Converged && converged
+	}
+	return nil
+}
+
+// solve minimizes f with Solver. Xd is X as a *mat.Dense for liblinear, sag and saga
+func (regr *LogisticRegression) solve(ctx context.Context, f *logisticLoss, Xd *mat.Dense) (theta []float64, nIter int, converged bool, err error) {
+	progress := newSolverProgress(ctx, regr, regr.MaxIter)
+	switch regr.Solver {
+	case "newton-cg":
+		return newtonCG(f, regr.MaxIter, regr.Tol, progress)
+	case "liblinear":
+		return coordinateDescent(f, Xd, regr.MaxIter, regr.Tol, progress)
+	case "sag", "saga":
+		rng := base.CheckRandomState(regr.RandomState).Rand()
+		return stochasticAverageGradient(f, Xd, regr.Solver == "saga", rng, regr.MaxIter, regr.Tol, progress)
+	}
+	return lbfgsSolve(ctx, regr, f, make([]float64, f.nParams()), regr.MaxIter, regr.Tol)
+}
+
+// penalty returns the strengths of the l1 and l2 penalties: 1/C, or Alpha if C is 0, split according to Penalty
+func (regr *LogisticRegression) penalty() (l1, l2 float64) {
+	strength := regr.Alpha
+	if regr.C > 0 {
+		strength = 1 / regr.C
+	}
+	switch regr.Penalty {
+	case "none":
+		return 0, 0
+	case "l1":
+		return strength, 0
+	case "elasticnet":
+		return strength * regr.L1Ratio, strength * (1 - regr.L1Ratio)
+	}
+	return 0, strength
+}
+
+// checkSolver returns a *base.ParamError if Solver is not a known solver, or if the parameters of a logistic solver
+// are invalid or not supported by Solver
+func (regr *LogisticRegression) checkSolver() error {
+	if !logisticSolvers[regr.Solver] {
+		if _, ok := base.Solvers[regr.Solver]; regr.Solver != "" && !ok {
+			return &base.ParamError{Estimator: regr, Param: "Solver", Value: regr.Solver}
+		}
+		return nil
+	}
+	if regr.C < 0 || math.IsNaN(regr.C) {
+		return &base.ParamError{Estimator: regr, Param: "C", Value: regr.C, Msg: "must be >= 0"}
+	}
+	if regr.MaxIter <= 0 {
+		return &base.ParamError{Estimator: regr, Param: "MaxIter", Value: regr.MaxIter, Msg: "must be > 0"}
+	}
+	switch regr.Penalty {
+	case "", "l2", "none":
+	case "l1":
+		if regr.Solver != "liblinear" && regr.Solver != "saga" {
+			return &base.ParamError{Estimator: regr, Param: "Penalty", Value: regr.Penalty, Msg: "l1 is supported by liblinear and saga only"}
+		}
+	case "elasticnet":
+		if regr.Solver != "saga" {
+			return &base.ParamError{Estimator: regr, Param: "Penalty", Value: regr.Penalty, Msg: "elasticnet is supported by saga only"}
+		}
+	default:
+		return &base.ParamError{Estimator: regr, Param: "Penalty", Value: regr.Penalty, Msg: "must be l1, l2, elasticnet or none"}
+	}
+	switch regr.MultiClass {
+	case "", "auto", "ovr":
+	case "multinomial":
+		if regr.Solver == "liblinear" {
+			return &base.ParamError{Estimator: regr, Param: "MultiClass", Value: regr.MultiClass, Msg: "liblinear fits one-vs-rest only"}
+		}
+	default:
+		return &base.ParamError{Estimator: regr, Param: "MultiClass", Value: regr.MultiClass, Msg: "must be auto, ovr or multinomial"}
+	}
+	return nil
+}
+
+// checkFit checks X,Ycls and the parameters of the fit
+func (regr *LogisticRegression) checkFit(op string, X, Ycls *mat.Dense) error {
+	if err := base.CheckFitXY(op, X, Ycls); err != nil {
+		return err
+	}
+	if err := checkAlphaL1Ratio(regr, regr.Alpha, regr.L1Ratio); err != nil {
+		return err
+	}
+	return regr.checkSolver()
+}
+
+// sortedClasses returns the sorted distinct values of the first column of Ycls
+func sortedClasses(Ycls *mat.Dense) []float64 {
+	seen := make(map[float64]bool)
+	var classes []float64
+	for _, y := range mat.Col(nil, 0, Ycls) {
+		if !seen[y] {
+			seen[y] = true
+			classes = append(classes, y)
+		}
+	}
+	sort.Float64s(classes)
+	return classes
+}
+
+// oneHot returns the one-hot encoding of the first column of Ycls, or a single column indicating the second class
+// if there are 2 classes
+func oneHot(Ycls *mat.Dense, classes []float64) *mat.Dense {
+	nSamples, _ := Ycls.Dims()
+	nOutputs := len(classes)
+	if nOutputs == 2 {
+		nOutputs = 1
+	}
+	Y := mat.NewDense(nSamples, nOutputs, nil)
+	for i := 0; i < nSamples; i++ {
+		k := sort.SearchFloat64s(classes, Ycls.At(i, 0))
+		if nOutputs == 1 {
+			Y.Set(i, 0, float64(k))
+		} else {
+			Y.Set(i, k, 1)
+		}
+	}
+	return Y
+}
+
+// denseOf returns X if it is a *mat.Dense, else a *mat.Dense copy of X
+func denseOf(X mat.Matrix) *mat.Dense {
+	if Xd, ok := X.(*mat.Dense); ok {
+		return Xd
+	}
+	return mat.DenseCopyOf(X)
 }
 
-// PredictProba predicts probability of y=1 for X using Coef
+// PredictProba predicts probability of y=1 for X using Coef.
+// with more than 2 Classes, Y has a column per class: the softmax of the decision function if the fit was multinomial,
+// else its logistic normalized to sum 1
 func (regr *LogisticRegression) PredictProba(X, Y *mat.Dense) {
-	regr.DecisionFunction(X, Y)
-	Y.Apply(func(i int, o int, y float64) float64 {
-		return regr.ActivationFunction.F(y)
-	}, Y)
+	regr.predictProba(X, Y)
+}
+
+func (regr *LogisticRegression) predictProba(X mat.Matrix, Y *mat.Dense) {
+	regr.decisionFunction(X, Y)
+	if regr.Classes == nil {
+		Y.Apply(func(i int, o int, y float64) float64 {
+			return regr.ActivationFunction.F(y)
+		}, Y)
+		return
+	}
+	nSamples, nOutputs := Y.Dims()
+	for i := 0; i < nSamples; i++ {
+		row := Y.RawRowView(i)
+		if regr.LogisticResult.Multinomial {
+			lse := logSumExp(row)
+			for o, z := range row {
+				row[o] = math.Exp(z - lse)
+			}
+			continue
+		}
+		for o, z := range row {
+			row[o] = expit(z)
+		}
+		if nOutputs > 1 {
+			floats.Scale(1/floats.Sum(row), row)
+		}
+	}
+}
+
+// PredictLogProba is the log of PredictProba
+func (regr *LogisticRegression) PredictLogProba(X, Y *mat.Dense) {
+	if !regr.LogisticResult.Multinomial || regr.Classes == nil {
+		regr.predictProba(X, Y)
+		Y.Apply(func(_, _ int, p float64) float64 { return math.Log(p) }, Y)
+		return
+	}
+	regr.decisionFunction(X, Y)
+	nSamples, _ := Y.Dims()
+	for i := 0; i < nSamples; i++ {
+		row := Y.RawRowView(i)
+		floats.AddConst(-logSumExp(row), row)
+	}
 }
 
 // Predict predicts y for X using Coef
 func (regr *LogisticRegression) Predict(X, Ycls *mat.Dense) {
+	regr.predict(X, Ycls)
+}
+
+func (regr *LogisticRegression) predict(X mat.Matrix, Ycls *mat.Dense) {
+	if regr.Classes != nil {
+		regr.predictClasses(X, Ycls)
+		return
+	}
 	var Y = Ycls
 	if regr.LabelBinarizer != nil {
 		Y = &mat.Dense{}
 	}
-	regr.PredictProba(X, Y)
+	regr.predictProba(X, Y)
 	//nSamples, nOutputs := Y.Dims()
 	if regr.LabelBinarizer != nil {
 		_, Ycls1 := regr.LabelBinarizer.InverseTransform(nil, Y)
@@ -93,3 +370,61 @@ func (regr *LogisticRegression) Predict(X, Ycls *mat.Dense) {
 
 	}
 }
+
+// predictClasses puts into Ycls the class of highest decision function, or the second class if the single
+// decision function of a binary problem is positive
+func (regr *LogisticRegression) predictClasses(X mat.Matrix, Ycls *mat.Dense) {
+	Z := &mat.Dense{}
+	regr.decisionFunction(X, Z)
+	nSamples, _ := Z.Dims()
+	if Ycls.IsZero() {
+		*Ycls = *mat.NewDense(nSamples, 1, nil)
+	}
+	for i := 0; i < nSamples; i++ {
+		row := Z.RawRowView(i)
+		k := floats.MaxIdx(row)
+		if len(row) == 1 {
+			k = 0
+			if row[0] > 0 {
+				k = 1
+			}
+		}
+		Ycls.Set(i, 0, regr.Classes[k])
+	}
+}
+
+// FitE is Fit returning an error instead of panicking.
+// a *base.ConvergenceError is returned if a logistic solver did not reach Tol in MaxIter iterations, the model is still usable
+func (regr *LogisticRegression) FitE(X, Ycls *mat.Dense) error {
+	if err := regr.checkFit("LogisticRegression.Fit", X, Ycls); err != nil {
+		return err
+	}
+	return regr.fitE(context.Background(), X, Ycls, nil)
+}
+
+func (regr *LogisticRegression) fitE(ctx context.Context, X, Ycls *mat.Dense, sampleWeight []float64) error {
+	var err error
+	if rerr := base.Recover(func() { err = regr.fit(ctx, X, Ycls, sampleWeight) }); rerr != nil {
+		return rerr
+	}
+	if err == nil && regr.Classes != nil && !regr.LogisticResult.Converged {
+		err = &base.ConvergenceError{Estimator: regr, NIter: regr.LogisticResult.NIter, Msg: fmt.Sprintf("%s solver did not reach tolerance %g", regr.Solver, regr.Tol)}
+	}
+	return err
+}
+
+// FitContext is FitE stopping between epochs when ctx is done. see RegularizedRegression.FitContext
+func (regr *LogisticRegression) FitContext(ctx context.Context, X, Ycls *mat.Dense) error {
+	if err := regr.checkFit("LogisticRegression.Fit", X, Ycls); err != nil {
+		return err
+	}
+	return regr.fitE(ctx, X, Ycls, nil)
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (regr *LogisticRegression) PredictE(X, Ycls *mat.Dense) error {
+	if err := regr.checkPredict(regr, "LogisticRegression.Predict", X, Ycls); err != nil {
+		return err
+	}
+	return base.Recover(func() { regr.Predict(X, Ycls) })
+}
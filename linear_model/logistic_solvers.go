@@ -0,0 +1,495 @@
+package linearmodel
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// logisticSolvers are the LogisticRegression solvers minimizing logisticLoss. the other solvers are the
+// gradient descent ones of base.Solvers
+var logisticSolvers = map[string]bool{"lbfgs": true, "newton-cg": true, "liblinear": true, "sag": true, "saga": true}
+
+// logisticLoss is the objective of the logistic solvers: the sum of the log losses weighted by sampleWeight plus
+// the penalty, all divided by the sum of the weights (ie scikit-learn objective C·Σloss+penalty scaled by 1/(C·Σweights)).
+// when multinomial is true, Y is one-hot and the loss is the cross entropy of the softmax of X·W+b, else Y is a
+// single 0/1 column and the loss is the one of the logistic of X·W+b.
+// the parameters theta are W (nFeatures×nOutputs, row major) followed by b if fitIntercept. b is not penalized
+type logisticLoss struct {
+	X                             mat.Matrix
+	Y                             *mat.Dense
+	sampleWeight                  []float64
+	multinomial, fitIntercept     bool
+	l1, l2                        float64
+	nSamples, nFeatures, nOutputs int
+}
+
+// newLogisticLoss returns the logisticLoss of X,Y. sampleWeight may be nil. l1 and l2 are the penalty strengths
+func newLogisticLoss(X mat.Matrix, Y *mat.Dense, sampleWeight []float64, multinomial, fitIntercept bool, l1, l2 float64) *logisticLoss {
+	nSamples, nFeatures := X.Dims()
+	_, nOutputs := Y.Dims()
+	sw := make([]float64, nSamples)
+	sum := float64(nSamples)
+	if sampleWeight != nil {
+		sum = floats.Sum(sampleWeight)
+	}
+	for i := range sw {
+		sw[i] = 1 / sum
+		if sampleWeight != nil {
+			sw[i] = sampleWeight[i] / sum
+		}
+	}
+	return &logisticLoss{X: X, Y: Y, sampleWeight: sw, multinomial: multinomial, fitIntercept: fitIntercept,
+		l1: l1 / sum, l2: l2 / sum, nSamples: nSamples, nFeatures: nFeatures, nOutputs: nOutputs}
+}
+
+func (f *logisticLoss) nParams() int {
+	if f.fitIntercept {
+		return (f.nFeatures + 1) * f.nOutputs
+	}
+	return f.nFeatures * f.nOutputs
+}
+
+// params returns W and b viewing theta. b is nil if !fitIntercept
+func (f *logisticLoss) params(theta []float64) (W *mat.Dense, b []float64) {
+	n := f.nFeatures * f.nOutputs
+	W = mat.NewDense(f.nFeatures, f.nOutputs, theta[:n])
+	if f.fitIntercept {
+		b = theta[n : n+f.nOutputs]
+	}
+	return
+}
+
+// linear returns X·W+b
+func (f *logisticLoss) linear(theta []float64) *mat.Dense {
+	W, b := f.params(theta)
+	Z := &mat.Dense{}
+	mulTo(Z, f.X, W)
+	if b != nil {
+		for i := 0; i < f.nSamples; i++ {
+			floats.Add(Z.RawRowView(i), b)
+		}
+	}
+	return Z
+}
+
+// probabilities replaces each row of Z with the softmax of the row if multinomial, else with the logistic of Z
+func (f *logisticLoss) probabilities(Z *mat.Dense) {
+	for i := 0; i < f.nSamples; i++ {
+		row := Z.RawRowView(i)
+		if !f.multinomial {
+			row[0] = expit(row[0])
+			continue
+		}
+		lse := logSumExp(row)
+		for o, z := range row {
+			row[o] = math.Exp(z - lse)
+		}
+	}
+}
+
+// sampleLoss returns the log loss of the linear output z of a sample of target y
+func (f *logisticLoss) sampleLoss(z, y []float64) float64 {
+	if !f.multinomial {
+		return softplus(z[0]) - y[0]*z[0]
+	}
+	return logSumExp(z) - floats.Dot(y, z)
+}
+
+// lossGrad returns the objective at theta and puts its gradient into grad if grad is not nil.
+// the gradient is the one of the differentiable part, without the l1 penalty
+func (f *logisticLoss) lossGrad(theta, grad []float64) float64 {
+	Z := f.linear(theta)
+	J := 0.
+	for i, sw := range f.sampleWeight {
+		J += sw * f.sampleLoss(Z.RawRowView(i), f.Y.RawRowView(i))
+	}
+	n := f.nFeatures * f.nOutputs
+	for _, w := range theta[:n] {
+		J += f.l2/2*w*w + f.l1*math.Abs(w)
+	}
+	if grad == nil {
+		return J
+	}
+	// G is the gradient of the loss relatively to Z
+	f.probabilities(Z)
+	G := Z
+	for i, sw := range f.sampleWeight {
+		row, y := G.RawRowView(i), f.Y.RawRowView(i)
+		for o := range row {
+			row[o] = sw * (row[o] - y[o])
+		}
+	}
+	f.backward(G, grad)
+	floats.AddScaled(grad[:n], f.l2, theta[:n])
+	return J
+}
+
+// backward puts Xᵀ·G and the sums of the columns of G into the W and b parts of grad
+func (f *logisticLoss) backward(G *mat.Dense, grad []float64) {
+	n := f.nFeatures * f.nOutputs
+	gW := &mat.Dense{}
+	mulTo(gW, f.X.T(), G)
+	for j := 0; j < f.nFeatures; j++ {
+		copy(grad[j*f.nOutputs:(j+1)*f.nOutputs], gW.RawRowView(j))
+	}
+	if f.fitIntercept {
+		gb := grad[n : n+f.nOutputs]
+		for o := range gb {
+			gb[o] = 0
+		}
+		for i := 0; i < f.nSamples; i++ {
+			floats.Add(gb, G.RawRowView(i))
+		}
+	}
+}
+
+// hessp returns the product of the Hessian of the objective at theta with a vector v
+func (f *logisticLoss) hessp(theta []float64) func(v, hv []float64) {
+	P := f.linear(theta)
+	f.probabilities(P)
+	n := f.nFeatures * f.nOutputs
+	return func(v, hv []float64) {
+		R := f.linear(v)
+		for i, sw := range f.sampleWeight {
+			p, r := P.RawRowView(i), R.RawRowView(i)
+			if !f.multinomial {
+				r[0] *= sw * p[0] * (1 - p[0])
+				continue
+			}
+			pr := floats.Dot(p, r)
+			for o := range r {
+				r[o] = sw * p[o] * (r[o] - pr)
+			}
+		}
+		f.backward(R, hv)
+		floats.AddScaled(hv[:n], f.l2, v[:n])
+	}
+}
+
+// solverProgress reports the loss of an iteration of a solver and returns a non nil error to stop the solver
+type solverProgress func(iter int, loss float64) error
+
+// lossGrader is a differentiable objective: lossGrad returns the loss of theta and puts its gradient into grad
+// if grad is not nil
+type lossGrader interface {
+	lossGrad(theta, grad []float64) float64
+}
+
+// lbfgsSolve minimizes f from theta0 with gonum L-BFGS until the infinity norm of the gradient is below tol
+func lbfgsSolve(ctx context.Context, estimator interface{}, f lossGrader, theta0 []float64, maxIter int, tol float64) (theta []float64, nIter int, converged bool, err error) {
+	p := optimize.Problem{
+		Func: func(x []float64) float64 { return f.lossGrad(x, nil) },
+		Grad: func(grad, x []float64) []float64 {
+			if grad == nil {
+				grad = make([]float64, len(x))
+			}
+			f.lossGrad(x, grad)
+			return grad
+		},
+	}
+	settings := &optimize.Settings{
+		GradientThreshold: tol,
+		MajorIterations:   maxIter,
+		Converger:         optimize.NeverTerminate{},
+		Recorder:          &base.ContextRecorder{Context: ctx, Estimator: estimator, Epochs: maxIter},
+	}
+	res, err := optimize.Minimize(p, theta0, settings, &optimize.LBFGS{})
+	if ctxErr := base.ContextErr(ctx); ctxErr != nil {
+		return nil, 0, false, ctxErr
+	}
+	if res == nil {
+		return nil, 0, false, err
+	}
+	converged = res.Status == optimize.GradientThreshold || floats.Norm(res.Gradient, math.Inf(1)) <= tol
+	return res.X, res.MajorIterations, converged, nil
+}
+
+// newtonCG minimizes f with Newton steps, each solved approximately by conjugate gradient and followed by
+// a backtracking line search, until the infinity norm of the gradient is below tol
+func newtonCG(f *logisticLoss, maxIter int, tol float64, progress solverProgress) (theta []float64, nIter int, converged bool, err error) {
+	theta = make([]float64, f.nParams())
+	grad := make([]float64, len(theta))
+	trial := make([]float64, len(theta))
+	J := f.lossGrad(theta, grad)
+	for nIter < maxIter {
+		if floats.Norm(grad, math.Inf(1)) <= tol {
+			return theta, nIter, true, nil
+		}
+		gnorm := floats.Norm(grad, 2)
+		d := conjugateGradient(f.hessp(theta), grad, math.Min(.5, math.Sqrt(gnorm))*gnorm, 200)
+		slope := floats.Dot(grad, d)
+		for t, k := 1., 0; k < 30; t, k = t/2, k+1 {
+			floats.AddScaledTo(trial, theta, t, d)
+			if Jt := f.lossGrad(trial, nil); Jt <= J+1e-4*t*slope {
+				break
+			}
+		}
+		copy(theta, trial)
+		J = f.lossGrad(theta, grad)
+		nIter++
+		if err := progress(nIter, J); err != nil {
+			return nil, nIter, false, err
+		}
+	}
+	return theta, nIter, floats.Norm(grad, math.Inf(1)) <= tol, nil
+}
+
+// conjugateGradient returns an approximate solution d of H·d=-g, stopping when the residual is below tol,
+// at maxIter iterations or at a direction of non positive curvature
+func conjugateGradient(hessp func(v, hv []float64), g []float64, tol float64, maxIter int) []float64 {
+	d := make([]float64, len(g))
+	r := make([]float64, len(g))
+	floats.ScaleTo(r, -1, g)
+	p := append([]float64{}, r...)
+	hp := make([]float64, len(g))
+	rr := floats.Dot(r, r)
+	for k := 0; k < maxIter && math.Sqrt(rr) > tol; k++ {
+		hessp(p, hp)
+		php := floats.Dot(p, hp)
+		if php <= 0 {
+			if k == 0 {
+				copy(d, r)
+			}
+			break
+		}
+		a := rr / php
+		floats.AddScaled(d, a, p)
+		floats.AddScaled(r, -a, hp)
+		rrNext := floats.Dot(r, r)
+		for i := range p {
+			p[i] = r[i] + rrNext/rr*p[i]
+		}
+		rr = rrNext
+	}
+	return d
+}
+
+// coordinateDescent minimizes the binary f in the manner of liblinear: a cyclic pass over the coefficients, each one
+// moved by a one dimensional Newton step (soft thresholded for the l1 penalty) shortened until the objective decreases.
+// it stops when the largest change of a pass is below tol times the largest coefficient
+func coordinateDescent(f *logisticLoss, X *mat.Dense, maxIter int, tol float64, progress solverProgress) (theta []float64, nIter int, converged bool, err error) {
+	theta = make([]float64, f.nParams())
+	xm := X.RawMatrix()
+	y := mat.Col(nil, 0, f.Y)
+	z := make([]float64, f.nSamples)
+	nCoefs := len(theta)
+	for nIter < maxIter {
+		maxChange, maxCoef := 0., 0.
+		for j := 0; j < nCoefs; j++ {
+			intercept := j == f.nFeatures
+			x := func(i int) float64 {
+				if intercept {
+					return 1
+				}
+				return xm.Data[i*xm.Stride+j]
+			}
+			l1, l2 := f.l1, f.l2
+			if intercept {
+				l1, l2 = 0, 0
+			}
+			g, h := 0., 0.
+			for i, sw := range f.sampleWeight {
+				if xi := x(i); xi != 0 {
+					p := expit(z[i])
+					g += sw * (p - y[i]) * xi
+					h += sw * p * (1 - p) * xi * xi
+				}
+			}
+			wj := theta[j]
+			g += l2 * wj
+			h += l2 + 1e-12
+			var d float64
+			switch {
+			case g+l1 <= h*wj:
+				d = -(g + l1) / h
+			case g-l1 >= h*wj:
+				d = -(g - l1) / h
+			default:
+				d = -wj
+			}
+			if d != 0 {
+				lossAt := func(t float64) float64 {
+					J := 0.
+					for i, sw := range f.sampleWeight {
+						zi := z[i] + t*d*x(i)
+						J += sw * (softplus(zi) - y[i]*zi)
+					}
+					wt := wj + t*d
+					return J + l2/2*wt*wt + l1*math.Abs(wt)
+				}
+				J0 := lossAt(0)
+				decrease := g*d + l1*(math.Abs(wj+d)-math.Abs(wj))
+				t := 1.
+				for k := 0; k < 20 && lossAt(t)-J0 > .01*t*decrease; k++ {
+					t /= 2
+				}
+				theta[j] = wj + t*d
+				for i := range z {
+					z[i] += t * d * x(i)
+				}
+				maxChange = math.Max(maxChange, math.Abs(t*d))
+			}
+			maxCoef = math.Max(maxCoef, math.Abs(theta[j]))
+		}
+		nIter++
+		if err := progress(nIter, f.lossGrad(theta, nil)); err != nil {
+			return nil, nIter, false, err
+		}
+		if maxChange <= tol*maxCoef || maxChange == 0 {
+			return theta, nIter, true, nil
+		}
+	}
+	return theta, nIter, false, nil
+}
+
+// stochasticAverageGradient minimizes f with SAG, or SAGA and the proximal operator of the l1 penalty if saga is true.
+// samples are drawn by rng. it stops when the largest change of an epoch is below tol times the largest coefficient
+func stochasticAverageGradient(f *logisticLoss, X *mat.Dense, saga bool, rng *rand.Rand, maxIter int, tol float64, progress solverProgress) (theta []float64, nIter int, converged bool, err error) {
+	n, nOutputs := f.nSamples, f.nOutputs
+	theta = make([]float64, f.nParams())
+	W, b := theta[:f.nFeatures*nOutputs], theta[f.nFeatures*nOutputs:]
+	memory := make([]float64, n*nOutputs)
+	sumGrad, sumB := make([]float64, len(W)), make([]float64, nOutputs)
+	seen, nSeen := make([]bool, n), 0
+
+	// step from the Lipschitz constant of the loss of a sample, n·sampleWeight·loss
+	maxSquaredSum, maxWeight := 0., 0.
+	for i, sw := range f.sampleWeight {
+		row := X.RawRowView(i)
+		maxSquaredSum = math.Max(maxSquaredSum, floats.Dot(row, row))
+		maxWeight = math.Max(maxWeight, float64(n)*sw)
+	}
+	if f.fitIntercept {
+		maxSquaredSum++
+	}
+	lipschitz := .25 * maxWeight * maxSquaredSum
+	if f.multinomial {
+		lipschitz *= 2
+	}
+	lipschitz += f.l2
+	step := 1 / lipschitz
+	if saga {
+		step = 1 / (2*lipschitz + math.Min(2*float64(n)*f.l2, lipschitz))
+	}
+
+	z, gnew, diff := make([]float64, nOutputs), make([]float64, nOutputs), make([]float64, nOutputs)
+	prev := make([]float64, len(theta))
+	for nIter < maxIter {
+		copy(prev, theta)
+		for k := 0; k < n; k++ {
+			i := rng.Intn(n)
+			x, y := X.RawRowView(i), f.Y.RawRowView(i)
+			for o := range z {
+				z[o] = 0
+				if f.fitIntercept {
+					z[o] = b[o]
+				}
+			}
+			for j, xj := range x {
+				floats.AddScaled(z, xj, W[j*nOutputs:(j+1)*nOutputs])
+			}
+			if f.multinomial {
+				lse := logSumExp(z)
+				for o := range z {
+					gnew[o] = float64(n) * f.sampleWeight[i] * (math.Exp(z[o]-lse) - y[o])
+				}
+			} else {
+				gnew[0] = float64(n) * f.sampleWeight[i] * (expit(z[0]) - y[0])
+			}
+			gi := memory[i*nOutputs : (i+1)*nOutputs]
+			floats.SubTo(diff, gnew, gi)
+			if saga {
+				// W = prox(W - step·(diff·x + sumGrad/n + l2·W)), sumGrad being the one before the update
+				for j, xj := range x {
+					for o := 0; o < nOutputs; o++ {
+						jo := j*nOutputs + o
+						W[jo] -= step * (diff[o]*xj + sumGrad[jo]/float64(n) + f.l2*W[jo])
+					}
+				}
+				if f.fitIntercept {
+					for o := range b {
+						b[o] -= step * (diff[o] + sumB[o]/float64(n))
+					}
+				}
+				if f.l1 > 0 {
+					for jo, w := range W {
+						W[jo] = sgn(w) * math.Max(math.Abs(w)-step*f.l1, 0)
+					}
+				}
+			}
+			for j, xj := range x {
+				floats.AddScaled(sumGrad[j*nOutputs:(j+1)*nOutputs], xj, diff)
+			}
+			floats.Add(sumB, diff)
+			copy(gi, gnew)
+			if !saga {
+				if !seen[i] {
+					seen[i] = true
+					nSeen++
+				}
+				for jo := range W {
+					W[jo] -= step * (sumGrad[jo]/float64(nSeen) + f.l2*W[jo])
+				}
+				if f.fitIntercept {
+					floats.AddScaled(b, -step/float64(nSeen), sumB)
+				}
+			}
+		}
+		nIter++
+		if err := progress(nIter, f.lossGrad(theta, nil)); err != nil {
+			return nil, nIter, false, err
+		}
+		maxChange, maxCoef := 0., 0.
+		for k, w := range theta {
+			maxChange = math.Max(maxChange, math.Abs(w-prev[k]))
+			maxCoef = math.Max(maxCoef, math.Abs(w))
+		}
+		if maxChange <= tol*maxCoef || maxChange == 0 {
+			return theta, nIter, true, nil
+		}
+	}
+	return theta, nIter, false, nil
+}
+
+// newSolverProgress returns the solverProgress reporting to the ProgressCallback of ctx, stopping when ctx is done
+func newSolverProgress(ctx context.Context, estimator interface{}, maxIter int) solverProgress {
+	start := time.Now()
+	return func(iter int, loss float64) error {
+		if err := base.ContextErr(ctx); err != nil {
+			return err
+		}
+		base.ReportProgress(ctx, base.Progress{Estimator: estimator, Epoch: iter, Epochs: maxIter, Loss: loss, ValidationScore: math.NaN(), Elapsed: time.Since(start)})
+		return nil
+	}
+}
+
+// expit is the logistic function
+func expit(z float64) float64 {
+	if z >= 0 {
+		return 1 / (1 + math.Exp(-z))
+	}
+	e := math.Exp(z)
+	return e / (1 + e)
+}
+
+// softplus is log(1+exp(z))
+func softplus(z float64) float64 {
+	return math.Max(z, 0) + math.Log1p(math.Exp(-math.Abs(z)))
+}
+
+// logSumExp is log(sum(exp(z)))
+func logSumExp(z []float64) float64 {
+	m := floats.Max(z)
+	s := 0.
+	for _, v := range z {
+		s += math.Exp(v - m)
+	}
+	return m + math.Log(s)
+}
@@ -0,0 +1,183 @@
+package linearmodel
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// newBlobsProblem returns nClasses overlapping gaussian blobs of labels 1 to nClasses, with nNoise features of noise after the 2 informative ones
+func newBlobsProblem(nSamples, nClasses, nNoise int) (X, Y *mat.Dense) {
+	rnd := rand.New(rand.NewSource(7))
+	X = mat.NewDense(nSamples, 2+nNoise, nil)
+	Y = mat.NewDense(nSamples, 1, nil)
+	for i := 0; i < nSamples; i++ {
+		k := i % nClasses
+		angle := 2 * math.Pi * float64(k) / float64(nClasses)
+		row := X.RawRowView(i)
+		for j := range row {
+			row[j] = rnd.NormFloat64()
+		}
+		row[0] += 2 * math.Cos(angle)
+		row[1] += 2 * math.Sin(angle)
+		Y.Set(i, 0, float64(k+1))
+	}
+	return
+}
+
+func TestLogisticRegressionSolvers(t *testing.T) {
+	for _, nClasses := range []int{2, 3} {
+		X, Y := newBlobsProblem(150, nClasses, 1)
+		var ref *LogisticRegression
+		for _, solver := range []string{"lbfgs", "newton-cg", "liblinear", "sag", "saga"} {
+			if solver == "liblinear" && nClasses > 2 {
+				// liblinear is one-vs-rest only
+				continue
+			}
+			regr := NewLogisticRegression()
+			regr.Solver = solver
+			regr.C = 1
+			regr.MaxIter = 2000
+			regr.Tol = 1e-8
+			regr.RandomState = base.NewRandomState(7)
+			if err := regr.FitE(X, Y); err != nil {
+				t.Fatalf("%s: %s", solver, err)
+			}
+			if regr.LogisticResult.Multinomial != (nClasses > 2) {
+				t.Errorf("%s: unexpected Multinomial %v for %d classes", solver, regr.LogisticResult.Multinomial, nClasses)
+			}
+			if ref == nil {
+				ref = regr
+				continue
+			}
+			if !mat.EqualApprox(regr.Coef, ref.Coef, 1e-3) || !mat.EqualApprox(regr.Intercept, ref.Intercept, 1e-3) {
+				t.Errorf("%d classes %s: expected coef %g intercept %g, got %g %g", nClasses, solver,
+					mat.Formatted(ref.Coef.T()), mat.Formatted(ref.Intercept),
+					mat.Formatted(regr.Coef.T()), mat.Formatted(regr.Intercept))
+			}
+		}
+	}
+}
+
+func TestLogisticRegressionPredictProba(t *testing.T) {
+	X, Y := newBlobsProblem(150, 3, 0)
+	for _, multiClass := range []string{"multinomial", "ovr"} {
+		regr := NewLogisticRegression()
+		regr.Solver, regr.MultiClass = "lbfgs", multiClass
+		regr.Fit(X, Y)
+		if !floats.Equal(regr.Classes, []float64{1, 2, 3}) {
+			t.Errorf("%s: expected classes 1 2 3, got %g", multiClass, regr.Classes)
+		}
+		P, logP, Ypred := &mat.Dense{}, &mat.Dense{}, &mat.Dense{}
+		regr.PredictProba(X, P)
+		regr.PredictLogProba(X, logP)
+		regr.Predict(X, Ypred)
+		correct := 0
+		for i := 0; i < 150; i++ {
+			row := P.RawRowView(i)
+			if math.Abs(floats.Sum(row)-1) > 1e-9 {
+				t.Fatalf("%s: probabilities of sample %d sum to %g", multiClass, i, floats.Sum(row))
+			}
+			if Ypred.At(i, 0) != regr.Classes[floats.MaxIdx(row)] {
+				t.Fatalf("%s: sample %d predicted %g with probabilities %g", multiClass, i, Ypred.At(i, 0), row)
+			}
+			for o, p := range row {
+				if math.Abs(math.Log(p)-logP.At(i, o)) > 1e-9 {
+					t.Fatalf("%s: log probability %g of %g", multiClass, logP.At(i, o), p)
+				}
+			}
+			if Ypred.At(i, 0) == Y.At(i, 0) {
+				correct++
+			}
+		}
+		if correct < 120 {
+			t.Errorf("%s: expected an accuracy above 80%%, got %d/150", multiClass, correct)
+		}
+	}
+}
+
+func TestLogisticRegressionL1(t *testing.T) {
+	X, Y := newBlobsProblem(200, 2, 4)
+	for _, solver := range []string{"liblinear", "saga"} {
+		regr := NewLogisticRegression()
+		regr.Solver = solver
+		regr.Penalty = "l1"
+		regr.C = .05
+		regr.MaxIter = 1000
+		regr.RandomState = base.NewRandomState(7)
+		if err := regr.FitE(X, Y); err != nil {
+			t.Fatalf("%s: %s", solver, err)
+		}
+		coef := mat.Col(nil, 0, regr.Coef)
+		for j, w := range coef[2:] {
+			if w != 0 {
+				t.Errorf("%s: expected a zero coefficient for noise feature %d, got %g", solver, j, coef)
+				break
+			}
+		}
+		if coef[0] == 0 {
+			t.Errorf("%s: expected an informative feature, got %g", solver, coef)
+		}
+	}
+}
+
+func TestLogisticRegressionSolverErrors(t *testing.T) {
+	X, Y := newBlobsProblem(60, 3, 0)
+	for _, params := range []func(*LogisticRegression){
+		func(regr *LogisticRegression) { regr.Solver, regr.Penalty = "lbfgs", "l1" },
+		func(regr *LogisticRegression) { regr.Solver, regr.Penalty = "liblinear", "elasticnet" },
+		func(regr *LogisticRegression) { regr.Solver, regr.MultiClass = "liblinear", "multinomial" },
+		func(regr *LogisticRegression) { regr.Solver, regr.MultiClass = "lbfgs", "crammer_singer" },
+		func(regr *LogisticRegression) { regr.Solver, regr.C = "lbfgs", -1 },
+		func(regr *LogisticRegression) { regr.Solver, regr.MaxIter = "newton-cg", 0 },
+		func(regr *LogisticRegression) { regr.Solver = "bogus" },
+	} {
+		regr := NewLogisticRegression()
+		params(regr)
+		var paramErr *base.ParamError
+		if err := regr.FitE(X, Y); !errors.As(err, &paramErr) {
+			t.Errorf("expected a *base.ParamError, got %v", err)
+		}
+	}
+
+	regr := NewLogisticRegression()
+	regr.Solver = "lbfgs"
+	regr.MaxIter = 1
+	var convergenceErr *base.ConvergenceError
+	if err := regr.FitE(X, Y); !errors.As(err, &convergenceErr) || convergenceErr.NIter != 1 {
+		t.Errorf("expected a *base.ConvergenceError after 1 iteration, got %v", err)
+	}
+	var valueErr *base.ValueError
+	if err := regr.FitE(X, mat.NewDense(60, 1, nil)); !errors.As(err, &valueErr) {
+		t.Errorf("expected a *base.ValueError for a single class, got %v", err)
+	}
+}
+
+func ExampleLogisticRegression_multinomial() {
+	ds := datasets.LoadIris()
+	regr := NewLogisticRegression()
+	regr.Solver = "lbfgs"
+	regr.C = 1
+	regr.MaxIter = 1000
+	if err := regr.FitE(ds.X, ds.Y); err != nil {
+		fmt.Println(err)
+	}
+	Ypred := &mat.Dense{}
+	regr.Predict(ds.X, Ypred)
+	correct := 0
+	for i, y := range ds.Target {
+		if Ypred.At(i, 0) == y {
+			correct++
+		}
+	}
+	fmt.Printf("classes:%g multinomial:%v accuracy:%.2f\n", regr.Classes, regr.LogisticResult.Multinomial, float64(correct)/float64(len(ds.Target)))
+	// Output:
+	// classes:[0 1 2] multinomial:true accuracy:0.97
+}
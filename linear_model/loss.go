@@ -31,7 +31,7 @@ var LossFunctions = map[string]Loss{"square": SquareLoss, "log": LogLoss, "cross
 // grad:  hprime*(h-y)
 //
 func SquareLoss(Ytrue, X mat.Matrix, Theta, Ypred, Ydiff, grad *mat.Dense, Alpha, L1Ratio float64, nSamples int, activation Activation, disableRegularizationOfFirstFeature bool) (J float64) {
-	Ypred.Mul(X, Theta)
+	mulTo(Ypred, X, Theta)
 	Ypred.Apply(func(i, o int, xtheta float64) float64 { return activation.F(xtheta) }, Ypred)
 	Ydiff.Sub(Ypred, Ytrue)
 	J = 0.
@@ -42,7 +42,7 @@ func SquareLoss(Ytrue, X mat.Matrix, Theta, Ypred, Ydiff, grad *mat.Dense, Alpha
 	// put into grad
 	if grad != nil {
 		if _, ok := activation.(base.Identity); ok {
-			grad.Mul(X.T(), Ydiff) //<- for identity only
+			mulTo(grad, X.T(), Ydiff) //<- for identity only
 
 		} else {
 			grad.Apply(func(j, o int, theta float64) float64 {
@@ -67,7 +67,7 @@ func SquareLoss(Ytrue, X mat.Matrix, Theta, Ypred, Ydiff, grad *mat.Dense, Alpha
 
 // LogLoss for one versus rest classifiers
 func LogLoss(Ytrue, X mat.Matrix, Theta, Ypred, Ydiff, grad *mat.Dense, Alpha, L1Ratio float64, nSamples int, activation Activation, disableRegularizationOfFirstFeature bool) (J float64) {
-	Ypred.Mul(X, Theta)
+	mulTo(Ypred, X, Theta)
 	Ypred.Apply(func(i, o int, xtheta float64) float64 { return activation.F(xtheta) }, Ypred)
 	Ydiff.Sub(Ypred, Ytrue)
 	J = 0.
@@ -120,7 +120,7 @@ func LogLoss(Ytrue, X mat.Matrix, Theta, Ypred, Ydiff, grad *mat.Dense, Alpha, L
 // grad:  hprime*(-y/h + (1-y)/(1-h))
 //
 func CrossEntropyLoss(Ytrue, X mat.Matrix, Theta, Ypred, Ydiff, grad *mat.Dense, Alpha, L1Ratio float64, nSamples int, activation Activation, disableRegularizationOfFirstFeature bool) (J float64) {
-	Ypred.Mul(X, Theta)
+	mulTo(Ypred, X, Theta)
 	Ypred.Apply(func(i, o int, xtheta float64) float64 { return panicIfNaN(activation.F(xtheta)) }, Ypred)
 	Ydiff.Sub(Ypred, Ytrue)
 	J = 0.
@@ -145,7 +145,7 @@ func CrossEntropyLoss(Ytrue, X mat.Matrix, Theta, Ypred, Ydiff, grad *mat.Dense,
 	}, Ypred)
 	if grad != nil {
 		if _, ok := activation.(base.Logistic); ok {
-			grad.Mul(X.T(), Ydiff)
+			mulTo(grad, X.T(), Ydiff)
 		} else {
 			// // for Logistic activation only
 			grad.Apply(func(j, o int, theta float64) float64 {
@@ -171,33 +171,7 @@ func CrossEntropyLoss(Ytrue, X mat.Matrix, Theta, Ypred, Ydiff, grad *mat.Dense,
 	}
 	// add regularization to cost and grad
 	if Alpha > 0. {
-		//J += regularization(Theta, grad, Alpha, L1Ratio, disableRegularizationOfFirstFeature)
-		rmt := Theta.RawMatrix()
-		L1, L2 := 0., 0.
-		jstart := 0
-		if disableRegularizationOfFirstFeature {
-			jstart++
-		}
-		for j, trowpos := jstart, jstart*rmt.Stride; j < rmt.Rows; j, trowpos = j+1, trowpos+rmt.Stride {
-			for o := 0; o < rmt.Cols; o++ {
-				c := rmt.Data[trowpos+o]
-				L1 += math.Abs(c)
-				L2 += c * c / 2
-			}
-		}
-
-		if grad != nil {
-			rmg := grad.RawMatrix()
-			for j, trowpos, growpos := jstart, jstart*rmt.Stride, jstart*rmg.Stride; j < rmt.Rows; j, trowpos, growpos = j+1, trowpos+rmt.Stride, growpos+rmg.Stride {
-				for o := 0; o < rmt.Cols; o++ {
-					c := rmt.Data[trowpos+o]
-					rmg.Data[growpos+o] += Alpha * (L1Ratio*sgn(c) + (1.-L1Ratio)*c)
-				}
-			}
-		}
-		//fmt.Printf("J=1/%d * { %g + %g * ( %g*%g +%g*%g) }\n", nSamples, J, Alpha, L1Ratio, L1, 1.-L1Ratio, L2)
-		J += Alpha * (L1Ratio*L1 + (1.-L1Ratio)*L2)
-
+		J += crossEntropyRegularization(Theta, grad, Alpha, L1Ratio, disableRegularizationOfFirstFeature)
 	}
 	J /= float64(nSamples)
 	if grad != nil {
@@ -246,6 +220,34 @@ func panicIfNaN(v float64) float64 {
 	return v
 }
 
+// crossEntropyRegularization returns the elasticnet penalty of CrossEntropyLoss and adds its gradient to grad if not nil
+func crossEntropyRegularization(Theta, grad *mat.Dense, Alpha, L1Ratio float64, disableRegularizationOfFirstFeature bool) float64 {
+	rmt := Theta.RawMatrix()
+	L1, L2 := 0., 0.
+	jstart := 0
+	if disableRegularizationOfFirstFeature {
+		jstart++
+	}
+	for j, trowpos := jstart, jstart*rmt.Stride; j < rmt.Rows; j, trowpos = j+1, trowpos+rmt.Stride {
+		for o := 0; o < rmt.Cols; o++ {
+			c := rmt.Data[trowpos+o]
+			L1 += math.Abs(c)
+			L2 += c * c / 2
+		}
+	}
+
+	if grad != nil {
+		rmg := grad.RawMatrix()
+		for j, trowpos, growpos := jstart, jstart*rmt.Stride, jstart*rmg.Stride; j < rmt.Rows; j, trowpos, growpos = j+1, trowpos+rmt.Stride, growpos+rmg.Stride {
+			for o := 0; o < rmt.Cols; o++ {
+				c := rmt.Data[trowpos+o]
+				rmg.Data[growpos+o] += Alpha * (L1Ratio*sgn(c) + (1.-L1Ratio)*c)
+			}
+		}
+	}
+	return Alpha * (L1Ratio*L1 + (1.-L1Ratio)*L2)
+}
+
 func regularization(Theta, grad *mat.Dense, Alpha, L1Ratio float64, disableRegularizationOfFirstFeature bool) float64 {
 	JRegul := 0.
 	// add regularization to cost and grad
@@ -0,0 +1,257 @@
+package linearmodel
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// PassiveAggressiveClassifier is a linear classifier updated by each sample just enough for its hinge loss to be
+// zero: Loss hinge steps by min(C, loss/|x|²) (PA-I), squared_hinge by loss/(|x|²+1/(2C)) (PA-II).
+// the other fields are those of SGDClassifier, except Alpha, L1Ratio, Penalty, LearningRate, Eta0 and PowerT
+// which are not used.
+// see "Online Passive-Aggressive Algorithms", K. Crammer, O. Dekel, J. Keshet, S. Shalev-Shwartz, Y. Singer, JMLR 2006
+type PassiveAggressiveClassifier struct {
+	SGDClassifier
+	// C is the maximal step, the larger the more aggressive
+	C float64
+}
+
+// NewPassiveAggressiveClassifier creates a *PassiveAggressiveClassifier with defaults: hinge loss and C 1
+func NewPassiveAggressiveClassifier() *PassiveAggressiveClassifier {
+	regr := &PassiveAggressiveClassifier{SGDClassifier: *NewSGDClassifier(), C: 1}
+	regr.Penalty, regr.LearningRate = "none", ""
+	return regr
+}
+
+// Clone for PassiveAggressiveClassifier
+func (regr *PassiveAggressiveClassifier) Clone() base.Transformer {
+	clone := *regr
+	return &clone
+}
+
+// sgd returns the classifierFit of PassiveAggressiveClassifier, or a *base.ParamError for an invalid Loss or C
+func (regr *PassiveAggressiveClassifier) sgd() (classifierFit, error) {
+	cf := classifierFit{est: regr, name: "PassiveAggressiveClassifier", pa: paUpdate{c: regr.C}}
+	switch regr.Loss {
+	case "hinge":
+		cf.pa.variant = 1
+	case "squared_hinge":
+		cf.pa.variant = 2
+	default:
+		return cf, &base.ParamError{Estimator: regr, Param: "Loss", Value: regr.Loss, Msg: "must be hinge or squared_hinge"}
+	}
+	if !(regr.C > 0) {
+		return cf, &base.ParamError{Estimator: regr, Param: "C", Value: regr.C, Msg: "must be > 0"}
+	}
+	return cf, nil
+}
+
+// Fit learns Coef and Intercept from X and the classes Y, from zero weights unless WarmStart
+func (regr *PassiveAggressiveClassifier) Fit(X, Y *mat.Dense) base.Transformer {
+	cf, err := regr.sgd()
+	if err == nil {
+		err = regr.fit(context.Background(), cf, "PassiveAggressiveClassifier.Fit", X, Y, nil, nil, false)
+	}
+	if err != nil {
+		panic(err)
+	}
+	return regr
+}
+
+// FitE is Fit returning an error instead of panicking.
+// a *base.ConvergenceError is returned if the loss still improved by Tol after MaxIter epochs, the model is still usable
+func (regr *PassiveAggressiveClassifier) FitE(X, Y *mat.Dense) error {
+	return regr.FitContext(context.Background(), X, Y)
+}
+
+// FitContext is FitE stopping between epochs when ctx is done
+func (regr *PassiveAggressiveClassifier) FitContext(ctx context.Context, X, Y *mat.Dense) error {
+	cf, err := regr.sgd()
+	if err != nil {
+		return err
+	}
+	return regr.fitE(ctx, cf, "Fit", X, Y, nil)
+}
+
+// FitWeighted is FitE with the step of each sample weighted by sampleWeight
+func (regr *PassiveAggressiveClassifier) FitWeighted(X, Y *mat.Dense, sampleWeight []float64) error {
+	cf, err := regr.sgd()
+	if err != nil {
+		return err
+	}
+	return regr.fitE(context.Background(), cf, "FitWeighted", X, Y, sampleWeight)
+}
+
+// PartialFit runs a single epoch on the samples X,Y, see SGDClassifier.PartialFit
+func (regr *PassiveAggressiveClassifier) PartialFit(X, Y *mat.Dense, classes []float64) error {
+	cf, err := regr.sgd()
+	if err != nil {
+		return err
+	}
+	return regr.partialFit(cf, X, Y, classes)
+}
+
+// Predict puts into Y the class of highest decision function, see SGDClassifier.Predict
+func (regr *PassiveAggressiveClassifier) Predict(X, Y *mat.Dense) base.Regressor {
+	regr.SGDClassifier.Predict(X, Y)
+	return regr
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (regr *PassiveAggressiveClassifier) PredictE(X, Y *mat.Dense) error {
+	return regr.predictE(classifierFit{est: regr, name: "PassiveAggressiveClassifier"}, X, Y)
+}
+
+// PassiveAggressiveRegressor is a linear regression updated by each sample just enough for its
+// epsilon_insensitive loss to be zero: Loss epsilon_insensitive steps by min(C, loss/|x|²) (PA-I),
+// squared_epsilon_insensitive by loss/(|x|²+1/(2C)) (PA-II). the epochs are those of SGDParams, whose Penalty,
+// LearningRate, Eta0 and PowerT are not used
+type PassiveAggressiveRegressor struct {
+	LinearModel
+	SGDParams
+	// C is the maximal step, the larger the more aggressive
+	C, Tol    float64
+	Loss      string
+	SGDResult SGDResult
+}
+
+// NewPassiveAggressiveRegressor creates a *PassiveAggressiveRegressor with defaults: epsilon_insensitive loss,
+// Epsilon .1 and C 1
+func NewPassiveAggressiveRegressor() *PassiveAggressiveRegressor {
+	regr := &PassiveAggressiveRegressor{C: 1, Tol: 1e-3, Loss: "epsilon_insensitive", SGDParams: SGDParams{
+		Penalty: "none", MaxIter: 1000, Shuffle: true, Epsilon: .1, NIterNoChange: 5, ValidationFraction: .1,
+	}}
+	regr.FitIntercept = true
+	return regr
+}
+
+// Clone for PassiveAggressiveRegressor
+func (regr *PassiveAggressiveRegressor) Clone() base.Transformer {
+	clone := *regr
+	return &clone
+}
+
+// Fit learns Coef and Intercept from X,Y, from zero weights unless WarmStart
+func (regr *PassiveAggressiveRegressor) Fit(X, Y *mat.Dense) base.Transformer {
+	if err := regr.checkFit("PassiveAggressiveRegressor.Fit", X, Y); err != nil {
+		panic(err)
+	}
+	if err := regr.fit(context.Background(), X, Y, nil, false); err != nil {
+		panic(err)
+	}
+	return regr
+}
+
+// FitE is Fit returning an error instead of panicking.
+// a *base.ConvergenceError is returned if the loss still improved by Tol after MaxIter epochs, the model is still usable
+func (regr *PassiveAggressiveRegressor) FitE(X, Y *mat.Dense) error {
+	return regr.fitE(context.Background(), "PassiveAggressiveRegressor.Fit", X, Y, nil)
+}
+
+// FitContext is FitE stopping between epochs when ctx is done. it reports the mean loss of each epoch
+// to the ProgressCallback of ctx
+func (regr *PassiveAggressiveRegressor) FitContext(ctx context.Context, X, Y *mat.Dense) error {
+	return regr.fitE(ctx, "PassiveAggressiveRegressor.Fit", X, Y, nil)
+}
+
+// FitWeighted is FitE with the step of each sample weighted by sampleWeight
+func (regr *PassiveAggressiveRegressor) FitWeighted(X, Y *mat.Dense, sampleWeight []float64) error {
+	if err := base.CheckSampleWeight("PassiveAggressiveRegressor.FitWeighted", X, sampleWeight); err != nil {
+		return err
+	}
+	return regr.fitE(context.Background(), "PassiveAggressiveRegressor.FitWeighted", X, Y, sampleWeight)
+}
+
+// PartialFit runs a single epoch on the samples X,Y, from the current weights
+func (regr *PassiveAggressiveRegressor) PartialFit(X, Y *mat.Dense) error {
+	const op = "PassiveAggressiveRegressor.PartialFit"
+	if err := regr.checkFit(op, X, Y); err != nil {
+		return err
+	}
+	var err error
+	if rerr := base.Recover(func() { err = regr.fit(context.Background(), X, Y, nil, true) }); rerr != nil {
+		return rerr
+	}
+	return err
+}
+
+func (regr *PassiveAggressiveRegressor) fitE(ctx context.Context, op string, X, Y *mat.Dense, sampleWeight []float64) error {
+	if err := regr.checkFit(op, X, Y); err != nil {
+		return err
+	}
+	var err error
+	if rerr := base.Recover(func() { err = regr.fit(ctx, X, Y, sampleWeight, false) }); rerr != nil {
+		return rerr
+	}
+	if err == nil && !regr.SGDResult.Converged {
+		err = &base.ConvergenceError{Estimator: regr, NIter: regr.SGDResult.NIter, Msg: fmt.Sprintf("the loss still improves by Tol %g, increase MaxIter", regr.Tol)}
+	}
+	return err
+}
+
+func (regr *PassiveAggressiveRegressor) checkFit(op string, X, Y *mat.Dense) error {
+	if err := base.CheckFitXY(op, X, Y); err != nil {
+		return err
+	}
+	if _, err := regr.paUpdate(); err != nil {
+		return err
+	}
+	return regr.SGDParams.checkEpochs(regr)
+}
+
+// paUpdate returns the paUpdate of Loss and C, or a *base.ParamError if one is invalid
+func (regr *PassiveAggressiveRegressor) paUpdate() (paUpdate, error) {
+	pa := paUpdate{c: regr.C}
+	switch regr.Loss {
+	case "epsilon_insensitive":
+		pa.variant = 1
+	case "squared_epsilon_insensitive":
+		pa.variant = 2
+	default:
+		return pa, &base.ParamError{Estimator: regr, Param: "Loss", Value: regr.Loss, Msg: "must be epsilon_insensitive or squared_epsilon_insensitive"}
+	}
+	if !(regr.C > 0) {
+		return pa, &base.ParamError{Estimator: regr, Param: "C", Value: regr.C, Msg: "must be > 0"}
+	}
+	return pa, nil
+}
+
+// fit fits Coef and Intercept, a single epoch continuing from them if partial is true
+func (regr *PassiveAggressiveRegressor) fit(ctx context.Context, X, Y *mat.Dense, sampleWeight []float64, partial bool) error {
+	pa, err := regr.paUpdate()
+	if err != nil {
+		return err
+	}
+	warm, err := warmStart("PassiveAggressiveRegressor.Fit", regr.Coef, &regr.SGDResult, X, Y, regr.WarmStart, partial)
+	if err != nil {
+		return err
+	}
+	f := newSGDFit(ctx, "PassiveAggressiveRegressor.Fit", regr, regr.SGDParams, epsilonInsensitiveLoss{epsilon: regr.Epsilon}, 0, 0, regr.Tol, regr.FitIntercept, partial)
+	f.passiveAggressive(pa)
+	return f.fit(X, Y, sampleWeight, &regr.Coef, &regr.Intercept, &regr.SGDResult, warm)
+}
+
+// Predict puts into Y the predictions of X
+func (regr *PassiveAggressiveRegressor) Predict(X, Y *mat.Dense) base.Regressor {
+	regr.DecisionFunction(X, Y)
+	return regr
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (regr *PassiveAggressiveRegressor) PredictE(X, Y *mat.Dense) error {
+	if err := regr.checkPredict(regr, "PassiveAggressiveRegressor.Predict", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { regr.Predict(X, Y) })
+}
+
+// Transform is for Pipeline
+func (regr *PassiveAggressiveRegressor) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
+	r, c := Y.Dims()
+	Xout, Yout = X, mat.NewDense(r, c, nil)
+	regr.Predict(X, Yout)
+	return
+}
@@ -0,0 +1,130 @@
+package linearmodel
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/metrics"
+	"gonum.org/v1/gonum/mat"
+)
+
+func ExamplePassiveAggressiveRegressor_PartialFit() {
+	X, Y := newOutliersProblem(600)
+	regr := NewPassiveAggressiveRegressor()
+	regr.C = .1
+	regr.RandomState = base.NewRandomState(7)
+	// learn from batches of 100 samples, as if they were read from a stream
+	for epoch := 0; epoch < 5; epoch++ {
+		for start := 0; start < 600; start += 100 {
+			Xb, Yb := X.Slice(start, start+100, 0, 3).(*mat.Dense), Y.Slice(start, start+100, 0, 1).(*mat.Dense)
+			if err := regr.PartialFit(Xb, Yb); err != nil {
+				fmt.Println(err)
+			}
+		}
+	}
+	fmt.Printf("coef:%.1f intercept:%.1f\n", mat.Col(nil, 0, regr.Coef), regr.Intercept.At(0, 0))
+	// Output:
+	// coef:[2.0 -1.0 0.5] intercept:1.0
+}
+
+func TestPassiveAggressiveClassifier(t *testing.T) {
+	for _, nClasses := range []int{2, 3} {
+		X, Y := newBlobsProblem(300, nClasses, 1)
+		for _, loss := range []string{"hinge", "squared_hinge"} {
+			clf := NewPassiveAggressiveClassifier()
+			clf.Loss = loss
+			clf.C = .01
+			clf.RandomState = base.NewRandomState(7)
+			if err := clf.FitE(X, Y); err != nil {
+				t.Fatalf("%s: %s", loss, err)
+			}
+			if score := clf.Score(X, Y); score < .75 {
+				t.Errorf("%d classes %s: expected an accuracy above .75, got %.3f", nClasses, loss, score)
+			}
+		}
+	}
+
+	// balanced class weights find more samples of the minority class
+	X, Y := newImbalancedProblem(400)
+	recall := func(clf *PassiveAggressiveClassifier) float64 {
+		Ypred := &mat.Dense{}
+		clf.Predict(X, Ypred)
+		found := 0.
+		for i := 0; i < 400; i += 20 {
+			found += Ypred.At(i, 0)
+		}
+		return found / 20
+	}
+	plain, balanced := NewPassiveAggressiveClassifier(), NewPassiveAggressiveClassifier()
+	plain.C, balanced.C = .01, .01
+	plain.RandomState, balanced.RandomState = base.NewRandomState(7), base.NewRandomState(7)
+	balanced.ClassWeight = "balanced"
+	plain.Fit(X, Y)
+	balanced.Fit(X, Y)
+	if recall(balanced) <= recall(plain) {
+		t.Errorf("expected balanced class weights to improve the recall %g of class 1, got %g", recall(plain), recall(balanced))
+	}
+
+	for _, params := range []func(*PassiveAggressiveClassifier){
+		func(clf *PassiveAggressiveClassifier) { clf.Loss = "log" },
+		func(clf *PassiveAggressiveClassifier) { clf.C = 0 },
+		func(clf *PassiveAggressiveClassifier) { clf.MaxIter = 0 },
+	} {
+		clf := NewPassiveAggressiveClassifier()
+		params(clf)
+		var paramErr *base.ParamError
+		if err := clf.FitE(X, Y); !errors.As(err, &paramErr) {
+			t.Errorf("expected a *base.ParamError, got %v", err)
+		} else if _, ok := paramErr.Estimator.(*PassiveAggressiveClassifier); !ok {
+			t.Errorf("expected the error of the *PassiveAggressiveClassifier, got %T", paramErr.Estimator)
+		}
+	}
+}
+
+func TestPassiveAggressiveRegressor(t *testing.T) {
+	// the samples of a noiseless problem are eventually all within Epsilon
+	p := NewRandomLinearProblem(200, 3, 2)
+	for _, loss := range []string{"epsilon_insensitive", "squared_epsilon_insensitive"} {
+		regr := NewPassiveAggressiveRegressor()
+		regr.Loss = loss
+		regr.RandomState = base.NewRandomState(7)
+		if err := regr.FitE(p.X, p.Y); err != nil {
+			t.Fatalf("%s: %s", loss, err)
+		}
+		Ypred := &mat.Dense{}
+		regr.Predict(p.X, Ypred)
+		if r2 := metrics.R2Score(p.Y, Ypred, nil, "").At(0, 0); r2 < .999 {
+			t.Errorf("%s: expected a R2 score above .999, got %g", loss, r2)
+		}
+	}
+
+	// the steps of PA-I are at most C, few outliers do not move the weights much
+	X, Y := newOutliersProblem(400)
+	regr := NewPassiveAggressiveRegressor()
+	regr.C = .1
+	regr.RandomState = base.NewRandomState(7)
+	if err := regr.FitE(X, Y); err != nil {
+		t.Fatal(err)
+	}
+	coef, intercept := mat.Col(nil, 0, regr.Coef), regr.Intercept.At(0, 0)
+	if math.Abs(coef[0]-2) > .2 || math.Abs(coef[1]+1) > .2 || math.Abs(coef[2]-.5) > .2 || math.Abs(intercept-1) > .5 {
+		t.Errorf("expected coef [2 -1 .5] intercept 1 despite the outliers, got %.3f %.3f", coef, intercept)
+	}
+
+	for _, params := range []func(*PassiveAggressiveRegressor){
+		func(regr *PassiveAggressiveRegressor) { regr.Loss = "huber" },
+		func(regr *PassiveAggressiveRegressor) { regr.C = -1 },
+		func(regr *PassiveAggressiveRegressor) { regr.NIterNoChange = 0 },
+		func(regr *PassiveAggressiveRegressor) { regr.EarlyStopping, regr.ValidationFraction = true, 1 },
+	} {
+		regr := NewPassiveAggressiveRegressor()
+		params(regr)
+		var paramErr *base.ParamError
+		if err := regr.FitE(X, Y); !errors.As(err, &paramErr) {
+			t.Errorf("expected a *base.ParamError, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package linearmodel
+
+import (
+	"context"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Perceptron is a SGDClassifier of defaults perceptron loss, no penalty and constant learning rate Eta0 1:
+// each misclassified sample adds itself, times its label, to the weights
+type Perceptron struct {
+	SGDClassifier
+}
+
+// NewPerceptron creates a *Perceptron with defaults
+func NewPerceptron() *Perceptron {
+	regr := &Perceptron{SGDClassifier: *NewSGDClassifier()}
+	regr.Loss, regr.Penalty, regr.LearningRate, regr.Eta0 = "perceptron", "none", "constant", 1
+	return regr
+}
+
+// Clone for Perceptron
+func (regr *Perceptron) Clone() base.Transformer {
+	clone := *regr
+	return &clone
+}
+
+// sgd returns the classifierFit of Perceptron
+func (regr *Perceptron) sgd() classifierFit { return classifierFit{est: regr, name: "Perceptron"} }
+
+// Fit learns Coef and Intercept from X and the classes Y, from zero weights unless WarmStart
+func (regr *Perceptron) Fit(X, Y *mat.Dense) base.Transformer {
+	if err := regr.fit(context.Background(), regr.sgd(), "Perceptron.Fit", X, Y, nil, nil, false); err != nil {
+		panic(err)
+	}
+	return regr
+}
+
+// FitE is Fit returning an error instead of panicking.
+// a *base.ConvergenceError is returned if the loss still improved by Tol after MaxIter epochs, the model is still usable
+func (regr *Perceptron) FitE(X, Y *mat.Dense) error {
+	return regr.fitE(context.Background(), regr.sgd(), "Fit", X, Y, nil)
+}
+
+// FitContext is FitE stopping between epochs when ctx is done
+func (regr *Perceptron) FitContext(ctx context.Context, X, Y *mat.Dense) error {
+	return regr.fitE(ctx, regr.sgd(), "Fit", X, Y, nil)
+}
+
+// FitWeighted is FitE with the loss of each sample weighted by sampleWeight
+func (regr *Perceptron) FitWeighted(X, Y *mat.Dense, sampleWeight []float64) error {
+	return regr.fitE(context.Background(), regr.sgd(), "FitWeighted", X, Y, sampleWeight)
+}
+
+// PartialFit runs a single epoch on the samples X,Y, see SGDClassifier.PartialFit
+func (regr *Perceptron) PartialFit(X, Y *mat.Dense, classes []float64) error {
+	return regr.partialFit(regr.sgd(), X, Y, classes)
+}
+
+// Predict puts into Y the class of highest decision function, see SGDClassifier.Predict
+func (regr *Perceptron) Predict(X, Y *mat.Dense) base.Regressor {
+	regr.SGDClassifier.Predict(X, Y)
+	return regr
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (regr *Perceptron) PredictE(X, Y *mat.Dense) error {
+	return regr.predictE(regr.sgd(), X, Y)
+}
@@ -0,0 +1,56 @@
+package linearmodel
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+func ExamplePerceptron() {
+	X, Y := newBlobsProblem(300, 3, 0)
+	clf := NewPerceptron()
+	clf.RandomState = base.NewRandomState(7)
+	if err := clf.FitE(X, Y); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Printf("classes:%g epochs:%d accuracy:%.2f\n", clf.Classes, clf.SGDResult.NIter, clf.Score(X, Y))
+	// Output:
+	// classes:[1 2 3] epochs:16 accuracy:0.85
+}
+
+func TestPerceptron(t *testing.T) {
+	// linearly separable classes, the perceptron makes no mistake
+	X := mat.NewDense(100, 2, nil)
+	Y := mat.NewDense(100, 1, nil)
+	for i := 0; i < 100; i++ {
+		x0, x1 := float64(i%10)-4.5, float64(i/10)-4.5
+		X.Set(i, 0, x0)
+		X.Set(i, 1, x1)
+		if x0+2*x1 > .5 {
+			Y.Set(i, 0, 1)
+		}
+	}
+	clf := NewPerceptron()
+	clf.RandomState = base.NewRandomState(7)
+	clf.Fit(X, Y)
+	if score := clf.Score(X, Y); score != 1 {
+		t.Errorf("expected no mistake on separable classes, got an accuracy of %g", score)
+	}
+	if _, ok := clf.Clone().(*Perceptron); !ok {
+		t.Errorf("expected Clone to return a *Perceptron, got %T", clf.Clone())
+	}
+	if _, ok := clf.Predict(X, &mat.Dense{}).(*Perceptron); !ok {
+		t.Error("expected Predict to return the *Perceptron")
+	}
+
+	clf.Eta0 = 0
+	var paramErr *base.ParamError
+	if err := clf.FitE(X, Y); !errors.As(err, &paramErr) {
+		t.Errorf("expected a *base.ParamError for Eta0 0, got %v", err)
+	} else if _, ok := paramErr.Estimator.(*Perceptron); !ok {
+		t.Errorf("expected the error of the *Perceptron, got %T", paramErr.Estimator)
+	}
+}
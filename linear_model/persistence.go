@@ -0,0 +1,153 @@
+package linearmodel
+
+import (
+	"io"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func init() {
+	base.Register(&LinearRegression{})
+	base.Register(&RegularizedRegression{})
+	base.Register(&SGDRegressor{})
+	base.Register(&LogisticRegression{})
+	base.Register(&BayesianRidge{})
+	base.Register(&ElasticNet{})
+	base.Register(&SGDClassifier{})
+	base.Register(&Perceptron{})
+	base.Register(&PassiveAggressiveClassifier{})
+	base.Register(&PassiveAggressiveRegressor{})
+	base.Register(&HuberRegressor{})
+	base.Register(&RANSACRegressor{})
+	base.Register(&TheilSenRegressor{})
+	base.Register(&Lars{})
+	base.Register(&LassoLars{})
+	base.Register(&LassoLarsIC{})
+}
+
+// Save writes the LinearRegression to w. see base.Save
+func (regr *LinearRegression) Save(w io.Writer) error { return base.Save(w, regr) }
+
+// Load reads a LinearRegression written by Save
+func (regr *LinearRegression) Load(r io.Reader) error { return base.LoadInto(r, regr) }
+
+// Save writes the RegularizedRegression to w. SolverConfigure and LossFunction are not saved
+func (regr *RegularizedRegression) Save(w io.Writer) error { return base.Save(w, regr) }
+
+// Load reads a RegularizedRegression written by Save
+func (regr *RegularizedRegression) Load(r io.Reader) error { return base.LoadInto(r, regr) }
+
+// Save writes the SGDRegressor to w. Method is not saved
+func (regr *SGDRegressor) Save(w io.Writer) error { return base.Save(w, regr) }
+
+// Load reads a SGDRegressor written by Save. Method is reset to LBFGS
+func (regr *SGDRegressor) Load(r io.Reader) error { return base.LoadInto(r, regr) }
+
+// GobEncode encodes SGDRegressor without Method
+func (regr *SGDRegressor) GobEncode() ([]byte, error) {
+	type sgdRegressor SGDRegressor
+	fields := sgdRegressor(*regr)
+	fields.Method = nil
+	return base.GobEncodeFields(&fields)
+}
+
+// GobDecode decodes SGDRegressor and resets Method to LBFGS
+func (regr *SGDRegressor) GobDecode(b []byte) error {
+	type sgdRegressor SGDRegressor
+	if err := base.GobDecodeFields(b, (*sgdRegressor)(regr)); err != nil {
+		return err
+	}
+	regr.Method = &optimize.LBFGS{}
+	return nil
+}
+
+// Save writes the LogisticRegression to w
+func (regr *LogisticRegression) Save(w io.Writer) error { return base.Save(w, regr) }
+
+// Load reads a LogisticRegression written by Save
+func (regr *LogisticRegression) Load(r io.Reader) error { return base.LoadInto(r, regr) }
+
+// Restore restores LossFunction and GOMethodCreator defaults after Load
+func (regr *LogisticRegression) Restore() error {
+	defaults := NewLogisticRegression()
+	if regr.LossFunction == nil {
+		regr.LossFunction = defaults.LossFunction
+	}
+	if regr.Options.GOMethodCreator == nil {
+		regr.Options.GOMethodCreator = defaults.Options.GOMethodCreator
+	}
+	return nil
+}
+
+// Save writes the BayesianRidge to w
+func (regr *BayesianRidge) Save(w io.Writer) error { return base.Save(w, regr) }
+
+// Load reads a BayesianRidge written by Save
+func (regr *BayesianRidge) Load(r io.Reader) error { return base.LoadInto(r, regr) }
+
+// Save writes the ElasticNet (or Lasso) to w
+func (regr *ElasticNet) Save(w io.Writer) error { return base.Save(w, regr) }
+
+// Load reads an ElasticNet written by Save
+func (regr *ElasticNet) Load(r io.Reader) error { return base.LoadInto(r, regr) }
+
+// Save writes the SGDClassifier to w
+func (regr *SGDClassifier) Save(w io.Writer) error { return base.Save(w, regr) }
+
+// Load reads a SGDClassifier written by Save
+func (regr *SGDClassifier) Load(r io.Reader) error { return base.LoadInto(r, regr) }
+
+// Save writes the Perceptron to w
+func (regr *Perceptron) Save(w io.Writer) error { return base.Save(w, regr) }
+
+// Load reads a Perceptron written by Save
+func (regr *Perceptron) Load(r io.Reader) error { return base.LoadInto(r, regr) }
+
+// Save writes the PassiveAggressiveClassifier to w
+func (regr *PassiveAggressiveClassifier) Save(w io.Writer) error { return base.Save(w, regr) }
+
+// Load reads a PassiveAggressiveClassifier written by Save
+func (regr *PassiveAggressiveClassifier) Load(r io.Reader) error { return base.LoadInto(r, regr) }
+
+// Save writes the PassiveAggressiveRegressor to w
+func (regr *PassiveAggressiveRegressor) Save(w io.Writer) error { return base.Save(w, regr) }
+
+// Load reads a PassiveAggressiveRegressor written by Save
+func (regr *PassiveAggressiveRegressor) Load(r io.Reader) error { return base.LoadInto(r, regr) }
+
+// Save writes the HuberRegressor to w
+func (regr *HuberRegressor) Save(w io.Writer) error { return base.Save(w, regr) }
+
+// Load reads a HuberRegressor written by Save
+func (regr *HuberRegressor) Load(r io.Reader) error { return base.LoadInto(r, regr) }
+
+// Save writes the RANSACRegressor to w
+func (regr *RANSACRegressor) Save(w io.Writer) error { return base.Save(w, regr) }
+
+// Load reads a RANSACRegressor written by Save
+func (regr *RANSACRegressor) Load(r io.Reader) error { return base.LoadInto(r, regr) }
+
+// Save writes the TheilSenRegressor to w
+func (regr *TheilSenRegressor) Save(w io.Writer) error { return base.Save(w, regr) }
+
+// Load reads a TheilSenRegressor written by Save
+func (regr *TheilSenRegressor) Load(r io.Reader) error { return base.LoadInto(r, regr) }
+
+// Save writes the Lars to w
+func (regr *Lars) Save(w io.Writer) error { return base.Save(w, regr) }
+
+// Load reads a Lars written by Save
+func (regr *Lars) Load(r io.Reader) error { return base.LoadInto(r, regr) }
+
+// Save writes the LassoLars to w
+func (regr *LassoLars) Save(w io.Writer) error { return base.Save(w, regr) }
+
+// Load reads a LassoLars written by Save
+func (regr *LassoLars) Load(r io.Reader) error { return base.LoadInto(r, regr) }
+
+// Save writes the LassoLarsIC to w
+func (regr *LassoLarsIC) Save(w io.Writer) error { return base.Save(w, regr) }
+
+// Load reads a LassoLarsIC written by Save
+func (regr *LassoLarsIC) Load(r io.Reader) error { return base.LoadInto(r, regr) }
@@ -0,0 +1,85 @@
+package linearmodel
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestSaveLoad(t *testing.T) {
+	p := NewRandomLinearProblem(50, 3, 2)
+	Yclass := mat.NewDense(50, 1, nil)
+	for i := 0; i < 50; i++ {
+		if p.Y.At(i, 0) > 0 {
+			Yclass.Set(i, 0, 1)
+		}
+	}
+	// X first column is constant, avoid Normalize dividing by zero
+	bayes := NewBayesianRidge()
+	bayes.Normalize = false
+	type estimator interface {
+		base.Transformer
+		base.PredicterE
+	}
+	for _, test := range []struct {
+		regr estimator
+		Y    *mat.Dense
+	}{
+		{NewLinearRegression(), p.Y},
+		{NewRidge(), p.Y},
+		{NewLasso(), p.Y},
+		{bayes, base.MatDenseSlice(p.Y, 0, 50, 0, 1)},
+		{NewSGDRegressor(), p.Y},
+		{NewLogisticRegression(), Yclass},
+		{NewSGDClassifier(), Yclass},
+		{NewPerceptron(), Yclass},
+		{NewPassiveAggressiveClassifier(), Yclass},
+		{NewPassiveAggressiveRegressor(), p.Y},
+		{NewHuberRegressor(), p.Y},
+		{NewRANSACRegressor(), p.Y},
+		{NewTheilSenRegressor(), p.Y},
+		{NewLars(), p.Y},
+		{NewLassoLars(), p.Y},
+		{NewLassoLarsIC(), base.MatDenseSlice(p.Y, 0, 50, 0, 1)},
+	} {
+		test.regr.Fit(p.X, test.Y)
+		var buf bytes.Buffer
+		if err := base.Save(&buf, test.regr); err != nil {
+			t.Errorf("%T: %s", test.regr, err)
+			continue
+		}
+		loaded, err := base.Load(&buf)
+		if err != nil {
+			t.Errorf("%T: %s", test.regr, err)
+			continue
+		}
+		_, nOutputs := test.Y.Dims()
+		Ypred, Yloaded := mat.NewDense(50, nOutputs, nil), mat.NewDense(50, nOutputs, nil)
+		if err = test.regr.PredictE(p.X, Ypred); err == nil {
+			err = loaded.(estimator).PredictE(p.X, Yloaded)
+		}
+		if err != nil {
+			t.Errorf("%T: %s", test.regr, err)
+		} else if !mat.Equal(Ypred, Yloaded) {
+			t.Errorf("%T: predictions differ after Load", test.regr)
+		}
+	}
+	regr := NewLogisticRegression()
+	regr.Fit(p.X, Yclass)
+	var buf bytes.Buffer
+	if err := regr.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded := &LogisticRegression{}
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.LossFunction == nil || loaded.Options.GOMethodCreator == nil {
+		t.Error("LogisticRegression defaults not restored")
+	}
+	if err := loaded.Load(&buf); err == nil {
+		t.Error("expected an error loading an empty stream")
+	}
+}
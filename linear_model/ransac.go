@@ -0,0 +1,269 @@
+package linearmodel
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// RANSACRegressor fits Estimator to the inliers of X,Y found by RANdom SAmple Consensus: Estimator is fitted to
+// random subsets of MinSamples samples, the inliers of a subset being the samples whose absolute residual, summed
+// over the outputs, is at most ResidualThreshold. the subset of most inliers, of best Score for a tie, wins and
+// Estimator is fitted again to its inliers.
+// see M. A. Fischler, R. C. Bolles, "Random Sample Consensus", Communications of the ACM, 1981
+type RANSACRegressor struct {
+	// Estimator is the base.Regressor fitted, a LinearRegression if nil. it must implement base.TransformerCloner
+	Estimator base.Regressor
+	// MinSamples is the size of the random subsets: a number of samples if >= 1, a fraction of them if < 1, the
+	// number of features plus one if 0
+	MinSamples float64
+	// ResidualThreshold is the largest residual of an inlier, the median absolute deviation of Y if 0
+	ResidualThreshold float64
+	// MaxTrials is the maximal number of random subsets. once a subset of inlier ratio w is found, no more than
+	// log(1-StopProbability)/log(1-w^MinSamples) are drawn, enough to draw a subset of inliers only with
+	// StopProbability
+	MaxTrials       int
+	StopProbability float64
+	// MaxSkips, if > 0, stops the trials once more subsets have been skipped, see NSkipsNoInliers.
+	// StopNInliers and StopScore, if > 0, stop them once a subset has as many inliers or as good a Score
+	MaxSkips, StopNInliers int
+	StopScore              float64
+	// IsDataValid and IsModelValid, if not nil, reject a random subset before fitting, or its fitted estimator
+	IsDataValid  func(X, Y *mat.Dense) bool
+	IsModelValid func(estimator base.Regressor, X, Y *mat.Dense) bool
+	RandomState  *base.RandomState
+
+	// BestEstimator is Estimator fitted to the inliers of the best subset, InlierMask tells them
+	BestEstimator base.Regressor
+	InlierMask    []bool
+	// NTrials is the number of random subsets drawn. NSkipsNoInliers, NSkipsInvalidData and NSkipsInvalidModel
+	// are the numbers of those skipped for fewer inliers than the best subset, by IsDataValid and by IsModelValid
+	NTrials, NSkipsNoInliers, NSkipsInvalidData, NSkipsInvalidModel int
+}
+
+// NewRANSACRegressor creates a *RANSACRegressor of a LinearRegression, with defaults: MaxTrials 100 and
+// StopProbability .99
+func NewRANSACRegressor() *RANSACRegressor {
+	return &RANSACRegressor{Estimator: NewLinearRegression(), MaxTrials: 100, StopProbability: .99}
+}
+
+// Clone for RANSACRegressor. Estimator is cloned too
+func (regr *RANSACRegressor) Clone() base.Transformer {
+	clone := *regr
+	if cloner, ok := regr.Estimator.(base.TransformerCloner); ok {
+		clone.Estimator = cloner.Clone().(base.Regressor)
+	}
+	return &clone
+}
+
+// Fit finds the inliers of X,Y and fits BestEstimator to them
+func (regr *RANSACRegressor) Fit(X, Y *mat.Dense) base.Transformer {
+	if err := regr.FitE(X, Y); err != nil {
+		panic(err)
+	}
+	return regr
+}
+
+// FitE is Fit returning an error instead of panicking.
+// a *base.ValueError is returned if no random subset has inliers
+func (regr *RANSACRegressor) FitE(X, Y *mat.Dense) error {
+	const op = "RANSACRegressor.Fit"
+	if err := base.CheckFitXY(op, X, Y); err != nil {
+		return err
+	}
+	estimator, minSamples, err := regr.checkFit(X)
+	if err != nil {
+		return err
+	}
+	var ferr error
+	if rerr := base.Recover(func() { ferr = regr.fit(op, estimator, minSamples, X, Y) }); rerr != nil {
+		return rerr
+	}
+	return ferr
+}
+
+// checkFit returns the cloner of Estimator and the number of samples of the random subsets of X, or a
+// *base.ParamError
+func (regr *RANSACRegressor) checkFit(X *mat.Dense) (base.TransformerCloner, int, error) {
+	nSamples, nFeatures := X.Dims()
+	var estimator base.Regressor = NewLinearRegression()
+	if regr.Estimator != nil {
+		estimator = regr.Estimator
+	}
+	cloner, ok := estimator.(base.TransformerCloner)
+	if !ok {
+		return nil, 0, &base.ParamError{Estimator: regr, Param: "Estimator", Value: regr.Estimator, Msg: "must implement base.TransformerCloner"}
+	}
+	minSamples := nFeatures + 1
+	switch {
+	case regr.MinSamples >= 1:
+		minSamples = int(regr.MinSamples)
+	case regr.MinSamples > 0:
+		minSamples = int(math.Ceil(regr.MinSamples * float64(nSamples)))
+	}
+	if regr.MinSamples < 0 || minSamples > nSamples {
+		return nil, 0, &base.ParamError{Estimator: regr, Param: "MinSamples", Value: regr.MinSamples, Msg: fmt.Sprintf("must be in [0,%d]", nSamples)}
+	}
+	if regr.ResidualThreshold < 0 {
+		return nil, 0, &base.ParamError{Estimator: regr, Param: "ResidualThreshold", Value: regr.ResidualThreshold, Msg: "must be >= 0"}
+	}
+	if regr.MaxTrials <= 0 {
+		return nil, 0, &base.ParamError{Estimator: regr, Param: "MaxTrials", Value: regr.MaxTrials, Msg: "must be > 0"}
+	}
+	if !(regr.StopProbability >= 0 && regr.StopProbability <= 1) {
+		return nil, 0, &base.ParamError{Estimator: regr, Param: "StopProbability", Value: regr.StopProbability, Msg: "must be in [0,1]"}
+	}
+	return cloner, minSamples, nil
+}
+
+func (regr *RANSACRegressor) fit(op string, estimator base.TransformerCloner, minSamples int, X, Y *mat.Dense) error {
+	nSamples, _ := X.Dims()
+	_, nOutputs := Y.Dims()
+	threshold := regr.ResidualThreshold
+	if threshold == 0 {
+		y := mat.DenseCopyOf(Y).RawMatrix().Data
+		m := median(y)
+		for i := range y {
+			y[i] = math.Abs(y[i] - m)
+		}
+		threshold = median(y)
+	}
+	rs := base.CheckRandomState(regr.RandomState)
+	regr.NTrials, regr.NSkipsNoInliers, regr.NSkipsInvalidData, regr.NSkipsInvalidModel = 0, 0, 0, 0
+	var bestMask []bool
+	var bestInliers []int
+	bestScore := math.Inf(-1)
+	Ypred := mat.NewDense(nSamples, nOutputs, nil)
+	for maxTrials := float64(regr.MaxTrials); float64(regr.NTrials) < maxTrials; {
+		if regr.MaxSkips > 0 && regr.NSkipsNoInliers+regr.NSkipsInvalidData+regr.NSkipsInvalidModel > regr.MaxSkips {
+			break
+		}
+		regr.NTrials++
+		subset := rs.Perm(nSamples)[:minSamples]
+		Xs, Ys := takeRows(X, subset).(*mat.Dense), takeRows(Y, subset).(*mat.Dense)
+		if regr.IsDataValid != nil && !regr.IsDataValid(Xs, Ys) {
+			regr.NSkipsInvalidData++
+			continue
+		}
+		trial := estimator.Clone().(base.Regressor)
+		if err := fitRegressor(trial, Xs, Ys); err != nil {
+			return err
+		}
+		if regr.IsModelValid != nil && !regr.IsModelValid(trial, Xs, Ys) {
+			regr.NSkipsInvalidModel++
+			continue
+		}
+		trial.Predict(X, Ypred)
+		mask, inliers := make([]bool, nSamples), []int{}
+		for i := range mask {
+			residual := 0.
+			for o := 0; o < nOutputs; o++ {
+				residual += math.Abs(Y.At(i, o) - Ypred.At(i, o))
+			}
+			if residual <= threshold {
+				mask[i] = true
+				inliers = append(inliers, i)
+			}
+		}
+		if len(inliers) == 0 || len(inliers) < len(bestInliers) {
+			regr.NSkipsNoInliers++
+			continue
+		}
+		score := trial.Score(takeRows(X, inliers).(*mat.Dense), takeRows(Y, inliers).(*mat.Dense))
+		if len(inliers) == len(bestInliers) && score < bestScore {
+			continue
+		}
+		bestMask, bestInliers, bestScore = mask, inliers, score
+		maxTrials = math.Min(maxTrials, ransacMaxTrials(len(inliers), nSamples, minSamples, regr.StopProbability))
+		if (regr.StopNInliers > 0 && len(inliers) >= regr.StopNInliers) || (regr.StopScore > 0 && score >= regr.StopScore) {
+			break
+		}
+	}
+	if bestMask == nil {
+		return &base.ValueError{Op: op, Msg: fmt.Sprintf("no random subset out of %d has inliers (%d skipped by IsDataValid, %d by IsModelValid), check ResidualThreshold", regr.NTrials, regr.NSkipsInvalidData, regr.NSkipsInvalidModel)}
+	}
+	best := estimator.Clone().(base.Regressor)
+	if err := fitRegressor(best, takeRows(X, bestInliers).(*mat.Dense), takeRows(Y, bestInliers).(*mat.Dense)); err != nil {
+		return err
+	}
+	regr.BestEstimator, regr.InlierMask = best, bestMask
+	return nil
+}
+
+// ransacMaxTrials returns the number of random subsets of minSamples samples to draw to draw one of nInliers
+// inliers only out of nSamples with probability
+func ransacMaxTrials(nInliers, nSamples, minSamples int, probability float64) float64 {
+	const eps = 1e-12
+	nom := math.Max(eps, 1-probability)
+	denom := math.Max(eps, 1-math.Pow(float64(nInliers)/float64(nSamples), float64(minSamples)))
+	if nom == 1 {
+		return 0
+	}
+	if denom == 1 {
+		return math.Inf(1)
+	}
+	return math.Ceil(math.Log(nom) / math.Log(denom))
+}
+
+// fitRegressor fits estimator to X,Y, with FitE if it has one. a *base.ConvergenceError leaves a usable model
+// and is not returned
+func fitRegressor(estimator base.Regressor, X, Y *mat.Dense) error {
+	var err error
+	if e, ok := estimator.(base.FitterE); ok {
+		err = e.FitE(X, Y)
+	} else {
+		err = base.Recover(func() { estimator.Fit(X, Y) })
+	}
+	var convergenceErr *base.ConvergenceError
+	if errors.As(err, &convergenceErr) {
+		return nil
+	}
+	return err
+}
+
+// median returns the median of x, which it sorts
+func median(x []float64) float64 {
+	sort.Float64s(x)
+	n := len(x)
+	if n%2 == 1 {
+		return x[n/2]
+	}
+	return (x[n/2-1] + x[n/2]) / 2
+}
+
+// Predict puts into Y the predictions of BestEstimator
+func (regr *RANSACRegressor) Predict(X, Y *mat.Dense) base.Regressor {
+	regr.BestEstimator.Predict(X, Y)
+	return regr
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (regr *RANSACRegressor) PredictE(X, Y *mat.Dense) error {
+	if regr.BestEstimator == nil {
+		return &base.NotFittedError{Estimator: regr}
+	}
+	if e, ok := regr.BestEstimator.(base.PredicterE); ok {
+		return e.PredictE(X, Y)
+	}
+	if err := base.CheckXY("RANSACRegressor.Predict", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { regr.Predict(X, Y) })
+}
+
+// Score returns the Score of BestEstimator
+func (regr *RANSACRegressor) Score(X, Y *mat.Dense) float64 {
+	return regr.BestEstimator.Score(X, Y)
+}
+
+// Transform is for Pipeline
+func (regr *RANSACRegressor) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
+	r, c := Y.Dims()
+	Xout, Yout = X, mat.NewDense(r, c, nil)
+	regr.Predict(X, Yout)
+	return
+}
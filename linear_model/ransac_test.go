@@ -0,0 +1,118 @@
+package linearmodel
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+func ExampleRANSACRegressor() {
+	X, Y := newOutliersProblem(200)
+	regr := NewRANSACRegressor()
+	regr.ResidualThreshold = .5
+	regr.RandomState = base.NewRandomState(7)
+	if err := regr.FitE(X, Y); err != nil {
+		fmt.Println(err)
+	}
+	ols := regr.BestEstimator.(*LinearRegression)
+	inliers := 0
+	for _, inlier := range regr.InlierMask {
+		if inlier {
+			inliers++
+		}
+	}
+	fmt.Printf("coef:%.1f intercept:%.1f inliers:%d/200\n", mat.Col(nil, 0, ols.Coef), ols.Intercept.At(0, 0), inliers)
+	// Output:
+	// coef:[2.0 -1.0 0.5] intercept:1.0 inliers:180/200
+}
+
+func TestRANSACRegressor(t *testing.T) {
+	X, Y := newOutliersProblem(200)
+	// a Huber estimator, MinSamples as a fraction and the default threshold
+	regr := &RANSACRegressor{Estimator: NewHuberRegressor(), MinSamples: .1, MaxTrials: 50, StopProbability: .99, RandomState: base.NewRandomState(7)}
+	if err := regr.FitE(X, Y); err != nil {
+		t.Fatal(err)
+	}
+	for i, inlier := range regr.InlierMask {
+		if i%10 == 0 && inlier {
+			t.Errorf("expected sample %d to be an outlier", i)
+		}
+	}
+	if regr.NTrials > 50 {
+		t.Errorf("expected at most MaxTrials trials, got %d", regr.NTrials)
+	}
+	Ypred := mat.NewDense(200, 1, nil)
+	if err := regr.PredictE(X, Ypred); err != nil {
+		t.Error(err)
+	}
+
+	// IsDataValid skips every subset
+	regr = NewRANSACRegressor()
+	regr.IsDataValid = func(X, Y *mat.Dense) bool { return false }
+	var valueErr *base.ValueError
+	if err := regr.FitE(X, Y); !errors.As(err, &valueErr) || regr.NSkipsInvalidData != regr.MaxTrials {
+		t.Errorf("expected a *base.ValueError after %d invalid subsets, got %v after %d", regr.MaxTrials, err, regr.NSkipsInvalidData)
+	}
+	// no sample is within a tiny threshold of a noisy fit
+	regr = NewRANSACRegressor()
+	regr.ResidualThreshold = 1e-12
+	regr.MinSamples = 20
+	if err := regr.FitE(X, Y); !errors.As(err, &valueErr) {
+		t.Errorf("expected a *base.ValueError for a tiny ResidualThreshold, got %v", err)
+	}
+
+	var notFittedErr *base.NotFittedError
+	if err := NewRANSACRegressor().PredictE(X, Ypred); !errors.As(err, &notFittedErr) {
+		t.Errorf("expected a *base.NotFittedError, got %v", err)
+	}
+	for _, params := range []func(*RANSACRegressor){
+		func(regr *RANSACRegressor) { regr.MinSamples = -1 },
+		func(regr *RANSACRegressor) { regr.MinSamples = 201 },
+		func(regr *RANSACRegressor) { regr.ResidualThreshold = -1 },
+		func(regr *RANSACRegressor) { regr.MaxTrials = 0 },
+		func(regr *RANSACRegressor) { regr.StopProbability = 2 },
+	} {
+		regr := NewRANSACRegressor()
+		params(regr)
+		var paramErr *base.ParamError
+		if err := regr.FitE(X, Y); !errors.As(err, &paramErr) {
+			t.Errorf("expected a *base.ParamError, got %v", err)
+		}
+	}
+}
+
+func TestRANSACRegressorClone(t *testing.T) {
+	regr := NewRANSACRegressor()
+	regr.Estimator = NewRidge()
+	clone1, clone2 := regr.Clone().(*RANSACRegressor), regr.Clone().(*RANSACRegressor)
+	clone1.Estimator.(*RegularizedRegression).Alpha = .1
+	clone2.Estimator.(*RegularizedRegression).Alpha = 5
+	if alpha := regr.Estimator.(*RegularizedRegression).Alpha; alpha != 1 || clone1.Estimator.(*RegularizedRegression).Alpha != .1 {
+		t.Errorf("expected independent estimators, got Alpha %g, %g and %g", alpha, clone1.Estimator.(*RegularizedRegression).Alpha, clone2.Estimator.(*RegularizedRegression).Alpha)
+	}
+	if err := base.SetParams(clone1, map[string]interface{}{"Estimator__Alpha": .5}); err != nil {
+		t.Fatal(err)
+	}
+	if alpha := clone2.Estimator.(*RegularizedRegression).Alpha; alpha != 5 {
+		t.Errorf("expected SetParams on a clone to leave the others unchanged, got Alpha %g", alpha)
+	}
+}
+
+func TestRANSACMaxTrials(t *testing.T) {
+	for _, test := range []struct {
+		nInliers, minSamples  int
+		probability, expected float64
+	}{
+		// log(.01)/log(1-.5²) = 16.01
+		{50, 2, .99, 17},
+		{100, 2, .99, 1},
+		{50, 2, 0, 0},
+	} {
+		if actual := ransacMaxTrials(test.nInliers, 100, test.minSamples, test.probability); actual != test.expected {
+			t.Errorf("%v: expected %g trials, got %g", test, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,605 @@
+package linearmodel
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// SGDParams are the parameters of the stochastic gradient descent of SGDClassifier, SGDRegressor, Perceptron,
+// PassiveAggressiveClassifier and PassiveAggressiveRegressor. the objective is the mean of the losses of the samples plus Alpha times the penalty. each sample updates the
+// weights by its loss gradient times the learning rate, then by the penalty
+type SGDParams struct {
+	// Penalty is l2, l1 (truncated cumulative penalty), elasticnet (mixed by L1Ratio) or none
+	Penalty string
+	// MaxIter is the maximal number of epochs of Fit, PartialFit runs a single epoch
+	MaxIter int
+	// Shuffle shuffles the samples at each epoch
+	Shuffle bool
+	// Epsilon is the threshold of the huber, epsilon_insensitive and squared_epsilon_insensitive losses
+	Epsilon float64
+	// LearningRate is constant (Eta0), optimal (1/(Alpha·(t0+t)), t0 being Léon Bottou's heuristic), invscaling
+	// (Eta0/t^PowerT) or adaptive (Eta0, divided by 5 each time NIterNoChange epochs do not improve the loss by Tol).
+	// t is the number of updates
+	LearningRate string
+	Eta0, PowerT float64
+	// Average, if > 0, makes Coef and Intercept the averages of the weights of the updates from the Average-th one
+	Average int
+	// NIterNoChange is the number of epochs without improvement of the loss by Tol stopping Fit
+	NIterNoChange int
+	// EarlyStopping makes Fit hold out ValidationFraction of the samples, drawn within each class for the
+	// classifiers, and stop when their score (accuracy or R²) did not improve by Tol for NIterNoChange epochs
+	EarlyStopping      bool
+	ValidationFraction float64
+	// WarmStart makes Fit start from the current Coef and Intercept instead of zero weights
+	WarmStart   bool
+	RandomState *base.RandomState
+}
+
+// SGDResult is the state of a stochastic gradient descent fit. PartialFit continues from it
+type SGDResult struct {
+	// NIter is the number of epochs of the last Fit or PartialFit, T is the number of updates since Fit.
+	// Converged is false if the loss still improved by Tol at the end of Fit
+	NIter     int
+	T         float64
+	Converged bool
+	// StandardCoef and StandardIntercept are the weights of the last update when Average > 0, Coef and Intercept
+	// being their averages
+	StandardCoef, StandardIntercept *mat.Dense
+}
+
+// paUpdate is the passive aggressive update rule of PassiveAggressiveClassifier and PassiveAggressiveRegressor,
+// stepping by the loss of the sample over its squared norm: at most c for variant 1 (PA-I), regularized by 1/(2c)
+// for variant 2 (PA-II). the zero paUpdate is the stochastic gradient descent
+type paUpdate struct {
+	variant int
+	c       float64
+}
+
+// step returns the step of a sample of loss and squared norm sqnorm
+func (pa paUpdate) step(loss, sqnorm float64) float64 {
+	if pa.variant == 1 {
+		if sqnorm == 0 {
+			return 0
+		}
+		return math.Min(pa.c, loss/sqnorm)
+	}
+	return loss / (sqnorm + .5/pa.c)
+}
+
+// sgdLoss is a loss of a sample of decision function p and target y (-1 or 1 for the classification losses)
+type sgdLoss interface {
+	loss(p, y float64) float64
+	// dloss is the derivative of loss relatively to p
+	dloss(p, y float64) float64
+}
+
+// hingeLoss is max(0, threshold-p·y): the loss of the linear SVM for threshold 1 and of the perceptron for threshold 0
+type hingeLoss struct{ threshold float64 }
+
+func (l hingeLoss) loss(p, y float64) float64 { return math.Max(0, l.threshold-p*y) }
+func (l hingeLoss) dloss(p, y float64) float64 {
+	if p*y <= l.threshold {
+		return -y
+	}
+	return 0
+}
+
+// squaredHingeLoss is max(0, threshold-p·y)²
+type squaredHingeLoss struct{ threshold float64 }
+
+func (l squaredHingeLoss) loss(p, y float64) float64 {
+	z := math.Max(0, l.threshold-p*y)
+	return z * z
+}
+func (l squaredHingeLoss) dloss(p, y float64) float64 { return -2 * y * math.Max(0, l.threshold-p*y) }
+
+// sgdLogLoss is log(1+exp(-p·y)), the loss of the logistic regression
+type sgdLogLoss struct{}
+
+func (sgdLogLoss) loss(p, y float64) float64  { return softplus(-p * y) }
+func (sgdLogLoss) dloss(p, y float64) float64 { return -y * expit(-p*y) }
+
+// modifiedHuberLoss is the quadratically smoothed hinge loss: max(0, 1-p·y)² for p·y >= -1, else -4·p·y
+type modifiedHuberLoss struct{}
+
+func (modifiedHuberLoss) loss(p, y float64) float64 {
+	z := p * y
+	switch {
+	case z >= 1:
+		return 0
+	case z >= -1:
+		return (1 - z) * (1 - z)
+	}
+	return -4 * z
+}
+func (modifiedHuberLoss) dloss(p, y float64) float64 {
+	z := p * y
+	switch {
+	case z >= 1:
+		return 0
+	case z >= -1:
+		return -2 * (1 - z) * y
+	}
+	return -4 * y
+}
+
+// squaredLoss is (p-y)²/2
+type squaredLoss struct{}
+
+func (squaredLoss) loss(p, y float64) float64  { return (p - y) * (p - y) / 2 }
+func (squaredLoss) dloss(p, y float64) float64 { return p - y }
+
+// huberLoss is squaredLoss for |p-y| <= epsilon, and linear beyond
+type huberLoss struct{ epsilon float64 }
+
+func (l huberLoss) loss(p, y float64) float64 {
+	r := math.Abs(p - y)
+	if r <= l.epsilon {
+		return r * r / 2
+	}
+	return l.epsilon * (r - l.epsilon/2)
+}
+func (l huberLoss) dloss(p, y float64) float64 { return math.Max(-l.epsilon, math.Min(l.epsilon, p-y)) }
+
+// epsilonInsensitiveLoss is max(0, |p-y|-epsilon), the loss of the linear support vector regression
+type epsilonInsensitiveLoss struct{ epsilon float64 }
+
+func (l epsilonInsensitiveLoss) loss(p, y float64) float64 {
+	return math.Max(0, math.Abs(p-y)-l.epsilon)
+}
+func (l epsilonInsensitiveLoss) dloss(p, y float64) float64 {
+	if math.Abs(p-y) > l.epsilon {
+		return sgn(p - y)
+	}
+	return 0
+}
+
+// squaredEpsilonInsensitiveLoss is max(0, |p-y|-epsilon)²
+type squaredEpsilonInsensitiveLoss struct{ epsilon float64 }
+
+func (l squaredEpsilonInsensitiveLoss) loss(p, y float64) float64 {
+	r := math.Max(0, math.Abs(p-y)-l.epsilon)
+	return r * r
+}
+func (l squaredEpsilonInsensitiveLoss) dloss(p, y float64) float64 {
+	return 2 * sgn(p-y) * math.Max(0, math.Abs(p-y)-l.epsilon)
+}
+
+// newSGDLoss returns the sgdLoss named loss, or nil if loss is unknown
+func newSGDLoss(loss string, epsilon float64) sgdLoss {
+	switch loss {
+	case "hinge":
+		return hingeLoss{threshold: 1}
+	case "perceptron":
+		return hingeLoss{threshold: 0}
+	case "squared_hinge":
+		return squaredHingeLoss{threshold: 1}
+	case "log":
+		return sgdLogLoss{}
+	case "modified_huber":
+		return modifiedHuberLoss{}
+	case "squared_loss":
+		return squaredLoss{}
+	case "huber":
+		return huberLoss{epsilon: epsilon}
+	case "epsilon_insensitive":
+		return epsilonInsensitiveLoss{epsilon: epsilon}
+	case "squared_epsilon_insensitive":
+		return squaredEpsilonInsensitiveLoss{epsilon: epsilon}
+	}
+	return nil
+}
+
+// check returns a *base.ParamError if a parameter of p is invalid. alpha is the penalty strength of estimator
+func (p *SGDParams) check(estimator interface{}, alpha, l1Ratio float64) error {
+	if err := checkAlphaL1Ratio(estimator, alpha, l1Ratio); err != nil {
+		return err
+	}
+	switch p.Penalty {
+	case "l2", "l1", "elasticnet", "none":
+	default:
+		return &base.ParamError{Estimator: estimator, Param: "Penalty", Value: p.Penalty, Msg: "must be l2, l1, elasticnet or none"}
+	}
+	if err := p.checkEpochs(estimator); err != nil {
+		return err
+	}
+	switch p.LearningRate {
+	case "optimal":
+		if alpha == 0 {
+			return &base.ParamError{Estimator: estimator, Param: "Alpha", Value: alpha, Msg: "must be > 0 with the optimal learning rate"}
+		}
+	case "constant", "invscaling", "adaptive":
+		if !(p.Eta0 > 0) {
+			return &base.ParamError{Estimator: estimator, Param: "Eta0", Value: p.Eta0, Msg: "must be > 0 with the " + p.LearningRate + " learning rate"}
+		}
+	default:
+		return &base.ParamError{Estimator: estimator, Param: "LearningRate", Value: p.LearningRate, Msg: "must be constant, optimal, invscaling or adaptive"}
+	}
+	return nil
+}
+
+// checkEpochs is check of the parameters of the epochs only, the passive aggressive updates having neither
+// penalty nor learning rate
+func (p *SGDParams) checkEpochs(estimator interface{}) error {
+	if p.MaxIter <= 0 {
+		return &base.ParamError{Estimator: estimator, Param: "MaxIter", Value: p.MaxIter, Msg: "must be > 0"}
+	}
+	if p.Epsilon < 0 {
+		return &base.ParamError{Estimator: estimator, Param: "Epsilon", Value: p.Epsilon, Msg: "must be >= 0"}
+	}
+	if p.Average < 0 {
+		return &base.ParamError{Estimator: estimator, Param: "Average", Value: p.Average, Msg: "must be >= 0"}
+	}
+	if p.NIterNoChange <= 0 {
+		return &base.ParamError{Estimator: estimator, Param: "NIterNoChange", Value: p.NIterNoChange, Msg: "must be > 0"}
+	}
+	if p.EarlyStopping && !(p.ValidationFraction > 0 && p.ValidationFraction < 1) {
+		return &base.ParamError{Estimator: estimator, Param: "ValidationFraction", Value: p.ValidationFraction, Msg: "must be in ]0,1[ with EarlyStopping"}
+	}
+	return nil
+}
+
+// checkCoefShape returns a *base.ShapeError if coef, fitted before, does not have nFeatures rows and nOutputs columns
+func checkCoefShape(op string, coef *mat.Dense, nFeatures, nOutputs int) error {
+	if r, c := coef.Dims(); r != nFeatures || c != nOutputs {
+		return &base.ShapeError{Op: op, Msg: fmt.Sprintf("X,Y have %d features and %d outputs, the model %d and %d", nFeatures, nOutputs, r, c)}
+	}
+	return nil
+}
+
+// sgdFit is the stochastic gradient descent of the outputs of a linear model, one after the other.
+// classification makes the validation score of EarlyStopping the accuracy of targets -1 or 1, else it is R².
+// labels, if not nil, are the classes the validation samples are drawn within
+type sgdFit struct {
+	SGDParams
+	op                   string
+	loss                 sgdLoss
+	pa                   paUpdate
+	alpha, l1, l2, tol   float64
+	fitIntercept, single bool
+	classification       bool
+	labels               []float64
+	rs                   *base.RandomState
+	progress             solverProgress
+}
+
+// newSGDFit returns the sgdFit of the operation op of estimator, for the ctx of its fit. single is true for
+// PartialFit: a single epoch without stopping criterion
+func newSGDFit(ctx context.Context, op string, estimator interface{}, params SGDParams, loss sgdLoss, alpha, l1Ratio, tol float64, fitIntercept, single bool) *sgdFit {
+	f := &sgdFit{SGDParams: params, op: op, loss: loss, alpha: alpha, tol: tol, fitIntercept: fitIntercept, single: single,
+		rs: base.CheckRandomState(params.RandomState)}
+	switch params.Penalty {
+	case "l2":
+		f.l2 = alpha
+	case "l1":
+		f.l1 = alpha
+	case "elasticnet":
+		f.l1, f.l2 = alpha*l1Ratio, alpha*(1-l1Ratio)
+	}
+	maxIter := params.MaxIter
+	if single {
+		maxIter = 1
+	}
+	f.progress = newSolverProgress(ctx, estimator, maxIter)
+	return f
+}
+
+// passiveAggressive makes f update the weights by pa, without penalty nor learning rate
+func (f *sgdFit) passiveAggressive(pa paUpdate) {
+	f.pa = pa
+	f.alpha, f.l1, f.l2 = 0, 0, 0
+	f.LearningRate = ""
+}
+
+// split returns the indices of the samples of the epochs and of the validation of EarlyStopping, the
+// ValidationFraction of the samples of each of the labels. there is no validation without EarlyStopping or for
+// PartialFit
+func (f *sgdFit) split(nSamples int) (train, validation []int, err error) {
+	if !f.EarlyStopping || f.single {
+		train = make([]int, nSamples)
+		for i := range train {
+			train[i] = i
+		}
+		return
+	}
+	groups := map[float64][]int{}
+	for _, i := range f.rs.Perm(nSamples) {
+		label := 0.
+		if f.labels != nil {
+			label = f.labels[i]
+		}
+		groups[label] = append(groups[label], i)
+	}
+	labels := make([]float64, 0, len(groups))
+	for label := range groups {
+		labels = append(labels, label)
+	}
+	sort.Float64s(labels)
+	for _, label := range labels {
+		group := groups[label]
+		nValidation := int(math.Ceil(f.ValidationFraction * float64(len(group))))
+		validation = append(validation, group[:nValidation]...)
+		train = append(train, group[nValidation:]...)
+	}
+	if len(train) == 0 {
+		return nil, nil, &base.ValueError{Op: f.op, Msg: fmt.Sprintf("ValidationFraction %g leaves no training sample out of %d", f.ValidationFraction, nSamples)}
+	}
+	// the epochs without Shuffle visit the samples in order
+	sort.Ints(train)
+	sort.Ints(validation)
+	return
+}
+
+// validationScore returns the score of the weights w and intercept b on the validation samples of target y:
+// the weighted accuracy of the sign of the decision function if classification, else R²
+func (f *sgdFit) validationScore(X *mat.Dense, y, sampleWeight, w []float64, b float64, validation []int) float64 {
+	weight := func(i int) float64 {
+		if sampleWeight == nil {
+			return 1
+		}
+		return sampleWeight[i]
+	}
+	sumWeights, mean := 0., 0.
+	for _, i := range validation {
+		sumWeights += weight(i)
+		mean += weight(i) * y[i]
+	}
+	mean /= sumWeights
+	correct, ssRes, ssTot := 0., 0., 0.
+	for _, i := range validation {
+		p := floats.Dot(X.RawRowView(i), w) + b
+		if f.classification {
+			if (p > 0) == (y[i] > 0) {
+				correct += weight(i)
+			}
+			continue
+		}
+		ssRes += weight(i) * (y[i] - p) * (y[i] - p)
+		ssTot += weight(i) * (y[i] - mean) * (y[i] - mean)
+	}
+	if f.classification {
+		return correct / sumWeights
+	}
+	if ssTot == 0 {
+		return 0
+	}
+	return 1 - ssRes/ssTot
+}
+
+// fit updates the linear model of coef, intercept and result with Y, the target of each of its outputs.
+// coef and intercept are replaced by new matrices, fitted from the current ones if warm is true, else from zero.
+// sampleWeight may be nil
+func (f *sgdFit) fit(X, Y *mat.Dense, sampleWeight []float64, coef, intercept **mat.Dense, result *SGDResult, warm bool) error {
+	_, nFeatures := X.Dims()
+	_, nOutputs := Y.Dims()
+	averaged := f.Average > 0
+	W, b := mat.NewDense(nFeatures, nOutputs, nil), mat.NewDense(1, nOutputs, nil)
+	avgW, avgB := mat.NewDense(nFeatures, nOutputs, nil), mat.NewDense(1, nOutputs, nil)
+	if warm {
+		avgW.Copy(*coef)
+		avgB.Copy(*intercept)
+		W.Copy(*coef)
+		b.Copy(*intercept)
+		if averaged && result.StandardCoef != nil {
+			W.Copy(result.StandardCoef)
+			b.Copy(result.StandardIntercept)
+		}
+	} else {
+		*result = SGDResult{}
+	}
+	nSamples, _ := X.Dims()
+	train, validation, err := f.split(nSamples)
+	if err != nil {
+		return err
+	}
+	t := result.T
+	converged, nIter := true, 0
+	for o := 0; o < nOutputs; o++ {
+		w, avgw := mat.Col(nil, o, W), mat.Col(nil, o, avgW)
+		bo, avgbo := b.At(0, o), avgB.At(0, o)
+		outputIter, outputConverged, err := f.epochs(X, mat.Col(nil, o, Y), sampleWeight, w, &bo, avgw, &avgbo, t, train, validation)
+		if err != nil {
+			return err
+		}
+		W.SetCol(o, w)
+		b.Set(0, o, bo)
+		avgW.SetCol(o, avgw)
+		avgB.Set(0, o, avgbo)
+		if outputIter > nIter {
+			nIter = outputIter
+		}
+		converged = converged && outputConverged
+	}
+	result.NIter, result.T, result.Converged = nIter, t+float64(nIter*len(train)), converged
+	*coef, *intercept = W, b
+	result.StandardCoef, result.StandardIntercept = nil, nil
+	if averaged {
+		result.StandardCoef, result.StandardIntercept = W, b
+		if result.T >= float64(f.Average) {
+			*coef, *intercept = avgW, avgB
+		}
+	}
+	return nil
+}
+
+// epochs runs the epochs of the stochastic gradient descent of the weights w and the intercept b of the output of
+// target y, their averages being updated into avgw and avgb, from t updates. the epochs visit the samples of
+// train, and stop when the loss, or the score of the samples of validation with EarlyStopping, stops improving.
+// it returns the number of epochs and whether they stopped improving
+func (f *sgdFit) epochs(X *mat.Dense, y, sampleWeight, w []float64, b *float64, avgw []float64, avgb *float64, t float64, train, validation []int) (nIter int, converged bool, err error) {
+	_, nFeatures := X.Dims()
+	nSamples := len(train)
+	order := append([]int{}, train...)
+	eta := f.Eta0
+	// optimalInit is the t0 of the optimal learning rate, so that the first step is about 1/(Alpha·t0)
+	optimalInit := 0.
+	if f.LearningRate == "optimal" {
+		typw := math.Sqrt(1 / math.Sqrt(f.alpha))
+		eta0 := typw / math.Max(1, f.loss.dloss(-typw, 1))
+		optimalInit = 1 / (eta0 * f.alpha)
+	}
+	// u and q are the total l1 penalty and the one applied to each weight, of the truncated cumulative penalty
+	u, q := 0., make([]float64, nFeatures)
+	maxIter := f.MaxIter
+	if f.single {
+		maxIter = 1
+	}
+	bestLoss, bestScore, noImprovement := math.Inf(1), math.Inf(-1), 0
+	for nIter < maxIter {
+		if f.Shuffle {
+			f.rs.Shuffle(nSamples, func(i, j int) { order[i], order[j] = order[j], order[i] })
+		}
+		sumLoss := 0.
+		for _, i := range order {
+			x := X.RawRowView(i)
+			p := floats.Dot(x, w) + *b
+			sumLoss += f.loss.loss(p, y[i])
+			switch f.LearningRate {
+			case "optimal":
+				eta = 1 / (f.alpha * (optimalInit + t))
+			case "invscaling":
+				eta = f.Eta0 / math.Pow(t+1, f.PowerT)
+			}
+			var update float64
+			if f.pa.variant == 0 {
+				update = -eta * math.Max(-1e12, math.Min(1e12, f.loss.dloss(p, y[i])))
+			} else {
+				update = -sgn(f.loss.dloss(p, y[i])) * f.pa.step(f.loss.loss(p, y[i]), floats.Dot(x, x))
+			}
+			if sampleWeight != nil {
+				update *= sampleWeight[i]
+			}
+			if f.l2 > 0 {
+				floats.Scale(math.Max(0, 1-eta*f.l2), w)
+			}
+			if update != 0 {
+				floats.AddScaled(w, update, x)
+				if f.fitIntercept {
+					*b += update
+				}
+			}
+			if f.l1 > 0 {
+				u += eta * f.l1
+				for j, wj := range w {
+					if wj > 0 {
+						w[j] = math.Max(0, wj-(u+q[j]))
+					} else if wj < 0 {
+						w[j] = math.Min(0, wj+(u-q[j]))
+					}
+					q[j] += w[j] - wj
+				}
+			}
+			t++
+			if f.Average > 0 && t >= float64(f.Average) {
+				mu := 1 / (t - float64(f.Average) + 1)
+				for j, wj := range w {
+					avgw[j] += mu * (wj - avgw[j])
+				}
+				*avgb += mu * (*b - *avgb)
+			}
+		}
+		nIter++
+		if norm := floats.Norm(w, 1) + math.Abs(*b); math.IsNaN(norm) || math.IsInf(norm, 0) {
+			return nIter, false, &base.ValueError{Op: f.op, Msg: fmt.Sprintf("floating-point overflow at epoch %d, scale X or reduce Eta0", nIter)}
+		}
+		if err := f.progress(nIter, sumLoss/float64(nSamples)); err != nil {
+			return nIter, false, err
+		}
+		if f.single {
+			break
+		}
+		if f.EarlyStopping {
+			score := f.validationScore(X, y, sampleWeight, w, *b, validation)
+			if score < bestScore+f.tol {
+				noImprovement++
+			} else {
+				noImprovement = 0
+			}
+			bestScore = math.Max(bestScore, score)
+		} else {
+			if sumLoss > bestLoss-f.tol*float64(nSamples) {
+				noImprovement++
+			} else {
+				noImprovement = 0
+			}
+			bestLoss = math.Min(bestLoss, sumLoss)
+		}
+		if noImprovement >= f.NIterNoChange {
+			if f.LearningRate != "adaptive" || eta <= 1e-6 {
+				return nIter, true, nil
+			}
+			eta /= 5
+			noImprovement = 0
+		}
+	}
+	return nIter, f.single, nil
+}
+
+// PartialFit runs a single epoch of stochastic gradient descent of Loss on the samples X,Y, from the current weights
+func (regr *SGDRegressor) PartialFit(X, Y *mat.Dense) error {
+	const op = "SGDRegressor.PartialFit"
+	if err := base.CheckFitXY(op, X, Y); err != nil {
+		return err
+	}
+	if newSGDLoss(regr.sgdLoss(), regr.Epsilon) == nil {
+		return &base.ParamError{Estimator: regr, Param: "Loss", Value: regr.Loss, Msg: "must be squared_loss, huber, epsilon_insensitive or squared_epsilon_insensitive"}
+	}
+	if err := regr.SGDParams.check(regr, regr.Alpha, regr.L1Ratio); err != nil {
+		return err
+	}
+	if regr.Coef != nil {
+		_, nFeatures := X.Dims()
+		_, nOutputs := Y.Dims()
+		if err := checkCoefShape(op, regr.Coef, nFeatures, nOutputs); err != nil {
+			return err
+		}
+	}
+	var err error
+	if rerr := base.Recover(func() { err = regr.fitSGD(context.Background(), X, Y, nil, true) }); rerr != nil {
+		return rerr
+	}
+	return err
+}
+
+// sgdLoss returns the name of the sgdLoss of Loss
+func (regr *SGDRegressor) sgdLoss() string {
+	if regr.Loss == "" {
+		return "squared_loss"
+	}
+	return regr.Loss
+}
+
+// fitSGD fits Coef and Intercept by stochastic gradient descent, a single epoch continuing from them if partial is true
+func (regr *SGDRegressor) fitSGD(ctx context.Context, X mat.Matrix, Y *mat.Dense, sampleWeight []float64, partial bool) error {
+	warm, err := warmStart("SGDRegressor.Fit", regr.Coef, &regr.SGDResult, X, Y, regr.WarmStart, partial)
+	if err != nil {
+		return err
+	}
+	// the weights apply to X itself, not to X centered
+	regr.XOffset, regr.XScale = nil, nil
+	f := newSGDFit(ctx, "SGDRegressor.Fit", regr, regr.SGDParams, newSGDLoss(regr.sgdLoss(), regr.Epsilon), regr.Alpha, regr.L1Ratio, regr.Tol, regr.FitIntercept, partial)
+	return f.fit(denseOf(X), Y, sampleWeight, &regr.Coef, &regr.Intercept, &regr.SGDResult, warm)
+}
+
+// warmStart returns whether the fit of X,Y continues from coef, fitted before: always for PartialFit, for Fit with
+// WarmStart, which restarts the count of updates of result. it returns a *base.ShapeError for a coef of other dims
+func warmStart(op string, coef *mat.Dense, result *SGDResult, X mat.Matrix, Y *mat.Dense, warmStart, partial bool) (bool, error) {
+	if coef == nil || !(partial || warmStart) {
+		return false, nil
+	}
+	_, nFeatures := X.Dims()
+	_, nOutputs := Y.Dims()
+	if err := checkCoefShape(op, coef, nFeatures, nOutputs); err != nil {
+		return false, err
+	}
+	if !partial {
+		result.T = 0
+	}
+	return true, nil
+}
@@ -0,0 +1,216 @@
+package linearmodel
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/metrics"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestSGDLosses(t *testing.T) {
+	for _, name := range []string{"hinge", "perceptron", "squared_hinge", "log", "modified_huber",
+		"squared_loss", "huber", "epsilon_insensitive", "squared_epsilon_insensitive"} {
+		loss := newSGDLoss(name, .1)
+		for _, y := range []float64{-1, 1} {
+			// decision functions away from the kinks of the losses
+			for _, p := range []float64{-2.3, -.7, -.05, .43, .8, 1.7} {
+				h := 1e-6
+				numeric := (loss.loss(p+h, y) - loss.loss(p-h, y)) / (2 * h)
+				if math.Abs(numeric-loss.dloss(p, y)) > 1e-5 {
+					t.Errorf("%s: dloss(%g,%g)=%g, numeric derivative %g", name, p, y, loss.dloss(p, y), numeric)
+				}
+			}
+		}
+	}
+	if newSGDLoss("bogus", .1) != nil {
+		t.Error("expected a nil loss for an unknown name")
+	}
+}
+
+// newOutliersProblem returns a linear problem of 3 standard features whose targets 2x0-x1+.5x2+1 have a small noise,
+// 1 sample out of 10 being an outlier
+func newOutliersProblem(nSamples int) (X, Y *mat.Dense) {
+	rnd := rand.New(rand.NewSource(7))
+	X, Y = mat.NewDense(nSamples, 3, nil), mat.NewDense(nSamples, 1, nil)
+	for i := 0; i < nSamples; i++ {
+		row := X.RawRowView(i)
+		for j := range row {
+			row[j] = rnd.NormFloat64()
+		}
+		y := 2*row[0] - row[1] + .5*row[2] + 1 + .05*rnd.NormFloat64()
+		if i%10 == 0 {
+			y += 30
+		}
+		Y.Set(i, 0, y)
+	}
+	return
+}
+
+func TestSGDRegressorLosses(t *testing.T) {
+	X, Y := newOutliersProblem(400)
+	for _, loss := range []string{"huber", "epsilon_insensitive"} {
+		regr := NewSGDRegressor()
+		regr.Loss = loss
+		regr.Alpha = 1e-5
+		regr.RandomState = base.NewRandomState(7)
+		if err := regr.FitE(X, Y); err != nil {
+			t.Fatalf("%s: %s", loss, err)
+		}
+		coef, intercept := mat.Col(nil, 0, regr.Coef), regr.Intercept.At(0, 0)
+		if math.Abs(coef[0]-2) > .1 || math.Abs(coef[1]+1) > .1 || math.Abs(coef[2]-.5) > .1 || math.Abs(intercept-1) > .3 {
+			t.Errorf("%s: expected coef [2 -1 .5] intercept 1 despite the outliers, got %.3f %.3f", loss, coef, intercept)
+		}
+	}
+	// the squared losses follow the outliers
+	regr := NewSGDRegressor()
+	regr.Loss = "squared_epsilon_insensitive"
+	regr.RandomState = base.NewRandomState(7)
+	if err := regr.FitE(X, Y); err != nil {
+		t.Fatal(err)
+	}
+	if intercept := regr.Intercept.At(0, 0); math.Abs(intercept-4) > .5 {
+		t.Errorf("expected the intercept 1 plus the mean outlier 3, got %.3f", intercept)
+	}
+
+	var paramErr *base.ParamError
+	regr.Loss = "hinge"
+	if err := regr.FitE(X, Y); !errors.As(err, &paramErr) {
+		t.Errorf("expected a *base.ParamError for the hinge loss, got %v", err)
+	}
+	regr.Loss, regr.LearningRate = "huber", "bogus"
+	if err := regr.FitE(X, Y); !errors.As(err, &paramErr) {
+		t.Errorf("expected a *base.ParamError for an unknown learning rate, got %v", err)
+	}
+	regr.LearningRate, regr.MaxIter = "invscaling", 2
+	var convergenceErr *base.ConvergenceError
+	if err := regr.FitE(X, Y); !errors.As(err, &convergenceErr) || convergenceErr.NIter != 2 {
+		t.Errorf("expected a *base.ConvergenceError after 2 epochs, got %v", err)
+	}
+}
+
+func TestSGDRegressorPartialFit(t *testing.T) {
+	p := NewRandomLinearProblem(300, 3, 2)
+	regr := NewSGDRegressor()
+	regr.RandomState = base.NewRandomState(7)
+	for epoch := 0; epoch < 20; epoch++ {
+		for start := 0; start < 300; start += 100 {
+			Xb := p.X.Slice(start, start+100, 0, 3).(*mat.Dense)
+			Yb := p.Y.Slice(start, start+100, 0, 2).(*mat.Dense)
+			if err := regr.PartialFit(Xb, Yb); err != nil {
+				t.Fatal(err)
+			}
+			if regr.SGDResult.NIter != 1 {
+				t.Fatalf("expected a single epoch per PartialFit, got %d", regr.SGDResult.NIter)
+			}
+		}
+	}
+	if regr.SGDResult.T != 20*300 {
+		t.Errorf("expected %d updates, got %g", 20*300, regr.SGDResult.T)
+	}
+	Ypred := mat.NewDense(300, 2, nil)
+	regr.Predict(p.X, Ypred)
+	if r2 := metrics.R2Score(p.Y, Ypred, nil, "").At(0, 0); r2 < .99 {
+		t.Errorf("expected a R2 score above .99, got %g", r2)
+	}
+	var shapeErr *base.ShapeError
+	if err := regr.PartialFit(p.X.Slice(0, 10, 0, 2).(*mat.Dense), p.Y.Slice(0, 10, 0, 2).(*mat.Dense)); !errors.As(err, &shapeErr) {
+		t.Errorf("expected a *base.ShapeError for a batch of 2 features, got %v", err)
+	}
+}
+
+func TestSGDEarlyStopping(t *testing.T) {
+	// the validation samples are drawn within each class
+	labels := make([]float64, 300)
+	for i := range labels {
+		labels[i] = float64(i % 3)
+	}
+	f := &sgdFit{SGDParams: SGDParams{EarlyStopping: true, ValidationFraction: .1}, labels: labels, rs: base.NewRandomState(7)}
+	train, validation, err := f.split(300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[float64]int{}
+	for _, i := range validation {
+		counts[labels[i]]++
+	}
+	if len(train) != 270 || counts[0] != 10 || counts[1] != 10 || counts[2] != 10 {
+		t.Errorf("expected 270 training samples and 10 validation samples per class, got %d %v", len(train), counts)
+	}
+	f.ValidationFraction = .999
+	var valueErr *base.ValueError
+	if _, _, err := f.split(300); !errors.As(err, &valueErr) {
+		t.Errorf("expected a *base.ValueError without training samples, got %v", err)
+	}
+
+	X, Y := newBlobsProblem(300, 3, 0)
+	clf := NewSGDClassifier()
+	clf.EarlyStopping = true
+	clf.RandomState = base.NewRandomState(7)
+	if err := clf.FitE(X, Y); err != nil {
+		t.Fatal(err)
+	}
+	if clf.SGDResult.T != float64(clf.SGDResult.NIter*270) {
+		t.Errorf("expected %d epochs of 270 samples, got %g updates", clf.SGDResult.NIter, clf.SGDResult.T)
+	}
+	if score := clf.Score(X, Y); score < .8 {
+		t.Errorf("expected an accuracy above .8, got %.3f", score)
+	}
+
+	p := NewRandomLinearProblem(200, 3, 1)
+	regr := NewPassiveAggressiveRegressor()
+	regr.EarlyStopping = true
+	regr.RandomState = base.NewRandomState(7)
+	if err := regr.FitE(p.X, p.Y); err != nil {
+		t.Fatal(err)
+	}
+	Ypred := &mat.Dense{}
+	regr.Predict(p.X, Ypred)
+	if r2 := metrics.R2Score(p.Y, Ypred, nil, "").At(0, 0); r2 < .99 {
+		t.Errorf("expected a R2 score above .99, got %g", r2)
+	}
+}
+
+func TestSGDWarmStart(t *testing.T) {
+	X, Y := newOutliersProblem(200)
+	newRegressor := func() *PassiveAggressiveRegressor {
+		regr := NewPassiveAggressiveRegressor()
+		regr.MaxIter = 1
+		regr.RandomState = base.NewRandomState(7)
+		return regr
+	}
+	cold, warm := newRegressor(), newRegressor()
+	warm.WarmStart = true
+	cold.Fit(X, Y)
+	warm.Fit(X, Y)
+	coef := mat.DenseCopyOf(cold.Coef)
+	cold.Fit(X, Y)
+	warm.Fit(X, Y)
+	if !mat.Equal(coef, cold.Coef) {
+		t.Error("expected Fit to restart from zero weights without WarmStart")
+	}
+	if mat.Equal(coef, warm.Coef) {
+		t.Error("expected Fit to continue from the weights of the previous fit with WarmStart")
+	}
+	if warm.SGDResult.T != 200 {
+		t.Errorf("expected Fit to restart the count of updates, got %g", warm.SGDResult.T)
+	}
+	var shapeErr *base.ShapeError
+	if err := warm.FitE(X.Slice(0, 200, 0, 2).(*mat.Dense), Y); !errors.As(err, &shapeErr) {
+		t.Errorf("expected a *base.ShapeError for 2 features, got %v", err)
+	}
+
+	Xcls, Ycls := newBlobsProblem(300, 3, 0)
+	clf := NewPerceptron()
+	clf.WarmStart = true
+	clf.Fit(Xcls, Ycls)
+	Yother := mat.NewDense(300, 1, nil)
+	Yother.Apply(func(_, _ int, y float64) float64 { return y + 1 }, Ycls)
+	var valueErr *base.ValueError
+	if err := clf.FitE(Xcls, Yother); !errors.As(err, &valueErr) {
+		t.Errorf("expected a *base.ValueError for a label missing from the classes of the previous fit, got %v", err)
+	}
+}
@@ -0,0 +1,344 @@
+package linearmodel
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// SGDClassifier is a linear classifier fitted by stochastic gradient descent, see SGDParams.
+// Loss is hinge (default, a linear SVM), log (a logistic regression), modified_huber (a smoothed hinge loss
+// tolerant to outliers), squared_hinge or perceptron.
+// a single column Y holds class labels: 2 classes are fitted as a single output, positive for the second class,
+// more classes one-vs-rest. the columns of a Y of several columns are 0/1 indicators, each fitted as a binary problem.
+// PartialFit learns from a batch of samples in a single epoch, to train on data that do not fit in memory.
+// ClassWeight is nil, "balanced" or a map[float64]float64 from class labels to weights, see base.ComputeClassWeight
+type SGDClassifier struct {
+	LinearModel
+	SGDParams
+	Loss                string
+	Tol, Alpha, L1Ratio float64
+	ClassWeight         interface{}
+	// Classes are the sorted classes of a single column Y
+	Classes   []float64
+	SGDResult SGDResult
+}
+
+// NewSGDClassifier creates a *SGDClassifier with defaults: hinge loss, l2 penalty and optimal learning rate
+func NewSGDClassifier() *SGDClassifier {
+	regr := &SGDClassifier{Loss: "hinge", Tol: 1e-3, Alpha: 1e-4, L1Ratio: .15, SGDParams: SGDParams{
+		Penalty: "l2", MaxIter: 1000, Shuffle: true, Epsilon: .1, LearningRate: "optimal", PowerT: .5, NIterNoChange: 5, ValidationFraction: .1,
+	}}
+	regr.FitIntercept = true
+	return regr
+}
+
+// Clone for SGDClassifier
+func (regr *SGDClassifier) Clone() base.Transformer {
+	clone := *regr
+	return &clone
+}
+
+// sgd returns the classifierFit of SGDClassifier
+func (regr *SGDClassifier) sgd() classifierFit {
+	return classifierFit{est: regr, name: "SGDClassifier"}
+}
+
+// Fit learns Coef and Intercept from X and the classes Y, from zero weights unless WarmStart
+func (regr *SGDClassifier) Fit(X, Y *mat.Dense) base.Transformer {
+	if err := regr.fit(context.Background(), regr.sgd(), "SGDClassifier.Fit", X, Y, nil, nil, false); err != nil {
+		panic(err)
+	}
+	return regr
+}
+
+// FitE is Fit returning an error instead of panicking.
+// a *base.ConvergenceError is returned if the loss still improved by Tol after MaxIter epochs, the model is still usable
+func (regr *SGDClassifier) FitE(X, Y *mat.Dense) error {
+	return regr.fitE(context.Background(), regr.sgd(), "Fit", X, Y, nil)
+}
+
+// FitContext is FitE stopping between epochs when ctx is done. it reports the mean loss of each epoch
+// to the ProgressCallback of ctx
+func (regr *SGDClassifier) FitContext(ctx context.Context, X, Y *mat.Dense) error {
+	return regr.fitE(ctx, regr.sgd(), "Fit", X, Y, nil)
+}
+
+// FitWeighted is FitE with the loss of each sample weighted by sampleWeight
+func (regr *SGDClassifier) FitWeighted(X, Y *mat.Dense, sampleWeight []float64) error {
+	return regr.fitE(context.Background(), regr.sgd(), "FitWeighted", X, Y, sampleWeight)
+}
+
+// PartialFit runs a single epoch of stochastic gradient descent on the samples X,Y, from the current weights.
+// the first call of a single column Y must list all the classes in classes, later calls may pass nil.
+// "balanced" ClassWeight is not supported, the class frequencies being unknown before the last batch
+func (regr *SGDClassifier) PartialFit(X, Y *mat.Dense, classes []float64) error {
+	return regr.partialFit(regr.sgd(), X, Y, classes)
+}
+
+// classifierFit is how the estimator est named name, SGDClassifier or an estimator embedding it, fits it:
+// by the passive aggressive update pa, or by stochastic gradient descent for the zero pa
+type classifierFit struct {
+	est  interface{}
+	name string
+	pa   paUpdate
+}
+
+func (regr *SGDClassifier) partialFit(cf classifierFit, X, Y *mat.Dense, classes []float64) error {
+	op := cf.name + ".PartialFit"
+	if err := regr.checkFit(cf, op, X, Y); err != nil {
+		return err
+	}
+	if s, ok := regr.ClassWeight.(string); ok && s == "balanced" {
+		return &base.ParamError{Estimator: cf.est, Param: "ClassWeight", Value: regr.ClassWeight, Msg: "balanced is not supported by PartialFit"}
+	}
+	_, nOutputs := Y.Dims()
+	fitted := regr.Coef != nil
+	if nOutputs == 1 && !fitted && classes == nil {
+		return &base.ValueError{Op: op, Msg: "classes must be passed on the first call"}
+	}
+	if classes != nil {
+		classes = append([]float64{}, classes...)
+		sort.Float64s(classes)
+		if fitted && regr.Classes != nil && !floats.Equal(classes, regr.Classes) {
+			return &base.ValueError{Op: op, Msg: fmt.Sprintf("classes %g differ from the classes %g of the previous calls", classes, regr.Classes)}
+		}
+	}
+	var err error
+	if rerr := base.Recover(func() { err = regr.fit(context.Background(), cf, op, X, Y, nil, classes, true) }); rerr != nil {
+		return rerr
+	}
+	return err
+}
+
+// fitE is FitE, FitContext and FitWeighted, method being the name of the latter
+func (regr *SGDClassifier) fitE(ctx context.Context, cf classifierFit, method string, X, Y *mat.Dense, sampleWeight []float64) error {
+	op := cf.name + "." + method
+	if err := regr.checkFit(cf, op, X, Y); err != nil {
+		return err
+	}
+	if sampleWeight != nil {
+		if err := base.CheckSampleWeight(op, X, sampleWeight); err != nil {
+			return err
+		}
+	}
+	var err error
+	if rerr := base.Recover(func() { err = regr.fit(ctx, cf, op, X, Y, sampleWeight, nil, false) }); rerr != nil {
+		return rerr
+	}
+	if err == nil && !regr.SGDResult.Converged {
+		err = &base.ConvergenceError{Estimator: cf.est, NIter: regr.SGDResult.NIter, Msg: fmt.Sprintf("the loss still improves by Tol %g, increase MaxIter", regr.Tol)}
+	}
+	return err
+}
+
+func (regr *SGDClassifier) checkFit(cf classifierFit, op string, X, Y *mat.Dense) error {
+	if err := base.CheckFitXY(op, X, Y); err != nil {
+		return err
+	}
+	if cf.pa.variant != 0 {
+		return regr.SGDParams.checkEpochs(cf.est)
+	}
+	switch regr.Loss {
+	case "hinge", "log", "modified_huber", "squared_hinge", "perceptron":
+	default:
+		return &base.ParamError{Estimator: cf.est, Param: "Loss", Value: regr.Loss, Msg: "must be hinge, log, modified_huber, squared_hinge or perceptron"}
+	}
+	return regr.SGDParams.check(cf.est, regr.Alpha, regr.L1Ratio)
+}
+
+// fit fits the weights to X,Y for the operation op. partial is true for PartialFit, classes being its classes or nil
+func (regr *SGDClassifier) fit(ctx context.Context, cf classifierFit, op string, X, Y *mat.Dense, sampleWeight []float64, classes []float64, partial bool) error {
+	sampleWeight, err := base.ApplyClassWeight(regr.ClassWeight, Y, sampleWeight)
+	if err != nil {
+		return err
+	}
+	_, nOutputs := Y.Dims()
+	// Classes, hence the outputs, are those of the previous fit when continuing from it
+	continued := partial || regr.WarmStart
+	if !continued || regr.Coef == nil {
+		regr.Classes = nil
+		if nOutputs == 1 {
+			regr.Classes = classes
+			if regr.Classes == nil {
+				regr.Classes = sortedClasses(Y)
+			}
+			if len(regr.Classes) < 2 {
+				return &base.ValueError{Op: op, Msg: "Y must have at least 2 classes"}
+			}
+		}
+	}
+	targets, err := regr.signTargets(op, Y)
+	if err != nil {
+		return err
+	}
+	warm, err := warmStart(op, regr.Coef, &regr.SGDResult, X, targets, regr.WarmStart, partial)
+	if err != nil {
+		return err
+	}
+	loss := newSGDLoss(regr.Loss, regr.Epsilon)
+	if cf.pa.variant != 0 {
+		// both variants step along the hinge loss
+		loss = hingeLoss{threshold: 1}
+	}
+	f := newSGDFit(ctx, op, cf.est, regr.SGDParams, loss, regr.Alpha, regr.L1Ratio, regr.Tol, regr.FitIntercept, partial)
+	if cf.pa.variant != 0 {
+		f.passiveAggressive(cf.pa)
+	}
+	f.classification = true
+	if nOutputs == 1 {
+		f.labels = mat.Col(nil, 0, Y)
+	}
+	return f.fit(X, targets, sampleWeight, &regr.Coef, &regr.Intercept, &regr.SGDResult, warm)
+}
+
+// signTargets returns the targets of the outputs, 1 for the samples of their class, else -1. the outputs are the
+// one-vs-rest Classes, a single one for 2 classes, or the columns of a Y of several columns
+func (regr *SGDClassifier) signTargets(op string, Y *mat.Dense) (*mat.Dense, error) {
+	nSamples, nOutputs := Y.Dims()
+	if regr.Classes == nil {
+		if nOutputs == 1 {
+			return nil, &base.ShapeError{Op: op, Msg: "Y has a single column, the model was fitted to 0/1 indicator columns"}
+		}
+		targets := &mat.Dense{}
+		targets.Apply(func(_, _ int, y float64) float64 {
+			if y > 0 {
+				return 1
+			}
+			return -1
+		}, Y)
+		return targets, nil
+	}
+	if nOutputs != 1 {
+		return nil, &base.ShapeError{Op: op, Msg: fmt.Sprintf("Y has %d columns, the model was fitted to a single column of classes", nOutputs)}
+	}
+	nOutputs = len(regr.Classes)
+	if nOutputs == 2 {
+		nOutputs = 1
+	}
+	targets := mat.NewDense(nSamples, nOutputs, nil)
+	for i := 0; i < nSamples; i++ {
+		y := Y.At(i, 0)
+		k := sort.SearchFloat64s(regr.Classes, y)
+		if k == len(regr.Classes) || regr.Classes[k] != y {
+			return nil, &base.ValueError{Op: op, Msg: fmt.Sprintf("label %g is not in classes %g", y, regr.Classes)}
+		}
+		row := targets.RawRowView(i)
+		for o := range row {
+			row[o] = -1
+		}
+		if nOutputs == 1 {
+			row[0] = 2*float64(k) - 1
+		} else {
+			row[k] = 1
+		}
+	}
+	return targets, nil
+}
+
+// Predict puts into Y the class of highest decision function, or 0/1 indicators when fitted to several columns
+func (regr *SGDClassifier) Predict(X, Y *mat.Dense) base.Regressor {
+	Z := &mat.Dense{}
+	regr.decisionFunction(X, Z)
+	nSamples, nOutputs := Z.Dims()
+	if regr.Classes == nil {
+		if Y.IsZero() {
+			*Y = *mat.NewDense(nSamples, nOutputs, nil)
+		}
+		Y.Apply(func(i, o int, _ float64) float64 {
+			if Z.At(i, o) > 0 {
+				return 1
+			}
+			return 0
+		}, Y)
+		return regr
+	}
+	if Y.IsZero() {
+		*Y = *mat.NewDense(nSamples, 1, nil)
+	}
+	for i := 0; i < nSamples; i++ {
+		row := Z.RawRowView(i)
+		k := floats.MaxIdx(row)
+		if nOutputs == 1 {
+			k = 0
+			if row[0] > 0 {
+				k = 1
+			}
+		}
+		Y.Set(i, 0, regr.Classes[k])
+	}
+	return regr
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (regr *SGDClassifier) PredictE(X, Y *mat.Dense) error {
+	return regr.predictE(regr.sgd(), X, Y)
+}
+
+func (regr *SGDClassifier) predictE(cf classifierFit, X, Y *mat.Dense) error {
+	if err := regr.checkPredict(cf.est, cf.name+".Predict", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { regr.Predict(X, Y) })
+}
+
+// PredictProba puts into Y the probability of the second class, or of each class for more than 2 classes,
+// normalized to sum 1. it is the logistic of the decision function for the log loss and (clip(z,-1,1)+1)/2 for
+// the modified_huber loss. it panics with a *base.ParamError for the other losses
+func (regr *SGDClassifier) PredictProba(X, Y *mat.Dense) {
+	var proba func(z float64) float64
+	switch regr.Loss {
+	case "log":
+		proba = expit
+	case "modified_huber":
+		proba = func(z float64) float64 { return (math.Max(-1, math.Min(1, z)) + 1) / 2 }
+	default:
+		panic(&base.ParamError{Estimator: regr, Param: "Loss", Value: regr.Loss, Msg: "PredictProba needs the log or modified_huber loss"})
+	}
+	regr.decisionFunction(X, Y)
+	nSamples, nOutputs := Y.Dims()
+	for i := 0; i < nSamples; i++ {
+		row := Y.RawRowView(i)
+		for o, z := range row {
+			row[o] = proba(z)
+		}
+		if nOutputs == 1 || regr.Classes == nil {
+			continue
+		}
+		if sum := floats.Sum(row); sum > 0 {
+			floats.Scale(1/sum, row)
+		} else {
+			// no class is likely, all are
+			for o := range row {
+				row[o] = 1 / float64(nOutputs)
+			}
+		}
+	}
+}
+
+// Score returns the accuracy of the predictions of X, the proportion of samples whose classes are predicted
+func (regr *SGDClassifier) Score(X, Y *mat.Dense) float64 {
+	nSamples, nOutputs := Y.Dims()
+	Ypred := mat.NewDense(nSamples, nOutputs, nil)
+	regr.Predict(X, Ypred)
+	correct := 0
+	for i := 0; i < nSamples; i++ {
+		if floats.Equal(Y.RawRowView(i), Ypred.RawRowView(i)) {
+			correct++
+		}
+	}
+	return float64(correct) / float64(nSamples)
+}
+
+// Transform is for Pipeline
+func (regr *SGDClassifier) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
+	r, c := Y.Dims()
+	Xout, Yout = X, mat.NewDense(r, c, nil)
+	regr.Predict(X, Yout)
+	return
+}
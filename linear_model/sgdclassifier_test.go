@@ -0,0 +1,173 @@
+package linearmodel
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+func ExampleSGDClassifier_PartialFit() {
+	X, Y := newBlobsProblem(600, 3, 0)
+	clf := NewSGDClassifier()
+	clf.Loss = "log"
+	clf.RandomState = base.NewRandomState(7)
+	// learn from batches of 100 samples, as if they were read from a stream
+	for epoch := 0; epoch < 5; epoch++ {
+		for start := 0; start < 600; start += 100 {
+			Xb, Yb := X.Slice(start, start+100, 0, 2).(*mat.Dense), Y.Slice(start, start+100, 0, 1).(*mat.Dense)
+			if err := clf.PartialFit(Xb, Yb, []float64{1, 2, 3}); err != nil {
+				fmt.Println(err)
+			}
+		}
+	}
+	fmt.Printf("classes:%g accuracy:%.2f\n", clf.Classes, clf.Score(X, Y))
+	// Output:
+	// classes:[1 2 3] accuracy:0.89
+}
+
+func TestSGDClassifierLosses(t *testing.T) {
+	for _, nClasses := range []int{2, 3} {
+		X, Y := newBlobsProblem(300, nClasses, 1)
+		for _, loss := range []string{"hinge", "log", "modified_huber", "squared_hinge", "perceptron"} {
+			clf := NewSGDClassifier()
+			clf.Loss = loss
+			clf.RandomState = base.NewRandomState(7)
+			if err := clf.FitE(X, Y); err != nil {
+				t.Fatalf("%s: %s", loss, err)
+			}
+			// 2 classes are a single output, more are one-vs-rest
+			expected := nClasses
+			if nClasses == 2 {
+				expected = 1
+			}
+			if _, nOutputs := clf.Coef.Dims(); nOutputs != expected {
+				t.Errorf("%d classes %s: expected %d outputs, got %d", nClasses, loss, expected, nOutputs)
+			}
+			if score := clf.Score(X, Y); score < .75 {
+				t.Errorf("%d classes %s: expected an accuracy above .75, got %.3f", nClasses, loss, score)
+			}
+		}
+	}
+}
+
+func TestSGDClassifierParams(t *testing.T) {
+	X, Y := newBlobsProblem(300, 2, 4)
+	for _, params := range []func(*SGDClassifier){
+		func(clf *SGDClassifier) { clf.LearningRate, clf.Eta0 = "constant", .01 },
+		func(clf *SGDClassifier) { clf.LearningRate, clf.Eta0 = "invscaling", .1 },
+		func(clf *SGDClassifier) { clf.LearningRate, clf.Eta0 = "adaptive", .1 },
+		func(clf *SGDClassifier) { clf.Penalty = "elasticnet" },
+		func(clf *SGDClassifier) { clf.Average = 10 },
+		func(clf *SGDClassifier) { clf.ClassWeight = "balanced" },
+		func(clf *SGDClassifier) { clf.Shuffle = false },
+	} {
+		clf := NewSGDClassifier()
+		clf.RandomState = base.NewRandomState(7)
+		params(clf)
+		if err := clf.FitE(X, Y); err != nil {
+			t.Errorf("%s %g average %d: %s", clf.LearningRate, clf.Eta0, clf.Average, err)
+			continue
+		}
+		if score := clf.Score(X, Y); score < .8 {
+			t.Errorf("%s %g average %d: expected an accuracy above .8, got %.3f", clf.LearningRate, clf.Eta0, clf.Average, score)
+		}
+		if clf.Average > 0 && (clf.SGDResult.StandardCoef == nil || mat.Equal(clf.Coef, clf.SGDResult.StandardCoef)) {
+			t.Error("expected Coef to be the average of the StandardCoef of the updates")
+		}
+	}
+
+	// l1 zeroes the coefficients of the noise features
+	clf := NewSGDClassifier()
+	clf.Penalty, clf.Alpha = "l1", .05
+	clf.RandomState = base.NewRandomState(7)
+	if err := clf.FitE(X, Y); err != nil {
+		t.Fatal(err)
+	}
+	coef := mat.Col(nil, 0, clf.Coef)
+	if coef[0] == 0 || floats.Norm(coef[2:], 1) != 0 {
+		t.Errorf("expected zero coefficients for the noise features only, got %g", coef)
+	}
+
+	for _, params := range []func(*SGDClassifier){
+		func(clf *SGDClassifier) { clf.Loss = "squared_loss" },
+		func(clf *SGDClassifier) { clf.Penalty = "l3" },
+		func(clf *SGDClassifier) { clf.LearningRate = "constant" },
+		func(clf *SGDClassifier) { clf.Alpha = 0 },
+		func(clf *SGDClassifier) { clf.MaxIter = 0 },
+		func(clf *SGDClassifier) { clf.NIterNoChange = 0 },
+	} {
+		clf := NewSGDClassifier()
+		params(clf)
+		var paramErr *base.ParamError
+		if err := clf.FitE(X, Y); !errors.As(err, &paramErr) {
+			t.Errorf("expected a *base.ParamError, got %v", err)
+		}
+	}
+}
+
+func TestSGDClassifierPartialFit(t *testing.T) {
+	X, Y := newBlobsProblem(300, 3, 0)
+	clf := NewSGDClassifier()
+	var valueErr *base.ValueError
+	if err := clf.PartialFit(X, Y, nil); !errors.As(err, &valueErr) {
+		t.Errorf("expected a *base.ValueError without classes, got %v", err)
+	}
+	if err := clf.PartialFit(X, Y, []float64{1, 2}); !errors.As(err, &valueErr) {
+		t.Errorf("expected a *base.ValueError for a label missing in classes, got %v", err)
+	}
+	if err := clf.PartialFit(X, Y, []float64{3, 2, 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := clf.PartialFit(X, Y, []float64{1, 2, 4}); !errors.As(err, &valueErr) {
+		t.Errorf("expected a *base.ValueError for other classes, got %v", err)
+	}
+	T := clf.SGDResult.T
+	if err := clf.PartialFit(X, Y, nil); err != nil || clf.SGDResult.T != T+300 {
+		t.Errorf("expected a second epoch from the first one, got %v T=%g", err, clf.SGDResult.T)
+	}
+
+	// PartialFit after Fit continues from its weights, and does not change a clone
+	clf.Fit(X, Y)
+	clone := clf.Clone().(*SGDClassifier)
+	coef := mat.DenseCopyOf(clf.Coef)
+	if err := clone.PartialFit(X, Y, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !mat.Equal(coef, clf.Coef) || mat.Equal(coef, clone.Coef) {
+		t.Error("expected PartialFit to update the clone only")
+	}
+
+	clf.ClassWeight = "balanced"
+	var paramErr *base.ParamError
+	if err := clf.PartialFit(X, Y, nil); !errors.As(err, &paramErr) {
+		t.Errorf("expected a *base.ParamError for balanced class weights, got %v", err)
+	}
+}
+
+func TestSGDClassifierPredictProba(t *testing.T) {
+	X, Y := newBlobsProblem(300, 3, 0)
+	for _, loss := range []string{"log", "modified_huber"} {
+		clf := NewSGDClassifier()
+		clf.Loss = loss
+		clf.RandomState = base.NewRandomState(7)
+		clf.Fit(X, Y)
+		P := &mat.Dense{}
+		clf.PredictProba(X, P)
+		for i := 0; i < 300; i++ {
+			if sum := floats.Sum(P.RawRowView(i)); math.Abs(sum-1) > 1e-9 {
+				t.Fatalf("%s: probabilities of sample %d sum to %g", loss, i, sum)
+			}
+		}
+	}
+	clf := NewSGDClassifier()
+	clf.Fit(X, Y)
+	var paramErr *base.ParamError
+	if err := base.Recover(func() { clf.PredictProba(X, &mat.Dense{}) }); !errors.As(err, &paramErr) {
+		t.Errorf("expected a *base.ParamError for the hinge loss, got %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+package linearmodel
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/sparse"
+	"gonum.org/v1/gonum/mat"
+)
+
+// centered is a sparse X minus its column means Offset, never materialized:
+// products with it are computed from the products with X so X stays sparse.
+// when transposed is true it is the transpose of X-Offset
+type centered struct {
+	X          *sparse.CSR
+	Offset     []float64
+	transposed bool
+}
+
+func (m *centered) Dims() (r, c int) {
+	r, c = m.X.Dims()
+	if m.transposed {
+		r, c = c, r
+	}
+	return
+}
+
+func (m *centered) At(i, j int) float64 {
+	if m.transposed {
+		i, j = j, i
+	}
+	return m.X.At(i, j) - m.Offset[j]
+}
+
+func (m *centered) T() mat.Matrix {
+	return &centered{X: m.X, Offset: m.Offset, transposed: !m.transposed}
+}
+
+// mulTo computes a×b into dst, using the sparsity of a or b, or the float32 elements of a *base.Dense32 a.
+// dst is allocated if empty
+func mulTo(dst *mat.Dense, a, b mat.Matrix) {
+	switch m := a.(type) {
+	case *base.Dense32:
+		m.MulTo(dst, b)
+		return
+	case base.MatTranspose:
+		if m32, ok := m.Matrix.(*base.Dense32); ok {
+			m32.TMulTo(dst, b)
+			return
+		}
+	}
+	c, ok := a.(*centered)
+	if !ok {
+		if sparse.IsSparse(a) || sparse.IsSparse(b) {
+			sparse.Mul(dst, a, b)
+		} else {
+			dst.Mul(a, b)
+		}
+		return
+	}
+	_, bc := b.Dims()
+	offset := mat.NewDense(1, len(c.Offset), c.Offset)
+	if !c.transposed {
+		// (X-1·offset)×b = X×b - 1·(offset×b)
+		sparse.Mul(dst, c.X, b)
+		ob := &mat.Dense{}
+		ob.Mul(offset, b)
+		obrow := ob.RawRowView(0)
+		r, _ := dst.Dims()
+		for i := 0; i < r; i++ {
+			row := dst.RawRowView(i)
+			for o := 0; o < bc; o++ {
+				row[o] -= obrow[o]
+			}
+		}
+		return
+	}
+	// (X-1·offset)ᵀ×b = Xᵀ×b - offsetᵀ·(1ᵀ×b)
+	sparse.Mul(dst, c.X.T(), b)
+	br, _ := b.Dims()
+	bsum := make([]float64, bc)
+	for i := 0; i < br; i++ {
+		for o := range bsum {
+			bsum[o] += b.At(i, o)
+		}
+	}
+	for j, off := range c.Offset {
+		row := dst.RawRowView(j)
+		for o := range row {
+			row[o] -= off * bsum[o]
+		}
+	}
+}
+
+// sliceRows returns the rows i to k-1 of X
+func sliceRows(X mat.Matrix, i, k int) mat.Matrix {
+	switch m := X.(type) {
+	case *mat.Dense:
+		_, c := m.Dims()
+		return m.Slice(i, k, 0, c)
+	case *centered:
+		return &centered{X: m.X.SliceRows(i, k), Offset: m.Offset}
+	case *base.Dense32:
+		return m.SliceRows(i, k)
+	}
+	return sparse.CSROf(X).SliceRows(i, k)
+}
+
+// takeRows returns a copy of the rows of X in the order of rows
+func takeRows(X mat.Matrix, rows []int) mat.Matrix {
+	switch m := X.(type) {
+	case *mat.Dense:
+		_, c := m.Dims()
+		out := mat.NewDense(len(rows), c, nil)
+		for k, i := range rows {
+			copy(out.RawRowView(k), m.RawRowView(i))
+		}
+		return out
+	case *centered:
+		return &centered{X: m.X.TakeRows(rows), Offset: m.Offset}
+	case *base.Dense32:
+		return m.TakeRows(rows)
+	}
+	return sparse.CSROf(X).TakeRows(rows)
+}
+
+// preprocessSparse is PreprocessData for a sparse X: X is not centered but wrapped in a centered matrix
+// when FitIntercept is true, and not normalized. Y is centered
+func preprocessSparse(X mat.Matrix, Y *mat.Dense, FitIntercept bool) (Xout mat.Matrix, Yout, XOffset, YOffset, XScale *mat.Dense) {
+	csr := sparse.CSROf(X)
+	nSamples, nFeatures := csr.Dims()
+	XOffset = mat.NewDense(1, nFeatures, nil)
+	XScale = mat.NewDense(1, nFeatures, nil)
+	for j := 0; j < nFeatures; j++ {
+		XScale.Set(0, j, 1)
+	}
+	_, nOutputs := Y.Dims()
+	YOffset = mat.NewDense(1, nOutputs, nil)
+	if !FitIntercept {
+		return csr, Y, XOffset, YOffset, XScale
+	}
+	offset := XOffset.RawRowView(0)
+	csr.DoNonZero(func(_, j int, v float64) { offset[j] += v })
+	for j := range offset {
+		offset[j] /= float64(nSamples)
+	}
+	Yout, YOffset = centerY(Y)
+	return &centered{X: csr, Offset: offset}, Yout, XOffset, YOffset, XScale
+}
+
+// centerY returns Y minus the mean of its columns, and the means
+func centerY(Y *mat.Dense) (Yout, YOffset *mat.Dense) {
+	nSamples, nOutputs := Y.Dims()
+	YOffset = mat.NewDense(1, nOutputs, nil)
+	Yout = mat.NewDense(nSamples, nOutputs, nil)
+	for o := 0; o < nOutputs; o++ {
+		mean := mat.Sum(Y.ColView(o)) / float64(nSamples)
+		YOffset.Set(0, o, mean)
+		for i := 0; i < nSamples; i++ {
+			Yout.Set(i, o, Y.At(i, o)-mean)
+		}
+	}
+	return
+}
+
+// preprocess is PreprocessData for a dense X, preprocessDense32 for a *base.Dense32 and preprocessSparse for a sparse one.
+// sampleWeight is used for a dense X only
+func preprocess(X mat.Matrix, Y *mat.Dense, FitIntercept, Normalize bool, sampleWeight []float64) (Xout mat.Matrix, Yout, XOffset, YOffset, XScale *mat.Dense) {
+	switch m := X.(type) {
+	case *mat.Dense:
+		return PreprocessData(m, Y, FitIntercept, Normalize, weightVec(sampleWeight))
+	case *base.Dense32:
+		return preprocessDense32(m, Y, FitIntercept, Normalize)
+	}
+	return preprocessSparse(X, Y, FitIntercept)
+}
+
+// checkFitSparse is base.CheckFitXY for a sparse X
+func checkFitSparse(op string, X mat.Matrix, Y *mat.Dense) error {
+	if X == nil || Y == nil {
+		return &base.ShapeError{Op: op, Msg: "X or Y is nil"}
+	}
+	xr, _ := X.Dims()
+	if yr, _ := Y.Dims(); xr != yr {
+		return &base.ShapeError{Op: op, Msg: "X and Y have different numbers of rows"}
+	}
+	return nil
+}
+
+// FitSparse is Fit for a sparse X, a *sparse.CSR or a *sparse.CSC. X is centered implicitly, Normalize is ignored
+func (regr *RegularizedRegression) FitSparse(X mat.Matrix, Y *mat.Dense) base.Transformer {
+	if err := regr.fit(context.Background(), X, Y, nil); err != nil {
+		panic(err)
+	}
+	return regr
+}
+
+// DecisionFunctionSparse is DecisionFunction for a sparse X. Y is allocated if empty
+func (regr *LinearModel) DecisionFunctionSparse(X mat.Matrix, Y *mat.Dense) {
+	regr.decisionFunction(X, Y)
+}
+
+// PredictSparse is Predict for a sparse X
+func (regr *LinearRegression) PredictSparse(X mat.Matrix, Y *mat.Dense) base.Regressor {
+	regr.decisionFunction(X, Y)
+	return regr
+}
+
+// FitSparse is Fit for a sparse X, a *sparse.CSR or a *sparse.CSC. X is centered implicitly, Normalize is ignored
+func (regr *SGDRegressor) FitSparse(X mat.Matrix, Y *mat.Dense) base.Transformer {
+	if err := regr.fit(context.Background(), X, Y, nil); err != nil {
+		panic(err)
+	}
+	return regr
+}
+
+// PredictSparse is Predict for a sparse X
+func (regr *SGDRegressor) PredictSparse(X mat.Matrix, Y *mat.Dense) base.Regressor {
+	regr.decisionFunction(X, Y)
+	return regr
+}
+
+// FitSparse is Fit for a sparse X, a *sparse.CSR or a *sparse.CSC. X is centered implicitly, Normalize is ignored
+func (regr *LogisticRegression) FitSparse(X mat.Matrix, Ycls *mat.Dense) base.Transformer {
+	if err := regr.fit(context.Background(), X, Ycls, nil); err != nil {
+		panic(err)
+	}
+	return regr
+}
+
+// PredictSparse is Predict for a sparse X
+func (regr *LogisticRegression) PredictSparse(X mat.Matrix, Ycls *mat.Dense) {
+	regr.predict(X, Ycls)
+}
+
+// FitSparse is Fit for a sparse X, a *sparse.CSR or a *sparse.CSC, with a single output.
+// X is centered implicitly, Normalize is ignored
+func (regr *ElasticNet) FitSparse(X mat.Matrix, Y0 *mat.Dense) base.Transformer {
+	if err := checkFitSparse("ElasticNet.FitSparse", X, Y0); err != nil {
+		panic(err)
+	}
+	if _, NOutputs := Y0.Dims(); NOutputs != 1 {
+		panic(&base.ShapeError{Op: "ElasticNet.FitSparse", Msg: "sparse X is supported for a single output only"})
+	}
+	var Y, YOffset *mat.Dense
+	_, Y, regr.XOffset, YOffset, regr.XScale = preprocessSparse(X, Y0, regr.FitIntercept)
+	csc := sparse.CSCOf(X)
+	NSamples, NFeatures := csc.Dims()
+	var XMean []float64
+	if regr.FitIntercept {
+		XMean = regr.XOffset.RawRowView(0)
+	}
+	l1reg := regr.Alpha * regr.L1Ratio * float64(NSamples)
+	l2reg := regr.Alpha * (1. - regr.L1Ratio) * float64(NSamples)
+	if !regr.WarmStart || regr.Coef == nil {
+		regr.Coef = mat.NewDense(NFeatures, 1, nil)
+	} else {
+		regr.Coef = mat.DenseCopyOf(regr.Coef)
+	}
+	w, y := &mat.VecDense{}, &mat.VecDense{}
+	w.ColViewOf(regr.Coef, 0)
+	y.ColViewOf(Y, 0)
+	rng := base.CheckRandomState(regr.RandomState).Rand()
+	regr.CDResult = *sparseEnetCoordinateDescent(w, l1reg, l2reg, csc, XMean, y, regr.MaxIter, regr.Tol, rng, strings.EqualFold("random", regr.Selection), regr.Positive)
+	regr.LinearModel.setIntercept(regr.XOffset, YOffset, regr.XScale)
+	return regr
+}
@@ -0,0 +1,95 @@
+package linearmodel
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/sparse"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// newSparseProblem returns a X with about 70% zeros and Y=X·coef+1
+func newSparseProblem(nSamples, nFeatures int) (X, Y *mat.Dense) {
+	rnd := rand.New(rand.NewSource(7))
+	X = mat.NewDense(nSamples, nFeatures, nil)
+	X.Apply(func(i, j int, _ float64) float64 {
+		if rnd.Float64() < .7 {
+			return 0
+		}
+		return rnd.NormFloat64()
+	}, X)
+	coef := mat.NewDense(nFeatures, 1, nil)
+	coef.Apply(func(j, _ int, _ float64) float64 { return float64(j + 1) }, coef)
+	Y = &mat.Dense{}
+	Y.Mul(X, coef)
+	Y.Apply(func(_, _ int, y float64) float64 { return y + 1 }, Y)
+	return
+}
+
+func TestElasticNetSparse(t *testing.T) {
+	X, Y := newSparseProblem(50, 6)
+	for _, fitIntercept := range []bool{true, false} {
+		dense, sp := NewElasticNet(), NewElasticNet()
+		dense.Alpha, sp.Alpha = .01, .01
+		dense.Tol, sp.Tol = 1e-8, 1e-8
+		dense.FitIntercept, sp.FitIntercept = fitIntercept, fitIntercept
+		dense.Fit(X, Y)
+		sp.FitSparse(sparse.CSCCopyOf(X), Y)
+		if !mat.EqualApprox(dense.Coef, sp.Coef, 1e-5) || !mat.EqualApprox(dense.Intercept, sp.Intercept, 1e-5) {
+			t.Errorf("FitIntercept=%v expected coef %g intercept %g, got %g %g", fitIntercept,
+				mat.Formatted(dense.Coef.T()), mat.Formatted(dense.Intercept), mat.Formatted(sp.Coef.T()), mat.Formatted(sp.Intercept))
+		}
+	}
+}
+
+func TestRegressionSparse(t *testing.T) {
+	X, Y := newSparseProblem(50, 6)
+	csr := sparse.CSRCopyOf(X)
+	ridge, sgd := NewRidge(), NewSGDRegressor()
+	ridge.Alpha, ridge.Tol, ridge.Solver = 0, 1e-4, "adam"
+	ridge.RandomState = base.NewRandomState(7)
+	sgd.Alpha, sgd.Method = 0, &optimize.LBFGS{}
+	for _, m := range []interface {
+		FitSparse(mat.Matrix, *mat.Dense) base.Transformer
+		PredictSparse(mat.Matrix, *mat.Dense) base.Regressor
+		Score(X, Y *mat.Dense) float64
+	}{ridge, sgd} {
+		m.FitSparse(csr, Y)
+		Ypred := &mat.Dense{}
+		m.PredictSparse(csr, Ypred)
+		if score := m.Score(X, Y); score < .99 {
+			t.Errorf("%T score %g", m, score)
+		}
+		Ydense := &mat.Dense{}
+		m.(base.Regressor).Predict(X, Ydense)
+		if !mat.EqualApprox(Ypred, Ydense, 1e-10) {
+			t.Errorf("%T PredictSparse differs from Predict", m)
+		}
+	}
+}
+
+func TestLogisticRegressionSparse(t *testing.T) {
+	X, Y := newSparseProblem(60, 4)
+	Y.Apply(func(_, _ int, y float64) float64 {
+		if y > 1 {
+			return 1
+		}
+		return 0
+	}, Y)
+	dense, sp := NewLogisticRegression(), NewLogisticRegression()
+	dense.RandomState, sp.RandomState = base.NewRandomState(7), base.NewRandomState(7)
+	dense.Fit(X, Y)
+	sp.FitSparse(sparse.CSRCopyOf(X), Y)
+	if !mat.EqualApprox(dense.Coef, sp.Coef, 1e-3) {
+		t.Errorf("expected coef %g, got %g", mat.Formatted(dense.Coef.T()), mat.Formatted(sp.Coef.T()))
+	}
+	Ypred := &mat.Dense{}
+	sp.PredictSparse(sparse.CSRCopyOf(X), Ypred)
+	Ydense := &mat.Dense{}
+	sp.Predict(X, Ydense)
+	if !mat.EqualApprox(Ypred, Ydense, 1e-10) {
+		t.Error("PredictSparse differs from Predict")
+	}
+}
@@ -0,0 +1,301 @@
+package linearmodel
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// TheilSenRegressor is a linear regression robust to outliers: the weights of each output, intercept included,
+// are the spatial median of the least squares solutions of subsets of NSubsamples samples, all the subsets or
+// MaxSubpopulation random ones if there are more. for 2 features, up to 29% of outliers do not move the weights.
+// see X. Dang, H. Peng, X. Wang, H. Zhang, "Theil-Sen Estimators in a Multiple Linear Regression Model", 2008
+type TheilSenRegressor struct {
+	LinearModel
+	// MaxSubpopulation is the maximal number of subsets
+	MaxSubpopulation int
+	// NSubsamples is the size of the subsets, the number of weights if 0: the number of features, plus one with
+	// FitIntercept. the larger, the less robust and the closer to least squares
+	NSubsamples int
+	// MaxIter and Tol stop the Weiszfeld iterations of the spatial median
+	MaxIter int
+	Tol     float64
+	// NJobs is the number of goroutines solving the subsets, the number of CPUs if <= 0
+	NJobs          int
+	RandomState    *base.RandomState
+	TheilSenResult TheilSenResult
+}
+
+// TheilSenResult is the result of a TheilSenRegressor fit
+type TheilSenResult struct {
+	// NSubpopulation is the number of subsets, NIter the largest number of iterations of the spatial medians of
+	// the outputs. Converged is false if a spatial median still moved by Tol after MaxIter iterations
+	NSubpopulation, NIter int
+	Converged             bool
+}
+
+// NewTheilSenRegressor creates a *TheilSenRegressor with defaults: MaxSubpopulation 1e4, MaxIter 300, Tol 1e-3
+func NewTheilSenRegressor() *TheilSenRegressor {
+	regr := &TheilSenRegressor{MaxSubpopulation: 1e4, MaxIter: 300, Tol: 1e-3}
+	regr.FitIntercept = true
+	return regr
+}
+
+// Clone for TheilSenRegressor
+func (regr *TheilSenRegressor) Clone() base.Transformer {
+	clone := *regr
+	return &clone
+}
+
+// Fit learns Coef and Intercept from X,Y
+func (regr *TheilSenRegressor) Fit(X, Y *mat.Dense) base.Transformer {
+	nSubsamples, err := regr.checkFit(X)
+	if err == nil {
+		err = regr.fit(X, Y, nSubsamples)
+	}
+	if err != nil {
+		panic(err)
+	}
+	return regr
+}
+
+// FitE is Fit returning an error instead of panicking.
+// a *base.ConvergenceError is returned if a spatial median still moved by Tol after MaxIter iterations, the
+// model is still usable
+func (regr *TheilSenRegressor) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckFitXY("TheilSenRegressor.Fit", X, Y); err != nil {
+		return err
+	}
+	nSubsamples, err := regr.checkFit(X)
+	if err != nil {
+		return err
+	}
+	if rerr := base.Recover(func() { err = regr.fit(X, Y, nSubsamples) }); rerr != nil {
+		return rerr
+	}
+	if err == nil && !regr.TheilSenResult.Converged {
+		err = &base.ConvergenceError{Estimator: regr, NIter: regr.TheilSenResult.NIter, Msg: fmt.Sprintf("the spatial median still moves by Tol %g, increase MaxIter", regr.Tol)}
+	}
+	return err
+}
+
+// checkFit returns the size of the subsets of X, or a *base.ParamError
+func (regr *TheilSenRegressor) checkFit(X *mat.Dense) (int, error) {
+	nSamples, nParams := X.Dims()
+	if regr.FitIntercept {
+		nParams++
+	}
+	nSubsamples := regr.NSubsamples
+	if nSubsamples == 0 {
+		nSubsamples = nParams
+		if nSamples < nParams {
+			nSubsamples = nSamples
+		}
+	}
+	if nSubsamples > nSamples || (nSamples >= nParams && nSubsamples < nParams) || (nSamples < nParams && nSubsamples != nSamples) {
+		return 0, &base.ParamError{Estimator: regr, Param: "NSubsamples", Value: regr.NSubsamples, Msg: fmt.Sprintf("must be in [%d,%d]", nParams, nSamples)}
+	}
+	if regr.MaxSubpopulation <= 0 {
+		return 0, &base.ParamError{Estimator: regr, Param: "MaxSubpopulation", Value: regr.MaxSubpopulation, Msg: "must be > 0"}
+	}
+	if regr.MaxIter <= 0 {
+		return 0, &base.ParamError{Estimator: regr, Param: "MaxIter", Value: regr.MaxIter, Msg: "must be > 0"}
+	}
+	return nSubsamples, nil
+}
+
+func (regr *TheilSenRegressor) fit(X, Y *mat.Dense, nSubsamples int) error {
+	nSamples, nFeatures := X.Dims()
+	_, nOutputs := Y.Dims()
+	// A is X with a first column of ones for the intercept
+	A := X
+	if regr.FitIntercept {
+		A = mat.NewDense(nSamples, nFeatures+1, nil)
+		for i := 0; i < nSamples; i++ {
+			row := A.RawRowView(i)
+			row[0] = 1
+			copy(row[1:], X.RawRowView(i))
+		}
+	}
+	subsets := regr.subsets(nSamples, nSubsamples)
+	_, nParams := A.Dims()
+	// weights holds the solution of each subset for each output, a row per subset
+	weights := make([]*mat.Dense, nOutputs)
+	for o := range weights {
+		weights[o] = mat.NewDense(len(subsets), nParams, nil)
+	}
+	base.Parallelize(regr.NJobs, len(subsets), func(th, start, end int) {
+		for s := start; s < end; s++ {
+			W := lstsq(takeRows(A, subsets[s]).(*mat.Dense), takeRows(Y, subsets[s]).(*mat.Dense))
+			for o := range weights {
+				weights[o].SetRow(s, mat.Col(nil, o, W))
+			}
+		}
+	})
+	result := TheilSenResult{NSubpopulation: len(subsets), Converged: true}
+	coef, intercept := mat.NewDense(nFeatures, nOutputs, nil), mat.NewDense(1, nOutputs, nil)
+	for o, W := range weights {
+		w, nIter, converged := spatialMedian(W, regr.MaxIter, regr.Tol)
+		if regr.FitIntercept {
+			intercept.Set(0, o, w[0])
+			w = w[1:]
+		}
+		coef.SetCol(o, w)
+		if nIter > result.NIter {
+			result.NIter = nIter
+		}
+		result.Converged = result.Converged && converged
+	}
+	regr.XOffset, regr.XScale = nil, nil
+	regr.Coef, regr.Intercept, regr.TheilSenResult = coef, intercept, result
+	return nil
+}
+
+// subsets returns the subsets of nSubsamples of nSamples samples, all of them or MaxSubpopulation random ones if
+// there are more
+func (regr *TheilSenRegressor) subsets(nSamples, nSubsamples int) [][]int {
+	// nSubsets is the binomial coefficient, stopped as soon as it exceeds MaxSubpopulation
+	nSubsets := 1.
+	for i := 0; i < nSubsamples && nSubsets <= float64(regr.MaxSubpopulation); i++ {
+		nSubsets = nSubsets * float64(nSamples-i) / float64(i+1)
+	}
+	if nSubsets > float64(regr.MaxSubpopulation) {
+		rs := base.CheckRandomState(regr.RandomState)
+		subsets := make([][]int, regr.MaxSubpopulation)
+		for s := range subsets {
+			subsets[s] = rs.Perm(nSamples)[:nSubsamples]
+		}
+		return subsets
+	}
+	subsets := make([][]int, 0, int(math.Round(nSubsets)))
+	subset := make([]int, nSubsamples)
+	for i := range subset {
+		subset[i] = i
+	}
+	for {
+		subsets = append(subsets, append([]int{}, subset...))
+		// next combination in lexicographic order
+		i := nSubsamples - 1
+		for i >= 0 && subset[i] == nSamples-nSubsamples+i {
+			i--
+		}
+		if i < 0 {
+			return subsets
+		}
+		subset[i]++
+		for j := i + 1; j < nSubsamples; j++ {
+			subset[j] = subset[j-1] + 1
+		}
+	}
+}
+
+// lstsq returns the minimal norm least squares solution W of A·W = B, by singular value decomposition
+func lstsq(A, B *mat.Dense) *mat.Dense {
+	r, c := A.Dims()
+	var svd mat.SVD
+	if !svd.Factorize(A, mat.SVDThin) {
+		panic(&base.ValueError{Op: "lstsq", Msg: "singular value decomposition failed"})
+	}
+	s := svd.Values(nil)
+	var U, V mat.Dense
+	svd.UTo(&U)
+	svd.VTo(&V)
+	// the singular values below rcond·max(s) are zero
+	rcond := math.Max(float64(r), float64(c)) * 2.220446049250313e-16
+	UtB := &mat.Dense{}
+	UtB.Mul(U.T(), B)
+	for k, sk := range s {
+		scale := 0.
+		if sk > rcond*s[0] {
+			scale = 1 / sk
+		}
+		floats.Scale(scale, UtB.RawRowView(k))
+	}
+	W := &mat.Dense{}
+	W.Mul(&V, UtB)
+	return W
+}
+
+// spatialMedian returns the point minimizing the sum of the euclidean distances to the rows of X, found by the
+// Weiszfeld iterations modified by Vardi and Zhang to handle a point of X, until it moves by less than tol
+// (squared) or after maxIter iterations
+func spatialMedian(X *mat.Dense, maxIter int, tol float64) (median []float64, nIter int, converged bool) {
+	nPoints, nDims := X.Dims()
+	median = make([]float64, nDims)
+	if nDims == 1 {
+		return []float64{medianOf(mat.Col(nil, 0, X))}, 1, true
+	}
+	for i := 0; i < nPoints; i++ {
+		floats.Add(median, X.RawRowView(i))
+	}
+	floats.Scale(1/float64(nPoints), median)
+	const eps = 2.220446049250313e-16
+	next, quotient, diff := make([]float64, nDims), make([]float64, nDims), make([]float64, nDims)
+	for nIter < maxIter {
+		nIter++
+		for j := range next {
+			next[j], quotient[j] = 0, 0
+		}
+		sumInvNorms, inX := 0., 0.
+		for i := 0; i < nPoints; i++ {
+			x := X.RawRowView(i)
+			floats.SubTo(diff, x, median)
+			norm := floats.Norm(diff, 2)
+			if norm < eps {
+				// median is a point of X
+				inX = 1
+				continue
+			}
+			floats.AddScaled(quotient, 1/norm, diff)
+			floats.AddScaled(next, 1/norm, x)
+			sumInvNorms += 1 / norm
+		}
+		quotientNorm := floats.Norm(quotient, 2)
+		if quotientNorm > eps {
+			floats.Scale(1/sumInvNorms, next)
+		} else {
+			for j := range next {
+				next[j] = 1
+			}
+			quotientNorm = 1
+		}
+		floats.Scale(math.Max(0, 1-inX/quotientNorm), next)
+		floats.AddScaled(next, math.Min(1, inX/quotientNorm), median)
+		floats.SubTo(diff, next, median)
+		median, next = next, median
+		if floats.Dot(diff, diff) < tol {
+			return median, nIter, true
+		}
+	}
+	return median, nIter, false
+}
+
+// medianOf returns the median of x, leaving it unchanged
+func medianOf(x []float64) float64 {
+	return median(append([]float64{}, x...))
+}
+
+// Predict puts into Y the predictions of X
+func (regr *TheilSenRegressor) Predict(X, Y *mat.Dense) base.Regressor {
+	regr.DecisionFunction(X, Y)
+	return regr
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (regr *TheilSenRegressor) PredictE(X, Y *mat.Dense) error {
+	if err := regr.checkPredict(regr, "TheilSenRegressor.Predict", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { regr.Predict(X, Y) })
+}
+
+// Transform is for Pipeline
+func (regr *TheilSenRegressor) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
+	r, c := Y.Dims()
+	Xout, Yout = X, mat.NewDense(r, c, nil)
+	regr.Predict(X, Yout)
+	return
+}
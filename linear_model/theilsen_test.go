@@ -0,0 +1,85 @@
+package linearmodel
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+func ExampleTheilSenRegressor() {
+	X, Y := newOutliersProblem(40)
+	regr := NewTheilSenRegressor()
+	regr.RandomState = base.NewRandomState(7)
+	if err := regr.FitE(X, Y); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Printf("subsets:%d coef:%.1f intercept:%.1f\n", regr.TheilSenResult.NSubpopulation, mat.Col(nil, 0, regr.Coef), regr.Intercept.At(0, 0))
+	// Output:
+	// subsets:10000 coef:[2.0 -1.0 0.5] intercept:1.0
+}
+
+func TestTheilSenRegressor(t *testing.T) {
+	X, Y := newOutliersProblem(12)
+	regr := NewTheilSenRegressor()
+	regr.FitIntercept = false
+	regr.NJobs = 2
+	if err := regr.FitE(X, Y); err != nil {
+		t.Fatal(err)
+	}
+	// 12 choose 3
+	if regr.TheilSenResult.NSubpopulation != 220 {
+		t.Errorf("expected 220 subsets, got %d", regr.TheilSenResult.NSubpopulation)
+	}
+	for _, params := range []func(*TheilSenRegressor){
+		func(regr *TheilSenRegressor) { regr.NSubsamples = 3 },
+		func(regr *TheilSenRegressor) { regr.NSubsamples = 13 },
+		func(regr *TheilSenRegressor) { regr.MaxSubpopulation = 0 },
+		func(regr *TheilSenRegressor) { regr.MaxIter = 0 },
+	} {
+		regr := NewTheilSenRegressor()
+		params(regr)
+		var paramErr *base.ParamError
+		if err := regr.FitE(X, Y); !errors.As(err, &paramErr) {
+			t.Errorf("expected a *base.ParamError, got %v", err)
+		}
+	}
+}
+
+func TestTheilSenSubsets(t *testing.T) {
+	regr := NewTheilSenRegressor()
+	subsets := regr.subsets(5, 3)
+	if len(subsets) != 10 || fmt.Sprint(subsets[0], subsets[1], subsets[9]) != "[0 1 2] [0 1 3] [2 3 4]" {
+		t.Errorf("unexpected combinations %v", subsets)
+	}
+	regr.MaxSubpopulation, regr.RandomState = 4, base.NewRandomState(7)
+	if subsets := regr.subsets(5, 3); len(subsets) != 4 || len(subsets[0]) != 3 {
+		t.Errorf("expected 4 random subsets of 3 samples, got %v", subsets)
+	}
+}
+
+func TestSpatialMedian(t *testing.T) {
+	// the spatial median of the vertices of a square and its center is the center
+	X := mat.NewDense(5, 2, []float64{0, 0, 2, 0, 0, 2, 2, 2, 1, 1})
+	median, _, converged := spatialMedian(X, 300, 1e-6)
+	if !converged || math.Abs(median[0]-1) > 1e-3 || math.Abs(median[1]-1) > 1e-3 {
+		t.Errorf("expected [1 1], got %v converged:%v", median, converged)
+	}
+	// a single dimension is the median
+	if median, _, _ := spatialMedian(mat.NewDense(4, 1, []float64{3, 1, 100, 2}), 300, 1e-6); median[0] != 2.5 {
+		t.Errorf("expected 2.5, got %v", median)
+	}
+}
+
+func TestLstsq(t *testing.T) {
+	// a singular A: the minimal norm solution splits the weight between the identical columns
+	A := mat.NewDense(3, 2, []float64{1, 1, 2, 2, 3, 3})
+	B := mat.NewDense(3, 1, []float64{2, 4, 6})
+	W := lstsq(A, B)
+	if math.Abs(W.At(0, 0)-1) > 1e-10 || math.Abs(W.At(1, 0)-1) > 1e-10 {
+		t.Errorf("expected [1 1], got %v", mat.Col(nil, 0, W))
+	}
+}
@@ -0,0 +1,253 @@
+package linearmodel
+
+import (
+	"context"
+	"math"
+	"reflect"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// weightVec returns sampleWeight as a *mat.VecDense, or nil if sampleWeight is nil
+func weightVec(sampleWeight []float64) *mat.VecDense {
+	if sampleWeight == nil {
+		return nil
+	}
+	return mat.NewVecDense(len(sampleWeight), sampleWeight)
+}
+
+// normalizeWeights returns sampleWeight scaled to sum to its length, so that a weighted mean square error is the
+// mean square error of the samples repeated sampleWeight times. it is used where regularization is not scaled
+// by the number of samples
+func normalizeWeights(sampleWeight []float64) []float64 {
+	if sampleWeight == nil {
+		return nil
+	}
+	sum := 0.
+	for _, w := range sampleWeight {
+		sum += w
+	}
+	out := make([]float64, len(sampleWeight))
+	for i, w := range sampleWeight {
+		out[i] = w * float64(len(sampleWeight)) / sum
+	}
+	return out
+}
+
+// rescaleRows returns copies of X and Y whose rows are multiplied by the square root of their weight,
+// so that square losses on them are square losses on X and Y weighted by sampleWeight
+func rescaleRows(X, Y *mat.Dense, sampleWeight []float64) (Xout, Yout *mat.Dense) {
+	Xout, Yout = mat.DenseCopyOf(X), mat.DenseCopyOf(Y)
+	for i, w := range sampleWeight {
+		sw := math.Sqrt(w)
+		xrow, yrow := Xout.RawRowView(i), Yout.RawRowView(i)
+		for j := range xrow {
+			xrow[j] *= sw
+		}
+		for o := range yrow {
+			yrow[o] *= sw
+		}
+	}
+	return
+}
+
+// weightedLoss returns loss with the terms of row i of X weighted by sampleWeight[i]. like loss, it is evaluated at
+// once on all the rows, the gradient terms of each row being scaled by its weight, and regularization is added once.
+// loss must be SquareLoss, LogLoss or CrossEntropyLoss
+func weightedLoss(loss Loss, sampleWeight []float64) Loss {
+	switch reflect.ValueOf(loss).Pointer() {
+	case reflect.ValueOf(SquareLoss).Pointer():
+		return weightedSquareLoss(sampleWeight)
+	case reflect.ValueOf(LogLoss).Pointer():
+		return weightedLogLoss(sampleWeight)
+	case reflect.ValueOf(CrossEntropyLoss).Pointer():
+		return weightedCrossEntropyLoss(sampleWeight)
+	}
+	panic(&base.ParamError{Param: "LossFunction", Value: "custom", Msg: "sample weights need SquareLoss, LogLoss or CrossEntropyLoss"})
+}
+
+// weightedDelta returns a matrix of the dimensions of Ydiff with element i,o set to sampleWeight[i]*delta(i,o)
+func weightedDelta(Ydiff *mat.Dense, sampleWeight []float64, delta func(i, o int) float64) *mat.Dense {
+	nRows, nOutputs := Ydiff.Dims()
+	D := mat.NewDense(nRows, nOutputs, nil)
+	D.Apply(func(i, o int, _ float64) float64 { return sampleWeight[i] * delta(i, o) }, D)
+	return D
+}
+
+// setColumnSums sets every row of grad to the column sums of D, for the gradients of LogLoss and CrossEntropyLoss
+// which don't depend on X
+func setColumnSums(grad, D *mat.Dense) {
+	nFeatures, nOutputs := grad.Dims()
+	for o := 0; o < nOutputs; o++ {
+		sum := mat.Sum(D.ColView(o))
+		for j := 0; j < nFeatures; j++ {
+			grad.Set(j, o, sum)
+		}
+	}
+}
+
+// weightedSquareLoss is SquareLoss with the terms of row i weighted by sampleWeight[i]
+func weightedSquareLoss(sampleWeight []float64) Loss {
+	return func(Ytrue, X mat.Matrix, Theta, Ypred, Ydiff, grad *mat.Dense, Alpha, L1Ratio float64, nSamples int, activation Activation, disableRegularizationOfFirstFeature bool) (J float64) {
+		mulTo(Ypred, X, Theta)
+		Ypred.Apply(func(i, o int, xtheta float64) float64 { return activation.F(xtheta) }, Ypred)
+		Ydiff.Sub(Ypred, Ytrue)
+		Ydiff.Apply(func(i int, _ int, ydiff float64) float64 {
+			J += sampleWeight[i] * ydiff * ydiff
+			return ydiff
+		}, Ydiff)
+		if grad != nil {
+			_, identity := activation.(base.Identity)
+			mulTo(grad, X.T(), weightedDelta(Ydiff, sampleWeight, func(i, o int) float64 {
+				if identity {
+					return Ydiff.At(i, o)
+				}
+				return Ydiff.At(i, o) * activation.Fprime(Ypred.At(i, o))
+			}))
+		}
+		if Alpha > 0. {
+			J += regularization(Theta, grad, Alpha, L1Ratio, disableRegularizationOfFirstFeature)
+		}
+		J /= 2. * float64(nSamples)
+		if grad != nil {
+			grad.Scale(1./float64(nSamples), grad)
+		}
+		return
+	}
+}
+
+// weightedLogLoss is LogLoss with the terms of row i weighted by sampleWeight[i]
+func weightedLogLoss(sampleWeight []float64) Loss {
+	return func(Ytrue, X mat.Matrix, Theta, Ypred, Ydiff, grad *mat.Dense, Alpha, L1Ratio float64, nSamples int, activation Activation, disableRegularizationOfFirstFeature bool) (J float64) {
+		mulTo(Ypred, X, Theta)
+		Ypred.Apply(func(i, o int, xtheta float64) float64 { return activation.F(xtheta) }, Ypred)
+		Ydiff.Sub(Ypred, Ytrue)
+		Ypred.Apply(func(i int, o int, hpred float64) float64 {
+			eps := 1e-10
+			h := hpred
+			if hpred == 0. {
+				h += eps
+			} else if h == 1. {
+				h -= eps
+			}
+			J += -sampleWeight[i] * Ytrue.At(i, o) * math.Log(h)
+			return hpred
+		}, Ypred)
+		if grad != nil {
+			setColumnSums(grad, weightedDelta(Ydiff, sampleWeight, func(i, o int) float64 {
+				h := Ypred.At(i, o)
+				return -Ytrue.At(i, o) * activation.Fprime(h) / h
+			}))
+		}
+		if Alpha > 0. {
+			J += regularization(Theta, grad, Alpha, L1Ratio, disableRegularizationOfFirstFeature)
+		}
+		J /= float64(nSamples)
+		if grad != nil {
+			grad.Scale(1./float64(nSamples), grad)
+		}
+		return
+	}
+}
+
+// weightedCrossEntropyLoss is CrossEntropyLoss with the terms of row i weighted by sampleWeight[i]
+func weightedCrossEntropyLoss(sampleWeight []float64) Loss {
+	return func(Ytrue, X mat.Matrix, Theta, Ypred, Ydiff, grad *mat.Dense, Alpha, L1Ratio float64, nSamples int, activation Activation, disableRegularizationOfFirstFeature bool) (J float64) {
+		mulTo(Ypred, X, Theta)
+		Ypred.Apply(func(i, o int, xtheta float64) float64 { return panicIfNaN(activation.F(xtheta)) }, Ypred)
+		Ydiff.Sub(Ypred, Ytrue)
+		Ypred.Apply(func(i int, o int, hpred float64) float64 {
+			eps := 1e-10
+			h := hpred
+			if h <= 0. {
+				h = eps
+			} else if h >= 1. {
+				h = 1. - eps
+			}
+			y := Ytrue.At(i, o)
+			J += sampleWeight[i] * (-y*math.Log(h) - (1.-y)*math.Log1p(-h))
+			return hpred
+		}, Ypred)
+		if grad != nil {
+			if _, ok := activation.(base.Logistic); ok {
+				mulTo(grad, X.T(), weightedDelta(Ydiff, sampleWeight, Ydiff.At))
+			} else {
+				setColumnSums(grad, weightedDelta(Ydiff, sampleWeight, func(i, o int) float64 {
+					h, y := Ypred.At(i, o), Ytrue.At(i, o)
+					hprime := activation.Fprime(h)
+					switch y {
+					case 1.:
+						return -y * hprime / h
+					case 0.:
+						return (1. - y) * hprime / (1. - h)
+					default:
+						return -y*hprime/h + (1.-y)*hprime/(1.-h)
+					}
+				}))
+			}
+		}
+		if Alpha > 0. {
+			J += crossEntropyRegularization(Theta, grad, Alpha, L1Ratio, disableRegularizationOfFirstFeature)
+		}
+		J /= float64(nSamples)
+		if grad != nil {
+			grad.Scale(1./float64(nSamples), grad)
+		}
+		return
+	}
+}
+
+// FitWeighted is FitE with the loss of each sample weighted by sampleWeight. Y is centered on its weighted mean
+func (regr *LinearRegression) FitWeighted(X, Y *mat.Dense, sampleWeight []float64) error {
+	if err := base.CheckFitXY("LinearRegression.FitWeighted", X, Y); err != nil {
+		return err
+	}
+	if err := base.CheckSampleWeight("LinearRegression.FitWeighted", X, sampleWeight); err != nil {
+		return err
+	}
+	return base.Recover(func() { regr.fit(X, Y, sampleWeight) })
+}
+
+// FitWeighted is FitE with the loss of each sample weighted by sampleWeight
+func (regr *RegularizedRegression) FitWeighted(X, Y *mat.Dense, sampleWeight []float64) error {
+	if err := regr.checkFit(X, Y); err != nil {
+		return err
+	}
+	if err := base.CheckSampleWeight("RegularizedRegression.FitWeighted", X, sampleWeight); err != nil {
+		return err
+	}
+	var err error
+	if rerr := base.Recover(func() { err = regr.fit(context.Background(), X, Y, sampleWeight) }); rerr != nil {
+		return rerr
+	}
+	return err
+}
+
+// FitWeighted is FitE with the loss of each sample weighted by sampleWeight
+func (regr *LogisticRegression) FitWeighted(X, Ycls *mat.Dense, sampleWeight []float64) error {
+	if err := regr.checkFit("LogisticRegression.FitWeighted", X, Ycls); err != nil {
+		return err
+	}
+	if err := base.CheckSampleWeight("LogisticRegression.FitWeighted", X, sampleWeight); err != nil {
+		return err
+	}
+	return regr.fitE(context.Background(), X, Ycls, sampleWeight)
+}
+
+// FitWeighted is FitE with the loss of each sample weighted by sampleWeight
+func (regr *SGDRegressor) FitWeighted(X, Y *mat.Dense, sampleWeight []float64) error {
+	if err := regr.checkFit(X, Y); err != nil {
+		return err
+	}
+	if err := base.CheckSampleWeight("SGDRegressor.FitWeighted", X, sampleWeight); err != nil {
+		return err
+	}
+	return regr.fitE(context.Background(), X, Y, sampleWeight)
+}
+
+// FitWeighted is FitE with the loss of each sample weighted by sampleWeight.
+// a *base.ConvergenceError is returned if the duality gap is above tolerance after MaxIter iterations
+func (regr *ElasticNet) FitWeighted(X, Y *mat.Dense, sampleWeight []float64) error {
+	return regr.fitE("ElasticNet.FitWeighted", X, Y, sampleWeight)
+}
@@ -0,0 +1,184 @@
+package linearmodel
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// newWeightedProblem returns a noisy regression problem with integer sample weights,
+// and its unweighted equivalent where each row is repeated by its weight
+func newWeightedProblem(nSamples, nFeatures int) (X, Y *mat.Dense, sampleWeight []float64, Xrep, Yrep *mat.Dense) {
+	rnd := rand.New(rand.NewSource(7))
+	X = mat.NewDense(nSamples, nFeatures, nil)
+	X.Apply(func(_, _ int, _ float64) float64 { return rnd.NormFloat64() }, X)
+	Y = mat.NewDense(nSamples, 1, nil)
+	sampleWeight = make([]float64, nSamples)
+	for i := 0; i < nSamples; i++ {
+		y := 1 + rnd.NormFloat64()
+		for j := 0; j < nFeatures; j++ {
+			y += float64(j+1) * X.At(i, j)
+		}
+		Y.Set(i, 0, y)
+		sampleWeight[i] = float64(rnd.Intn(4))
+	}
+	return X, Y, sampleWeight, base.RepeatRows(X, sampleWeight), base.RepeatRows(Y, sampleWeight)
+}
+
+func TestWeightedLoss(t *testing.T) {
+	X, Y, sampleWeight, Xrep, Yrep := newWeightedProblem(20, 3)
+	Ybin, Ybinrep := mat.NewDense(20, 1, nil), mat.NewDense(len(Yrep.RawMatrix().Data), 1, nil)
+	Ybin.Apply(func(i, _ int, y float64) float64 { return math.Max(0, math.Copysign(1, y-1)) }, Y)
+	Ybinrep.Apply(func(i, _ int, y float64) float64 { return math.Max(0, math.Copysign(1, y-1)) }, Yrep)
+	Theta := mat.NewDense(3, 1, []float64{.3, -.2, .1})
+	// the weighted loss is the loss of the repeated rows, nSamples being the number of repeated rows
+	for _, test := range []struct {
+		name       string
+		loss       Loss
+		activation Activation
+		Y, Yrep    *mat.Dense
+	}{
+		{"square", SquareLoss, base.Identity{}, Y, Yrep},
+		{"square tanh", SquareLoss, base.Tanh{}, Y, Yrep},
+		{"log", LogLoss, base.Logistic{}, Ybin, Ybinrep},
+		{"cross-entropy", CrossEntropyLoss, base.Logistic{}, Ybin, Ybinrep},
+		{"cross-entropy tanh", CrossEntropyLoss, base.Tanh{}, Ybin, Ybinrep},
+	} {
+		nRows, nRepRows := 20, len(Yrep.RawMatrix().Data)
+		grad, gradRep := mat.NewDense(3, 1, nil), mat.NewDense(3, 1, nil)
+		J := weightedLoss(test.loss, sampleWeight)(test.Y, X, Theta, mat.NewDense(nRows, 1, nil), mat.NewDense(nRows, 1, nil), grad, .1, .5, nRepRows, test.activation, true)
+		JRep := test.loss(test.Yrep, Xrep, Theta, mat.NewDense(nRepRows, 1, nil), mat.NewDense(nRepRows, 1, nil), gradRep, .1, .5, nRepRows, test.activation, true)
+		if math.Abs(J-JRep) > 1e-10 || !mat.EqualApprox(grad, gradRep, 1e-10) {
+			t.Errorf("%s: expected J %g grad %g, got %g %g", test.name, JRep, mat.Col(nil, 0, gradRep), J, mat.Col(nil, 0, grad))
+		}
+	}
+	var paramErr *base.ParamError
+	custom := Loss(func(Ytrue, X mat.Matrix, Theta, Ypred, Ydiff, grad *mat.Dense, Alpha, L1Ratio float64, nSamples int, activation Activation, disableRegularizationOfFirstFeature bool) float64 {
+		return 0
+	})
+	if err := base.Recover(func() { weightedLoss(custom, sampleWeight) }); !errors.As(err, &paramErr) {
+		t.Errorf("expected a *base.ParamError for a custom loss, got %v", err)
+	}
+}
+
+func TestFitWeighted(t *testing.T) {
+	X, Y, sampleWeight, Xrep, Yrep := newWeightedProblem(40, 3)
+	newElasticNet := func() base.FitterWeighted {
+		m := NewElasticNet()
+		m.Alpha, m.Tol = .1, 1e-10
+		return m
+	}
+	newRidge := func() base.FitterWeighted {
+		m := NewRidge()
+		m.Alpha = .1
+		m.RandomState = base.NewRandomState(7)
+		return m
+	}
+	for name, create := range map[string]func() base.FitterWeighted{
+		"LinearRegression": func() base.FitterWeighted { return NewLinearRegression() },
+		"ElasticNet":       newElasticNet,
+		"Ridge":            newRidge,
+		"SGDRegressor":     func() base.FitterWeighted { return NewSGDRegressor() },
+	} {
+		weighted, repeated := create(), create()
+		if err := weighted.FitWeighted(X, Y, sampleWeight); err != nil {
+			t.Errorf("%s: %s", name, err)
+			continue
+		}
+		if err := repeated.FitWeighted(Xrep, Yrep, nil); err != nil {
+			t.Errorf("%s: %s", name, err)
+			continue
+		}
+		Yw, Yr := &mat.Dense{}, &mat.Dense{}
+		weighted.(base.PredicterE).PredictE(X, Yw)
+		repeated.(base.PredicterE).PredictE(X, Yr)
+		if !mat.EqualApprox(Yw, Yr, 1e-3) {
+			t.Errorf("%s: weighted fit differs from fit on repeated rows", name)
+		}
+	}
+}
+
+func TestLogisticRegressionFitWeighted(t *testing.T) {
+	X, Y, sampleWeight, Xrep, Yrep := newWeightedProblem(40, 2)
+	Ycls := func(Y *mat.Dense) *mat.Dense {
+		out := &mat.Dense{}
+		out.Apply(func(_, _ int, y float64) float64 {
+			if y > 1 {
+				return 1
+			}
+			return 0
+		}, Y)
+		return out
+	}
+	weighted, repeated := NewLogisticRegression(), NewLogisticRegression()
+	weighted.Alpha, repeated.Alpha = 1, 1
+	weighted.RandomState, repeated.RandomState = base.NewRandomState(7), base.NewRandomState(7)
+	if err := weighted.FitWeighted(X, Ycls(Y), sampleWeight); err != nil {
+		t.Fatal(err)
+	}
+	repeated.Fit(Xrep, Ycls(Yrep))
+	if !mat.EqualApprox(weighted.Coef, repeated.Coef, 1e-3) || !mat.EqualApprox(weighted.Intercept, repeated.Intercept, 1e-3) {
+		t.Errorf("expected coef %g intercept %g, got %g %g", mat.Formatted(repeated.Coef.T()), mat.Formatted(repeated.Intercept),
+			mat.Formatted(weighted.Coef.T()), mat.Formatted(weighted.Intercept))
+	}
+	if err := weighted.FitWeighted(X, Ycls(Y), sampleWeight[1:]); err == nil {
+		t.Error("expected an error for a sampleWeight of wrong length")
+	}
+}
+
+// newImbalancedProblem returns 2 overlapping gaussian classes, 1 sample out of 20 being of class 1
+func newImbalancedProblem(nSamples int) (X, Y *mat.Dense) {
+	rnd := rand.New(rand.NewSource(7))
+	X = mat.NewDense(nSamples, 2, nil)
+	Y = mat.NewDense(nSamples, 1, nil)
+	for i := 0; i < nSamples; i++ {
+		y := 0.
+		if i%20 == 0 {
+			y = 1
+		}
+		X.Set(i, 0, rnd.NormFloat64()+1.5*y)
+		X.Set(i, 1, rnd.NormFloat64()+1.5*y)
+		Y.Set(i, 0, y)
+	}
+	return
+}
+
+func TestLogisticRegressionClassWeight(t *testing.T) {
+	X, Y := newImbalancedProblem(400)
+	// "balanced" weighs the 380 samples of class 0 400/(2*380) and the 20 of class 1 400/(2*20)
+	balanced, mapped := NewLogisticRegression(), NewLogisticRegression()
+	balanced.ClassWeight = "balanced"
+	mapped.ClassWeight = map[float64]float64{0: 400. / 760, 1: 10}
+	if err := balanced.FitE(X, Y); err != nil {
+		t.Fatal(err)
+	}
+	mapped.Fit(X, Y)
+	if !mat.EqualApprox(balanced.Coef, mapped.Coef, 1e-6) {
+		t.Errorf("expected coef %g, got %g", mat.Formatted(mapped.Coef.T()), mat.Formatted(balanced.Coef.T()))
+	}
+
+	// a ClassWeight map is equivalent to the same weights per sample
+	weighted := NewLogisticRegression()
+	mapped.ClassWeight = map[float64]float64{1: 5}
+	mapped.Fit(X, Y)
+	sampleWeight := make([]float64, len(Y.RawMatrix().Data))
+	for i, y := range Y.RawMatrix().Data {
+		sampleWeight[i] = 1 + 4*y
+	}
+	if err := weighted.FitWeighted(X, Y, sampleWeight); err != nil {
+		t.Fatal(err)
+	}
+	if !mat.EqualApprox(mapped.Coef, weighted.Coef, 1e-6) {
+		t.Errorf("expected coef %g, got %g", mat.Formatted(weighted.Coef.T()), mat.Formatted(mapped.Coef.T()))
+	}
+
+	mapped.ClassWeight = "auto"
+	var paramErr *base.ParamError
+	if err := mapped.FitE(X, Y); !errors.As(err, &paramErr) || paramErr.Param != "ClassWeight" {
+		t.Errorf("expected a ClassWeight ParamError, got %v", err)
+	}
+}
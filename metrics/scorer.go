@@ -0,0 +1,263 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Scorer is a named score function with its direction and the predictions it needs.
+// ScoreFunc is called with the true targets and, depending on NeedsProba and NeedsThreshold,
+// the estimator predictions (from Transform), its class probabilities (from PredictProba)
+// or its decision function (from DecisionFunction, or PredictProba if there is none).
+// GreaterIsBetter is false for losses like mean squared error. the registry scorers are all
+// "greater is better": losses are registered negated, like "neg_mean_squared_error"
+type Scorer struct {
+	Name            string
+	ScoreFunc       func(Ytrue, Ypred *mat.Dense) float64
+	GreaterIsBetter bool
+	NeedsProba      bool
+	NeedsThreshold  bool
+}
+
+// MakeScorer returns an unnamed Scorer for scoreFunc using the estimator predictions
+func MakeScorer(scoreFunc func(Ytrue, Ypred *mat.Dense) float64, greaterIsBetter bool) *Scorer {
+	return &Scorer{ScoreFunc: scoreFunc, GreaterIsBetter: greaterIsBetter}
+}
+
+// Score returns the score of the fitted estimator on X,Y
+func (s *Scorer) Score(estimator base.Transformer, X, Y *mat.Dense) float64 {
+	return s.ScoreFunc(Y, s.predict(estimator, X, Y, make(map[string]*mat.Dense)))
+}
+
+// MultiScore returns the score of the fitted estimator on X,Y for each of scorers.
+// predictions are computed once for each kind of prediction needed
+func MultiScore(scorers map[string]*Scorer, estimator base.Transformer, X, Y *mat.Dense) map[string]float64 {
+	cache := make(map[string]*mat.Dense)
+	scores := make(map[string]float64, len(scorers))
+	for name, s := range scorers {
+		scores[name] = s.ScoreFunc(Y, s.predict(estimator, X, Y, cache))
+	}
+	return scores
+}
+
+type probaPredicter interface {
+	PredictProba(X, Y *mat.Dense)
+}
+type probaPredicterTransformer interface {
+	PredictProba(X, Y *mat.Dense) base.Transformer
+}
+type decisionFunctioner interface {
+	DecisionFunction(X, Y *mat.Dense)
+}
+
+// predict returns the predictions s needs, memoized in cache by kind.
+// a PredictProba returning a base.Transformer (like KNeighborsClassifier) needs an allocated Y:
+// it is allocated with one column per distinct value of Y, so every class must be in Y
+func (s *Scorer) predict(estimator base.Transformer, X, Y *mat.Dense, cache map[string]*mat.Dense) *mat.Dense {
+	kind := "predict"
+	switch {
+	case s.NeedsProba:
+		kind = "proba"
+	case s.NeedsThreshold:
+		kind = "decision"
+		if _, ok := estimator.(decisionFunctioner); !ok {
+			kind = "proba"
+		}
+	}
+	if Ypred, ok := cache[kind]; ok {
+		return Ypred
+	}
+	var Ypred *mat.Dense
+	switch kind {
+	case "predict":
+		_, Ypred = estimator.Transform(X, Y)
+	case "decision":
+		Ypred = &mat.Dense{}
+		estimator.(decisionFunctioner).DecisionFunction(X, Ypred)
+	default:
+		switch est := estimator.(type) {
+		case probaPredicter:
+			Ypred = &mat.Dense{}
+			est.PredictProba(X, Ypred)
+		case probaPredicterTransformer:
+			nSamples, _ := X.Dims()
+			Ypred = mat.NewDense(nSamples, len(uniqueValues(Y)), nil)
+			est.PredictProba(X, Ypred)
+		default:
+			panic(fmt.Errorf("scorer %s: %T has no PredictProba method", s.Name, estimator))
+		}
+	}
+	cache[kind] = Ypred
+	return Ypred
+}
+
+// uniqueValues returns the sorted distinct values of the first column of Y
+func uniqueValues(Y *mat.Dense) []float64 {
+	seen := make(map[float64]bool)
+	var values []float64
+	nSamples, _ := Y.Dims()
+	for i := 0; i < nSamples; i++ {
+		if v := Y.At(i, 0); !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	}
+	sort.Float64s(values)
+	return values
+}
+
+// positiveScore returns the scores of the positive class: Yscore itself if it has one column,
+// else its last column (the probability of the greater class)
+func positiveScore(Yscore *mat.Dense) *mat.Dense {
+	nSamples, nCols := Yscore.Dims()
+	if nCols == 1 {
+		return Yscore
+	}
+	return mat.DenseCopyOf(Yscore.Slice(0, nSamples, nCols-1, nCols))
+}
+
+// oneVsRest returns the indicator matrix of the classes of Ytrue, one column per class
+func oneVsRest(Ytrue *mat.Dense, nClasses int) *mat.Dense {
+	classes := uniqueValues(Ytrue)
+	if len(classes) != nClasses {
+		panic(fmt.Errorf("oneVsRest: %d classes in Ytrue and %d score columns", len(classes), nClasses))
+	}
+	nSamples, _ := Ytrue.Dims()
+	Y := mat.NewDense(nSamples, nClasses, nil)
+	for i := 0; i < nSamples; i++ {
+		Y.Set(i, sort.SearchFloat64s(classes, Ytrue.At(i, 0)), 1)
+	}
+	return Y
+}
+
+// binaryPrecisionRecallF1 returns the precision, recall and F1 score of the positive class 1
+func binaryPrecisionRecallF1(Ytrue, Ypred *mat.Dense) (precision, recall, f1 float64) {
+	var tp, truesum, predsum float64
+	nSamples, _ := Ytrue.Dims()
+	for i := 0; i < nSamples; i++ {
+		yt, yp := Ytrue.At(i, 0) == 1, Ypred.At(i, 0) == 1
+		if yt && yp {
+			tp++
+		}
+		if yt {
+			truesum++
+		}
+		if yp {
+			predsum++
+		}
+	}
+	if predsum > 0 {
+		precision = tp / predsum
+	}
+	if truesum > 0 {
+		recall = tp / truesum
+	}
+	if precision+recall > 0 {
+		f1 = 2 * precision * recall / (precision + recall)
+	}
+	return
+}
+
+var (
+	scorersMu sync.RWMutex
+	scorers   = make(map[string]*Scorer)
+)
+
+// RegisterScorer adds s to the registry under s.Name, replacing any scorer with the same name
+func RegisterScorer(s *Scorer) {
+	if s.Name == "" {
+		panic(fmt.Errorf("RegisterScorer: scorer has no name"))
+	}
+	scorersMu.Lock()
+	defer scorersMu.Unlock()
+	scorers[s.Name] = s
+}
+
+// GetScorer returns the registered scorer named name
+func GetScorer(name string) (*Scorer, error) {
+	scorersMu.RLock()
+	defer scorersMu.RUnlock()
+	s, ok := scorers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown scorer %q", name)
+	}
+	return s, nil
+}
+
+// GetScorers returns the registered scorers named names, by name. it's suitable for GridSearchCV.Scoring
+func GetScorers(names ...string) (map[string]*Scorer, error) {
+	out := make(map[string]*Scorer, len(names))
+	for _, name := range names {
+		s, err := GetScorer(name)
+		if err != nil {
+			return nil, err
+		}
+		out[name] = s
+	}
+	return out, nil
+}
+
+// ScorerNames returns the sorted names of the registered scorers
+func ScorerNames() []string {
+	scorersMu.RLock()
+	defer scorersMu.RUnlock()
+	names := make([]string, 0, len(scorers))
+	for name := range scorers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	register := func(name string, scoreFunc func(Ytrue, Ypred *mat.Dense) float64, needsProba, needsThreshold bool) {
+		RegisterScorer(&Scorer{Name: name, ScoreFunc: scoreFunc, GreaterIsBetter: true, NeedsProba: needsProba, NeedsThreshold: needsThreshold})
+	}
+	// regression
+	register("r2", func(Ytrue, Ypred *mat.Dense) float64 { return R2Score(Ytrue, Ypred, nil, "").At(0, 0) }, false, false)
+	register("neg_mean_squared_error", func(Ytrue, Ypred *mat.Dense) float64 {
+		return -MeanSquaredError(Ytrue, Ypred, nil, "").At(0, 0)
+	}, false, false)
+	register("neg_root_mean_squared_error", func(Ytrue, Ypred *mat.Dense) float64 {
+		return -math.Sqrt(MeanSquaredError(Ytrue, Ypred, nil, "").At(0, 0))
+	}, false, false)
+	register("neg_mean_absolute_error", func(Ytrue, Ypred *mat.Dense) float64 {
+		return -MeanAbsoluteError(Ytrue, Ypred, nil, "").At(0, 0)
+	}, false, false)
+	// classification
+	register("accuracy", func(Ytrue, Ypred *mat.Dense) float64 { return AccuracyScore(Ytrue, Ypred, true, nil) }, false, false)
+	register("precision", func(Ytrue, Ypred *mat.Dense) float64 {
+		p, _, _ := binaryPrecisionRecallF1(Ytrue, Ypred)
+		return p
+	}, false, false)
+	register("recall", func(Ytrue, Ypred *mat.Dense) float64 {
+		_, r, _ := binaryPrecisionRecallF1(Ytrue, Ypred)
+		return r
+	}, false, false)
+	register("f1", func(Ytrue, Ypred *mat.Dense) float64 {
+		_, _, f := binaryPrecisionRecallF1(Ytrue, Ypred)
+		return f
+	}, false, false)
+	for _, average := range []string{"macro", "micro", "weighted"} {
+		average := average
+		register("precision_"+average, func(Ytrue, Ypred *mat.Dense) float64 { return PrecisionScore(Ytrue, Ypred, average, nil) }, false, false)
+		register("recall_"+average, func(Ytrue, Ypred *mat.Dense) float64 { return RecallScore(Ytrue, Ypred, average, nil) }, false, false)
+		register("f1_"+average, func(Ytrue, Ypred *mat.Dense) float64 { return F1Score(Ytrue, Ypred, average, nil) }, false, false)
+	}
+	// ranking
+	register("roc_auc", func(Ytrue, Yscore *mat.Dense) float64 {
+		return ROCAUCScore(Ytrue, positiveScore(Yscore), "", nil)
+	}, false, true)
+	register("roc_auc_ovr", func(Ytrue, Yproba *mat.Dense) float64 {
+		_, nClasses := Yproba.Dims()
+		return ROCAUCScore(oneVsRest(Ytrue, nClasses), Yproba, "macro", nil)
+	}, true, false)
+	register("average_precision", func(Ytrue, Yscore *mat.Dense) float64 {
+		return AveragePrecisionScore(Ytrue, positiveScore(Yscore), "", nil)
+	}, false, true)
+}
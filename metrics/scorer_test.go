@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// thresholdClassifier predicts 1 when the first feature is > .5, its decision function is the first feature
+type thresholdClassifier struct{}
+
+func (m *thresholdClassifier) Fit(X, Y *mat.Dense) base.Transformer { return m }
+func (m *thresholdClassifier) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
+	nSamples, _ := X.Dims()
+	Yout = mat.NewDense(nSamples, 1, nil)
+	for i := 0; i < nSamples; i++ {
+		if X.At(i, 0) > .5 {
+			Yout.Set(i, 0, 1)
+		}
+	}
+	return X, Yout
+}
+func (m *thresholdClassifier) DecisionFunction(X, Y *mat.Dense) {
+	nSamples, _ := X.Dims()
+	Y.Clone(X.Slice(0, nSamples, 0, 1))
+}
+
+func ExampleGetScorer() {
+	X := mat.NewDense(4, 1, []float64{.1, .4, .35, .8})
+	Y := mat.NewDense(4, 1, []float64{0, 0, 1, 1})
+	for _, name := range []string{"accuracy", "recall", "roc_auc"} {
+		scorer, err := GetScorer(name)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Printf("%s: %.2f\n", name, scorer.Score(&thresholdClassifier{}, X, Y))
+	}
+	_, err := GetScorer("nope")
+	fmt.Println(err)
+	// Output:
+	// accuracy: 0.75
+	// recall: 0.50
+	// roc_auc: 0.75
+	// unknown scorer "nope"
+}
+
+func TestMultiScore(t *testing.T) {
+	X := mat.NewDense(4, 1, []float64{.1, .4, .35, .8})
+	Y := mat.NewDense(4, 1, []float64{0, 0, 1, 1})
+	scorers, err := GetScorers("accuracy", "f1", "f1_macro", "neg_mean_squared_error", "average_precision")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scores := MultiScore(scorers, &thresholdClassifier{}, X, Y)
+	expected := map[string]float64{"accuracy": .75, "f1": 2. / 3, "f1_macro": (.8 + 2./3) / 2, "neg_mean_squared_error": -.25, "average_precision": 5. / 6}
+	for name, want := range expected {
+		if math.Abs(scores[name]-want) > 1e-12 {
+			t.Errorf("%s: got %g, want %g", name, scores[name], want)
+		}
+		if !scorers[name].GreaterIsBetter {
+			t.Errorf("%s should be greater is better", name)
+		}
+	}
+	custom := MakeScorer(func(Ytrue, Ypred *mat.Dense) float64 { return MeanSquaredError(Ytrue, Ypred, nil, "").At(0, 0) }, false)
+	if s := custom.Score(&thresholdClassifier{}, X, Y); s != .25 {
+		t.Errorf("custom scorer: got %g", s)
+	}
+	custom.Name = "mse"
+	RegisterScorer(custom)
+	if s, err := GetScorer("mse"); err != nil || s != custom {
+		t.Errorf("registered scorer not found: %v", err)
+	}
+}
@@ -0,0 +1,357 @@
+package modelselection
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// BayesSearchCV is a sequential model-based parameter search: after NInitialPoints random candidates,
+// each new candidate maximizes the expected improvement of the mean CV score predicted by a gaussian process
+// fitted on the candidates evaluated so far.
+// SearchSpaces keys are parameters names accepted by base.SetParams. dimensions are Uniform and LogUniform for continuous
+// parameters, RandInt for integer ones and Choice for categorical ones.
+// NIter is the number of evaluated candidates, default 20. NInitialPoints defaults to 5.
+// NAcquisitionPoints is the number of random points among which the expected improvement is maximized, default 1000.
+// RandomState makes the search reproducible.
+// CVResults has one row per evaluated candidate, in evaluation order. Trace records each step of the optimisation.
+// other members are those of GridSearchCV
+type BayesSearchCV struct {
+	Estimator          base.Transformer
+	SearchSpaces       map[string]Distribution
+	NIter              int
+	NInitialPoints     int
+	NAcquisitionPoints int
+	RandomState        *RandomState
+	Scorer             func(Ytrue, Ypred *mat.Dense) float64
+	CV                 Splitter
+	Verbose            bool
+	NJobs              int
+	LowerScoreIsBetter bool
+	NoRefit            bool
+	ReturnTrainScore   bool
+
+	CVResults     CVResults
+	BestEstimator base.Transformer
+	BestScore     float64
+	BestParams    map[string]interface{}
+	BestIndex     int
+	Trace         []BayesSearchStep
+}
+
+// BayesSearchStep is a step of BayesSearchCV.
+// ExpectedImprovement is the acquisition value of Params, NaN for the initial random points
+type BayesSearchStep struct {
+	Params              map[string]interface{}
+	Score               float64
+	BestScore           float64
+	ExpectedImprovement float64
+}
+
+// Clone ...
+func (bscv *BayesSearchCV) Clone() base.Transformer {
+	clone := *bscv
+	return &clone
+}
+
+// Fit runs the sequential optimisation
+func (bscv *BayesSearchCV) Fit(X, Y *mat.Dense) base.Transformer {
+	if bscv.NIter <= 0 {
+		bscv.NIter = 20
+	}
+	if bscv.NInitialPoints <= 0 {
+		bscv.NInitialPoints = 5
+	}
+	if bscv.NAcquisitionPoints <= 0 {
+		bscv.NAcquisitionPoints = 1000
+	}
+	space := newSearchSpace(bscv.SearchSpaces)
+	r := newRand(bscv.RandomState)
+	sign := 1.
+	if bscv.LowerScoreIsBetter {
+		sign = -1
+	}
+	var (
+		candidates []map[string]interface{}
+		cvres      []CrossValidateResult
+		points     [][]float64
+		scores     []float64
+	)
+	bscv.Trace = nil
+	bscv.BestIndex = -1
+	for iter := 0; iter < bscv.NIter; iter++ {
+		var params map[string]interface{}
+		ei := math.NaN()
+		if iter < bscv.NInitialPoints {
+			params = ParameterSampler(bscv.SearchSpaces, 1, r)[0]
+		} else {
+			params, ei = space.nextCandidate(points, scores, bscv.NAcquisitionPoints, r)
+		}
+		res, err := evaluateCandidates(bscv.Estimator, []map[string]interface{}{params}, X, Y, bscv.Scorer, bscv.CV, bscv.NJobs, bscv.LowerScoreIsBetter, crossValidateOptions{returnTrainScore: bscv.ReturnTrainScore})
+		if err != nil {
+			panic(err)
+		}
+		score := res.scores[0]
+		candidates = append(candidates, params)
+		cvres = append(cvres, res.cvres[0])
+		points = append(points, space.encode(params))
+		// the surrogate is maximized
+		scores = append(scores, sign*score)
+		if bscv.BestIndex < 0 || sign*score > sign*bscv.BestScore || math.IsNaN(bscv.BestScore) {
+			bscv.BestIndex, bscv.BestParams, bscv.BestScore, bscv.BestEstimator = iter, params, score, res.BestEstimator
+		}
+		bscv.Trace = append(bscv.Trace, BayesSearchStep{Params: params, Score: score, BestScore: bscv.BestScore, ExpectedImprovement: ei})
+		if bscv.Verbose {
+			fmt.Printf("BayesSearchCV iter %d score %g best %g params %v\n", iter, score, bscv.BestScore, params)
+		}
+	}
+	bscv.CVResults = makeCVResults(candidates, cvres, bscv.LowerScoreIsBetter, nil)
+	if !bscv.NoRefit {
+		var err error
+		if bscv.BestEstimator, err = refitEstimator(nil, bscv.Estimator, bscv.BestParams, X, Y); err != nil {
+			panic(err)
+		}
+	}
+	return bscv
+}
+
+// FitE is Fit returning an error instead of panicking.
+// dimensions are checked on a clone of Estimator before starting the search
+func (bscv *BayesSearchCV) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckFitXY("BayesSearchCV.Fit", X, Y); err != nil {
+		return err
+	}
+	estCloner, err := checkSearch(bscv, bscv.Estimator, bscv.CV)
+	if err != nil {
+		return err
+	}
+	for k, dim := range bscv.SearchSpaces {
+		switch dim.(type) {
+		case Uniform, LogUniform, RandInt, Choice:
+		default:
+			return &base.ParamError{Estimator: bscv, Param: "SearchSpaces", Value: k, Msg: fmt.Sprintf("unsupported dimension %T", dim)}
+		}
+	}
+	if err := checkDistributions(bscv, estCloner, bscv.SearchSpaces); err != nil {
+		return err
+	}
+	return base.Recover(func() { bscv.Fit(X, Y) })
+}
+
+// Predict fills Y with the predictions of BestEstimator
+func (bscv *BayesSearchCV) Predict(X, Y *mat.Dense) base.Regressor {
+	_, Ypred := bscv.BestEstimator.Transform(X, Y)
+	Y.Copy(Ypred)
+	return bscv
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (bscv *BayesSearchCV) PredictE(X, Y *mat.Dense) error {
+	if bscv.BestEstimator == nil {
+		return &base.NotFittedError{Estimator: bscv}
+	}
+	if err := base.CheckFitXY("BayesSearchCV.Predict", X, Y); err != nil {
+		return err
+	}
+	return predictBest(bscv.BestEstimator, X, Y, func() { bscv.Predict(X, Y) })
+}
+
+// Transform returns X and the predictions of BestEstimator
+func (bscv *BayesSearchCV) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
+	return bscv.BestEstimator.Transform(X, Y)
+}
+
+// Score returns the score of BestEstimator if it is a base.Regressor, else the Scorer value for its predictions
+func (bscv *BayesSearchCV) Score(X, Y *mat.Dense) float64 {
+	if regr, ok := bscv.BestEstimator.(base.Regressor); ok {
+		return regr.Score(X, Y)
+	}
+	_, Ypred := bscv.BestEstimator.Transform(X, Y)
+	return bscv.Scorer(Y, Ypred)
+}
+
+// searchSpace maps parameters to points of the unit hypercube where the surrogate is fitted.
+// continuous and integer dimensions use one coordinate, categorical ones use one coordinate per value (one-hot)
+type searchSpace struct {
+	names []string
+	dims  map[string]Distribution
+}
+
+func newSearchSpace(dims map[string]Distribution) *searchSpace {
+	s := &searchSpace{dims: dims}
+	for name, dim := range dims {
+		switch dim.(type) {
+		case Uniform, LogUniform, RandInt, Choice:
+		default:
+			panic(&base.ParamError{Param: "SearchSpaces", Value: name, Msg: fmt.Sprintf("unsupported dimension %T", dim)})
+		}
+		s.names = append(s.names, name)
+	}
+	sort.Strings(s.names)
+	return s
+}
+
+func unitInterval(v, low, high float64) float64 {
+	if high <= low {
+		return 0
+	}
+	return (v - low) / (high - low)
+}
+
+// encode returns the point of params
+func (s *searchSpace) encode(params map[string]interface{}) (point []float64) {
+	for _, name := range s.names {
+		v := params[name]
+		switch d := s.dims[name].(type) {
+		case Uniform:
+			point = append(point, unitInterval(v.(float64), d.Low, d.High))
+		case LogUniform:
+			point = append(point, unitInterval(math.Log(v.(float64)), math.Log(d.Low), math.Log(d.High)))
+		case RandInt:
+			point = append(point, unitInterval(float64(v.(int)), float64(d.Low), float64(d.High-1)))
+		case Choice:
+			for _, c := range d {
+				if reflect.DeepEqual(c, v) {
+					point = append(point, 1)
+				} else {
+					point = append(point, 0)
+				}
+			}
+		}
+	}
+	return
+}
+
+// nextCandidate draws nPoints random parameters and returns the one maximizing the expected improvement
+// of the gaussian process fitted on points and scores
+func (s *searchSpace) nextCandidate(points [][]float64, scores []float64, nPoints int, r *rand.Rand) (best map[string]interface{}, bestEI float64) {
+	gp := fitGaussianProcess(points, scores)
+	fmax := math.Inf(-1)
+	for _, y := range gp.y {
+		fmax = math.Max(fmax, y)
+	}
+	bestEI = math.Inf(-1)
+	for _, params := range ParameterSampler(s.dims, nPoints, r) {
+		mu, sigma := gp.predict(s.encode(params))
+		if ei := expectedImprovement(mu, sigma, fmax, .01); ei > bestEI {
+			best, bestEI = params, ei
+		}
+	}
+	return best, bestEI * gp.scale
+}
+
+// expectedImprovement is the expectation of max(f-fmax-xi,0) for f normal with mean mu and standard deviation sigma
+func expectedImprovement(mu, sigma, fmax, xi float64) float64 {
+	imp := mu - fmax - xi
+	if sigma <= 0 {
+		return math.Max(imp, 0)
+	}
+	z := imp / sigma
+	n := distuv.UnitNormal
+	return imp*n.CDF(z) + sigma*n.Prob(z)
+}
+
+// gaussianProcess is a gaussian process regressor with a RBF kernel on standardized targets.
+// the length scale is chosen among a few values by maximizing the log marginal likelihood
+type gaussianProcess struct {
+	points       [][]float64
+	y            []float64
+	mean, scale  float64
+	lengthScale  float64
+	noise        float64
+	chol         mat.Cholesky
+	alpha        *mat.VecDense
+	kernelVector *mat.VecDense
+}
+
+func rbf(a, b []float64, lengthScale float64) float64 {
+	d2 := 0.
+	for i := range a {
+		d := a[i] - b[i]
+		d2 += d * d
+	}
+	return math.Exp(-d2 / (2 * lengthScale * lengthScale))
+}
+
+// fitGaussianProcess fits a gaussianProcess. NaN scores are replaced by the lowest score
+func fitGaussianProcess(points [][]float64, scores []float64) *gaussianProcess {
+	y := make([]float64, len(scores))
+	lowest := math.Inf(1)
+	for _, v := range scores {
+		if !math.IsNaN(v) {
+			lowest = math.Min(lowest, v)
+		}
+	}
+	for i, v := range scores {
+		if math.IsNaN(v) {
+			v = lowest
+		}
+		y[i] = v
+	}
+	mean, std := stat.MeanStdDev(y, nil)
+	if std == 0 || math.IsNaN(std) {
+		std = 1
+	}
+	for i := range y {
+		y[i] = (y[i] - mean) / std
+	}
+	n := len(points)
+	var best *gaussianProcess
+	bestLL := math.Inf(-1)
+	for _, lengthScale := range []float64{.05, .1, .2, .5, 1, 2} {
+		gp := &gaussianProcess{points: points, y: y, mean: mean, scale: std, lengthScale: lengthScale, noise: 1e-6}
+		K := mat.NewSymDense(n, nil)
+		for i := 0; i < n; i++ {
+			for j := i; j < n; j++ {
+				K.SetSym(i, j, rbf(points[i], points[j], lengthScale))
+			}
+			K.SetSym(i, i, 1+gp.noise)
+		}
+		if ok := gp.chol.Factorize(K); !ok {
+			continue
+		}
+		gp.alpha = mat.NewVecDense(n, nil)
+		if err := gp.chol.SolveVec(gp.alpha, mat.NewVecDense(n, y)); !isConditionError(err) {
+			continue
+		}
+		// log marginal likelihood up to a constant: -y'K^-1y/2 - log|K|/2
+		ll := -floats.Dot(y, gp.alpha.RawVector().Data)/2 - gp.chol.LogDet()/2
+		if ll > bestLL {
+			best, bestLL = gp, ll
+		}
+	}
+	if best == nil {
+		panic(fmt.Errorf("BayesSearchCV: gaussian process kernel matrix is not positive definite"))
+	}
+	best.kernelVector = mat.NewVecDense(n, nil)
+	return best
+}
+
+// isConditionError returns true if err is nil or only a mat.Condition warning
+func isConditionError(err error) bool {
+	_, ok := err.(mat.Condition)
+	return err == nil || ok
+}
+
+// predict returns the mean and the standard deviation of the standardized target at point
+func (gp *gaussianProcess) predict(point []float64) (mu, sigma float64) {
+	k := gp.kernelVector
+	for i, p := range gp.points {
+		k.SetVec(i, rbf(point, p, gp.lengthScale))
+	}
+	mu = mat.Dot(k, gp.alpha)
+	v := mat.NewVecDense(len(gp.points), nil)
+	if err := gp.chol.SolveVec(v, k); !isConditionError(err) {
+		return mu, 0
+	}
+	variance := 1 + gp.noise - mat.Dot(k, v)
+	return mu, math.Sqrt(math.Max(variance, 0))
+}
@@ -0,0 +1,76 @@
+package modelselection
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	lm "github.com/pa-m/sklearn/linear_model"
+	"github.com/pa-m/sklearn/preprocessing"
+)
+
+func TestGaussianProcess(t *testing.T) {
+	points := [][]float64{{0}, {.25}, {.5}, {.75}, {1}}
+	scores := []float64{0, 1, 0, -1, 0}
+	gp := fitGaussianProcess(points, scores)
+	for i, p := range points {
+		mu, sigma := gp.predict(p)
+		if math.Abs(mu*gp.scale+gp.mean-scores[i]) > 1e-3 || sigma > 1e-2 {
+			t.Errorf("point %v: expected %g got %g±%g", p, scores[i], mu*gp.scale+gp.mean, sigma*gp.scale)
+		}
+	}
+	if _, sigma := gp.predict([]float64{3}); sigma < .5 {
+		t.Errorf("expected a large uncertainty far from the points, got %g", sigma)
+	}
+	if ei := expectedImprovement(0, 1, 0, 0); math.Abs(ei-1/math.Sqrt(2*math.Pi)) > 1e-12 {
+		t.Errorf("unexpected expected improvement %g", ei)
+	}
+}
+
+func TestBayesSearchCV(t *testing.T) {
+	X, Y := halvingTestData()
+	Xpoly, _ := preprocessing.NewPolynomialFeatures(2).FitTransform(X, Y)
+	randomState := base.NewRandomState(7)
+	newSearch := func() *BayesSearchCV {
+		return &BayesSearchCV{
+			Estimator:          lm.NewLasso(),
+			SearchSpaces:       map[string]Distribution{"Alpha": LogUniform{Low: 1e-4, High: 10}, "FitIntercept": Choice{true, false}},
+			NIter:              10,
+			NAcquisitionPoints: 200,
+			RandomState:        randomState,
+			Scorer:             mseScorer,
+			LowerScoreIsBetter: true,
+			CV:                 &KFold{NSplits: 3, RandomState: randomState},
+			NJobs:              1,
+		}
+	}
+	bscv := newSearch()
+	if err := bscv.FitE(Xpoly, Y); err != nil {
+		t.Fatal(err)
+	}
+	if len(bscv.Trace) != 10 || len(bscv.CVResults["params"]) != 10 {
+		t.Fatalf("expected 10 steps, got %d", len(bscv.Trace))
+	}
+	best := math.Inf(1)
+	for i, step := range bscv.Trace {
+		if math.IsNaN(step.ExpectedImprovement) != (i < 5) {
+			t.Errorf("step %d: unexpected expected improvement %g", i, step.ExpectedImprovement)
+		}
+		best = math.Min(best, step.Score)
+		if step.BestScore != best {
+			t.Errorf("step %d: BestScore %g, expected %g", i, step.BestScore, best)
+		}
+	}
+	if bscv.BestScore != best || bscv.CVResults["rank_test_score"][bscv.BestIndex] != 1 {
+		t.Errorf("inconsistent best candidate %d %g", bscv.BestIndex, bscv.BestScore)
+	}
+	if alpha := bscv.BestParams["Alpha"].(float64); alpha > .1 {
+		t.Errorf("expected a small Alpha, got %g", alpha)
+	}
+	other := newSearch()
+	other.Fit(Xpoly, Y)
+	if !reflect.DeepEqual(bscv.CVResults["Alpha"], other.CVResults["Alpha"]) {
+		t.Errorf("RandomState did not make the search reproducible")
+	}
+}
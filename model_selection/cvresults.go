@@ -0,0 +1,238 @@
+package modelselection
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pa-m/sklearn/metrics"
+	"gonum.org/v1/gonum/stat"
+)
+
+// CVResults is the table of a parameter search, one row per candidate.
+// keys are the parameter names, "params" (the parameters map of each candidate),
+// "split<i>_test_score", "mean_test_score", "std_test_score", "rank_test_score",
+// "mean_fit_time", "std_fit_time", "mean_score_time", "std_score_time" (in seconds)
+// and, when train scores are requested, "split<i>_train_score", "mean_train_score", "std_train_score".
+// "score" is kept as an alias of "mean_test_score".
+// searches with several scorers add "split<i>_test_<name>", "mean_test_<name>", "std_test_<name>", "rank_test_<name>"
+// (and the train columns) for each scorer name, the "_score" columns being those of the refit scorer.
+// successive halving searches add "iter" and "n_resources"
+type CVResults map[string][]interface{}
+
+// makeCVResults builds the CVResults of candidates params evaluated by cvres.
+// rank 1 is the best candidate. tied candidates get the same rank.
+// scoring gives the direction of the scores in cvres TestScores, it may be nil
+func makeCVResults(params []map[string]interface{}, cvres []CrossValidateResult, lowerScoreIsBetter bool, scoring map[string]*metrics.Scorer) CVResults {
+	n := len(params)
+	res := make(CVResults)
+	column := func(name string) []interface{} {
+		if _, ok := res[name]; !ok {
+			res[name] = make([]interface{}, n)
+		}
+		return res[name]
+	}
+	seconds := func(d []time.Duration) []float64 {
+		s := make([]float64, len(d))
+		for i, v := range d {
+			s[i] = v.Seconds()
+		}
+		return s
+	}
+	setStats := func(name string, i int, values []float64) {
+		mean, std := meanStd(values)
+		column("mean_" + name)[i] = mean
+		column("std_" + name)[i] = std
+	}
+	meanTest := make([]float64, n)
+	meanTestByName := make(map[string][]float64)
+	for name := range scoring {
+		meanTestByName[name] = make([]float64, n)
+	}
+	setScores := func(set string, i int, scores map[string][]float64) {
+		for name, values := range scores {
+			for split, score := range values {
+				column(fmt.Sprintf("split%d_%s_%s", split, set, name))[i] = score
+			}
+			setStats(set+"_"+name, i, values)
+		}
+	}
+	for i, p := range params {
+		column("params")[i] = p
+		for k, v := range p {
+			column(k)[i] = v
+		}
+		for split, score := range cvres[i].TestScore {
+			column(fmt.Sprintf("split%d_test_score", split))[i] = score
+		}
+		setStats("test_score", i, cvres[i].TestScore)
+		meanTest[i] = res["mean_test_score"][i].(float64)
+		column("score")[i] = meanTest[i]
+		if cvres[i].TrainScore != nil {
+			for split, score := range cvres[i].TrainScore {
+				column(fmt.Sprintf("split%d_train_score", split))[i] = score
+			}
+			setStats("train_score", i, cvres[i].TrainScore)
+		}
+		setScores("test", i, cvres[i].TestScores)
+		setScores("train", i, cvres[i].TrainScores)
+		for name := range cvres[i].TestScores {
+			meanTestByName[name][i] = res["mean_test_"+name][i].(float64)
+		}
+		setStats("fit_time", i, seconds(cvres[i].FitTime))
+		setStats("score_time", i, seconds(cvres[i].ScoreTime))
+	}
+	for i, rank := range rankScores(meanTest, lowerScoreIsBetter) {
+		column("rank_test_score")[i] = rank
+	}
+	for name, means := range meanTestByName {
+		if _, ok := res["mean_test_"+name]; !ok {
+			continue
+		}
+		for i, rank := range rankScores(means, !scoring[name].GreaterIsBetter) {
+			column("rank_test_" + name)[i] = rank
+		}
+	}
+	return res
+}
+
+// meanStd returns the mean and the population standard deviation of values
+func meanStd(values []float64) (mean, std float64) {
+	if len(values) == 0 {
+		return math.NaN(), math.NaN()
+	}
+	mean = stat.Mean(values, nil)
+	for _, v := range values {
+		std += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(std / float64(len(values)))
+}
+
+// rankScores returns the rank of each score, 1 for the best one. NaN scores are ranked last
+func rankScores(scores []float64, lowerIsBetter bool) []int {
+	better := func(a, b float64) bool {
+		switch {
+		case math.IsNaN(a):
+			return false
+		case math.IsNaN(b):
+			return true
+		case lowerIsBetter:
+			return a < b
+		default:
+			return a > b
+		}
+	}
+	idx := make([]int, len(scores))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool { return better(scores[idx[i]], scores[idx[j]]) })
+	ranks := make([]int, len(scores))
+	for pos, i := range idx {
+		if pos > 0 && !better(scores[idx[pos-1]], scores[i]) {
+			ranks[i] = ranks[idx[pos-1]]
+			continue
+		}
+		ranks[i] = pos + 1
+	}
+	return ranks
+}
+
+// Columns returns the column names in a stable order: parameters sorted by name, then iterations and resources
+// of halving searches, times,
+// test scores and train scores, then the scores of each named scorer sorted by name. "params" and "score" are omitted
+func (r CVResults) Columns() []string {
+	known := make(map[string]bool)
+	var ordered []string
+	add := func(names ...string) {
+		for _, name := range names {
+			if _, ok := r[name]; ok {
+				ordered = append(ordered, name)
+			}
+			known[name] = true
+		}
+	}
+	add("iter", "n_resources", "mean_fit_time", "std_fit_time", "mean_score_time", "std_score_time")
+	scoreNames := []string{"score"}
+	var scorerNames []string
+	for name := range r {
+		if strings.HasPrefix(name, "mean_test_") && name != "mean_test_score" {
+			scorerNames = append(scorerNames, strings.TrimPrefix(name, "mean_test_"))
+		}
+	}
+	sort.Strings(scorerNames)
+	for _, scoreName := range append(scoreNames, scorerNames...) {
+		for _, set := range []string{"test", "train"} {
+			for split := 0; ; split++ {
+				name := fmt.Sprintf("split%d_%s_%s", split, set, scoreName)
+				if _, ok := r[name]; !ok {
+					break
+				}
+				add(name)
+			}
+			add("mean_"+set+"_"+scoreName, "std_"+set+"_"+scoreName)
+			if set == "test" {
+				add("rank_test_" + scoreName)
+			}
+		}
+	}
+	known["params"], known["score"] = true, true
+	var params []string
+	for name := range r {
+		if !known[name] {
+			params = append(params, name)
+		}
+	}
+	sort.Strings(params)
+	return append(params, ordered...)
+}
+
+// WriteCSV writes the table to w as CSV with a header line. columns are those returned by Columns.
+// floats are written with the shortest representation, nil values as empty strings
+func (r CVResults) WriteCSV(w io.Writer) error {
+	columns := r.Columns()
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	nRows := 0
+	for _, name := range columns {
+		if len(r[name]) > nRows {
+			nRows = len(r[name])
+		}
+	}
+	record := make([]string, len(columns))
+	for i := 0; i < nRows; i++ {
+		for j, name := range columns {
+			record[j] = ""
+			if i < len(r[name]) {
+				record[j] = formatCSVValue(r[name][i])
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatCSVValue(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(x), 'g', -1, 32)
+	case string:
+		return x
+	default:
+		return fmt.Sprint(x)
+	}
+}
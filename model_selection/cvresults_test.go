@@ -0,0 +1,135 @@
+package modelselection
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pa-m/sklearn/base"
+	lm "github.com/pa-m/sklearn/linear_model"
+	"github.com/pa-m/sklearn/metrics"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestRankScores(t *testing.T) {
+	scores := []float64{.5, .9, math.NaN(), .9, .1}
+	if ranks := rankScores(scores, false); !reflect.DeepEqual(ranks, []int{3, 1, 5, 1, 4}) {
+		t.Errorf("higher is better: got %v", ranks)
+	}
+	if ranks := rankScores(scores, true); !reflect.DeepEqual(ranks, []int{2, 3, 5, 3, 1}) {
+		t.Errorf("lower is better: got %v", ranks)
+	}
+}
+
+func ExampleCVResults_WriteCSV() {
+	cvres := []CrossValidateResult{
+		{TestScore: []float64{1, 3}, TrainScore: []float64{4, 4}, FitTime: []time.Duration{time.Second, 3 * time.Second}, ScoreTime: []time.Duration{0, 0}},
+		{TestScore: []float64{2, 4}, TrainScore: []float64{5, 5}, FitTime: []time.Duration{time.Second, time.Second}, ScoreTime: []time.Duration{0, 0}},
+	}
+	params := []map[string]interface{}{{"Alpha": 1., "Normalize": true}, {"Alpha": 0.1, "Normalize": false}}
+	results := makeCVResults(params, cvres, false, nil)
+	if err := results.WriteCSV(os.Stdout); err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// Alpha,Normalize,mean_fit_time,std_fit_time,mean_score_time,std_score_time,split0_test_score,split1_test_score,mean_test_score,std_test_score,rank_test_score,split0_train_score,split1_train_score,mean_train_score,std_train_score
+	// 1,true,2,1,0,0,1,3,2,1,2,4,4,4,0
+	// 0.1,false,1,0,0,0,2,4,3,1,1,5,5,5,0
+}
+
+func TestGridSearchCVResults(t *testing.T) {
+	X := mat.NewDense(12, 1, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})
+	Y := mat.NewDense(12, 1, []float64{2.1, 3.9, 6.2, 8, 9.8, 12.1, 14, 15.9, 18.2, 20, 21.8, 24.1})
+	gscv := &GridSearchCV{
+		Estimator:          lm.NewLinearRegression(),
+		ParamGrid:          map[string][]interface{}{"FitIntercept": {true, false}},
+		Scorer:             func(Y, Ypred *mat.Dense) float64 { return metrics.MeanSquaredError(Y, Ypred, nil, "").At(0, 0) },
+		LowerScoreIsBetter: true,
+		CV:                 &KFold{NSplits: 3},
+		NJobs:              1,
+		ReturnTrainScore:   true,
+	}
+	if err := gscv.FitE(X, Y); err != nil {
+		t.Fatal(err)
+	}
+	res := gscv.CVResults
+	for _, name := range []string{"FitIntercept", "params", "split0_test_score", "split2_test_score", "mean_test_score", "std_test_score", "rank_test_score", "mean_fit_time", "split2_train_score", "mean_train_score"} {
+		if len(res[name]) != 2 {
+			t.Fatalf("column %s has %d values", name, len(res[name]))
+		}
+	}
+	if res["rank_test_score"][gscv.BestIndex] != 1 {
+		t.Errorf("best candidate %d has rank %v", gscv.BestIndex, res["rank_test_score"][gscv.BestIndex])
+	}
+	for i := range res["score"] {
+		mean := 0.
+		for split := 0; split < 3; split++ {
+			mean += res[fmt.Sprintf("split%d_test_score", split)][i].(float64) / 3
+		}
+		if math.Abs(mean-res["mean_test_score"][i].(float64)) > 1e-12 || res["score"][i] != res["mean_test_score"][i] {
+			t.Errorf("candidate %d: inconsistent mean_test_score", i)
+		}
+	}
+	var buf bytes.Buffer
+	if err := res.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || len(records[0]) != len(res.Columns()) {
+		t.Errorf("unexpected csv shape %dx%d", len(records), len(records[0]))
+	}
+}
+
+func TestGridSearchCVScoring(t *testing.T) {
+	X := mat.NewDense(12, 1, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})
+	Y := mat.NewDense(12, 1, []float64{2.1, 3.9, 6.2, 8, 9.8, 12.1, 14, 15.9, 18.2, 20, 21.8, 24.1})
+	scoring, err := metrics.GetScorers("r2", "neg_mean_squared_error")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gscv := &GridSearchCV{
+		Estimator: lm.NewLinearRegression(),
+		ParamGrid: map[string][]interface{}{"FitIntercept": {true, false}},
+		Scoring:   scoring,
+		CV:        &KFold{NSplits: 3},
+		NJobs:     1,
+	}
+	if err := gscv.FitE(X, Y); err == nil {
+		t.Error("expected an error for a missing Refit")
+	}
+	gscv.Refit = "neg_mean_squared_error"
+	gscv.NoRefit = true
+	var paramErr *base.ParamError
+	if err := gscv.FitE(X, Y); !errors.As(err, &paramErr) || paramErr.Param != "NoRefit" {
+		t.Errorf("expected ParamError on NoRefit with Refit, got %v", err)
+	}
+	gscv.NoRefit = false
+	if err := gscv.FitE(X, Y); err != nil {
+		t.Fatal(err)
+	}
+	res := gscv.CVResults
+	for _, name := range []string{"split0_test_r2", "mean_test_r2", "rank_test_r2", "split2_test_neg_mean_squared_error", "mean_test_neg_mean_squared_error", "rank_test_neg_mean_squared_error"} {
+		if len(res[name]) != 2 {
+			t.Fatalf("column %s has %d values", name, len(res[name]))
+		}
+	}
+	if res["rank_test_neg_mean_squared_error"][gscv.BestIndex] != 1 || res["mean_test_score"][gscv.BestIndex] != res["mean_test_neg_mean_squared_error"][gscv.BestIndex] {
+		t.Errorf("best candidate %d is not the best for the refit scorer", gscv.BestIndex)
+	}
+	if gscv.BestScore > 0 {
+		t.Errorf("negated mse should be <= 0, got %g", gscv.BestScore)
+	}
+	columns := res.Columns()
+	if columns[0] != "FitIntercept" || columns[len(columns)-1] != "rank_test_r2" {
+		t.Errorf("unexpected columns %v", columns)
+	}
+}
@@ -0,0 +1,84 @@
+package modelselection
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Distribution is a parameter distribution for RandomizedSearchCV.
+// Rvs draws a value using r, so that a search with a RandomState is reproducible
+type Distribution interface {
+	Rvs(r *rand.Rand) interface{}
+}
+
+// Uniform is the continuous uniform distribution on [Low,High)
+type Uniform struct{ Low, High float64 }
+
+// Rvs returns a float64
+func (d Uniform) Rvs(r *rand.Rand) interface{} {
+	return d.Low + (d.High-d.Low)*r.Float64()
+}
+
+// LogUniform is the distribution of exp(x) with x uniform on [log(Low),log(High)).
+// it's suitable for regularization strengths and learning rates. Low must be >0
+type LogUniform struct{ Low, High float64 }
+
+// Rvs returns a float64
+func (d LogUniform) Rvs(r *rand.Rand) interface{} {
+	low, high := math.Log(d.Low), math.Log(d.High)
+	return math.Exp(low + (high-low)*r.Float64())
+}
+
+// RandInt is the discrete uniform distribution on integers in [Low,High)
+type RandInt struct{ Low, High int }
+
+// Rvs returns an int
+func (d RandInt) Rvs(r *rand.Rand) interface{} {
+	return d.Low + r.Intn(d.High-d.Low)
+}
+
+// Choice is the categorical distribution choosing uniformly one of its values
+type Choice []interface{}
+
+// Rvs returns one of the values of d
+func (d Choice) Rvs(r *rand.Rand) interface{} {
+	return d[r.Intn(len(d))]
+}
+
+// Distuv adapts a gonum/stat/distuv distribution like distuv.Normal or distuv.Gamma.
+// when the distribution implements distuv.Quantiler, values are drawn by inverse transform sampling with the search random source.
+// else its own Rand method (and random source) is used
+type Distuv struct{ distuv.Rander }
+
+// Rvs returns a float64
+func (d Distuv) Rvs(r *rand.Rand) interface{} {
+	if q, ok := d.Rander.(distuv.Quantiler); ok {
+		p := r.Float64()
+		for p == 0 {
+			p = r.Float64()
+		}
+		return q.Quantile(p)
+	}
+	return d.Rand()
+}
+
+// ParameterSampler returns NIter parameters sets drawn from distributions.
+// parameters are drawn in the order of their names so that the result only depends on r
+func ParameterSampler(distributions map[string]Distribution, NIter int, r *rand.Rand) (out []map[string]interface{}) {
+	names := make([]string, 0, len(distributions))
+	for name := range distributions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i := 0; i < NIter; i++ {
+		params := make(map[string]interface{})
+		for _, name := range names {
+			params[name] = distributions[name].Rvs(r)
+		}
+		out = append(out, params)
+	}
+	return
+}
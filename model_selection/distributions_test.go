@@ -0,0 +1,47 @@
+package modelselection
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+func ExampleParameterSampler() {
+	distributions := map[string]Distribution{
+		"Alpha":  LogUniform{Low: 1e-4, High: 1},
+		"Degree": RandInt{Low: 1, High: 4},
+		"Solver": Choice{"adam", "lbfgs"},
+	}
+	for _, params := range ParameterSampler(distributions, 100, rand.New(rand.NewSource(7))) {
+		alpha, degree, solver := params["Alpha"].(float64), params["Degree"].(int), params["Solver"].(string)
+		if alpha < 1e-4 || alpha >= 1 || degree < 1 || degree >= 4 || (solver != "adam" && solver != "lbfgs") {
+			fmt.Println("unexpected", params)
+		}
+	}
+	fmt.Println(len(ParameterSampler(distributions, 3, rand.New(rand.NewSource(7)))))
+	// Output:
+	// 3
+}
+
+func TestParameterSamplerReproducible(t *testing.T) {
+	distributions := map[string]Distribution{
+		"A": Uniform{Low: -1, High: 1},
+		"B": Distuv{distuv.Normal{Mu: 10, Sigma: 1}},
+		"C": RandInt{Low: 0, High: 100},
+	}
+	a := ParameterSampler(distributions, 20, rand.New(rand.NewSource(3)))
+	b := ParameterSampler(distributions, 20, rand.New(rand.NewSource(3)))
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("same seed gave different parameters")
+	}
+	sum := 0.
+	for _, params := range a {
+		sum += params["B"].(float64)
+	}
+	if mean := sum / 20; mean < 9 || mean > 11 {
+		t.Errorf("unexpected mean %g for Normal{10,1}", mean)
+	}
+}
@@ -0,0 +1,70 @@
+package modelselection
+
+import (
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/base/estimatorchecks"
+	lm "github.com/pa-m/sklearn/linear_model"
+)
+
+func TestEstimatorChecks(t *testing.T) {
+	X, Y := estimatorchecks.RegressionData()
+	randomState := base.NewRandomState(7)
+	// KFold draws the start of its folds, a seed gives the same folds to each fit.
+	// searches hold funcs and splitters and are not saved, their BestEstimator is
+	for _, search := range []estimatorchecks.Estimator{
+		&GridSearchCV{
+			Estimator:          lm.NewLasso(),
+			ParamGrid:          map[string][]interface{}{"Alpha": {1e-3, 1e-1}},
+			Scorer:             mseScorer,
+			LowerScoreIsBetter: true,
+			CV:                 &KFold{NSplits: 3, RandomState: randomState},
+			NJobs:              1,
+		},
+		&RandomizedSearchCV{
+			Estimator:          lm.NewLasso(),
+			ParamDistributions: map[string]Distribution{"Alpha": LogUniform{Low: 1e-4, High: 1}},
+			NIter:              3,
+			RandomState:        randomState,
+			Scorer:             mseScorer,
+			LowerScoreIsBetter: true,
+			CV:                 &KFold{NSplits: 3, RandomState: randomState},
+			NJobs:              1,
+		},
+		&HalvingGridSearchCV{
+			Estimator:          lm.NewLasso(),
+			ParamGrid:          map[string][]interface{}{"Alpha": {1e-3, 1e-2, 1e-1}},
+			RandomState:        randomState,
+			Scorer:             mseScorer,
+			LowerScoreIsBetter: true,
+			CV:                 &KFold{NSplits: 3, RandomState: randomState},
+			NJobs:              1,
+		},
+		&HalvingRandomSearchCV{
+			Estimator:          lm.NewLasso(),
+			ParamDistributions: map[string]Distribution{"Alpha": LogUniform{Low: 1e-4, High: 1}},
+			NInitialCandidates: 4,
+			RandomState:        randomState,
+			Scorer:             mseScorer,
+			LowerScoreIsBetter: true,
+			CV:                 &KFold{NSplits: 3, RandomState: randomState},
+			NJobs:              1,
+		},
+		&BayesSearchCV{
+			Estimator:          lm.NewLasso(),
+			SearchSpaces:       map[string]Distribution{"Alpha": LogUniform{Low: 1e-4, High: 1}},
+			NIter:              4,
+			NInitialPoints:     2,
+			RandomState:        randomState,
+			Scorer:             mseScorer,
+			LowerScoreIsBetter: true,
+			CV:                 &KFold{NSplits: 3, RandomState: randomState},
+			NJobs:              1,
+		},
+	} {
+		if err := estimatorchecks.CheckEstimator(search, X, Y, estimatorchecks.Except("SaveLoad")...); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,383 @@
+package modelselection
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// halvingSettings are the successive halving settings shared by HalvingGridSearchCV and HalvingRandomSearchCV
+type halvingSettings struct {
+	estimator                  base.Transformer
+	scorer                     func(Ytrue, Ypred *mat.Dense) float64
+	cv                         Splitter
+	NJobs                      int
+	lowerScoreIsBetter         bool
+	returnTrainScore           bool
+	resource                   string
+	factor                     int
+	minResources, maxResources int
+}
+
+// resources returns the resources of the first and the last iterations, applying defaults:
+// the number of samples and twice the number of splits when the resource is the number of samples, else MaxResources and 1
+func (h *halvingSettings) resources(X, Y *mat.Dense) (minResources, maxResources int) {
+	minResources, maxResources = h.minResources, h.maxResources
+	if h.resource == "" {
+		if maxResources <= 0 {
+			maxResources, _ = X.Dims()
+		}
+		if minResources <= 0 {
+			nSplits := h.cv.GetNSplits(X, Y)
+			if nSplits <= 0 {
+				nSplits = 3
+			}
+			minResources = 2 * nSplits
+		}
+	} else if minResources <= 0 {
+		minResources = 1
+	}
+	if minResources > maxResources {
+		minResources = maxResources
+	}
+	return
+}
+
+// halvingResult is the outcome of successiveHalving
+type halvingResult struct {
+	searchResult
+	nCandidates, nResources []int
+}
+
+// successiveHalving evaluates candidates with a growing resource, keeping the best 1/factor of them at each iteration.
+// the resource is the number of samples drawn with r if h.resource is empty, else the value of the parameter named h.resource.
+// CVResults gathers the rows of every iteration with "iter" and "n_resources" columns.
+// the best candidate is the best one of the last iteration
+func successiveHalving(h halvingSettings, candidates []map[string]interface{}, X, Y *mat.Dense, r *rand.Rand) (res halvingResult) {
+	if h.factor <= 1 {
+		panic(&base.ParamError{Param: "Factor", Value: h.factor, Msg: "must be > 1"})
+	}
+	if h.resource != "" && h.maxResources <= 0 {
+		panic(&base.ParamError{Param: "MaxResources", Value: h.maxResources, Msg: fmt.Sprintf("must be set when the resource is %s", h.resource)})
+	}
+	minResources, maxResources := h.resources(X, Y)
+	logFactor := math.Log(float64(h.factor))
+	nRequired := 1 + int(math.Floor(math.Log(float64(len(candidates)))/logFactor+1e-9))
+	nPossible := 1 + int(math.Floor(math.Log(float64(maxResources)/float64(minResources))/logFactor+1e-9))
+	nIterations := nRequired
+	if nPossible < nIterations {
+		nIterations = nPossible
+	}
+
+	var (
+		allParams  []map[string]interface{}
+		allCVRes   []CrossValidateResult
+		iters      []interface{}
+		nResources []interface{}
+	)
+	nSamples, _ := X.Dims()
+	nRes := minResources
+	for iter := 0; iter < nIterations; iter++ {
+		if iter > 0 {
+			nRes *= h.factor
+		}
+		if nRes > maxResources {
+			nRes = maxResources
+		}
+		Xiter, Yiter := X, Y
+		if h.resource == "" {
+			Xiter, Yiter = subsample(X, Y, r.Perm(nSamples)[:nRes])
+		} else {
+			withResource := make([]map[string]interface{}, len(candidates))
+			for i, params := range candidates {
+				withResource[i] = make(map[string]interface{}, len(params)+1)
+				for k, v := range params {
+					withResource[i][k] = v
+				}
+				withResource[i][h.resource] = nRes
+			}
+			candidates = withResource
+		}
+		iterRes, err := evaluateCandidates(h.estimator, candidates, Xiter, Yiter, h.scorer, h.cv, h.NJobs, h.lowerScoreIsBetter, crossValidateOptions{returnTrainScore: h.returnTrainScore})
+		if err != nil {
+			panic(err)
+		}
+		res.nCandidates = append(res.nCandidates, len(candidates))
+		res.nResources = append(res.nResources, nRes)
+		if iter == nIterations-1 {
+			res.BestIndex = len(allParams) + iterRes.BestIndex
+			res.BestEstimator, res.BestParams, res.BestScore = iterRes.BestEstimator, iterRes.BestParams, iterRes.BestScore
+		}
+		allParams = append(allParams, candidates...)
+		allCVRes = append(allCVRes, iterRes.cvres...)
+		for range candidates {
+			iters = append(iters, iter)
+			nResources = append(nResources, nRes)
+		}
+
+		// keep the best ceil(len(candidates)/factor) candidates
+		order := make([]int, len(candidates))
+		for i := range order {
+			order[i] = i
+		}
+		ranks := rankScores(iterRes.scores, h.lowerScoreIsBetter)
+		sort.SliceStable(order, func(i, j int) bool { return ranks[order[i]] < ranks[order[j]] })
+		nKeep := (len(candidates) + h.factor - 1) / h.factor
+		kept := make([]map[string]interface{}, nKeep)
+		for i := range kept {
+			kept[i] = candidates[order[i]]
+		}
+		candidates = kept
+	}
+	res.CVResults = makeCVResults(allParams, allCVRes, h.lowerScoreIsBetter, nil)
+	res.CVResults["iter"], res.CVResults["n_resources"] = iters, nResources
+	return
+}
+
+// subsample returns the rows of X and Y in indices, in ascending order
+func subsample(X, Y *mat.Dense, indices []int) (Xsub, Ysub *mat.Dense) {
+	indices = append([]int(nil), indices...)
+	sort.Ints(indices)
+	return takeRows(X, indices), takeRows(Y, indices)
+}
+
+// checkHalving returns an error if halving settings of search are invalid
+func checkHalving(search interface{}, estCloner base.TransformerCloner, resource string, factor, maxResources int) error {
+	if factor == 1 || factor < 0 {
+		return &base.ParamError{Estimator: search, Param: "Factor", Value: factor, Msg: "must be > 1"}
+	}
+	if resource == "" {
+		return nil
+	}
+	if maxResources <= 0 {
+		return &base.ParamError{Estimator: search, Param: "MaxResources", Value: maxResources, Msg: fmt.Sprintf("must be set when the resource is %s", resource)}
+	}
+	return base.SetParams(estCloner.Clone(), map[string]interface{}{resource: maxResources})
+}
+
+// HalvingGridSearchCV searches the candidates of ParamGrid with successive halving:
+// all candidates are evaluated with MinResources, then the best 1/Factor of them with Factor times more resources, and so on.
+// Resource is the name of an integer parameter of Estimator like "Epochs" or "MaxIter", which requires MaxResources.
+// if Resource is empty, the resource is the number of samples, randomly drawn using RandomState.
+// a zero Factor defaults to 3, MinResources to 1 (twice the number of CV splits for samples), MaxResources to the number of samples.
+// CVResults has the rows of every iteration, with "iter" and "n_resources" columns.
+// BestIndex is the best candidate of the last iteration. NCandidates and NResources are the number of candidates and
+// resources of each iteration.
+// other members are those of GridSearchCV
+type HalvingGridSearchCV struct {
+	Estimator                  base.Transformer
+	ParamGrid                  map[string][]interface{}
+	Factor                     int
+	Resource                   string
+	MinResources, MaxResources int
+	RandomState                *RandomState
+	Scorer                     func(Ytrue, Ypred *mat.Dense) float64
+	CV                         Splitter
+	Verbose                    bool
+	NJobs                      int
+	LowerScoreIsBetter         bool
+	NoRefit                    bool
+	ReturnTrainScore           bool
+
+	CVResults               CVResults
+	BestEstimator           base.Transformer
+	BestScore               float64
+	BestParams              map[string]interface{}
+	BestIndex               int
+	NCandidates, NResources []int
+}
+
+// Clone ...
+func (hs *HalvingGridSearchCV) Clone() base.Transformer {
+	clone := *hs
+	return &clone
+}
+
+// Fit runs the successive halving search
+func (hs *HalvingGridSearchCV) Fit(X, Y *mat.Dense) base.Transformer {
+	if hs.Factor == 0 {
+		hs.Factor = 3
+	}
+	h := halvingSettings{estimator: hs.Estimator, scorer: hs.Scorer, cv: hs.CV, NJobs: hs.NJobs, lowerScoreIsBetter: hs.LowerScoreIsBetter, returnTrainScore: hs.ReturnTrainScore,
+		resource: hs.Resource, factor: hs.Factor, minResources: hs.MinResources, maxResources: hs.MaxResources}
+	res := successiveHalving(h, ParameterGrid(hs.ParamGrid), X, Y, newRand(hs.RandomState))
+	hs.CVResults, hs.BestIndex, hs.BestParams, hs.BestScore = res.CVResults, res.BestIndex, res.BestParams, res.BestScore
+	hs.BestEstimator, hs.NCandidates, hs.NResources = res.BestEstimator, res.nCandidates, res.nResources
+	if !hs.NoRefit {
+		var err error
+		if hs.BestEstimator, err = refitEstimator(nil, hs.Estimator, hs.BestParams, X, Y); err != nil {
+			panic(err)
+		}
+	}
+	return hs
+}
+
+// FitE is Fit returning an error instead of panicking.
+// every value of ParamGrid, and the Resource parameter, are checked on a clone of Estimator before starting the search
+func (hs *HalvingGridSearchCV) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckFitXY("HalvingGridSearchCV.Fit", X, Y); err != nil {
+		return err
+	}
+	estCloner, err := checkSearch(hs, hs.Estimator, hs.CV)
+	if err != nil {
+		return err
+	}
+	if err := checkHalving(hs, estCloner, hs.Resource, hs.Factor, hs.MaxResources); err != nil {
+		return err
+	}
+	for k, values := range hs.ParamGrid {
+		if len(values) == 0 {
+			return &base.ParamError{Estimator: hs, Param: "ParamGrid", Value: k, Msg: "no values"}
+		}
+		for _, v := range values {
+			if err := base.SetParams(estCloner.Clone(), map[string]interface{}{k: v}); err != nil {
+				return err
+			}
+		}
+	}
+	return base.Recover(func() { hs.Fit(X, Y) })
+}
+
+// Predict fills Y with the predictions of BestEstimator
+func (hs *HalvingGridSearchCV) Predict(X, Y *mat.Dense) base.Regressor {
+	_, Ypred := hs.BestEstimator.Transform(X, Y)
+	Y.Copy(Ypred)
+	return hs
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (hs *HalvingGridSearchCV) PredictE(X, Y *mat.Dense) error {
+	if hs.BestEstimator == nil {
+		return &base.NotFittedError{Estimator: hs}
+	}
+	if err := base.CheckFitXY("HalvingGridSearchCV.Predict", X, Y); err != nil {
+		return err
+	}
+	return predictBest(hs.BestEstimator, X, Y, func() { hs.Predict(X, Y) })
+}
+
+// Transform returns X and the predictions of BestEstimator
+func (hs *HalvingGridSearchCV) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
+	return hs.BestEstimator.Transform(X, Y)
+}
+
+// Score returns the score of BestEstimator if it is a base.Regressor, else the Scorer value for its predictions
+func (hs *HalvingGridSearchCV) Score(X, Y *mat.Dense) float64 {
+	if regr, ok := hs.BestEstimator.(base.Regressor); ok {
+		return regr.Score(X, Y)
+	}
+	_, Ypred := hs.BestEstimator.Transform(X, Y)
+	return hs.Scorer(Y, Ypred)
+}
+
+// HalvingRandomSearchCV is HalvingGridSearchCV for NInitialCandidates parameters sets drawn from ParamDistributions.
+// NInitialCandidates defaults to MaxResources/MinResources, so that the last iteration uses about MaxResources
+type HalvingRandomSearchCV struct {
+	Estimator                  base.Transformer
+	ParamDistributions         map[string]Distribution
+	NInitialCandidates         int
+	Factor                     int
+	Resource                   string
+	MinResources, MaxResources int
+	RandomState                *RandomState
+	Scorer                     func(Ytrue, Ypred *mat.Dense) float64
+	CV                         Splitter
+	Verbose                    bool
+	NJobs                      int
+	LowerScoreIsBetter         bool
+	NoRefit                    bool
+	ReturnTrainScore           bool
+
+	CVResults               CVResults
+	BestEstimator           base.Transformer
+	BestScore               float64
+	BestParams              map[string]interface{}
+	BestIndex               int
+	NCandidates, NResources []int
+}
+
+// Clone ...
+func (hs *HalvingRandomSearchCV) Clone() base.Transformer {
+	clone := *hs
+	return &clone
+}
+
+// Fit draws the candidates and runs the successive halving search
+func (hs *HalvingRandomSearchCV) Fit(X, Y *mat.Dense) base.Transformer {
+	if hs.Factor == 0 {
+		hs.Factor = 3
+	}
+	h := halvingSettings{estimator: hs.Estimator, scorer: hs.Scorer, cv: hs.CV, NJobs: hs.NJobs, lowerScoreIsBetter: hs.LowerScoreIsBetter, returnTrainScore: hs.ReturnTrainScore,
+		resource: hs.Resource, factor: hs.Factor, minResources: hs.MinResources, maxResources: hs.MaxResources}
+	r := newRand(hs.RandomState)
+	nCandidates := hs.NInitialCandidates
+	if nCandidates <= 0 {
+		minResources, maxResources := h.resources(X, Y)
+		nCandidates = maxResources / minResources
+	}
+	res := successiveHalving(h, ParameterSampler(hs.ParamDistributions, nCandidates, r), X, Y, r)
+	hs.CVResults, hs.BestIndex, hs.BestParams, hs.BestScore = res.CVResults, res.BestIndex, res.BestParams, res.BestScore
+	hs.BestEstimator, hs.NCandidates, hs.NResources = res.BestEstimator, res.nCandidates, res.nResources
+	if !hs.NoRefit {
+		var err error
+		if hs.BestEstimator, err = refitEstimator(nil, hs.Estimator, hs.BestParams, X, Y); err != nil {
+			panic(err)
+		}
+	}
+	return hs
+}
+
+// FitE is Fit returning an error instead of panicking.
+// distributions and the Resource parameter are checked on a clone of Estimator before starting the search
+func (hs *HalvingRandomSearchCV) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckFitXY("HalvingRandomSearchCV.Fit", X, Y); err != nil {
+		return err
+	}
+	estCloner, err := checkSearch(hs, hs.Estimator, hs.CV)
+	if err != nil {
+		return err
+	}
+	if err := checkHalving(hs, estCloner, hs.Resource, hs.Factor, hs.MaxResources); err != nil {
+		return err
+	}
+	if err := checkDistributions(hs, estCloner, hs.ParamDistributions); err != nil {
+		return err
+	}
+	return base.Recover(func() { hs.Fit(X, Y) })
+}
+
+// Predict fills Y with the predictions of BestEstimator
+func (hs *HalvingRandomSearchCV) Predict(X, Y *mat.Dense) base.Regressor {
+	_, Ypred := hs.BestEstimator.Transform(X, Y)
+	Y.Copy(Ypred)
+	return hs
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (hs *HalvingRandomSearchCV) PredictE(X, Y *mat.Dense) error {
+	if hs.BestEstimator == nil {
+		return &base.NotFittedError{Estimator: hs}
+	}
+	if err := base.CheckFitXY("HalvingRandomSearchCV.Predict", X, Y); err != nil {
+		return err
+	}
+	return predictBest(hs.BestEstimator, X, Y, func() { hs.Predict(X, Y) })
+}
+
+// Transform returns X and the predictions of BestEstimator
+func (hs *HalvingRandomSearchCV) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
+	return hs.BestEstimator.Transform(X, Y)
+}
+
+// Score returns the score of BestEstimator if it is a base.Regressor, else the Scorer value for its predictions
+func (hs *HalvingRandomSearchCV) Score(X, Y *mat.Dense) float64 {
+	if regr, ok := hs.BestEstimator.(base.Regressor); ok {
+		return regr.Score(X, Y)
+	}
+	_, Ypred := hs.BestEstimator.Transform(X, Y)
+	return hs.Scorer(Y, Ypred)
+}
@@ -0,0 +1,174 @@
+package modelselection
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	lm "github.com/pa-m/sklearn/linear_model"
+	"github.com/pa-m/sklearn/metrics"
+	"github.com/pa-m/sklearn/pipeline"
+	"github.com/pa-m/sklearn/preprocessing"
+	"gonum.org/v1/gonum/mat"
+)
+
+func halvingTestData() (X, Y *mat.Dense) {
+	r := rand.New(rand.NewSource(11))
+	X, Y = mat.NewDense(60, 1, nil), mat.NewDense(60, 1, nil)
+	for i := 0; i < 60; i++ {
+		x := float64(i)/10 - 3
+		X.Set(i, 0, x)
+		Y.Set(i, 0, x*x-2*x+1+r.NormFloat64()/10)
+	}
+	return
+}
+
+func mseScorer(Y, Ypred *mat.Dense) float64 {
+	return metrics.MeanSquaredError(Y, Ypred, nil, "").At(0, 0)
+}
+
+func TestHalvingGridSearchCVSamples(t *testing.T) {
+	X, Y := halvingTestData()
+	randomState := base.NewRandomState(3)
+	hs := &HalvingGridSearchCV{
+		Estimator: pipeline.NewPipeline(
+			pipeline.NamedStep{Name: "poly", Step: preprocessing.NewPolynomialFeatures(1)},
+			pipeline.NamedStep{Name: "regr", Step: lm.NewLinearRegression()},
+		),
+		ParamGrid:          map[string][]interface{}{"poly__Degree": {1, 2, 3}, "poly__IncludeBias": {false}},
+		RandomState:        randomState,
+		Scorer:             mseScorer,
+		LowerScoreIsBetter: true,
+		CV:                 &KFold{NSplits: 3},
+		NJobs:              1,
+	}
+	if err := hs.FitE(X, Y); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(hs.NCandidates, []int{3, 1}) || !reflect.DeepEqual(hs.NResources, []int{6, 18}) {
+		t.Errorf("unexpected iterations: candidates %v resources %v", hs.NCandidates, hs.NResources)
+	}
+	if len(hs.CVResults["iter"]) != 4 || hs.CVResults["iter"][3] != 1 || hs.CVResults["n_resources"][3] != 18 {
+		t.Errorf("unexpected iter/n_resources columns %v %v", hs.CVResults["iter"], hs.CVResults["n_resources"])
+	}
+	if hs.BestIndex != 3 || hs.BestParams["poly__Degree"] == 1 {
+		t.Errorf("unexpected best candidate %d %v", hs.BestIndex, hs.BestParams)
+	}
+	Ypred := mat.NewDense(60, 1, nil)
+	if err := hs.PredictE(X, Ypred); err != nil {
+		t.Fatal(err)
+	}
+	if mse := mseScorer(Y, Ypred); mse > .05 {
+		t.Errorf("unexpected mse %g", mse)
+	}
+}
+
+func TestHalvingGridSearchCVParamResource(t *testing.T) {
+	X, Y := halvingTestData()
+	Xpoly, _ := preprocessing.NewPolynomialFeatures(2).FitTransform(X, Y)
+	hs := &HalvingGridSearchCV{
+		Estimator:          lm.NewLasso(),
+		ParamGrid:          map[string][]interface{}{"Alpha": {1e-4, 1e-3, 1e-2, .03, .1, .3, 1, 3, 10}},
+		Resource:           "MaxIter",
+		MaxResources:       27,
+		Scorer:             mseScorer,
+		LowerScoreIsBetter: true,
+		CV:                 &KFold{NSplits: 3},
+		NJobs:              1,
+	}
+	if err := hs.FitE(Xpoly, Y); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(hs.NCandidates, []int{9, 3, 1}) || !reflect.DeepEqual(hs.NResources, []int{1, 3, 9}) {
+		t.Errorf("unexpected iterations: candidates %v resources %v", hs.NCandidates, hs.NResources)
+	}
+	if hs.BestParams["MaxIter"] != 9 || hs.BestEstimator.(*lm.Lasso).MaxIter != 9 {
+		t.Errorf("unexpected BestParams %v", hs.BestParams)
+	}
+
+	hs.MaxResources = 0
+	var paramErr *base.ParamError
+	if err := hs.FitE(Xpoly, Y); !errors.As(err, &paramErr) || paramErr.Param != "MaxResources" {
+		t.Errorf("expected ParamError on MaxResources, got %v", err)
+	}
+	hs.Resource, hs.MaxResources = "MaxIterations", 27
+	if err := hs.FitE(Xpoly, Y); !errors.As(err, &paramErr) || paramErr.Param != "MaxIterations" {
+		t.Errorf("expected ParamError on MaxIterations, got %v", err)
+	}
+}
+
+func TestHalvingRandomSearchCV(t *testing.T) {
+	X, Y := halvingTestData()
+	randomState := base.NewRandomState(5)
+	newSearch := func() *HalvingRandomSearchCV {
+		return &HalvingRandomSearchCV{
+			Estimator: pipeline.NewPipeline(
+				pipeline.NamedStep{Name: "poly", Step: preprocessing.NewPolynomialFeatures(1)},
+				pipeline.NamedStep{Name: "regr", Step: lm.NewLinearRegression()},
+			),
+			ParamDistributions: map[string]Distribution{"poly__Degree": RandInt{Low: 1, High: 4}, "poly__IncludeBias": Choice{false}},
+			RandomState:        randomState,
+			Scorer:             mseScorer,
+			LowerScoreIsBetter: true,
+			CV:                 &KFold{NSplits: 3, RandomState: randomState},
+			NJobs:              1,
+		}
+	}
+	hs := newSearch()
+	if err := hs.FitE(X, Y); err != nil {
+		t.Fatal(err)
+	}
+	// 60 samples, 6 samples at first iteration: 10 candidates
+	if !reflect.DeepEqual(hs.NCandidates, []int{10, 4, 2}) || !reflect.DeepEqual(hs.NResources, []int{6, 18, 54}) {
+		t.Errorf("unexpected iterations: candidates %v resources %v", hs.NCandidates, hs.NResources)
+	}
+	other := newSearch()
+	other.Fit(X, Y)
+	if !reflect.DeepEqual(hs.CVResults["poly__Degree"], other.CVResults["poly__Degree"]) || hs.BestIndex != other.BestIndex {
+		t.Errorf("RandomState did not make the search reproducible")
+	}
+}
+
+func TestHalvingFactor(t *testing.T) {
+	X, Y := halvingTestData()
+	grid := &HalvingGridSearchCV{
+		Estimator:          lm.NewLinearRegression(),
+		ParamGrid:          map[string][]interface{}{"FitIntercept": {true, false}},
+		Scorer:             mseScorer,
+		LowerScoreIsBetter: true,
+		CV:                 &KFold{NSplits: 3},
+		NJobs:              1,
+	}
+	random := &HalvingRandomSearchCV{
+		Estimator:          lm.NewLinearRegression(),
+		ParamDistributions: map[string]Distribution{"FitIntercept": Choice{true, false}},
+		Scorer:             mseScorer,
+		LowerScoreIsBetter: true,
+		CV:                 &KFold{NSplits: 3},
+		NJobs:              1,
+	}
+	// a negative Factor is rejected by Fit and FitE
+	for _, search := range []interface {
+		base.Transformer
+		FitE(X, Y *mat.Dense) error
+	}{grid, random} {
+		grid.Factor, random.Factor = -1, -1
+		var paramErr *base.ParamError
+		if err := search.FitE(X, Y); !errors.As(err, &paramErr) || paramErr.Param != "Factor" {
+			t.Errorf("%T.FitE: expected ParamError on Factor, got %v", search, err)
+		}
+		if err := base.Recover(func() { search.Fit(X, Y) }); !errors.As(err, &paramErr) || paramErr.Param != "Factor" {
+			t.Errorf("%T.Fit: expected ParamError on Factor, got %v", search, err)
+		}
+	}
+	// a zero Factor is the default 3
+	grid.Factor, random.Factor = 0, 0
+	if err := grid.FitE(X, Y); err != nil || grid.Factor != 3 {
+		t.Errorf("expected the default Factor 3, got %d %v", grid.Factor, err)
+	}
+	if err := random.FitE(X, Y); err != nil || random.Factor != 3 {
+		t.Errorf("expected the default Factor 3, got %d %v", random.Factor, err)
+	}
+}
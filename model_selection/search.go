@@ -1,10 +1,16 @@
 package modelselection
 
 import (
+	"context"
 	"fmt"
-	"reflect"
+	"math"
+	"math/rand"
+	"sort"
+	"sync/atomic"
+	"time"
 
 	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/metrics"
 	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
 )
@@ -40,19 +46,29 @@ func ParameterGrid(paramGrid map[string][]interface{}) (out []map[string]interfa
 
 // GridSearchCV ...
 // Estimator is the base estimator. it must implement base.TransformerCloner
+// ParamGrid keys are parameters names accepted by base.SetParams, like "Alpha" or "stepname__Alpha" for a pipeline
 // Scorer is a function  __returning a higher score when Ypred is better__
 // CV is a splitter (defaults to KFold)
+// unless NoRefit is set, BestEstimator is refitted with BestParams on the whole data and used by Predict and Transform
+// ReturnTrainScore adds the train scores of each split to CVResults
+// Scoring replaces Scorer and LowerScoreIsBetter to evaluate several metrics.Scorer at once, like those returned by
+// metrics.GetScorers. Refit is the name of the scorer used to choose BestParams and refit BestEstimator, it may be empty
+// if Scoring has only one scorer. FitE returns a *base.ParamError if both NoRefit and Refit are set
 type GridSearchCV struct {
 	Estimator          base.Transformer
 	ParamGrid          map[string][]interface{}
 	Scorer             func(Ytrue, Ypred *mat.Dense) float64
+	Scoring            map[string]*metrics.Scorer
+	Refit              string
 	CV                 Splitter
 	Verbose            bool
 	NJobs              int
 	LowerScoreIsBetter bool
 	UseChannels        bool
+	NoRefit            bool
+	ReturnTrainScore   bool
 
-	CVResults     map[string][]interface{}
+	CVResults     CVResults
 	BestEstimator base.Transformer
 	BestScore     float64
 	BestParams    map[string]interface{}
@@ -67,9 +83,70 @@ func (gscv *GridSearchCV) Clone() base.Transformer {
 
 // Fit ...
 func (gscv *GridSearchCV) Fit(X, Y *mat.Dense) base.Transformer {
+	if err := gscv.fit(nil, X, Y); err != nil {
+		panic(err)
+	}
+	return gscv
+}
+
+// FitContext is FitE returning ctx.Err() when ctx is cancelled or its deadline is exceeded.
+// the candidate estimators are fitted with ctx if they implement base.FitterContext.
+// the mean test score of each candidate is reported as ValidationScore to the ProgressCallback of ctx,
+// with Epoch the number of candidates done, as well as the progress of each cross validation and candidate fit
+func (gscv *GridSearchCV) FitContext(ctx context.Context, X, Y *mat.Dense) error {
+	if err := gscv.checkFit(X, Y); err != nil {
+		return err
+	}
+	var err error
+	if rerr := base.Recover(func() { err = gscv.fit(ctx, X, Y) }); rerr != nil {
+		return rerr
+	}
+	return err
+}
+
+func (gscv *GridSearchCV) fit(ctx context.Context, X, Y *mat.Dense) error {
+	if err := checkScoring(gscv, gscv.Scorer, gscv.Scoring, gscv.Refit, gscv.NoRefit); err != nil {
+		return err
+	}
+	opts := searchOptions(gscv.Scoring, gscv.Refit, gscv.ReturnTrainScore)
+	opts.ctx, opts.search = ctx, gscv
+	res, err := evaluateCandidates(gscv.Estimator, ParameterGrid(gscv.ParamGrid), X, Y, gscv.Scorer, gscv.CV, gscv.NJobs, gscv.LowerScoreIsBetter, opts)
+	if err != nil {
+		return err
+	}
+	gscv.CVResults, gscv.BestIndex, gscv.BestParams, gscv.BestScore = res.CVResults, res.BestIndex, res.BestParams, res.BestScore
+	gscv.BestEstimator = res.BestEstimator
+	if !gscv.NoRefit {
+		gscv.BestEstimator, err = refitEstimator(ctx, gscv.Estimator, gscv.BestParams, X, Y)
+	}
+	return err
+}
+
+// searchResult is the outcome of evaluateCandidates
+type searchResult struct {
+	CVResults     CVResults
+	BestEstimator base.Transformer
+	BestScore     float64
+	BestParams    map[string]interface{}
+	BestIndex     int
+	// per candidate cross validation results and mean test scores
+	cvres  []CrossValidateResult
+	scores []float64
+}
+
+// evaluateCandidates cross validates a clone of estimator for each parameters set of candidates.
+// BestEstimator is the best fold estimator of the best candidate.
+// when opts has a scoring, candidates are compared with the opts.refit scorer and lowerScoreIsBetter is ignored.
+// it is the search loop shared by GridSearchCV and the other parameter searches
+// when opts has a ctx, the mean test score of each candidate is reported to its ProgressCallback for opts.search.
+// err is the first error of crossValidate, like ctx.Err()
+func evaluateCandidates(estimator base.Transformer, candidates []map[string]interface{}, X, Y *mat.Dense, scorer func(Ytrue, Ypred *mat.Dense) float64, cv Splitter, NJobs int, lowerScoreIsBetter bool, opts crossValidateOptions) (res searchResult, err error) {
+	if opts.scoring != nil {
+		lowerScoreIsBetter = !opts.scoring[opts.refit].GreaterIsBetter
+	}
 
 	isBetter := func(score, refscore float64) bool {
-		if gscv.LowerScoreIsBetter {
+		if lowerScoreIsBetter {
 			return score < refscore
 		}
 		return score > refscore
@@ -84,130 +161,396 @@ func (gscv *GridSearchCV) Fit(X, Y *mat.Dense) base.Transformer {
 		return best
 	}
 
-	estCloner := gscv.Estimator.(base.TransformerCloner)
-	paramArray := ParameterGrid(gscv.ParamGrid)
-	gscv.CVResults = make(map[string][]interface{})
-	for k := range gscv.ParamGrid {
-		gscv.CVResults[k] = make([]interface{}, len(paramArray))
-	}
-	gscv.CVResults["score"] = make([]interface{}, len(paramArray))
+	estCloner := estimator.(base.TransformerCloner)
 
 	type structIn struct {
 		cvindex   int
 		params    map[string]interface{}
 		estimator base.Transformer
 		score     float64
+		cvres     CrossValidateResult
+		err       error
 	}
+	start := time.Now()
+	var candidatesDone int32
 	dowork := func(sin structIn) structIn {
 		sin.estimator = estCloner.Clone()
-		for k, v := range sin.params {
-			setParam(sin.estimator, k, v)
+		if err := base.SetParams(sin.estimator, sin.params); err != nil {
+			sin.err = err
+			return sin
+		}
+		CV := cv.Clone()
+		cvres, err := crossValidate(sin.estimator, X, Y, nil, scorer, CV, NJobs, opts)
+		if err != nil {
+			sin.err = err
+			return sin
 		}
-		CV := gscv.CV.Clone()
-		cvres := CrossValidate(sin.estimator, X, Y, nil, gscv.Scorer, CV, gscv.NJobs)
+		sin.cvres = cvres
 		sin.score = floats.Sum(cvres.TestScore) / float64(len(cvres.TestScore))
 		bestFold := bestIdx(cvres.TestScore)
 		sin.estimator = cvres.Estimator[bestFold]
+		done := atomic.AddInt32(&candidatesDone, 1)
+		base.ReportProgress(opts.ctx, base.Progress{Estimator: opts.search, Epoch: int(done), Epochs: len(candidates), Loss: math.NaN(), ValidationScore: sin.score, Elapsed: time.Since(start)})
 		return sin
 	}
-	gscv.BestIndex = -1
+	res.BestIndex = -1
 
-	/*if gscv.UseChannels { // use channels
-		chin := make(chan structIn)
-		chout := make(chan structIn)
-		worker := func(j int) {
-			for sin := range chin {
-				chout <- dowork(sin)
+	sin := make([]structIn, len(candidates))
+	for i, params := range candidates {
+		sin[i] = structIn{cvindex: i, params: params, estimator: estimator}
+	}
+	base.Parallelize(NJobs, len(candidates), func(th, start, end int) {
+		for i := start; i < end; i++ {
+			// a panic must be recovered in the goroutine of the candidate
+			if err := base.Recover(func() { sin[i] = dowork(sin[i]) }); err != nil {
+				sin[i].err = err
 			}
 		}
-		if gscv.NJobs <= 0 || gscv.NJobs > runtime.NumCPU() {
-			gscv.NJobs = runtime.NumCPU()
+	})
+	for _, sout := range sin {
+		if sout.err != nil {
+			return res, sout.err
 		}
-		for j := 0; j < gscv.NJobs; j++ {
-			go worker(j)
+	}
+	res.cvres = make([]CrossValidateResult, len(sin))
+	res.scores = make([]float64, len(sin))
+	for i, sout := range sin {
+		res.cvres[i], res.scores[i] = sout.cvres, sout.score
+	}
+	res.CVResults = makeCVResults(candidates, res.cvres, lowerScoreIsBetter, opts.scoring)
+	for _, sout := range sin {
+		if res.BestIndex == -1 || isBetter(sout.score, res.BestScore) {
+			res.BestIndex = sout.cvindex
+			res.BestEstimator = sout.estimator
+			res.BestParams = sout.params
+			res.BestScore = sout.score
 		}
-		for cvindex, params := range paramArray {
-			chin <- structIn{cvindex: cvindex, params: params}
+	}
+	return
+}
+
+// searchOptions returns the cross validation options of a search. it panics if refit doesn't name a scorer of scoring
+func searchOptions(scoring map[string]*metrics.Scorer, refit string, returnTrainScore bool) crossValidateOptions {
+	if len(scoring) == 0 {
+		return crossValidateOptions{returnTrainScore: returnTrainScore}
+	}
+	refit, err := refitScorer(scoring, refit)
+	if err != nil {
+		panic(err)
+	}
+	return crossValidateOptions{returnTrainScore: returnTrainScore, scoring: scoring, refit: refit}
+}
+
+// refitScorer returns the name of the scorer of scoring used to choose the best candidate
+func refitScorer(scoring map[string]*metrics.Scorer, refit string) (string, error) {
+	if len(scoring) == 0 {
+		return refit, nil
+	}
+	if refit == "" && len(scoring) == 1 {
+		for name := range scoring {
+			refit = name
 		}
-		close(chin)
-		for range paramArray {
-			sout := <-chout
-			for k, v := range sout.params {
-				gscv.CVResults[k][sout.cvindex] = v
-			}
-			gscv.CVResults["score"][sout.cvindex] = sout.score
-			if gscv.BestIndex == -1 || isBetter(sout.score, gscv.CVResults["score"][gscv.BestIndex].(float64)) {
-				gscv.BestIndex = sout.cvindex
-				gscv.BestEstimator = sout.estimator
-				gscv.BestParams = sout.params
-				gscv.BestScore = sout.score
-			}
+	}
+	if s, ok := scoring[refit]; !ok || s == nil {
+		names := make([]string, 0, len(scoring))
+		for name := range scoring {
+			names = append(names, name)
 		}
-		close(chout)
+		sort.Strings(names)
+		return "", fmt.Errorf("Refit %q must be one of the Scoring names %v", refit, names)
+	}
+	return refit, nil
+}
+
+// checkScoring returns an error if the search has neither Scorer nor Scoring, if Refit is not a Scoring name
+// or if NoRefit contradicts Refit
+func checkScoring(search interface{}, scorer func(Ytrue, Ypred *mat.Dense) float64, scoring map[string]*metrics.Scorer, refit string, noRefit bool) error {
+	if scorer == nil && len(scoring) == 0 {
+		return &base.ParamError{Estimator: search, Param: "Scorer", Value: nil, Msg: "Scorer or Scoring must be set"}
+	}
+	if noRefit && refit != "" {
+		return &base.ParamError{Estimator: search, Param: "NoRefit", Value: noRefit, Msg: fmt.Sprintf("conflicts with Refit %q", refit)}
+	}
+	if _, err := refitScorer(scoring, refit); err != nil {
+		return &base.ParamError{Estimator: search, Param: "Refit", Value: refit, Msg: err.Error()}
+	}
+	return nil
+}
+
+// searchScore returns the score of bestEstimator if it is a base.Regressor, else the scorer value for its predictions,
+// or the value of the refit scorer of scoring if scorer is nil
+func searchScore(bestEstimator base.Transformer, scorer func(Ytrue, Ypred *mat.Dense) float64, scoring map[string]*metrics.Scorer, refit string, X, Y *mat.Dense) float64 {
+	if regr, ok := bestEstimator.(base.Regressor); ok {
+		return regr.Score(X, Y)
+	}
+	if scorer == nil && len(scoring) > 0 {
+		return scoring[searchOptions(scoring, refit, false).refit].Score(bestEstimator, X, Y)
+	}
+	_, Ypred := bestEstimator.Transform(X, Y)
+	return scorer(Y, Ypred)
+}
 
-	} else*/{ // use sync.workGroup
-		sin := make([]structIn, len(paramArray))
-		for i, params := range paramArray {
-			sin[i] = structIn{cvindex: i, params: params, estimator: gscv.Estimator}
+// refitEstimator returns a clone of estimator with params, fitted on X,Y.
+// it is fitted with ctx if ctx is not nil and it implements base.FitterContext
+func refitEstimator(ctx context.Context, estimator base.Transformer, params map[string]interface{}, X, Y *mat.Dense) (base.Transformer, error) {
+	refitted := estimator.(base.TransformerCloner).Clone()
+	if err := base.SetParams(refitted, params); err != nil {
+		return nil, err
+	}
+	if fc, ok := refitted.(base.FitterContext); ok && ctx != nil {
+		return refitted, fc.FitContext(ctx, X, Y)
+	}
+	refitted.Fit(X, Y)
+	return refitted, nil
+}
+
+// FitE is Fit returning an error instead of panicking.
+// every value of ParamGrid is checked on a clone of Estimator before starting the search
+func (gscv *GridSearchCV) FitE(X, Y *mat.Dense) error {
+	if err := gscv.checkFit(X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { gscv.Fit(X, Y) })
+}
+
+func (gscv *GridSearchCV) checkFit(X, Y *mat.Dense) error {
+	if err := base.CheckFitXY("GridSearchCV.Fit", X, Y); err != nil {
+		return err
+	}
+	estCloner, err := checkSearch(gscv, gscv.Estimator, gscv.CV)
+	if err != nil {
+		return err
+	}
+	if err := checkScoring(gscv, gscv.Scorer, gscv.Scoring, gscv.Refit, gscv.NoRefit); err != nil {
+		return err
+	}
+	for k, values := range gscv.ParamGrid {
+		if len(values) == 0 {
+			return &base.ParamError{Estimator: gscv, Param: "ParamGrid", Value: k, Msg: "no values"}
 		}
-		base.Parallelize(gscv.NJobs, len(paramArray), func(th, start, end int) {
-			for i := start; i < end; i++ {
-				sin[i] = dowork(sin[i])
-				gscv.CVResults["score"][sin[i].cvindex] = sin[i].score
-			}
-		})
-		for _, sout := range sin {
-			if gscv.BestIndex == -1 || isBetter(sout.score, gscv.CVResults["score"][gscv.BestIndex].(float64)) {
-				gscv.BestIndex = sout.cvindex
-				gscv.BestEstimator = sout.estimator
-				gscv.BestParams = sout.params
-				gscv.BestScore = sout.score
+		for _, v := range values {
+			if err := base.SetParams(estCloner.Clone(), map[string]interface{}{k: v}); err != nil {
+				return err
 			}
 		}
 	}
+	return nil
+}
 
-	return gscv
+// checkSearch returns an error if estimator can't be cloned or cv is nil
+func checkSearch(search interface{}, estimator base.Transformer, cv Splitter) (base.TransformerCloner, error) {
+	estCloner, ok := estimator.(base.TransformerCloner)
+	if !ok {
+		return nil, &base.ParamError{Estimator: search, Param: "Estimator", Value: estimator, Msg: "must implement base.TransformerCloner"}
+	}
+	if cv == nil {
+		return nil, &base.ParamError{Estimator: search, Param: "CV", Value: cv, Msg: "must not be nil"}
+	}
+	return estCloner, nil
 }
 
-// Predict ...
-func (gscv *GridSearchCV) Predict(X, Y *mat.Dense) base.Transformer {
+// Predict fills Y with the predictions of BestEstimator
+func (gscv *GridSearchCV) Predict(X, Y *mat.Dense) base.Regressor {
+	_, Ypred := gscv.BestEstimator.Transform(X, Y)
+	Y.Copy(Ypred)
 	return gscv
 }
 
-// Transform ...
+// PredictE is Predict returning an error instead of panicking
+func (gscv *GridSearchCV) PredictE(X, Y *mat.Dense) error {
+	if gscv.BestEstimator == nil {
+		return &base.NotFittedError{Estimator: gscv}
+	}
+	if err := base.CheckFitXY("GridSearchCV.Predict", X, Y); err != nil {
+		return err
+	}
+	return predictBest(gscv.BestEstimator, X, Y, func() { gscv.Predict(X, Y) })
+}
+
+// Transform returns X and the predictions of BestEstimator, so that GridSearchCV can be the last step of a pipeline
 func (gscv *GridSearchCV) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
-	Xout = X
-	return
+	return gscv.BestEstimator.Transform(X, Y)
+}
+
+// Score returns the score of BestEstimator if it is a base.Regressor, else the Scorer (or Refit scorer) value for its predictions
+func (gscv *GridSearchCV) Score(X, Y *mat.Dense) float64 {
+	return searchScore(gscv.BestEstimator, gscv.Scorer, gscv.Scoring, gscv.Refit, X, Y)
+}
+
+// RandomizedSearchCV is GridSearchCV for NIter parameters sets drawn from ParamDistributions
+// instead of an exhaustive grid.
+// ParamDistributions keys are parameters names accepted by base.SetParams, values are distributions like
+// Uniform, LogUniform, RandInt, Choice or Distuv.
+// NIter defaults to 10. RandomState makes the drawn parameters reproducible
+// other members are those of GridSearchCV
+type RandomizedSearchCV struct {
+	Estimator          base.Transformer
+	ParamDistributions map[string]Distribution
+	NIter              int
+	RandomState        *RandomState
+	Scorer             func(Ytrue, Ypred *mat.Dense) float64
+	Scoring            map[string]*metrics.Scorer
+	Refit              string
+	CV                 Splitter
+	Verbose            bool
+	NJobs              int
+	LowerScoreIsBetter bool
+	NoRefit            bool
+	ReturnTrainScore   bool
+
+	CVResults     CVResults
+	BestEstimator base.Transformer
+	BestScore     float64
+	BestParams    map[string]interface{}
+	BestIndex     int
+}
+
+// Clone ...
+func (rscv *RandomizedSearchCV) Clone() base.Transformer {
+	clone := *rscv
+	return &clone
+}
+
+// Fit draws NIter parameters sets and cross validates Estimator with each of them
+func (rscv *RandomizedSearchCV) Fit(X, Y *mat.Dense) base.Transformer {
+	if err := rscv.fit(nil, X, Y); err != nil {
+		panic(err)
+	}
+	return rscv
+}
+
+// FitContext is FitE returning ctx.Err() when ctx is cancelled or its deadline is exceeded. see GridSearchCV.FitContext
+func (rscv *RandomizedSearchCV) FitContext(ctx context.Context, X, Y *mat.Dense) error {
+	if err := rscv.checkFit(X, Y); err != nil {
+		return err
+	}
+	var err error
+	if rerr := base.Recover(func() { err = rscv.fit(ctx, X, Y) }); rerr != nil {
+		return rerr
+	}
+	return err
 }
 
-func setParam(estimator base.Transformer, k string, v interface{}) {
-	est := reflect.ValueOf(estimator)
-	est = reflect.Indirect(est)
-	if est.Kind().String() != "struct" {
-		panic(est.Kind().String())
-	}
-	if field := est.FieldByName(k); field.Kind() != 0 {
-		switch field.Kind() {
-		case reflect.String:
-			field.SetString(v.(string))
-		case reflect.Float64:
-			switch vv := v.(type) {
-			case int:
-				field.SetFloat(float64(vv))
-			case float32:
-				field.SetFloat(float64(vv))
-			case float64:
-				field.SetFloat(float64(vv))
-			default:
-				panic(fmt.Errorf("failed to set %s %s to %v", k, field.Type().String(), v))
+func (rscv *RandomizedSearchCV) fit(ctx context.Context, X, Y *mat.Dense) error {
+	if rscv.NIter <= 0 {
+		rscv.NIter = 10
+	}
+	candidates := ParameterSampler(rscv.ParamDistributions, rscv.NIter, newRand(rscv.RandomState))
+	if err := checkScoring(rscv, rscv.Scorer, rscv.Scoring, rscv.Refit, rscv.NoRefit); err != nil {
+		return err
+	}
+	opts := searchOptions(rscv.Scoring, rscv.Refit, rscv.ReturnTrainScore)
+	opts.ctx, opts.search = ctx, rscv
+	res, err := evaluateCandidates(rscv.Estimator, candidates, X, Y, rscv.Scorer, rscv.CV, rscv.NJobs, rscv.LowerScoreIsBetter, opts)
+	if err != nil {
+		return err
+	}
+	rscv.CVResults, rscv.BestIndex, rscv.BestParams, rscv.BestScore = res.CVResults, res.BestIndex, res.BestParams, res.BestScore
+	rscv.BestEstimator = res.BestEstimator
+	if !rscv.NoRefit {
+		rscv.BestEstimator, err = refitEstimator(ctx, rscv.Estimator, rscv.BestParams, X, Y)
+	}
+	return err
+}
+
+// FitE is Fit returning an error instead of panicking.
+// a value of each distribution (every value for a Choice) is checked on a clone of Estimator before starting the search
+func (rscv *RandomizedSearchCV) FitE(X, Y *mat.Dense) error {
+	if err := rscv.checkFit(X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { rscv.Fit(X, Y) })
+}
+
+func (rscv *RandomizedSearchCV) checkFit(X, Y *mat.Dense) error {
+	if err := base.CheckFitXY("RandomizedSearchCV.Fit", X, Y); err != nil {
+		return err
+	}
+	estCloner, err := checkSearch(rscv, rscv.Estimator, rscv.CV)
+	if err != nil {
+		return err
+	}
+	if err := checkScoring(rscv, rscv.Scorer, rscv.Scoring, rscv.Refit, rscv.NoRefit); err != nil {
+		return err
+	}
+	return checkDistributions(rscv, estCloner, rscv.ParamDistributions)
+}
+
+// predictBest calls the PredictE of best if it has one, so that its shape errors are returned, else recovers predict
+func predictBest(best base.Transformer, X, Y *mat.Dense, predict func()) error {
+	if pe, ok := best.(base.PredicterE); ok {
+		return pe.PredictE(X, Y)
+	}
+	return base.Recover(predict)
+}
+
+// newRand returns the generator to use for randomState (see base.CheckRandomState)
+func newRand(randomState *RandomState) *rand.Rand {
+	return base.CheckRandomState(randomState).Rand()
+}
+
+// checkDistributions checks a value of each distribution (every value for a Choice) on a clone of the search estimator
+func checkDistributions(search interface{}, estCloner base.TransformerCloner, distributions map[string]Distribution) error {
+	if len(distributions) == 0 {
+		return &base.ParamError{Estimator: search, Param: "ParamDistributions", Value: distributions, Msg: "no distributions"}
+	}
+	r := rand.New(rand.NewSource(1))
+	for k, dist := range distributions {
+		var values []interface{}
+		switch d := dist.(type) {
+		case nil:
+			return &base.ParamError{Estimator: search, Param: "ParamDistributions", Value: k, Msg: "nil distribution"}
+		case Choice:
+			if len(d) == 0 {
+				return &base.ParamError{Estimator: search, Param: "ParamDistributions", Value: k, Msg: "no values"}
+			}
+			values = d
+		case RandInt:
+			if d.High <= d.Low {
+				return &base.ParamError{Estimator: search, Param: "ParamDistributions", Value: k, Msg: "RandInt High must be > Low"}
+			}
+			values = []interface{}{d.Rvs(r)}
+		case LogUniform:
+			if d.Low <= 0 || d.High < d.Low {
+				return &base.ParamError{Estimator: search, Param: "ParamDistributions", Value: k, Msg: "LogUniform needs 0 < Low <= High"}
 			}
+			values = []interface{}{d.Rvs(r)}
 		default:
-			panic(fmt.Errorf("failed to set %s %s to %v", k, field.Type().String(), v))
-
+			values = []interface{}{d.Rvs(r)}
+		}
+		for _, v := range values {
+			if err := base.SetParams(estCloner.Clone(), map[string]interface{}{k: v}); err != nil {
+				return err
+			}
 		}
+	}
+	return nil
+}
+
+// Predict fills Y with the predictions of BestEstimator
+func (rscv *RandomizedSearchCV) Predict(X, Y *mat.Dense) base.Regressor {
+	_, Ypred := rscv.BestEstimator.Transform(X, Y)
+	Y.Copy(Ypred)
+	return rscv
+}
 
-	} else {
-		panic(fmt.Errorf("no field %s in %T", k, estimator))
+// PredictE is Predict returning an error instead of panicking
+func (rscv *RandomizedSearchCV) PredictE(X, Y *mat.Dense) error {
+	if rscv.BestEstimator == nil {
+		return &base.NotFittedError{Estimator: rscv}
+	}
+	if err := base.CheckFitXY("RandomizedSearchCV.Predict", X, Y); err != nil {
+		return err
 	}
+	return predictBest(rscv.BestEstimator, X, Y, func() { rscv.Predict(X, Y) })
+}
+
+// Transform returns X and the predictions of BestEstimator
+func (rscv *RandomizedSearchCV) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
+	return rscv.BestEstimator.Transform(X, Y)
+}
 
+// Score returns the score of BestEstimator if it is a base.Regressor, else the Scorer (or Refit scorer) value for its predictions
+func (rscv *RandomizedSearchCV) Score(X, Y *mat.Dense) float64 {
+	return searchScore(rscv.BestEstimator, rscv.Scorer, rscv.Scoring, rscv.Refit, X, Y)
 }
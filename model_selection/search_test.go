@@ -1,13 +1,21 @@
 package modelselection
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math"
+	"reflect"
 	"sort"
+	"testing"
+	"time"
 
+	"github.com/pa-m/sklearn/base"
 	"github.com/pa-m/sklearn/datasets"
+	lm "github.com/pa-m/sklearn/linear_model"
 	"github.com/pa-m/sklearn/metrics"
 	neuralnetwork "github.com/pa-m/sklearn/neural_network"
+	"github.com/pa-m/sklearn/pipeline"
 	"github.com/pa-m/sklearn/preprocessing"
 	"gonum.org/v1/gonum/mat"
 )
@@ -55,13 +63,13 @@ func ExampleParameterGrid() {
 }
 
 func ExampleGridSearchCV() {
-	randomState := int64(7)
+	randomState := base.NewRandomState(7)
 
 	ds := datasets.LoadBoston()
 	X, Y := preprocessing.NewStandardScaler().FitTransform(ds.X, ds.Y)
 
 	mlp := neuralnetwork.NewMLPRegressor([]int{20}, "relu", "adam", 1e-4)
-	mlp.RandomState = &randomState
+	mlp.RandomState = randomState
 	mlp.Shuffle = false
 	mlp.MiniBatchSize = 22
 	mlp.Epochs = 60
@@ -73,7 +81,7 @@ func ExampleGridSearchCV() {
 		ParamGrid:          map[string][]interface{}{"Alpha": {0, 1e-4}, "WeightDecay": {0, 0.1}},
 		Scorer:             scorer,
 		LowerScoreIsBetter: true,
-		CV:                 &KFold{NSplits: 3, RandomState: &randomState},
+		CV:                 &KFold{NSplits: 3, RandomState: randomState},
 		Verbose:            true,
 		NJobs:              -1}
 	gscv.Fit(X, Y)
@@ -83,3 +91,258 @@ func ExampleGridSearchCV() {
 	// Output:
 	// 0.0001 0.1
 }
+
+func TestGridSearchCVFitE(t *testing.T) {
+	X := mat.NewDense(6, 1, []float64{1, 2, 3, 4, 5, 6})
+	Y := mat.NewDense(6, 1, []float64{2, 4, 6, 8, 10, 12})
+	scorer := func(Y, Ypred *mat.Dense) float64 {
+		return metrics.MeanSquaredError(Y, Ypred, nil, "").At(0, 0)
+	}
+	gscv := &GridSearchCV{
+		Estimator:          lm.NewRidge(),
+		ParamGrid:          map[string][]interface{}{"Alphaa": {0., 1.}},
+		Scorer:             scorer,
+		LowerScoreIsBetter: true,
+		CV:                 &KFold{NSplits: 3},
+	}
+	var paramErr *base.ParamError
+	if err := gscv.FitE(X, Y); !errors.As(err, &paramErr) || paramErr.Param != "Alphaa" {
+		t.Errorf("expected ParamError on Alphaa, got %v", err)
+	}
+	gscv.ParamGrid = map[string][]interface{}{"Alpha": {"a"}}
+	if err := gscv.FitE(X, Y); !errors.As(err, &paramErr) || paramErr.Param != "Alpha" {
+		t.Errorf("expected ParamError on Alpha, got %v", err)
+	}
+}
+
+func ExampleGridSearchCV_pipeline() {
+	X := mat.NewDense(12, 1, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})
+	Y := mat.NewDense(12, 1, []float64{1, 4, 9, 16, 25, 36, 49, 64, 81, 100, 121, 144})
+	pl := pipeline.NewPipeline(
+		pipeline.NamedStep{Name: "poly", Step: preprocessing.NewPolynomialFeatures(1)},
+		pipeline.NamedStep{Name: "regr", Step: lm.NewLinearRegression()},
+	)
+	scorer := func(Y, Ypred *mat.Dense) float64 {
+		return metrics.MeanSquaredError(Y, Ypred, nil, "").At(0, 0)
+	}
+	gscv := &GridSearchCV{
+		Estimator:          pl,
+		ParamGrid:          map[string][]interface{}{"poly__Degree": {1, 2}, "poly__IncludeBias": {false}},
+		Scorer:             scorer,
+		LowerScoreIsBetter: true,
+		CV:                 &KFold{NSplits: 3},
+		NJobs:              1,
+	}
+	if err := gscv.FitE(X, Y); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(gscv.BestParams["poly__Degree"])
+	// Output:
+	// 2
+}
+
+func TestGridSearchCVRefit(t *testing.T) {
+	X := mat.NewDense(12, 1, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})
+	Y := mat.NewDense(12, 1, []float64{1.1, 3.9, 9.2, 16, 24.8, 36.1, 49, 63.9, 81.2, 100, 120.8, 144.1})
+	scorer := func(Y, Ypred *mat.Dense) float64 {
+		return metrics.MeanSquaredError(Y, Ypred, nil, "").At(0, 0)
+	}
+	newGridSearch := func() *GridSearchCV {
+		return &GridSearchCV{
+			Estimator:          lm.NewLinearRegression(),
+			ParamGrid:          map[string][]interface{}{"FitIntercept": {true, false}},
+			Scorer:             scorer,
+			LowerScoreIsBetter: true,
+			CV:                 &KFold{NSplits: 3},
+			NJobs:              1,
+		}
+	}
+	gscv := newGridSearch()
+	pl := pipeline.MakePipeline(preprocessing.NewPolynomialFeatures(2), gscv)
+	if err := pl.FitE(X, Y); err != nil {
+		t.Fatal(err)
+	}
+	expected := lm.NewLinearRegression()
+	if err := base.SetParams(expected, gscv.BestParams); err != nil {
+		t.Fatal(err)
+	}
+	Xpoly, _ := preprocessing.NewPolynomialFeatures(2).FitTransform(X, Y)
+	expected.Fit(Xpoly, Y)
+	if !mat.EqualApprox(expected.Coef, gscv.BestEstimator.(*lm.LinearRegression).Coef, 1e-8) {
+		t.Errorf("BestEstimator was not refitted on the whole data")
+	}
+	Ypred := mat.NewDense(12, 1, nil)
+	if err := pl.PredictE(X, Ypred); err != nil {
+		t.Fatal(err)
+	}
+	if mse := scorer(Y, Ypred); mse > .1 {
+		t.Errorf("unexpected mse %g", mse)
+	}
+
+	gscv = newGridSearch()
+	gscv.NoRefit = true
+	gscv.Fit(Xpoly, Y)
+	if mat.EqualApprox(expected.Coef, gscv.BestEstimator.(*lm.LinearRegression).Coef, 1e-8) {
+		t.Errorf("BestEstimator should be the best fold estimator when NoRefit is set")
+	}
+}
+
+func TestRandomizedSearchCV(t *testing.T) {
+	X := mat.NewDense(12, 1, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})
+	Y := mat.NewDense(12, 1, []float64{1.1, 3.9, 9.2, 16, 24.8, 36.1, 49, 63.9, 81.2, 100, 120.8, 144.1})
+	scorer := func(Y, Ypred *mat.Dense) float64 {
+		return metrics.MeanSquaredError(Y, Ypred, nil, "").At(0, 0)
+	}
+	randomState := base.NewRandomState(5)
+	newSearch := func() *RandomizedSearchCV {
+		return &RandomizedSearchCV{
+			Estimator: pipeline.NewPipeline(
+				pipeline.NamedStep{Name: "poly", Step: preprocessing.NewPolynomialFeatures(1)},
+				pipeline.NamedStep{Name: "regr", Step: lm.NewLinearRegression()},
+			),
+			ParamDistributions: map[string]Distribution{"poly__Degree": RandInt{Low: 1, High: 3}, "poly__IncludeBias": Choice{false}},
+			NIter:              6,
+			RandomState:        randomState,
+			Scorer:             scorer,
+			LowerScoreIsBetter: true,
+			CV:                 &KFold{NSplits: 3},
+			NJobs:              1,
+		}
+	}
+	rscv := newSearch()
+	if err := rscv.FitE(X, Y); err != nil {
+		t.Fatal(err)
+	}
+	if len(rscv.CVResults["params"]) != 6 {
+		t.Errorf("expected 6 candidates, got %d", len(rscv.CVResults["params"]))
+	}
+	if !reflect.DeepEqual(rscv.CVResults["params"][rscv.BestIndex], rscv.BestParams) || rscv.BestParams["poly__Degree"] != 2 {
+		t.Errorf("unexpected BestParams %v", rscv.BestParams)
+	}
+	Ypred := mat.NewDense(12, 1, nil)
+	if err := rscv.PredictE(X, Ypred); err != nil {
+		t.Fatal(err)
+	}
+	if mse := scorer(Y, Ypred); mse > .1 {
+		t.Errorf("unexpected mse %g", mse)
+	}
+	other := newSearch()
+	other.Fit(X, Y)
+	if !reflect.DeepEqual(rscv.CVResults["poly__Degree"], other.CVResults["poly__Degree"]) {
+		t.Errorf("RandomState did not make the search reproducible")
+	}
+
+	rscv = newSearch()
+	rscv.ParamDistributions["poly__Degree"] = RandInt{Low: 2, High: 2}
+	var paramErr *base.ParamError
+	if err := rscv.FitE(X, Y); !errors.As(err, &paramErr) || paramErr.Param != "ParamDistributions" {
+		t.Errorf("expected ParamError on ParamDistributions, got %v", err)
+	}
+}
+
+func TestGridSearchCVFitContext(t *testing.T) {
+	X := mat.NewDense(12, 1, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})
+	Y := mat.NewDense(12, 1, []float64{2.1, 3.9, 6.2, 8, 9.8, 12.1, 14, 15.9, 18.2, 20, 21.8, 24.1})
+	newSearch := func() *GridSearchCV {
+		return &GridSearchCV{
+			Estimator: lm.NewRidge(),
+			ParamGrid: map[string][]interface{}{"Alpha": {0., .1, 1., 10.}},
+			Scorer:    func(Y, Ypred *mat.Dense) float64 { return metrics.R2Score(Y, Ypred, nil, "").At(0, 0) },
+			CV:        &KFold{NSplits: 3},
+			NJobs:     1,
+		}
+	}
+	gscv := newSearch()
+	var candidates, splits int
+	ctx := base.WithProgress(context.Background(), base.ProgressFunc(func(p base.Progress) {
+		switch p.Estimator.(type) {
+		case *GridSearchCV:
+			candidates++
+			if p.Epochs != 4 || math.IsNaN(p.ValidationScore) {
+				t.Errorf("unexpected candidate progress %+v", p)
+			}
+		case *lm.RegularizedRegression:
+			if p.Epochs == 3 {
+				splits++
+			}
+		}
+	}))
+	if err := gscv.FitContext(ctx, X, Y); err != nil {
+		t.Fatal(err)
+	}
+	if candidates != 4 || splits != 12 || gscv.BestEstimator == nil {
+		t.Errorf("expected 4 candidates and 12 splits reported, got %d and %d", candidates, splits)
+	}
+
+	gscv = newSearch()
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = base.WithProgress(ctx, base.ProgressFunc(func(p base.Progress) {
+		if p.Estimator == gscv {
+			cancel()
+		}
+	}))
+	if err := gscv.FitContext(ctx, X, Y); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if _, err := CrossValidateContext(ctx, lm.NewRidge(), X, Y, nil, gscv.Scorer, &KFold{NSplits: 3}, 2); !errors.Is(err, context.Canceled) {
+		t.Errorf("CrossValidateContext: expected context.Canceled, got %v", err)
+	}
+	deadline, cancelDeadline := context.WithTimeout(context.Background(), -time.Second)
+	defer cancelDeadline()
+	if err := newSearch().FitContext(deadline, X, Y); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+// alphaRegressor predicts Alpha for every sample
+type alphaRegressor struct{ Alpha float64 }
+
+func (m *alphaRegressor) Clone() base.Transformer              { clone := *m; return &clone }
+func (m *alphaRegressor) Fit(X, Y *mat.Dense) base.Transformer { return m }
+func (m *alphaRegressor) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
+	nSamples, nOutputs := Y.Dims()
+	Yout = mat.NewDense(nSamples, nOutputs, nil)
+	Yout.Apply(func(_, _ int, _ float64) float64 { return m.Alpha }, Yout)
+	return X, Yout
+}
+
+// shallowWrapper is a meta estimator whose clones share Estimator
+type shallowWrapper struct{ Estimator base.Transformer }
+
+func (m *shallowWrapper) Clone() base.Transformer { clone := *m; return &clone }
+func (m *shallowWrapper) Fit(X, Y *mat.Dense) base.Transformer {
+	m.Estimator.Fit(X, Y)
+	return m
+}
+func (m *shallowWrapper) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
+	return m.Estimator.Transform(X, Y)
+}
+
+func TestGridSearchCVNestedParams(t *testing.T) {
+	X := mat.NewDense(12, 1, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})
+	Y := mat.DenseCopyOf(X)
+	inner := &alphaRegressor{Alpha: 1}
+	alphas := []interface{}{.1, 5., 50., 500.}
+	gscv := &GridSearchCV{
+		Estimator: &shallowWrapper{Estimator: inner},
+		ParamGrid: map[string][]interface{}{"Estimator__Alpha": alphas},
+		// the mean prediction is the Alpha of the candidate
+		Scorer: func(Ytrue, Ypred *mat.Dense) float64 { return mat.Sum(Ypred) / float64(len(Ypred.RawMatrix().Data)) },
+		CV:     &PurgedKFold{NSplits: 3},
+		NJobs:  4,
+	}
+	if err := gscv.FitE(X, Y); err != nil {
+		t.Fatal(err)
+	}
+	if inner.Alpha != 1 {
+		t.Errorf("expected the original estimator untouched, got Alpha %g", inner.Alpha)
+	}
+	for c, alpha := range gscv.CVResults["Estimator__Alpha"] {
+		if score := gscv.CVResults["mean_test_score"][c].(float64); math.Abs(score-alpha.(float64)) > 1e-10 {
+			t.Errorf("candidate %d: expected score %g for Alpha %g, got %g", c, alpha, alpha, score)
+		}
+	}
+	if alpha := gscv.BestEstimator.(*shallowWrapper).Estimator.(*alphaRegressor).Alpha; alpha != 500 {
+		t.Errorf("expected the refitted Alpha 500, got %g", alpha)
+	}
+}
@@ -1,13 +1,17 @@
 package modelselection
 
 import (
+	"fmt"
+	"math"
 	"math/rand"
+	"sort"
 
+	"github.com/pa-m/sklearn/base"
 	"gonum.org/v1/gonum/mat"
 )
 
-// RandomState is to init a new random source for reproducibility
-type RandomState = int64
+// RandomState is base.RandomState, the seed or generator making splitters and searches reproducible
+type RandomState = base.RandomState
 
 // KFold ...
 type KFold struct {
@@ -43,13 +47,8 @@ func (splitter *KFold) Split(X, Y *mat.Dense) (ch chan Split) {
 	}
 	NSamples, _ := X.Dims()
 
-	Shuffle, intn := rand.Shuffle, rand.Intn
-	if splitter.RandomState != nil {
-		source := rand.NewSource(int64(*splitter.RandomState))
-		r := rand.New(source)
-		Shuffle, intn = r.Shuffle, r.Intn
-
-	}
+	r := newRand(splitter.RandomState)
+	Shuffle, intn := r.Shuffle, r.Intn
 
 	ch = make(chan Split)
 	go func() {
@@ -89,3 +88,927 @@ func (splitter *KFold) Split(X, Y *mat.Dense) (ch chan Split) {
 func (splitter *KFold) GetNSplits(X, Y *mat.Dense) int {
 	return splitter.NSplits
 }
+
+// GroupSplitter is implemented by splitters using the groups of the samples.
+// WithGroups returns a copy of the splitter using groups.
+// CrossValidate calls it with its groups argument, so these splitters can also be given groups through CrossValidate
+type GroupSplitter interface {
+	Splitter
+	WithGroups(groups []int) Splitter
+}
+
+var (
+	_ Splitter      = &StratifiedKFold{}
+	_ GroupSplitter = &GroupKFold{}
+	_ GroupSplitter = &StratifiedGroupKFold{}
+	_ Splitter      = &ShuffleSplit{}
+	_ Splitter      = &StratifiedShuffleSplit{}
+	_ Splitter      = &LeaveOneOut{}
+	_ Splitter      = &LeavePOut{}
+	_ GroupSplitter = &LeaveOneGroupOut{}
+	_ Splitter      = &RepeatedKFold{}
+	_ Splitter      = &RepeatedStratifiedKFold{}
+	_ Splitter      = &PredefinedSplit{}
+	_ Splitter      = &TimeSeriesSplit{}
+	_ Splitter      = &PurgedKFold{}
+)
+
+// sendSplits returns a channel sending splits
+func sendSplits(splits []Split) (ch chan Split) {
+	ch = make(chan Split)
+	go func() {
+		for _, sp := range splits {
+			ch <- sp
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+// foldsSplits returns the splits where the test samples of split i are those of fold i. samples of fold -1 are always in train
+func foldsSplits(testFold []int, nFolds int) []Split {
+	splits := make([]Split, nFolds)
+	for sample, fold := range testFold {
+		for i := range splits {
+			if i == fold {
+				splits[i].TestIndex = append(splits[i].TestIndex, sample)
+			} else {
+				splits[i].TrainIndex = append(splits[i].TrainIndex, sample)
+			}
+		}
+	}
+	return splits
+}
+
+// classLabels returns the class of each row of Y, numbered in the order of the sorted distinct rows, and the number of classes.
+// Y usually has one column of labels, rows of one-hot encoded Y are also distinct classes
+func classLabels(op string, Y *mat.Dense) (y []int, nClasses int) {
+	if Y == nil {
+		panic(fmt.Errorf("%s: Y is required to stratify", op))
+	}
+	nSamples, nOutputs := Y.Dims()
+	keys := make([]string, nSamples)
+	for i := range keys {
+		keys[i] = fmt.Sprint(Y.RawRowView(i))
+	}
+	order := make([]int, nSamples)
+	for i := range order {
+		order[i] = i
+	}
+	less := func(a, b int) bool {
+		ra, rb := Y.RawRowView(a), Y.RawRowView(b)
+		for j := 0; j < nOutputs; j++ {
+			if ra[j] != rb[j] {
+				return ra[j] < rb[j]
+			}
+		}
+		return false
+	}
+	sort.SliceStable(order, func(i, j int) bool { return less(order[i], order[j]) })
+	y = make([]int, nSamples)
+	classes := make(map[string]int)
+	for _, i := range order {
+		c, ok := classes[keys[i]]
+		if !ok {
+			c = len(classes)
+			classes[keys[i]] = c
+		}
+		y[i] = c
+	}
+	return y, len(classes)
+}
+
+// uniqueGroups returns the sorted distinct values of groups and the index of the group of each sample
+func uniqueGroups(groups []int) (unique []int, groupIndex []int) {
+	seen := make(map[int]bool)
+	for _, g := range groups {
+		if !seen[g] {
+			seen[g] = true
+			unique = append(unique, g)
+		}
+	}
+	sort.Ints(unique)
+	index := make(map[int]int, len(unique))
+	for i, g := range unique {
+		index[g] = i
+	}
+	groupIndex = make([]int, len(groups))
+	for i, g := range groups {
+		groupIndex[i] = index[g]
+	}
+	return
+}
+
+func checkGroups(op string, X *mat.Dense, groups []int) {
+	if groups == nil {
+		panic(fmt.Errorf("%s: groups are required", op))
+	}
+	if NSamples, _ := X.Dims(); len(groups) != NSamples {
+		panic(fmt.Errorf("%s: %d groups for %d samples", op, len(groups), NSamples))
+	}
+}
+
+// StratifiedKFold is KFold preserving the proportion of each class of Y in each fold.
+// NSplits defaults to 3. if Shuffle is set, samples of each class are shuffled using RandomState before being dispatched
+type StratifiedKFold struct {
+	NSplits     int
+	Shuffle     bool
+	RandomState *RandomState
+}
+
+// Clone ...
+func (splitter *StratifiedKFold) Clone() Splitter {
+	clone := *splitter
+	return &clone
+}
+
+// GetNSplits for StratifiedKFold
+func (splitter *StratifiedKFold) GetNSplits(X, Y *mat.Dense) int {
+	if splitter.NSplits <= 0 {
+		return 3
+	}
+	return splitter.NSplits
+}
+
+// Split generate Split structs
+func (splitter *StratifiedKFold) Split(X, Y *mat.Dense) (ch chan Split) {
+	nSplits := splitter.GetNSplits(X, Y)
+	return sendSplits(foldsSplits(stratifiedTestFolds("StratifiedKFold", Y, nSplits, splitter.Shuffle, newRand(splitter.RandomState)), nSplits))
+}
+
+// stratifiedTestFolds returns the test fold of each sample so that each class is dispatched evenly in the folds
+func stratifiedTestFolds(op string, Y *mat.Dense, nSplits int, shuffle bool, r *rand.Rand) []int {
+	y, nClasses := classLabels(op, Y)
+	// allocation[fold][class] is the number of samples of class in fold, as if the sorted labels were dealt to the folds
+	sorted := append([]int(nil), y...)
+	sort.Ints(sorted)
+	allocation := make([][]int, nSplits)
+	for fold := range allocation {
+		allocation[fold] = make([]int, nClasses)
+		for i := fold; i < len(sorted); i += nSplits {
+			allocation[fold][sorted[i]]++
+		}
+	}
+	testFold := make([]int, len(y))
+	for class := 0; class < nClasses; class++ {
+		var folds []int
+		for fold := range allocation {
+			for i := 0; i < allocation[fold][class]; i++ {
+				folds = append(folds, fold)
+			}
+		}
+		if shuffle {
+			r.Shuffle(len(folds), func(i, j int) { folds[i], folds[j] = folds[j], folds[i] })
+		}
+		next := 0
+		for sample, c := range y {
+			if c == class {
+				testFold[sample] = folds[next]
+				next++
+			}
+		}
+	}
+	return testFold
+}
+
+// GroupKFold is KFold where the samples of a group are in the same fold, so a group is never in both train and test.
+// groups are dispatched from the largest to the folds having the fewest samples. NSplits defaults to 3
+type GroupKFold struct {
+	NSplits int
+	Groups  []int
+}
+
+// Clone ...
+func (splitter *GroupKFold) Clone() Splitter {
+	clone := *splitter
+	return &clone
+}
+
+// WithGroups returns a GroupKFold using groups
+func (splitter *GroupKFold) WithGroups(groups []int) Splitter {
+	clone := *splitter
+	clone.Groups = groups
+	return &clone
+}
+
+// GetNSplits for GroupKFold
+func (splitter *GroupKFold) GetNSplits(X, Y *mat.Dense) int {
+	if splitter.NSplits <= 0 {
+		return 3
+	}
+	return splitter.NSplits
+}
+
+// Split generate Split structs
+func (splitter *GroupKFold) Split(X, Y *mat.Dense) (ch chan Split) {
+	checkGroups("GroupKFold", X, splitter.Groups)
+	nSplits := splitter.GetNSplits(X, Y)
+	unique, groupIndex := uniqueGroups(splitter.Groups)
+	if len(unique) < nSplits {
+		panic(fmt.Errorf("GroupKFold: NSplits=%d is greater than the number of groups %d", nSplits, len(unique)))
+	}
+	sizes := make([]int, len(unique))
+	for _, g := range groupIndex {
+		sizes[g]++
+	}
+	order := make([]int, len(unique))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return sizes[order[i]] > sizes[order[j]] })
+	foldSizes := make([]int, nSplits)
+	groupFold := make([]int, len(unique))
+	for _, g := range order {
+		lightest := 0
+		for fold, size := range foldSizes {
+			if size < foldSizes[lightest] {
+				lightest = fold
+			}
+		}
+		groupFold[g] = lightest
+		foldSizes[lightest] += sizes[g]
+	}
+	testFold := make([]int, len(groupIndex))
+	for sample, g := range groupIndex {
+		testFold[sample] = groupFold[g]
+	}
+	return sendSplits(foldsSplits(testFold, nSplits))
+}
+
+// StratifiedGroupKFold is GroupKFold trying to preserve the proportion of each class of Y in each fold.
+// groups are taken by decreasing variance of their class distribution (shuffled first if Shuffle is set)
+// and each is put in the fold where it best balances the classes. NSplits defaults to 3
+type StratifiedGroupKFold struct {
+	NSplits     int
+	Shuffle     bool
+	RandomState *RandomState
+	Groups      []int
+}
+
+// Clone ...
+func (splitter *StratifiedGroupKFold) Clone() Splitter {
+	clone := *splitter
+	return &clone
+}
+
+// WithGroups returns a StratifiedGroupKFold using groups
+func (splitter *StratifiedGroupKFold) WithGroups(groups []int) Splitter {
+	clone := *splitter
+	clone.Groups = groups
+	return &clone
+}
+
+// GetNSplits for StratifiedGroupKFold
+func (splitter *StratifiedGroupKFold) GetNSplits(X, Y *mat.Dense) int {
+	if splitter.NSplits <= 0 {
+		return 3
+	}
+	return splitter.NSplits
+}
+
+// Split generate Split structs
+func (splitter *StratifiedGroupKFold) Split(X, Y *mat.Dense) (ch chan Split) {
+	checkGroups("StratifiedGroupKFold", X, splitter.Groups)
+	nSplits := splitter.GetNSplits(X, Y)
+	y, nClasses := classLabels("StratifiedGroupKFold", Y)
+	unique, groupIndex := uniqueGroups(splitter.Groups)
+	if len(unique) < nSplits {
+		panic(fmt.Errorf("StratifiedGroupKFold: NSplits=%d is greater than the number of groups %d", nSplits, len(unique)))
+	}
+	classCounts := make([]float64, nClasses)
+	groupCounts := make([][]float64, len(unique))
+	for g := range groupCounts {
+		groupCounts[g] = make([]float64, nClasses)
+	}
+	for sample, g := range groupIndex {
+		groupCounts[g][y[sample]]++
+		classCounts[y[sample]]++
+	}
+	order := make([]int, len(unique))
+	for i := range order {
+		order[i] = i
+	}
+	if splitter.Shuffle {
+		r := newRand(splitter.RandomState)
+		r.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		_, vi := meanStd(groupCounts[order[i]])
+		_, vj := meanStd(groupCounts[order[j]])
+		return vi > vj
+	})
+	foldCounts := make([][]float64, nSplits)
+	for fold := range foldCounts {
+		foldCounts[fold] = make([]float64, nClasses)
+	}
+	foldSize := func(fold int) (n float64) {
+		for _, c := range foldCounts[fold] {
+			n += c
+		}
+		return
+	}
+	// evalFold returns the mean over classes of the std over folds of the class proportions, if group is put in fold
+	evalFold := func(g, fold int) float64 {
+		proportions := make([]float64, nSplits)
+		total := 0.
+		for class := 0; class < nClasses; class++ {
+			for f := range proportions {
+				proportions[f] = foldCounts[f][class] / classCounts[class]
+			}
+			proportions[fold] += groupCounts[g][class] / classCounts[class]
+			_, std := meanStd(proportions)
+			total += std
+		}
+		return total / float64(nClasses)
+	}
+	groupFold := make([]int, len(unique))
+	for _, g := range order {
+		best, bestEval := -1, 0.
+		for fold := 0; fold < nSplits; fold++ {
+			eval := evalFold(g, fold)
+			if best < 0 || eval < bestEval-1e-12 || (math.Abs(eval-bestEval) <= 1e-12 && foldSize(fold) < foldSize(best)) {
+				best, bestEval = fold, eval
+			}
+		}
+		groupFold[g] = best
+		for class, c := range groupCounts[g] {
+			foldCounts[best][class] += c
+		}
+	}
+	testFold := make([]int, len(groupIndex))
+	for sample, g := range groupIndex {
+		testFold[sample] = groupFold[g]
+	}
+	return sendSplits(foldsSplits(testFold, nSplits))
+}
+
+// splitSizes returns the train and test sizes of a shuffle split. sizes in (0,1) are fractions of nSamples, others are counts.
+// TestSize defaults to 0.1, TrainSize to the complement of TestSize
+func splitSizes(op string, nSamples int, trainSize, testSize float64) (nTrain, nTest int) {
+	if testSize <= 0 && trainSize <= 0 {
+		testSize = .1
+	}
+	size := func(s float64, ceil bool) int {
+		if s < 1 {
+			if ceil {
+				return int(math.Ceil(s * float64(nSamples)))
+			}
+			return int(math.Floor(s * float64(nSamples)))
+		}
+		return int(s)
+	}
+	if testSize > 0 {
+		nTest = size(testSize, true)
+	}
+	if trainSize > 0 {
+		nTrain = size(trainSize, false)
+	}
+	if testSize <= 0 {
+		nTest = nSamples - nTrain
+	}
+	if trainSize <= 0 {
+		nTrain = nSamples - nTest
+	}
+	if nTrain <= 0 || nTest <= 0 || nTrain+nTest > nSamples {
+		panic(fmt.Errorf("%s: invalid sizes train=%d test=%d for %d samples", op, nTrain, nTest, nSamples))
+	}
+	return
+}
+
+// ShuffleSplit generates NSplits independent random splits (test sets may overlap).
+// TrainSize and TestSize are fractions of the samples if in (0,1), else numbers of samples. see splitSizes for defaults.
+// NSplits defaults to 10
+type ShuffleSplit struct {
+	NSplits             int
+	TrainSize, TestSize float64
+	RandomState         *RandomState
+}
+
+// Clone ...
+func (splitter *ShuffleSplit) Clone() Splitter {
+	clone := *splitter
+	return &clone
+}
+
+// GetNSplits for ShuffleSplit
+func (splitter *ShuffleSplit) GetNSplits(X, Y *mat.Dense) int {
+	if splitter.NSplits <= 0 {
+		return 10
+	}
+	return splitter.NSplits
+}
+
+// Split generate Split structs
+func (splitter *ShuffleSplit) Split(X, Y *mat.Dense) (ch chan Split) {
+	NSamples, _ := X.Dims()
+	nTrain, nTest := splitSizes("ShuffleSplit", NSamples, splitter.TrainSize, splitter.TestSize)
+	r := newRand(splitter.RandomState)
+	splits := make([]Split, splitter.GetNSplits(X, Y))
+	for i := range splits {
+		perm := r.Perm(NSamples)
+		splits[i] = Split{TrainIndex: perm[nTest : nTest+nTrain], TestIndex: perm[:nTest]}
+	}
+	return sendSplits(splits)
+}
+
+// StratifiedShuffleSplit is ShuffleSplit preserving the proportion of each class of Y in train and test sets
+type StratifiedShuffleSplit struct {
+	NSplits             int
+	TrainSize, TestSize float64
+	RandomState         *RandomState
+}
+
+// Clone ...
+func (splitter *StratifiedShuffleSplit) Clone() Splitter {
+	clone := *splitter
+	return &clone
+}
+
+// GetNSplits for StratifiedShuffleSplit
+func (splitter *StratifiedShuffleSplit) GetNSplits(X, Y *mat.Dense) int {
+	if splitter.NSplits <= 0 {
+		return 10
+	}
+	return splitter.NSplits
+}
+
+// Split generate Split structs
+func (splitter *StratifiedShuffleSplit) Split(X, Y *mat.Dense) (ch chan Split) {
+	NSamples, _ := X.Dims()
+	nTrain, nTest := splitSizes("StratifiedShuffleSplit", NSamples, splitter.TrainSize, splitter.TestSize)
+	y, nClasses := classLabels("StratifiedShuffleSplit", Y)
+	if nTrain < nClasses || nTest < nClasses {
+		panic(fmt.Errorf("StratifiedShuffleSplit: train=%d and test=%d must be at least the number of classes %d", nTrain, nTest, nClasses))
+	}
+	classIndices := make([][]int, nClasses)
+	for sample, c := range y {
+		classIndices[c] = append(classIndices[c], sample)
+	}
+	classCounts := make([]int, nClasses)
+	for c, indices := range classIndices {
+		classCounts[c] = len(indices)
+	}
+	r := newRand(splitter.RandomState)
+	splits := make([]Split, splitter.GetNSplits(X, Y))
+	for i := range splits {
+		trainCounts := approximateMode(classCounts, nTrain, r)
+		remaining := make([]int, nClasses)
+		for c := range remaining {
+			remaining[c] = classCounts[c] - trainCounts[c]
+		}
+		testCounts := approximateMode(remaining, nTest, r)
+		var sp Split
+		for c, indices := range classIndices {
+			perm := r.Perm(len(indices))
+			for j, p := range perm {
+				switch {
+				case j < trainCounts[c]:
+					sp.TrainIndex = append(sp.TrainIndex, indices[p])
+				case j < trainCounts[c]+testCounts[c]:
+					sp.TestIndex = append(sp.TestIndex, indices[p])
+				}
+			}
+		}
+		r.Shuffle(len(sp.TrainIndex), func(i, j int) { sp.TrainIndex[i], sp.TrainIndex[j] = sp.TrainIndex[j], sp.TrainIndex[i] })
+		r.Shuffle(len(sp.TestIndex), func(i, j int) { sp.TestIndex[i], sp.TestIndex[j] = sp.TestIndex[j], sp.TestIndex[i] })
+		splits[i] = sp
+	}
+	return sendSplits(splits)
+}
+
+// approximateMode returns n draws from classCounts without replacement, as close as possible to the proportions of classCounts.
+// the remainder is given to the classes with the largest fractional parts, ties are broken at random
+func approximateMode(classCounts []int, n int, r *rand.Rand) []int {
+	total := 0
+	for _, c := range classCounts {
+		total += c
+	}
+	counts := make([]int, len(classCounts))
+	remainders := make([]float64, len(classCounts))
+	drawn := 0
+	for c, count := range classCounts {
+		exact := float64(count) * float64(n) / float64(total)
+		counts[c] = int(math.Floor(exact))
+		remainders[c] = exact - float64(counts[c])
+		drawn += counts[c]
+	}
+	order := r.Perm(len(classCounts))
+	sort.SliceStable(order, func(i, j int) bool { return remainders[order[i]] > remainders[order[j]] })
+	for _, c := range order {
+		if drawn >= n {
+			break
+		}
+		if counts[c] < classCounts[c] {
+			counts[c]++
+			drawn++
+		}
+	}
+	return counts
+}
+
+// LeaveOneOut has one split for each sample, with this sample as test set
+type LeaveOneOut struct{}
+
+// Clone ...
+func (splitter *LeaveOneOut) Clone() Splitter {
+	return &LeaveOneOut{}
+}
+
+// GetNSplits for LeaveOneOut is the number of samples
+func (splitter *LeaveOneOut) GetNSplits(X, Y *mat.Dense) int {
+	NSamples, _ := X.Dims()
+	return NSamples
+}
+
+// Split generate Split structs
+func (splitter *LeaveOneOut) Split(X, Y *mat.Dense) (ch chan Split) {
+	return (&LeavePOut{P: 1}).Split(X, Y)
+}
+
+// LeavePOut has one split for each combination of P samples, with these samples as test set.
+// the number of splits grows quickly with the number of samples
+type LeavePOut struct {
+	P int
+}
+
+// Clone ...
+func (splitter *LeavePOut) Clone() Splitter {
+	clone := *splitter
+	return &clone
+}
+
+// GetNSplits for LeavePOut is the number of combinations of P samples
+func (splitter *LeavePOut) GetNSplits(X, Y *mat.Dense) int {
+	NSamples, _ := X.Dims()
+	if splitter.P <= 0 || splitter.P >= NSamples {
+		return 0
+	}
+	n := 1
+	for i := 0; i < splitter.P; i++ {
+		n = n * (NSamples - i) / (i + 1)
+	}
+	return n
+}
+
+// Split generate Split structs
+func (splitter *LeavePOut) Split(X, Y *mat.Dense) (ch chan Split) {
+	NSamples, _ := X.Dims()
+	P := splitter.P
+	if P <= 0 || P >= NSamples {
+		panic(fmt.Errorf("LeavePOut: P=%d must be in [1,%d)", P, NSamples))
+	}
+	ch = make(chan Split)
+	go func() {
+		comb := make([]int, P)
+		for i := range comb {
+			comb[i] = i
+		}
+		for {
+			sp := Split{TestIndex: append([]int(nil), comb...)}
+			next := 0
+			for sample := 0; sample < NSamples; sample++ {
+				if next < P && comb[next] == sample {
+					next++
+					continue
+				}
+				sp.TrainIndex = append(sp.TrainIndex, sample)
+			}
+			ch <- sp
+			// next combination in lexicographic order
+			i := P - 1
+			for i >= 0 && comb[i] == NSamples-P+i {
+				i--
+			}
+			if i < 0 {
+				break
+			}
+			comb[i]++
+			for j := i + 1; j < P; j++ {
+				comb[j] = comb[j-1] + 1
+			}
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+// LeaveOneGroupOut has one split for each distinct group, with the samples of this group as test set
+type LeaveOneGroupOut struct {
+	Groups []int
+}
+
+// Clone ...
+func (splitter *LeaveOneGroupOut) Clone() Splitter {
+	clone := *splitter
+	return &clone
+}
+
+// WithGroups returns a LeaveOneGroupOut using groups
+func (splitter *LeaveOneGroupOut) WithGroups(groups []int) Splitter {
+	return &LeaveOneGroupOut{Groups: groups}
+}
+
+// GetNSplits for LeaveOneGroupOut is the number of distinct groups
+func (splitter *LeaveOneGroupOut) GetNSplits(X, Y *mat.Dense) int {
+	unique, _ := uniqueGroups(splitter.Groups)
+	return len(unique)
+}
+
+// Split generate Split structs
+func (splitter *LeaveOneGroupOut) Split(X, Y *mat.Dense) (ch chan Split) {
+	checkGroups("LeaveOneGroupOut", X, splitter.Groups)
+	unique, groupIndex := uniqueGroups(splitter.Groups)
+	if len(unique) < 2 {
+		panic(fmt.Errorf("LeaveOneGroupOut: at least 2 groups are required"))
+	}
+	return sendSplits(foldsSplits(groupIndex, len(unique)))
+}
+
+// RepeatedKFold repeats a shuffled k-fold NRepeats times with different randomization.
+// NSplits defaults to 3, NRepeats to 10
+type RepeatedKFold struct {
+	NSplits, NRepeats int
+	RandomState       *RandomState
+}
+
+// Clone ...
+func (splitter *RepeatedKFold) Clone() Splitter {
+	clone := *splitter
+	return &clone
+}
+
+// GetNSplits for RepeatedKFold is NSplits*NRepeats
+func (splitter *RepeatedKFold) GetNSplits(X, Y *mat.Dense) int {
+	return repeatedNSplits(splitter.NSplits, splitter.NRepeats)
+}
+
+// Split generate Split structs
+func (splitter *RepeatedKFold) Split(X, Y *mat.Dense) (ch chan Split) {
+	NSamples, _ := X.Dims()
+	nSplits := repeatedNSplits(splitter.NSplits, 1)
+	return repeatSplits(splitter.NRepeats, splitter.RandomState, func(r *rand.Rand) []Split {
+		// each sample is in the test set of exactly one fold
+		testFold := make([]int, NSamples)
+		for pos, sample := range r.Perm(NSamples) {
+			testFold[sample] = pos % nSplits
+		}
+		return foldsSplits(testFold, nSplits)
+	})
+}
+
+// RepeatedStratifiedKFold repeats a shuffled StratifiedKFold NRepeats times with different randomization.
+// NSplits defaults to 3, NRepeats to 10
+type RepeatedStratifiedKFold struct {
+	NSplits, NRepeats int
+	RandomState       *RandomState
+}
+
+// Clone ...
+func (splitter *RepeatedStratifiedKFold) Clone() Splitter {
+	clone := *splitter
+	return &clone
+}
+
+// GetNSplits for RepeatedStratifiedKFold is NSplits*NRepeats
+func (splitter *RepeatedStratifiedKFold) GetNSplits(X, Y *mat.Dense) int {
+	return repeatedNSplits(splitter.NSplits, splitter.NRepeats)
+}
+
+// Split generate Split structs
+func (splitter *RepeatedStratifiedKFold) Split(X, Y *mat.Dense) (ch chan Split) {
+	nSplits := repeatedNSplits(splitter.NSplits, 1)
+	return repeatSplits(splitter.NRepeats, splitter.RandomState, func(r *rand.Rand) []Split {
+		return foldsSplits(stratifiedTestFolds("RepeatedStratifiedKFold", Y, nSplits, true, r), nSplits)
+	})
+}
+
+func repeatedNSplits(nSplits, nRepeats int) int {
+	if nSplits <= 0 {
+		nSplits = 3
+	}
+	if nRepeats <= 0 {
+		nRepeats = 10
+	}
+	return nSplits * nRepeats
+}
+
+// repeatSplits sends the splits returned by nRepeats calls of splits, all using the same generator seeded with randomState
+func repeatSplits(nRepeats int, randomState *RandomState, splits func(r *rand.Rand) []Split) (ch chan Split) {
+	if nRepeats <= 0 {
+		nRepeats = 10
+	}
+	r := newRand(randomState)
+	var all []Split
+	for i := 0; i < nRepeats; i++ {
+		all = append(all, splits(r)...)
+	}
+	return sendSplits(all)
+}
+
+// PredefinedSplit uses the fold of each sample given by TestFold. there is one split per distinct fold >=0, with the samples
+// of this fold as test set. samples with a TestFold of -1 are always in the train set
+type PredefinedSplit struct {
+	TestFold []int
+}
+
+// Clone ...
+func (splitter *PredefinedSplit) Clone() Splitter {
+	clone := *splitter
+	return &clone
+}
+
+// GetNSplits for PredefinedSplit is the number of distinct folds >=0
+func (splitter *PredefinedSplit) GetNSplits(X, Y *mat.Dense) int {
+	unique, _ := splitter.folds()
+	return len(unique)
+}
+
+func (splitter *PredefinedSplit) folds() (unique, testFold []int) {
+	var folds []int
+	for _, f := range splitter.TestFold {
+		if f >= 0 {
+			folds = append(folds, f)
+		}
+	}
+	unique, _ = uniqueGroups(folds)
+	index := make(map[int]int, len(unique))
+	for i, f := range unique {
+		index[f] = i
+	}
+	testFold = make([]int, len(splitter.TestFold))
+	for i, f := range splitter.TestFold {
+		testFold[i] = -1
+		if f >= 0 {
+			testFold[i] = index[f]
+		}
+	}
+	return
+}
+
+// Split generate Split structs
+func (splitter *PredefinedSplit) Split(X, Y *mat.Dense) (ch chan Split) {
+	if NSamples, _ := X.Dims(); len(splitter.TestFold) != NSamples {
+		panic(fmt.Errorf("PredefinedSplit: %d folds for %d samples", len(splitter.TestFold), NSamples))
+	}
+	unique, testFold := splitter.folds()
+	return sendSplits(foldsSplits(testFold, len(unique)))
+}
+
+// TimeSeriesSplit splits time ordered samples so that test samples always follow train samples.
+// split i tests the i-th of NSplits consecutive blocks of TestSize samples ending with the last sample, and trains on the
+// samples before it (expanding window), or on the last MaxTrainSize of them if MaxTrainSize>0 (sliding window).
+// Gap samples are left out between the train and test sets.
+// NSplits defaults to 5, TestSize to NSamples/(NSplits+1)
+type TimeSeriesSplit struct {
+	NSplits      int
+	MaxTrainSize int
+	TestSize     int
+	Gap          int
+}
+
+// Clone ...
+func (splitter *TimeSeriesSplit) Clone() Splitter {
+	clone := *splitter
+	return &clone
+}
+
+// GetNSplits for TimeSeriesSplit
+func (splitter *TimeSeriesSplit) GetNSplits(X, Y *mat.Dense) int {
+	if splitter.NSplits <= 0 {
+		return 5
+	}
+	return splitter.NSplits
+}
+
+// Split generate Split structs
+func (splitter *TimeSeriesSplit) Split(X, Y *mat.Dense) (ch chan Split) {
+	NSamples, _ := X.Dims()
+	nSplits := splitter.GetNSplits(X, Y)
+	testSize := splitter.TestSize
+	if testSize <= 0 {
+		testSize = NSamples / (nSplits + 1)
+	}
+	if testSize <= 0 || NSamples-splitter.Gap-testSize*nSplits <= 0 {
+		panic(fmt.Errorf("TimeSeriesSplit: too many splits (%d) for %d samples with TestSize=%d and Gap=%d", nSplits, NSamples, testSize, splitter.Gap))
+	}
+	splits := make([]Split, nSplits)
+	for i := range splits {
+		testStart := NSamples - (nSplits-i)*testSize
+		trainEnd := testStart - splitter.Gap
+		trainStart := 0
+		if splitter.MaxTrainSize > 0 && splitter.MaxTrainSize < trainEnd {
+			trainStart = trainEnd - splitter.MaxTrainSize
+		}
+		splits[i] = Split{TrainIndex: rangeIndices(trainStart, trainEnd), TestIndex: rangeIndices(testStart, testStart+testSize)}
+	}
+	return sendSplits(splits)
+}
+
+func rangeIndices(start, end int) []int {
+	indices := make([]int, 0, end-start)
+	for i := start; i < end; i++ {
+		indices = append(indices, i)
+	}
+	return indices
+}
+
+// PurgedKFold is an unshuffled KFold for time ordered samples whose labels overlap, like returns over a horizon.
+// LabelEnd[i] is the index of the last sample the label of sample i depends on (i itself if LabelEnd is nil).
+// train samples whose label interval overlaps the test samples labels are purged, and the Embargo samples following the
+// test set are removed from the train set, so that no information leaks from test to train.
+// NSplits defaults to 3
+type PurgedKFold struct {
+	NSplits  int
+	LabelEnd []int
+	Embargo  int
+}
+
+// Clone ...
+func (splitter *PurgedKFold) Clone() Splitter {
+	clone := *splitter
+	return &clone
+}
+
+// GetNSplits for PurgedKFold
+func (splitter *PurgedKFold) GetNSplits(X, Y *mat.Dense) int {
+	if splitter.NSplits <= 0 {
+		return 3
+	}
+	return splitter.NSplits
+}
+
+// Split generate Split structs
+func (splitter *PurgedKFold) Split(X, Y *mat.Dense) (ch chan Split) {
+	NSamples, _ := X.Dims()
+	nSplits := splitter.GetNSplits(X, Y)
+	if nSplits > NSamples {
+		panic(fmt.Errorf("PurgedKFold: NSplits=%d is greater than the number of samples %d", nSplits, NSamples))
+	}
+	labelEnd := splitter.LabelEnd
+	if labelEnd == nil {
+		labelEnd = rangeIndices(0, NSamples)
+	}
+	if len(labelEnd) != NSamples {
+		panic(fmt.Errorf("PurgedKFold: %d label ends for %d samples", len(labelEnd), NSamples))
+	}
+	splits := make([]Split, nSplits)
+	testStart := 0
+	for i := range splits {
+		testSize := NSamples / nSplits
+		if i < NSamples%nSplits {
+			testSize++
+		}
+		testEnd := testStart + testSize
+		testLabelEnd := testEnd - 1
+		for _, end := range labelEnd[testStart:testEnd] {
+			if end > testLabelEnd {
+				testLabelEnd = end
+			}
+		}
+		sp := Split{TestIndex: rangeIndices(testStart, testEnd)}
+		for sample := 0; sample < NSamples; sample++ {
+			switch {
+			case sample >= testStart && sample < testEnd:
+			case sample < testStart && labelEnd[sample] >= testStart:
+				// purged: its label overlaps the test period
+			case sample >= testEnd && sample <= testLabelEnd:
+				// purged: its period overlaps the test labels
+			case sample >= testEnd && sample < testEnd+splitter.Embargo:
+				// embargoed
+			default:
+				sp.TrainIndex = append(sp.TrainIndex, sample)
+			}
+		}
+		splits[i] = sp
+		testStart = testEnd
+	}
+	return sendSplits(splits)
+}
+
+// TrainTestSplit splits X and Y (which may be nil) into a train and a test subsets.
+// testSize is a fraction of the samples if in (0,1), else a number of samples. it defaults to 0.25.
+// if shuffle is false, the test set is made of the last samples. else samples are drawn using randomState.
+// if stratify is not nil (Y usually), the proportion of each of its classes is preserved in both subsets, which requires shuffle
+func TrainTestSplit(X, Y *mat.Dense, testSize float64, shuffle bool, stratify *mat.Dense, randomState *RandomState) (Xtrain, Xtest, Ytrain, Ytest *mat.Dense) {
+	if testSize <= 0 {
+		testSize = .25
+	}
+	NSamples, _ := X.Dims()
+	var sp Split
+	switch {
+	case stratify != nil && !shuffle:
+		panic(fmt.Errorf("TrainTestSplit: stratify requires shuffle"))
+	case stratify != nil:
+		sp = <-(&StratifiedShuffleSplit{NSplits: 1, TestSize: testSize, RandomState: randomState}).Split(X, stratify)
+	case shuffle:
+		sp = <-(&ShuffleSplit{NSplits: 1, TestSize: testSize, RandomState: randomState}).Split(X, nil)
+	default:
+		nTrain, _ := splitSizes("TrainTestSplit", NSamples, 0, testSize)
+		sp = Split{TrainIndex: rangeIndices(0, nTrain), TestIndex: rangeIndices(nTrain, NSamples)}
+	}
+	Xtrain, Xtest = takeRows(X, sp.TrainIndex), takeRows(X, sp.TestIndex)
+	if Y != nil {
+		Ytrain, Ytest = takeRows(Y, sp.TrainIndex), takeRows(Y, sp.TestIndex)
+	}
+	return
+}
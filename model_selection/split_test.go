@@ -2,16 +2,19 @@ package modelselection
 
 import (
 	"fmt"
+	"reflect"
+	"testing"
 
+	"github.com/pa-m/sklearn/base"
 	"gonum.org/v1/gonum/mat"
 )
 
 func ExampleKFold() {
 	X := mat.NewDense(6, 1, []float64{1, 2, 3, 4, 5, 6})
 	subtest := func(shuffle bool) {
-		randomState := RandomState(7)
+		randomState := base.NewRandomState(7)
 		fmt.Println("shuffle", shuffle)
-		kf := &KFold{NSplits: 3, Shuffle: shuffle, RandomState: &randomState}
+		kf := &KFold{NSplits: 3, Shuffle: shuffle, RandomState: randomState}
 		for sp := range kf.Split(X, nil) {
 			fmt.Printf("%#v\n", sp)
 		}
@@ -30,3 +33,250 @@ func ExampleKFold() {
 	// modelselection.Split{TrainIndex:[]int{4, 3, 5, 0}, TestIndex:[]int{1, 2}}
 
 }
+
+func ExampleStratifiedKFold() {
+	X := mat.NewDense(8, 1, nil)
+	Y := mat.NewDense(8, 1, []float64{0, 0, 0, 0, 0, 0, 1, 1})
+	for sp := range (&StratifiedKFold{NSplits: 2}).Split(X, Y) {
+		fmt.Printf("%#v\n", sp)
+	}
+	// Output:
+	// modelselection.Split{TrainIndex:[]int{3, 4, 5, 7}, TestIndex:[]int{0, 1, 2, 6}}
+	// modelselection.Split{TrainIndex:[]int{0, 1, 2, 6}, TestIndex:[]int{3, 4, 5, 7}}
+}
+
+func ExampleGroupKFold() {
+	X := mat.NewDense(6, 1, nil)
+	groups := []int{1, 1, 1, 2, 2, 3}
+	for sp := range (&GroupKFold{NSplits: 2, Groups: groups}).Split(X, nil) {
+		fmt.Printf("%#v\n", sp)
+	}
+	// Output:
+	// modelselection.Split{TrainIndex:[]int{3, 4, 5}, TestIndex:[]int{0, 1, 2}}
+	// modelselection.Split{TrainIndex:[]int{0, 1, 2}, TestIndex:[]int{3, 4, 5}}
+}
+
+func ExampleLeaveOneGroupOut() {
+	X := mat.NewDense(4, 1, nil)
+	for sp := range (&LeaveOneGroupOut{Groups: []int{7, 3, 7, 5}}).Split(X, nil) {
+		fmt.Printf("%#v\n", sp)
+	}
+	// Output:
+	// modelselection.Split{TrainIndex:[]int{0, 2, 3}, TestIndex:[]int{1}}
+	// modelselection.Split{TrainIndex:[]int{0, 1, 2}, TestIndex:[]int{3}}
+	// modelselection.Split{TrainIndex:[]int{1, 3}, TestIndex:[]int{0, 2}}
+}
+
+func ExampleLeavePOut() {
+	X := mat.NewDense(4, 1, nil)
+	lpo := &LeavePOut{P: 2}
+	fmt.Println(lpo.GetNSplits(X, nil))
+	for sp := range lpo.Split(X, nil) {
+		fmt.Println(sp.TrainIndex, sp.TestIndex)
+	}
+	// Output:
+	// 6
+	// [2 3] [0 1]
+	// [1 3] [0 2]
+	// [1 2] [0 3]
+	// [0 3] [1 2]
+	// [0 2] [1 3]
+	// [0 1] [2 3]
+}
+
+func ExamplePredefinedSplit() {
+	X := mat.NewDense(5, 1, nil)
+	for sp := range (&PredefinedSplit{TestFold: []int{0, 1, -1, 1, 0}}).Split(X, nil) {
+		fmt.Println(sp.TrainIndex, sp.TestIndex)
+	}
+	// Output:
+	// [1 2 3] [0 4]
+	// [0 2 4] [1 3]
+}
+
+// checkPartition checks that each sample is in the train set or the test set of sp, not both
+func checkPartition(t *testing.T, name string, sp Split, NSamples int) {
+	t.Helper()
+	seen := make(map[int]bool)
+	for _, i := range append(append([]int(nil), sp.TrainIndex...), sp.TestIndex...) {
+		if seen[i] || i < 0 || i >= NSamples {
+			t.Errorf("%s: sample %d is duplicated or out of range in %v", name, i, sp)
+		}
+		seen[i] = true
+	}
+}
+
+func TestShuffleSplits(t *testing.T) {
+	X := mat.NewDense(20, 1, nil)
+	Y := mat.NewDense(20, 1, nil)
+	for i := 0; i < 20; i++ {
+		Y.Set(i, 0, float64(i%4/3)) // 15 samples of class 0, 5 of class 1
+	}
+	randomState := base.NewRandomState(1)
+	ss := &ShuffleSplit{NSplits: 4, TestSize: .25, RandomState: randomState}
+	n := 0
+	for sp := range ss.Split(X, Y) {
+		checkPartition(t, "ShuffleSplit", sp, 20)
+		if len(sp.TestIndex) != 5 || len(sp.TrainIndex) != 15 {
+			t.Errorf("ShuffleSplit: unexpected sizes %d %d", len(sp.TrainIndex), len(sp.TestIndex))
+		}
+		n++
+	}
+	if n != ss.GetNSplits(X, Y) {
+		t.Errorf("ShuffleSplit: %d splits, GetNSplits=%d", n, ss.GetNSplits(X, Y))
+	}
+	sss := &StratifiedShuffleSplit{NSplits: 4, TestSize: 8, TrainSize: 12, RandomState: randomState}
+	for sp := range sss.Split(X, Y) {
+		checkPartition(t, "StratifiedShuffleSplit", sp, 20)
+		count := func(indices []int) (n int) {
+			for _, i := range indices {
+				n += int(Y.At(i, 0))
+			}
+			return
+		}
+		if count(sp.TrainIndex) != 3 || count(sp.TestIndex) != 2 || len(sp.TestIndex) != 8 {
+			t.Errorf("StratifiedShuffleSplit: class 1 is not stratified in %v", sp)
+		}
+	}
+}
+
+func TestStratifiedGroupKFold(t *testing.T) {
+	X := mat.NewDense(12, 1, nil)
+	Y := mat.NewDense(12, 1, []float64{0, 0, 1, 0, 0, 1, 0, 0, 1, 0, 0, 1})
+	groups := []int{0, 0, 0, 1, 1, 1, 2, 2, 2, 3, 3, 3}
+	randomState := base.NewRandomState(2)
+	sgkf := &StratifiedGroupKFold{NSplits: 2, Shuffle: true, RandomState: randomState, Groups: groups}
+	for sp := range sgkf.Split(X, Y) {
+		checkPartition(t, "StratifiedGroupKFold", sp, 12)
+		testGroups := make(map[int]bool)
+		ones := 0
+		for _, i := range sp.TestIndex {
+			testGroups[groups[i]] = true
+			ones += int(Y.At(i, 0))
+		}
+		for _, i := range sp.TrainIndex {
+			if testGroups[groups[i]] {
+				t.Errorf("group %d is in train and test", groups[i])
+			}
+		}
+		if len(sp.TestIndex) != 6 || ones != 2 {
+			t.Errorf("unexpected test set %v", sp.TestIndex)
+		}
+	}
+}
+
+func TestRepeatedKFold(t *testing.T) {
+	X := mat.NewDense(9, 1, nil)
+	Y := mat.NewDense(9, 1, []float64{0, 1, 0, 1, 0, 1, 0, 1, 0})
+	randomState := base.NewRandomState(3)
+	for _, splitter := range []Splitter{
+		&RepeatedKFold{NSplits: 3, NRepeats: 2, RandomState: randomState},
+		&RepeatedStratifiedKFold{NSplits: 3, NRepeats: 2, RandomState: randomState},
+	} {
+		var splits []Split
+		for sp := range splitter.Split(X, Y) {
+			checkPartition(t, fmt.Sprintf("%T", splitter), sp, 9)
+			splits = append(splits, sp)
+		}
+		if len(splits) != 6 || splitter.GetNSplits(X, Y) != 6 {
+			t.Fatalf("%T: expected 6 splits, got %d", splitter, len(splits))
+		}
+		for repeat := 0; repeat < 2; repeat++ {
+			tested := 0
+			for _, sp := range splits[3*repeat : 3*repeat+3] {
+				tested += len(sp.TestIndex)
+			}
+			if tested != 9 {
+				t.Errorf("%T: repeat %d does not test each sample once", splitter, repeat)
+			}
+		}
+		var again []Split
+		for sp := range splitter.Clone().Split(X, Y) {
+			again = append(again, sp)
+		}
+		if !reflect.DeepEqual(splits, again) {
+			t.Errorf("%T: RandomState did not make splits reproducible", splitter)
+		}
+	}
+}
+
+func ExampleTimeSeriesSplit() {
+	X := mat.NewDense(6, 1, nil)
+	fmt.Println("expanding window")
+	for sp := range (&TimeSeriesSplit{NSplits: 5}).Split(X, nil) {
+		fmt.Println(sp.TrainIndex, sp.TestIndex)
+	}
+	fmt.Println("sliding window")
+	for sp := range (&TimeSeriesSplit{NSplits: 3, MaxTrainSize: 2}).Split(X, nil) {
+		fmt.Println(sp.TrainIndex, sp.TestIndex)
+	}
+	fmt.Println("gap")
+	X = mat.NewDense(12, 1, nil)
+	for sp := range (&TimeSeriesSplit{NSplits: 3, TestSize: 2, Gap: 2}).Split(X, nil) {
+		fmt.Println(sp.TrainIndex, sp.TestIndex)
+	}
+	// Output:
+	// expanding window
+	// [0] [1]
+	// [0 1] [2]
+	// [0 1 2] [3]
+	// [0 1 2 3] [4]
+	// [0 1 2 3 4] [5]
+	// sliding window
+	// [1 2] [3]
+	// [2 3] [4]
+	// [3 4] [5]
+	// gap
+	// [0 1 2 3] [6 7]
+	// [0 1 2 3 4 5] [8 9]
+	// [0 1 2 3 4 5 6 7] [10 11]
+}
+
+func ExamplePurgedKFold() {
+	X := mat.NewDense(9, 1, nil)
+	// the label of each sample depends on the next sample
+	labelEnd := []int{1, 2, 3, 4, 5, 6, 7, 8, 8}
+	for sp := range (&PurgedKFold{NSplits: 3, LabelEnd: labelEnd, Embargo: 2}).Split(X, nil) {
+		fmt.Println(sp.TrainIndex, sp.TestIndex)
+	}
+	// Output:
+	// [5 6 7 8] [0 1 2]
+	// [0 1 8] [3 4 5]
+	// [0 1 2 3 4] [6 7 8]
+}
+
+func ExampleTrainTestSplit() {
+	X := mat.NewDense(5, 1, []float64{1, 2, 3, 4, 5})
+	Y := mat.NewDense(5, 1, []float64{10, 20, 30, 40, 50})
+	Xtrain, Xtest, Ytrain, Ytest := TrainTestSplit(X, Y, .4, false, nil, nil)
+	fmt.Println(mat.Formatted(Xtrain.T()), mat.Formatted(Xtest.T()), mat.Formatted(Ytrain.T()), mat.Formatted(Ytest.T()))
+	// Output:
+	// [1  2  3] [4  5] [10  20  30] [40  50]
+}
+
+func TestTrainTestSplitStratify(t *testing.T) {
+	X := mat.NewDense(20, 2, nil)
+	Y := mat.NewDense(20, 1, nil)
+	for i := 0; i < 20; i++ {
+		X.Set(i, 0, float64(i))
+		Y.Set(i, 0, float64(i%4/3))
+	}
+	randomState := base.NewRandomState(9)
+	Xtrain, Xtest, Ytrain, Ytest := TrainTestSplit(X, Y, .2, true, Y, randomState)
+	if r, _ := Xtest.Dims(); r != 4 || mat.Sum(Ytest) != 1 || mat.Sum(Ytrain) != 4 {
+		t.Errorf("test set is not stratified: %v", mat.Formatted(Ytest.T()))
+	}
+	seen := make(map[float64]bool)
+	for _, M := range []*mat.Dense{Xtrain, Xtest} {
+		r, _ := M.Dims()
+		for i := 0; i < r; i++ {
+			seen[M.At(i, 0)] = true
+			if Y.At(int(M.At(i, 0)), 0) != map[bool]*mat.Dense{true: Ytrain, false: Ytest}[M == Xtrain].At(i, 0) {
+				t.Errorf("X and Y rows are not aligned")
+			}
+		}
+	}
+	if len(seen) != 20 {
+		t.Errorf("train and test sets overlap")
+	}
+}
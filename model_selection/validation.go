@@ -1,40 +1,151 @@
 package modelselection
 
 import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
 	"runtime"
+	"sync/atomic"
 	"time"
 
 	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/metrics"
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
 )
 
 // CrossValidateResult is the struct result of CrossValidate. it includes TestScore,FitTime,ScoreTime,Estimator
+// TrainScore is only filled when train scores are requested (see GridSearchCV.ReturnTrainScore)
+// TestScores and TrainScores hold the scores of each scorer by name when several scorers are used (see CrossValidateMultiMetric)
 type CrossValidateResult struct {
 	TestScore          []float64
+	TrainScore         []float64
+	TestScores         map[string][]float64
+	TrainScores        map[string][]float64
 	FitTime, ScoreTime []time.Duration
 	Estimator          []base.Transformer
 }
 
+// crossValidateOptions holds the CrossValidate settings which are not part of its signature
+// when scoring is set, it replaces scorer and TestScore holds the scores of the scorer named refit.
+// when ctx is set, splits are skipped once it's done and estimators implementing base.FitterContext are fitted with it.
+// when sampleWeight is set, the estimator is a base.FitterWeighted fitted with the weights of the train rows of each split
+type crossValidateOptions struct {
+	returnTrainScore bool
+	scoring          map[string]*metrics.Scorer
+	refit            string
+	ctx              context.Context
+	sampleWeight     []float64
+	// search is the parameter search reported to the ProgressCallback of ctx by evaluateCandidates
+	search interface{}
+}
+
 // CrossValidate Evaluate a score by cross-validation
 // scorer is a func(Ytrue,Ypred) float64
 // only mean_squared_error for now
 // NJobs is the number of goroutines. if <=0, runtime.NumCPU is used
+// groups are given to cv if it is a GroupSplitter, like GroupKFold or LeaveOneGroupOut
 func CrossValidate(estimator base.Transformer, X, Y *mat.Dense, groups []int, scorer func(Ytrue, Ypred *mat.Dense) float64, cv Splitter, NJobs int) (res CrossValidateResult) {
+	res, err := crossValidate(estimator, X, Y, groups, scorer, cv, NJobs, crossValidateOptions{})
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// CrossValidateContext is CrossValidate returning ctx.Err() when ctx is cancelled or its deadline is exceeded.
+// estimators implementing base.FitterContext are fitted with ctx, so that a long fit is stopped too, and their
+// fit errors are returned. the test score of each split is reported as ValidationScore to the ProgressCallback of ctx,
+// with Epoch the number of splits done
+func CrossValidateContext(ctx context.Context, estimator base.Transformer, X, Y *mat.Dense, groups []int, scorer func(Ytrue, Ypred *mat.Dense) float64, cv Splitter, NJobs int) (res CrossValidateResult, err error) {
+	if rerr := base.Recover(func() {
+		res, err = crossValidate(estimator, X, Y, groups, scorer, cv, NJobs, crossValidateOptions{ctx: ctx})
+	}); rerr != nil {
+		return res, rerr
+	}
+	return
+}
+
+// CrossValidateWeighted is CrossValidate for a base.FitterWeighted estimator, fitted on each split with the elements of
+// sampleWeight of its train rows. test scores are not weighted. fit errors are returned, and a *base.ParamError if
+// estimator does not implement base.FitterWeighted
+func CrossValidateWeighted(estimator base.Transformer, X, Y *mat.Dense, sampleWeight []float64, groups []int, scorer func(Ytrue, Ypred *mat.Dense) float64, cv Splitter, NJobs int) (res CrossValidateResult, err error) {
+	if _, ok := estimator.(base.FitterWeighted); !ok {
+		return res, &base.ParamError{Estimator: estimator, Param: "estimator", Value: fmt.Sprintf("%T", estimator), Msg: "does not implement base.FitterWeighted"}
+	}
+	if err := base.CheckSampleWeight("CrossValidateWeighted", X, sampleWeight); err != nil {
+		return res, err
+	}
+	if rerr := base.Recover(func() {
+		res, err = crossValidate(estimator, X, Y, groups, scorer, cv, NJobs, crossValidateOptions{sampleWeight: sampleWeight})
+	}); rerr != nil {
+		return res, rerr
+	}
+	return
+}
+
+// CrossValidateMultiMetric is CrossValidate evaluating several scorers at once, like those returned by metrics.GetScorers.
+// scores are in TestScores (and TrainScores if returnTrainScore) by scorer name, TestScore is nil.
+// each kind of prediction (Transform, PredictProba, DecisionFunction) is computed once per split
+func CrossValidateMultiMetric(estimator base.Transformer, X, Y *mat.Dense, groups []int, scoring map[string]*metrics.Scorer, cv Splitter, NJobs int, returnTrainScore bool) (res CrossValidateResult) {
+	if len(scoring) == 0 {
+		panic(fmt.Errorf("CrossValidateMultiMetric: no scorer"))
+	}
+	res, err := crossValidate(estimator, X, Y, groups, nil, cv, NJobs, crossValidateOptions{returnTrainScore: returnTrainScore, scoring: scoring})
+	if err != nil {
+		panic(err)
+	}
+	res.TestScore, res.TrainScore = nil, nil
+	return
+}
+
+func crossValidate(estimator base.Transformer, X, Y *mat.Dense, groups []int, scorer func(Ytrue, Ypred *mat.Dense) float64, cv Splitter, NJobs int, opts crossValidateOptions) (res CrossValidateResult, err error) {
 
 	if NJobs <= 0 {
 		NJobs = runtime.NumCPU()
 	}
+	if cv == Splitter(nil) {
+		cv = &KFold{NSplits: 3, Shuffle: true}
+	}
+	if gs, ok := cv.(GroupSplitter); ok && groups != nil {
+		cv = gs.WithGroups(groups)
+	}
 	NSplits := cv.GetNSplits(X, Y)
 	if NJobs > NSplits {
 		NJobs = NSplits
 	}
-	if cv == Splitter(nil) {
-		cv = &KFold{NSplits: 3, Shuffle: true}
-	}
 	res.Estimator = make([]base.Transformer, NSplits)
 	res.TestScore = make([]float64, NSplits)
 	res.FitTime = make([]time.Duration, NSplits)
 	res.ScoreTime = make([]time.Duration, NSplits)
+	if opts.returnTrainScore {
+		res.TrainScore = make([]float64, NSplits)
+	}
+	if opts.scoring != nil {
+		res.TestScores = make(map[string][]float64, len(opts.scoring))
+		for name := range opts.scoring {
+			res.TestScores[name] = make([]float64, NSplits)
+		}
+		if opts.returnTrainScore {
+			res.TrainScores = make(map[string][]float64, len(opts.scoring))
+			for name := range opts.scoring {
+				res.TrainScores[name] = make([]float64, NSplits)
+			}
+		}
+	}
+	// score returns the score of the fitted estimator on X,Y and sets the scores of each scorer for split iSplit
+	score := func(estimator base.Transformer, X, Y *mat.Dense, iSplit int, scores map[string][]float64) float64 {
+		if opts.scoring == nil {
+			_, Ypred := estimator.Transform(X, Y)
+			return scorer(Y, Ypred)
+		}
+		splitScores := metrics.MultiScore(opts.scoring, estimator, X, Y)
+		for name, s := range splitScores {
+			scores[name][iSplit] = s
+		}
+		return splitScores[opts.refit]
+	}
 	type structIn struct {
 		iSplit int
 		Split
@@ -42,7 +153,10 @@ func CrossValidate(estimator base.Transformer, X, Y *mat.Dense, groups []int, sc
 	type structOut struct {
 		iSplit int
 		score  float64
+		err    error
 	}
+	start := time.Now()
+	var splitsDone int32
 	estimatorCloner := estimator.(base.TransformerCloner)
 	NSamples, NFeatures := X.Dims()
 	_, NOutputs := Y.Dims()
@@ -64,16 +178,45 @@ func CrossValidate(estimator base.Transformer, X, Y *mat.Dense, groups []int, sc
 
 		res.Estimator[sin.iSplit] = estimatorCloner.Clone()
 		t0 := time.Now()
-		res.Estimator[sin.iSplit].Fit(Xtrain, Ytrain)
+		if err := base.ContextErr(opts.ctx); err != nil {
+			return structOut{sin.iSplit, math.NaN(), err}
+		}
+		if opts.sampleWeight != nil {
+			trainWeight := make([]float64, trainLen)
+			for i0, i1 := range sin.Split.TrainIndex {
+				trainWeight[i0] = opts.sampleWeight[i1]
+			}
+			if err := res.Estimator[sin.iSplit].(base.FitterWeighted).FitWeighted(Xtrain, Ytrain, trainWeight); err != nil {
+				return structOut{sin.iSplit, math.NaN(), err}
+			}
+		} else if fc, ok := res.Estimator[sin.iSplit].(base.FitterContext); ok && opts.ctx != nil {
+			if err := fc.FitContext(opts.ctx, Xtrain, Ytrain); err != nil {
+				return structOut{sin.iSplit, math.NaN(), err}
+			}
+		} else {
+			res.Estimator[sin.iSplit].Fit(Xtrain, Ytrain)
+		}
 		res.FitTime[sin.iSplit] = time.Since(t0)
 		t0 = time.Now()
-		_, Ypred := res.Estimator[sin.iSplit].Transform(Xtest, Ytest)
-		score := scorer(Ytest, Ypred)
+		testScore := score(res.Estimator[sin.iSplit], Xtest, Ytest, sin.iSplit, res.TestScores)
 		res.ScoreTime[sin.iSplit] = time.Since(t0)
-		//fmt.Printf("score for split %d is %g\n", sin.iSplit, score)
-		return structOut{sin.iSplit, score}
+		if opts.returnTrainScore {
+			res.TrainScore[sin.iSplit] = score(res.Estimator[sin.iSplit], Xtrain, Ytrain, sin.iSplit, res.TrainScores)
+		}
+		//fmt.Printf("score for split %d is %g\n", sin.iSplit, testScore)
+		done := atomic.AddInt32(&splitsDone, 1)
+		base.ReportProgress(opts.ctx, base.Progress{Estimator: estimator, Epoch: int(done), Epochs: NSplits, Loss: math.NaN(), ValidationScore: testScore, Elapsed: time.Since(start)})
+		return structOut{sin.iSplit, testScore, nil}
 
 	}
+	// processSplitE is processSplit returning a panic of the fit or the scoring as the error of the split: it runs
+	// in the goroutines of base.Parallelize, where the base.Recover of the callers can't catch it
+	processSplitE := func(job int, Xjob, Yjob *mat.Dense, sin structIn) (sout structOut) {
+		if err := base.Recover(func() { sout = processSplit(job, Xjob, Yjob, sin) }); err != nil {
+			sout = structOut{sin.iSplit, math.NaN(), err}
+		}
+		return
+	}
 	if NJobs > 1 {
 		/*var useChannels = false
 		if useChannels {
@@ -105,23 +248,345 @@ func CrossValidate(estimator base.Transformer, X, Y *mat.Dense, groups []int, sc
 			for split := range cv.Split(X, Y) {
 				sin = append(sin, structIn{iSplit: len(sin), Split: split})
 			}
+			errs := make([]error, NSplits)
 			base.Parallelize(NJobs, NSplits, func(th, start, end int) {
 				var Xjob, Yjob = mat.NewDense(NSamples, NFeatures, nil), mat.NewDense(NSamples, NOutputs, nil)
 				for i := start; i < end; i++ {
-					sout := processSplit(th, Xjob, Yjob, sin[i])
-					res.TestScore[sout.iSplit] = sout.score
+					sout := processSplitE(th, Xjob, Yjob, sin[i])
+					res.TestScore[sout.iSplit], errs[sout.iSplit] = sout.score, sout.err
 				}
 			})
+			if err := firstError(errs); err != nil {
+				return res, err
+			}
 		}
 	} else { // NJobs==1
 		var Xjob, Yjob = mat.NewDense(NSamples, NFeatures, nil), mat.NewDense(NSamples, NOutputs, nil)
 		var isplit int
 		for split := range cv.Split(X, Y) {
-			sout := processSplit(0, Xjob, Yjob, structIn{iSplit: isplit, Split: split})
-			res.TestScore[sout.iSplit] = sout.score
+			if err != nil {
+				// drain the splitter
+				continue
+			}
+			sout := processSplitE(0, Xjob, Yjob, structIn{iSplit: isplit, Split: split})
+			res.TestScore[sout.iSplit], err = sout.score, sout.err
 			isplit++
 		}
 
 	}
 	return
 }
+
+// takeRows returns a new matrix with the rows of M in indices
+func takeRows(M *mat.Dense, indices []int) *mat.Dense {
+	_, c := M.Dims()
+	out := mat.NewDense(len(indices), c, nil)
+	for i0, i1 := range indices {
+		out.SetRow(i0, M.RawRowView(i1))
+	}
+	return out
+}
+
+// splitsOf returns the splits of cv, given groups if cv is a GroupSplitter. cv defaults to KFold
+func splitsOf(cv Splitter, X, Y *mat.Dense, groups []int) (splits []Split) {
+	if cv == Splitter(nil) {
+		cv = &KFold{NSplits: 3, Shuffle: true}
+	}
+	if gs, ok := cv.(GroupSplitter); ok && groups != nil {
+		cv = gs.WithGroups(groups)
+	}
+	for split := range cv.Split(X, Y) {
+		splits = append(splits, split)
+	}
+	return
+}
+
+// crossValPredict fills the rows of the out-of-fold predictions with predict, called with a clone of estimator fitted
+// on the train set of each split and with Ypred allocated with nOutputs columns
+func crossValPredict(op string, estimator base.Transformer, X, Y *mat.Dense, groups []int, cv Splitter, NJobs, nOutputs int, predict func(estimator base.Transformer, Xtest, Ypred *mat.Dense)) *mat.Dense {
+	NSamples, _ := X.Dims()
+	if cv == Splitter(nil) {
+		// unshuffled contiguous folds
+		cv = &PurgedKFold{NSplits: 3}
+	}
+	splits := splitsOf(cv, X, Y, groups)
+	tested := make([]bool, NSamples)
+	for _, split := range splits {
+		for _, i := range split.TestIndex {
+			if tested[i] {
+				panic(fmt.Errorf("%s: sample %d is in several test sets. cv test sets must not overlap", op, i))
+			}
+			tested[i] = true
+		}
+	}
+	out := mat.NewDense(NSamples, nOutputs, nil)
+	for i, ok := range tested {
+		if !ok {
+			for o := 0; o < nOutputs; o++ {
+				out.Set(i, o, math.NaN())
+			}
+		}
+	}
+	estimatorCloner := estimator.(base.TransformerCloner)
+	errs := make([]error, len(splits))
+	base.Parallelize(NJobs, len(splits), func(th, start, end int) {
+		for iSplit := start; iSplit < end; iSplit++ {
+			split := splits[iSplit]
+			errs[iSplit] = base.Recover(func() {
+				est := estimatorCloner.Clone()
+				est.Fit(takeRows(X, split.TrainIndex), takeRows(Y, split.TrainIndex))
+				Ypred := mat.NewDense(len(split.TestIndex), nOutputs, nil)
+				predict(est, takeRows(X, split.TestIndex), Ypred)
+				for i0, i1 := range split.TestIndex {
+					out.SetRow(i1, Ypred.RawRowView(i0))
+				}
+			})
+		}
+	})
+	if err := firstError(errs); err != nil {
+		panic(err)
+	}
+	return out
+}
+
+// firstError returns the first error of errs which is not nil, the errors of jobs run by base.Parallelize, whose
+// panics must be recovered in each goroutine
+func firstError(errs []error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CrossValPredict returns the out-of-fold predictions of estimator: the prediction for each sample is made by a clone of
+// estimator fitted on the train set of the split where the sample is in the test set.
+// cv test sets must not overlap: KFold test sets may overlap, StratifiedKFold, GroupKFold, RepeatedKFold with NRepeats=1
+// or PurgedKFold (the default) may be used. samples in no test set (with TimeSeriesSplit for example) are predicted as NaN.
+// predictions are those returned by estimator Transform. groups are given to cv if it is a GroupSplitter
+func CrossValPredict(estimator base.Transformer, X, Y *mat.Dense, groups []int, cv Splitter, NJobs int) (Ypred *mat.Dense) {
+	_, NOutputs := Y.Dims()
+	return crossValPredict("CrossValPredict", estimator, X, Y, groups, cv, NJobs, NOutputs, func(estimator base.Transformer, Xtest, Ypred *mat.Dense) {
+		_, Yt := estimator.Transform(Xtest, Ypred)
+		Ypred.Copy(Yt)
+	})
+}
+
+// CrossValPredictProba is CrossValPredict returning out-of-fold probabilities.
+// estimator must have a PredictProba(X,Y) method filling Y with NClasses columns, like KNeighborsClassifier.
+// every class should be in each train set (use StratifiedKFold) so that all folds have the same columns
+func CrossValPredictProba(estimator base.Transformer, X, Y *mat.Dense, groups []int, cv Splitter, NJobs, NClasses int) (Yproba *mat.Dense) {
+	type probaPredicter interface {
+		PredictProba(X, Y *mat.Dense)
+	}
+	type probaPredicterTransformer interface {
+		PredictProba(X, Y *mat.Dense) base.Transformer
+	}
+	switch estimator.(type) {
+	case probaPredicter, probaPredicterTransformer:
+	default:
+		panic(fmt.Errorf("CrossValPredictProba: %T has no PredictProba method", estimator))
+	}
+	return crossValPredict("CrossValPredictProba", estimator, X, Y, groups, cv, NJobs, NClasses, func(estimator base.Transformer, Xtest, Yproba *mat.Dense) {
+		switch est := estimator.(type) {
+		case probaPredicter:
+			est.PredictProba(Xtest, Yproba)
+		case probaPredicterTransformer:
+			est.PredictProba(Xtest, Yproba)
+		}
+	})
+}
+
+// LearningCurve returns the train and test scores of estimator fitted on increasing subsets of the train set of each split.
+// trainSizes are fractions of the train set of the first split if <=1, else numbers of samples.
+// they default to {.1, .325, .55, .775, 1}. nTrain are the sizes in samples, trainScores and testScores have one row per
+// size and one column per split. groups are given to cv if it is a GroupSplitter
+func LearningCurve(estimator base.Transformer, X, Y *mat.Dense, groups []int, scorer func(Ytrue, Ypred *mat.Dense) float64, cv Splitter, trainSizes []float64, NJobs int) (nTrain []int, trainScores, testScores *mat.Dense) {
+	if len(trainSizes) == 0 {
+		trainSizes = []float64{.1, .325, .55, .775, 1}
+	}
+	splits := splitsOf(cv, X, Y, groups)
+	maxTrain := len(splits[0].TrainIndex)
+	nTrain = make([]int, len(trainSizes))
+	for i, size := range trainSizes {
+		nTrain[i] = int(size)
+		if size <= 1 {
+			nTrain[i] = int(math.Floor(size * float64(maxTrain)))
+		}
+		if nTrain[i] <= 0 || nTrain[i] > maxTrain {
+			panic(fmt.Errorf("LearningCurve: train size %g gives %d samples, it must be in (0,%d]", size, nTrain[i], maxTrain))
+		}
+	}
+	trainScores, testScores = mat.NewDense(len(nTrain), len(splits), nil), mat.NewDense(len(nTrain), len(splits), nil)
+	estimatorCloner := estimator.(base.TransformerCloner)
+	errs := make([]error, len(nTrain)*len(splits))
+	base.Parallelize(NJobs, len(nTrain)*len(splits), func(th, start, end int) {
+		for job := start; job < end; job++ {
+			iSize, iSplit := job/len(splits), job%len(splits)
+			split := splits[iSplit]
+			trainIndex := split.TrainIndex
+			if nTrain[iSize] < len(trainIndex) {
+				trainIndex = trainIndex[:nTrain[iSize]]
+			}
+			errs[job] = base.Recover(func() {
+				trainScore, testScore := fitAndScore(estimatorCloner.Clone(), X, Y, Split{TrainIndex: trainIndex, TestIndex: split.TestIndex}, scorer)
+				trainScores.Set(iSize, iSplit, trainScore)
+				testScores.Set(iSize, iSplit, testScore)
+			})
+		}
+	})
+	if err := firstError(errs); err != nil {
+		panic(err)
+	}
+	return
+}
+
+// ValidationCurve returns the train and test scores of estimator for each value of the parameter paramName.
+// paramName is a parameter name accepted by base.SetParams. trainScores and testScores have one row per value
+// and one column per split. groups are given to cv if it is a GroupSplitter
+func ValidationCurve(estimator base.Transformer, X, Y *mat.Dense, groups []int, scorer func(Ytrue, Ypred *mat.Dense) float64, cv Splitter, paramName string, paramRange []interface{}, NJobs int) (trainScores, testScores *mat.Dense) {
+	estimatorCloner := estimator.(base.TransformerCloner)
+	for _, value := range paramRange {
+		if err := base.SetParams(estimatorCloner.Clone(), map[string]interface{}{paramName: value}); err != nil {
+			panic(err)
+		}
+	}
+	splits := splitsOf(cv, X, Y, groups)
+	trainScores, testScores = mat.NewDense(len(paramRange), len(splits), nil), mat.NewDense(len(paramRange), len(splits), nil)
+	errs := make([]error, len(paramRange)*len(splits))
+	base.Parallelize(NJobs, len(paramRange)*len(splits), func(th, start, end int) {
+		for job := start; job < end; job++ {
+			iParam, iSplit := job/len(splits), job%len(splits)
+			est := estimatorCloner.Clone()
+			if errs[job] = base.SetParams(est, map[string]interface{}{paramName: paramRange[iParam]}); errs[job] != nil {
+				continue
+			}
+			errs[job] = base.Recover(func() {
+				trainScore, testScore := fitAndScore(est, X, Y, splits[iSplit], scorer)
+				trainScores.Set(iParam, iSplit, trainScore)
+				testScores.Set(iParam, iSplit, testScore)
+			})
+		}
+	})
+	if err := firstError(errs); err != nil {
+		panic(err)
+	}
+	return
+}
+
+// fitAndScore fits estimator on the train set of split and returns its scores on the train and test sets
+func fitAndScore(estimator base.Transformer, X, Y *mat.Dense, split Split, scorer func(Ytrue, Ypred *mat.Dense) float64) (trainScore, testScore float64) {
+	Xtrain, Ytrain := takeRows(X, split.TrainIndex), takeRows(Y, split.TrainIndex)
+	Xtest, Ytest := takeRows(X, split.TestIndex), takeRows(Y, split.TestIndex)
+	estimator.Fit(Xtrain, Ytrain)
+	_, Ypred := estimator.Transform(Xtrain, Ytrain)
+	trainScore = scorer(Ytrain, Ypred)
+	_, Ypred = estimator.Transform(Xtest, Ytest)
+	testScore = scorer(Ytest, Ypred)
+	return
+}
+
+// fixedSplits is a Splitter returning the same splits at each call
+type fixedSplits []Split
+
+func (s fixedSplits) Split(X, Y *mat.Dense) chan Split {
+	ch := make(chan Split)
+	go func() {
+		for _, split := range s {
+			ch <- split
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+func (s fixedSplits) GetNSplits(X, Y *mat.Dense) int { return len(s) }
+
+func (s fixedSplits) Clone() Splitter { return s }
+
+// PermutationTestScore evaluates the significance of the cross validated score of estimator.
+// score is the mean test score on the true targets, permutationScores the mean test scores for NPermutations
+// (defaults to 100) shuffles of the rows of Y, and pvalue the fraction of the permutations (counting the true targets)
+// scoring at least as well as score: (C+1)/(NPermutations+1). a small pvalue means estimator found a real dependency
+// between X and Y.
+// when groups is not nil, rows are shuffled within each group and groups are given to cv if it is a GroupSplitter.
+// the same splits of cv are used for every run. RandomState makes the permutations reproducible.
+// permutations are run in NJobs goroutines using base.Parallelize
+func PermutationTestScore(estimator base.Transformer, X, Y *mat.Dense, groups []int, scorer *metrics.Scorer, cv Splitter, NPermutations int, randomState *RandomState, NJobs int) (score float64, permutationScores []float64, pvalue float64) {
+	if NPermutations <= 0 {
+		NPermutations = 100
+	}
+	if NJobs <= 0 {
+		NJobs = runtime.NumCPU()
+	}
+	splits := fixedSplits(splitsOf(cv, X, Y, groups))
+	opts := crossValidateOptions{scoring: map[string]*metrics.Scorer{"score": scorer}, refit: "score"}
+	meanScore := func(Y *mat.Dense) float64 {
+		res, err := crossValidate(estimator, X, Y, nil, nil, splits, 1, opts)
+		if err != nil {
+			panic(err)
+		}
+		return floats.Sum(res.TestScore) / float64(len(res.TestScore))
+	}
+	score = meanScore(Y)
+
+	// one generator per permutation so that the permutations don't depend on NJobs
+	randomStates := randomState.Spawn(NPermutations)
+	permutationScores = make([]float64, NPermutations)
+	errs := make([]error, NPermutations)
+	base.Parallelize(NJobs, NPermutations, func(th, start, end int) {
+		for i := start; i < end; i++ {
+			errs[i] = base.Recover(func() { permutationScores[i] = meanScore(shuffleRows(Y, groups, randomStates[i].Rand())) })
+		}
+	})
+	if err := firstError(errs); err != nil {
+		panic(err)
+	}
+	count := 1.
+	for _, s := range permutationScores {
+		if (scorer.GreaterIsBetter && s >= score) || (!scorer.GreaterIsBetter && s <= score) {
+			count++
+		}
+	}
+	pvalue = count / float64(NPermutations+1)
+	return
+}
+
+// shuffleRows returns a copy of Y with its rows shuffled, within each group if groups is not nil
+func shuffleRows(Y *mat.Dense, groups []int, r *rand.Rand) *mat.Dense {
+	NSamples, _ := Y.Dims()
+	indices := make([]int, NSamples)
+	if groups == nil {
+		for i, j := range r.Perm(NSamples) {
+			indices[i] = j
+		}
+		return takeRows(Y, indices)
+	}
+	members := make(map[int][]int)
+	for i, g := range groups {
+		members[g] = append(members[g], i)
+	}
+	unique, _ := uniqueGroups(groups)
+	for _, g := range unique {
+		rows := members[g]
+		for i, j := range r.Perm(len(rows)) {
+			indices[rows[i]] = rows[j]
+		}
+	}
+	return takeRows(Y, indices)
+}
+
+// NestedCrossValidate evaluates search (and its parameter tuning) by cross validation:
+// a clone of search is fitted on the train set of each split of outerCV, where it runs its own inner cross validation
+// with its CV, and is scored on the test set with its Scorer, or its Refit scorer with every Scoring scorer in TestScores.
+// res.Estimator holds the fitted searches, so the parameters chosen for each outer split are
+// res.Estimator[i].(*GridSearchCV).BestParams. groups are given to outerCV if it is a GroupSplitter.
+// outer splits are run in NJobs goroutines using base.Parallelize
+func NestedCrossValidate(search *GridSearchCV, X, Y *mat.Dense, groups []int, outerCV Splitter, NJobs int) (res CrossValidateResult) {
+	res, err := crossValidate(search, X, Y, groups, search.Scorer, outerCV, NJobs, searchOptions(search.Scoring, search.Refit, false))
+	if err != nil {
+		panic(err)
+	}
+	return
+}
@@ -1,11 +1,18 @@
 package modelselection
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math"
+	"reflect"
+	"testing"
 
+	"github.com/pa-m/sklearn/base"
 	"github.com/pa-m/sklearn/datasets"
+	lm "github.com/pa-m/sklearn/linear_model"
 	"github.com/pa-m/sklearn/metrics"
+	"github.com/pa-m/sklearn/neighbors"
 	"github.com/pa-m/sklearn/neural_network"
 	"github.com/pa-m/sklearn/pipeline"
 	"github.com/pa-m/sklearn/preprocessing"
@@ -14,12 +21,12 @@ import (
 )
 
 func ExampleCrossValidate() {
-	randomState := int64(5)
+	randomState := base.NewRandomState(5)
 
 	ds := datasets.LoadBoston()
 	X, Y := ds.X, ds.Y
 	mlp := neuralnetwork.NewMLPRegressor([]int{20}, "identity", "adam", 1e-4)
-	mlp.RandomState = &randomState
+	mlp.RandomState = randomState
 	mlp.Shuffle = false
 	mlp.MiniBatchSize = 5
 	mlp.WeightDecay = .1
@@ -37,8 +44,264 @@ func ExampleCrossValidate() {
 	res := CrossValidate(m, X, Y,
 		nil,
 		scorer,
-		&KFold{NSplits: 10, Shuffle: true, RandomState: &randomState}, 10)
+		&KFold{NSplits: 10, Shuffle: true, RandomState: randomState}, 10)
 	fmt.Println(math.Sqrt(mean(res.TestScore)) < 6.)
 	// Output:
 	// true
 }
+
+func TestCrossValidateGroups(t *testing.T) {
+	X := mat.NewDense(9, 1, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9})
+	Y := mat.NewDense(9, 1, []float64{2, 4, 6, 8, 10, 12, 14, 16, 18})
+	groups := []int{0, 0, 1, 1, 1, 2, 2, 3, 3}
+	scorer := func(Y, Ypred *mat.Dense) float64 {
+		return metrics.MeanSquaredError(Y, Ypred, nil, "").At(0, 0)
+	}
+	res := CrossValidate(lm.NewLinearRegression(), X, Y, groups, scorer, &LeaveOneGroupOut{}, 1)
+	if len(res.TestScore) != 4 {
+		t.Errorf("expected one split per group, got %d", len(res.TestScore))
+	}
+	for i, score := range res.TestScore {
+		if score > 1e-8 {
+			t.Errorf("split %d: unexpected mse %g", i, score)
+		}
+	}
+}
+
+func TestCrossValidateTimeSeries(t *testing.T) {
+	X := mat.NewDense(12, 1, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})
+	Y := mat.NewDense(12, 1, []float64{3, 5, 7, 9, 11, 13, 15, 17, 19, 21, 23, 25})
+	scorer := func(Y, Ypred *mat.Dense) float64 {
+		return metrics.MeanSquaredError(Y, Ypred, nil, "").At(0, 0)
+	}
+	for _, cv := range []Splitter{&TimeSeriesSplit{NSplits: 3, Gap: 1}, &PurgedKFold{NSplits: 3, Embargo: 1}} {
+		res := CrossValidate(lm.NewLinearRegression(), X, Y, nil, scorer, cv, 1)
+		if len(res.TestScore) != 3 {
+			t.Errorf("%T: expected 3 splits, got %d", cv, len(res.TestScore))
+		}
+		for i, score := range res.TestScore {
+			if score > 1e-8 {
+				t.Errorf("%T split %d: unexpected mse %g", cv, i, score)
+			}
+		}
+	}
+}
+
+func TestCrossValPredict(t *testing.T) {
+	X := mat.NewDense(9, 1, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9})
+	Y := mat.NewDense(9, 1, []float64{3, 5, 7, 9, 11, 13, 15, 17, 19})
+	Ypred := CrossValPredict(lm.NewLinearRegression(), X, Y, nil, &RepeatedKFold{NSplits: 3, NRepeats: 1}, 1)
+	if !mat.EqualApprox(Y, Ypred, 1e-8) {
+		t.Errorf("unexpected out-of-fold predictions %v", mat.Formatted(Ypred.T()))
+	}
+
+	Xc := mat.NewDense(12, 1, []float64{0, .1, .2, .3, .4, .5, 5, 5.1, 5.2, 5.3, 5.4, 5.5})
+	Yc := mat.NewDense(12, 1, []float64{0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 1, 1})
+	Yproba := CrossValPredictProba(neighbors.NewKNeighborsClassifier(3, "uniform"), Xc, Yc, nil, &StratifiedKFold{NSplits: 3}, 1, 2)
+	for i := 0; i < 12; i++ {
+		if cl := int(Yc.At(i, 0)); Yproba.At(i, cl) != 1 || Yproba.At(i, 1-cl) != 0 {
+			t.Errorf("sample %d: unexpected probabilities %v", i, Yproba.RawRowView(i))
+		}
+	}
+}
+
+func TestLearningAndValidationCurves(t *testing.T) {
+	X := mat.NewDense(12, 1, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})
+	Y := mat.NewDense(12, 1, []float64{1.1, 3.9, 9.2, 16, 24.8, 36.1, 49, 63.9, 81.2, 100, 120.8, 144.1})
+	scorer := func(Y, Ypred *mat.Dense) float64 {
+		return metrics.MeanSquaredError(Y, Ypred, nil, "").At(0, 0)
+	}
+	poly := preprocessing.NewPolynomialFeatures(2)
+	poly.IncludeBias = false
+	pl := pipeline.NewPipeline(
+		pipeline.NamedStep{Name: "poly", Step: poly},
+		pipeline.NamedStep{Name: "regr", Step: lm.NewLinearRegression()},
+	)
+	nTrain, trainScores, testScores := LearningCurve(pl, X, Y, nil, scorer, &KFold{NSplits: 3}, []float64{.5, 1}, 1)
+	if !reflect.DeepEqual(nTrain, []int{4, 8}) {
+		t.Errorf("unexpected train sizes %v", nTrain)
+	}
+	if r, c := trainScores.Dims(); r != 2 || c != 3 {
+		t.Errorf("unexpected scores shape %d,%d", r, c)
+	}
+	if mat.Max(testScores.RowView(1).(*mat.VecDense)) > 1 {
+		t.Errorf("unexpected test scores %v", mat.Formatted(testScores))
+	}
+
+	trainScores, testScores = ValidationCurve(pl, X, Y, nil, scorer, &KFold{NSplits: 3}, "poly__Degree", []interface{}{1, 2}, 1)
+	for split := 0; split < 3; split++ {
+		if trainScores.At(0, split) <= trainScores.At(1, split) || testScores.At(0, split) <= testScores.At(1, split) {
+			t.Errorf("split %d: degree 2 should score better than degree 1", split)
+		}
+	}
+}
+
+func TestCrossValidateMultiMetric(t *testing.T) {
+	X := mat.NewDense(12, 1, []float64{0, .1, .2, .3, .4, .5, 5, 5.1, 5.2, 5.3, 5.4, 5.5})
+	Y := mat.NewDense(12, 1, []float64{0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 1, 1})
+	scoring, err := metrics.GetScorers("accuracy", "roc_auc", "f1_macro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := CrossValidateMultiMetric(neighbors.NewKNeighborsClassifier(3, "uniform"), X, Y, nil, scoring, &StratifiedKFold{NSplits: 3}, 1, true)
+	if res.TestScore != nil || len(res.TestScores) != 3 || len(res.TrainScores) != 3 {
+		t.Fatalf("unexpected scores %v %v", res.TestScores, res.TrainScores)
+	}
+	for name, scores := range res.TestScores {
+		if !reflect.DeepEqual(scores, []float64{1, 1, 1}) {
+			t.Errorf("%s: unexpected test scores %v", name, scores)
+		}
+	}
+}
+
+func TestPermutationTestScore(t *testing.T) {
+	X := mat.NewDense(12, 1, []float64{0, .1, .2, .3, .4, .5, 5, 5.1, 5.2, 5.3, 5.4, 5.5})
+	Y := mat.NewDense(12, 1, []float64{0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 1, 1})
+	accuracy, _ := metrics.GetScorer("accuracy")
+	randomState := base.NewRandomState(7)
+	knn := neighbors.NewKNeighborsClassifier(3, "uniform")
+	score, permutationScores, pvalue := PermutationTestScore(knn, X, Y, nil, accuracy, &StratifiedKFold{NSplits: 3}, 30, randomState, 1)
+	if score != 1 || len(permutationScores) != 30 || pvalue > .1 {
+		t.Errorf("expected a significant score, got score %g pvalue %g", score, pvalue)
+	}
+	_, permutationScores4, pvalue4 := PermutationTestScore(knn, X, Y, nil, accuracy, &StratifiedKFold{NSplits: 3}, 30, randomState, 4)
+	if !reflect.DeepEqual(permutationScores, permutationScores4) || pvalue != pvalue4 {
+		t.Errorf("permutations depend on NJobs")
+	}
+
+	// labels independent of X within groups of samples sharing the same X
+	Xn := mat.NewDense(12, 1, []float64{0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2, 2})
+	Yn := mat.NewDense(12, 1, []float64{0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1})
+	_, _, pvalue = PermutationTestScore(knn, Xn, Yn, []int{0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2, 2}, accuracy, &StratifiedKFold{NSplits: 2}, 30, randomState, 2)
+	if pvalue < .2 {
+		t.Errorf("expected a non significant score, got pvalue %g", pvalue)
+	}
+}
+
+func TestNestedCrossValidate(t *testing.T) {
+	X := mat.NewDense(12, 1, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})
+	Y := mat.NewDense(12, 1, nil)
+	Y.Apply(func(i, j int, v float64) float64 { return 2*X.At(i, 0) + 3 }, Y)
+	scoring, _ := metrics.GetScorers("neg_mean_squared_error", "r2")
+	gscv := &GridSearchCV{
+		Estimator: lm.NewLinearRegression(),
+		ParamGrid: map[string][]interface{}{"FitIntercept": {true, false}},
+		Scoring:   scoring,
+		Refit:     "neg_mean_squared_error",
+		CV:        &KFold{NSplits: 3},
+		NJobs:     1,
+	}
+	res := NestedCrossValidate(gscv, X, Y, nil, &PurgedKFold{NSplits: 3}, 3)
+	if len(res.TestScore) != 3 || len(res.TestScores["r2"]) != 3 {
+		t.Fatalf("expected 3 outer splits, got %d", len(res.TestScore))
+	}
+	for i, est := range res.Estimator {
+		inner := est.(*GridSearchCV)
+		if inner == gscv || inner.BestParams["FitIntercept"] != true {
+			t.Errorf("split %d: unexpected inner search %v", i, inner.BestParams)
+		}
+		if res.TestScore[i] < -1e-10 || res.TestScores["r2"][i] < 1-1e-10 {
+			t.Errorf("split %d: unexpected scores %g %g", i, res.TestScore[i], res.TestScores["r2"][i])
+		}
+	}
+}
+
+func TestCrossValidateRandomState(t *testing.T) {
+	ds := datasets.LoadBoston()
+	run := func(NJobs int) []float64 {
+		mlp := neuralnetwork.NewMLPRegressor([]int{5}, "relu", "adam", 1e-4)
+		mlp.RandomState = base.NewRandomState(3)
+		mlp.Epochs = 5
+		m := pipeline.NewPipeline(
+			pipeline.NamedStep{Name: "standardize", Step: preprocessing.NewStandardScaler()},
+			pipeline.NamedStep{Name: "mlpregressor", Step: mlp},
+		)
+		scorer := func(Y, Ypred *mat.Dense) float64 { return metrics.MeanSquaredError(Y, Ypred, nil, "").At(0, 0) }
+		res := CrossValidate(m, ds.X, ds.Y, nil, scorer, &KFold{NSplits: 4, Shuffle: true, RandomState: base.NewRandomState(5)}, NJobs)
+		return res.TestScore
+	}
+	sequential, parallel := run(1), run(4)
+	if !floats.Equal(sequential, parallel) {
+		t.Errorf("fits with the same RandomState differ: %v %v", sequential, parallel)
+	}
+}
+
+func TestCrossValidateWeighted(t *testing.T) {
+	X := mat.NewDense(12, 1, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})
+	Y := mat.NewDense(12, 1, []float64{2, 4, 100, 8, 10, 12, 14, -50, 18, 20, 22, 24})
+	// outliers are weighted 0
+	sampleWeight := []float64{1, 1, 0, 1, 1, 1, 1, 0, 1, 1, 1, 1}
+	scorer := func(Y, Ypred *mat.Dense) float64 {
+		return metrics.MeanSquaredError(Y, Ypred, nil, "").At(0, 0)
+	}
+	for _, NJobs := range []int{1, 3} {
+		res, err := CrossValidateWeighted(lm.NewLinearRegression(), X, Y, sampleWeight, nil, scorer, &KFold{NSplits: 3}, NJobs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, estimator := range res.Estimator {
+			if coef := estimator.(*lm.LinearRegression).Coef.At(0, 0); math.Abs(coef-2) > 1e-8 {
+				t.Errorf("NJobs %d split %d: expected coef 2, got %g", NJobs, i, coef)
+			}
+		}
+	}
+	var paramErr *base.ParamError
+	m := pipeline.NewPipeline(pipeline.NamedStep{Name: "regression", Step: lm.NewLinearRegression()})
+	if _, err := CrossValidateWeighted(m, X, Y, sampleWeight, nil, scorer, &KFold{NSplits: 3}, 1); !errors.As(err, &paramErr) {
+		t.Errorf("expected a *base.ParamError, got %v", err)
+	}
+}
+
+// panickingRegressor is a LinearRegression whose Fit panics if PanicAt is a value of X, so in some folds only
+type panickingRegressor struct {
+	lm.LinearRegression
+	PanicAt float64
+}
+
+func (regr *panickingRegressor) Clone() base.Transformer {
+	clone := *regr
+	return &clone
+}
+
+func (regr *panickingRegressor) Fit(X, Y *mat.Dense) base.Transformer {
+	for _, x := range mat.Col(nil, 0, X) {
+		if x == regr.PanicAt {
+			panic(fmt.Errorf("panickingRegressor: %g in X", x))
+		}
+	}
+	regr.LinearRegression.Fit(X, Y)
+	return regr
+}
+
+func TestParallelFitPanics(t *testing.T) {
+	// the panics of folds fitted in the goroutines of base.Parallelize are returned as errors
+	X := mat.NewDense(6, 1, []float64{1, 2, 3, 4, 5, 6})
+	Y := mat.NewDense(6, 1, []float64{2, 4, 6, 8, 10, 12})
+	scorer := func(Y, Ypred *mat.Dense) float64 {
+		return metrics.MeanSquaredError(Y, Ypred, nil, "").At(0, 0)
+	}
+	regr := &panickingRegressor{PanicAt: 6}
+	regr.FitIntercept = true
+	// contiguous folds, 6 is in the train set of the first two
+	cv := &PurgedKFold{NSplits: 3}
+	for name, f := range map[string]func(){
+		"CrossValidate": func() {
+			if _, err := CrossValidateContext(context.Background(), regr, X, Y, nil, scorer, cv, 2); err != nil {
+				panic(err)
+			}
+		},
+		"CrossValPredict": func() { CrossValPredict(regr, X, Y, nil, cv, 2) },
+		"LearningCurve":   func() { LearningCurve(regr, X, Y, nil, scorer, cv, []float64{1}, 2) },
+		"ValidationCurve": func() { ValidationCurve(regr, X, Y, nil, scorer, cv, "PanicAt", []interface{}{6., 7.}, 2) },
+		"GridSearchCV": func() {
+			gscv := &GridSearchCV{Estimator: regr, ParamGrid: map[string][]interface{}{"PanicAt": {7., 6.}}, Scorer: scorer, LowerScoreIsBetter: true, CV: cv, NJobs: 2}
+			if err := gscv.FitE(X, Y); err != nil {
+				panic(err)
+			}
+		},
+	} {
+		if err := base.Recover(f); err == nil || err.Error() != "panickingRegressor: 6 in X" {
+			t.Errorf("%s: expected the panic of a fold as error, got %v", name, err)
+		}
+	}
+}
@@ -14,16 +14,21 @@ import (
 // KNeighborsClassifier is a Regression based on k-nearest neighbors.
 // The target is predicted by local interpolation of the targets
 // associated of the nearest neighbors in the training set.
+// ClassWeight multiplies the votes of the neighbors of each class. it is nil, "balanced" or a map[float64]float64
+// from class labels to weights, see base.ComputeClassWeight
 type KNeighborsClassifier struct {
 	base.Classifier
 	NearestNeighbors
-	K        int
-	Weight   string
-	Scale    bool
-	Distance Distance
+	K           int
+	Weight      string
+	Scale       bool
+	Distance    Distance
+	ClassWeight interface{}
 	// Runtime members
 	Xscaled, Y *mat.Dense
 	Classes    [][]float64
+	// ClassWeights are the weights of Classes for each output, nil if ClassWeight is nil
+	ClassWeights [][]float64
 }
 
 // NewKNeighborsClassifier returns an initialized *KNeighborsClassifier
@@ -31,6 +36,12 @@ func NewKNeighborsClassifier(K int, Weights string) *KNeighborsClassifier {
 	return &KNeighborsClassifier{NearestNeighbors: *NewNearestNeighbors(), K: K, Weight: Weights}
 }
 
+// Clone for KNeighborsClassifier
+func (m *KNeighborsClassifier) Clone() base.Transformer {
+	clone := *m
+	return &clone
+}
+
 // Fit ...
 func (m *KNeighborsClassifier) Fit(X, Y *mat.Dense) base.Transformer {
 	m.Xscaled = mat.DenseCopyOf(X)
@@ -43,9 +54,53 @@ func (m *KNeighborsClassifier) Fit(X, Y *mat.Dense) base.Transformer {
 	}
 	m.NearestNeighbors.Fit(X)
 	m.Classes, _ = getClasses(Y)
+	m.ClassWeights = nil
+	if m.ClassWeight != nil {
+		NSamples, _ := Y.Dims()
+		m.ClassWeights = make([][]float64, len(m.Classes))
+		for o := range m.Classes {
+			var err error
+			if m.ClassWeights[o], err = base.ComputeClassWeight(m.ClassWeight, m.Classes[o], mat.Col(make([]float64, NSamples), o, Y)); err != nil {
+				panic(err)
+			}
+		}
+	}
 	return m
 }
 
+// FitE is Fit returning an error instead of panicking
+func (m *KNeighborsClassifier) FitE(X, Y *mat.Dense) error {
+	if err := checkKNeighborsFit(m, "KNeighborsClassifier.Fit", X, Y, m.K); err != nil {
+		return err
+	}
+	return base.Recover(func() { m.Fit(X, Y) })
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *KNeighborsClassifier) PredictE(X, Y *mat.Dense) error {
+	if m.Y == nil {
+		return &base.NotFittedError{Estimator: m}
+	}
+	_, nOutputs := m.Y.Dims()
+	if err := m.NearestNeighbors.checkPredict(m, "KNeighborsClassifier.Predict", X, Y, m.K, nOutputs); err != nil {
+		return err
+	}
+	return base.Recover(func() { m.Predict(X, Y) })
+}
+
+func checkKNeighborsFit(estimator interface{}, op string, X, Y *mat.Dense, K int) error {
+	if err := base.CheckFitXY(op, X, Y); err != nil {
+		return err
+	}
+	if K <= 0 {
+		return &base.ParamError{Estimator: estimator, Param: "K", Value: K, Msg: "must be > 0"}
+	}
+	if nSamples, _ := X.Dims(); K > nSamples {
+		return &base.ParamError{Estimator: estimator, Param: "K", Value: K, Msg: fmt.Sprintf("must be <= NSamples (%d)", nSamples)}
+	}
+	return nil
+}
+
 // Predict  for KNeighborsClassifier
 func (m *KNeighborsClassifier) Predict(X, Y *mat.Dense) base.Transformer {
 	return m._predict(X, Y, false)
@@ -79,30 +134,24 @@ func (m *KNeighborsClassifier) _predict(X, Y *mat.Dense, wantProba bool) base.Tr
 	base.Parallelize(NCPU, NX, func(th, start, end int) {
 		epsilon := 1e-15
 		weights := make([]float64, m.K)
-		sumweights := 0.
 		ys := make([]float64, m.K)
-		if !isWeightDistance {
-			for ik := range weights {
-				weights[ik] = 1.
-				sumweights += 1.
-			}
-		}
 		for sample := start; sample < end; sample++ {
 			// set Y(sample,output) to weighted average of K nearest
 
 			for o := 0; o < outputs; o++ {
 				classw := make(map[float64]float64)
-				if isWeightDistance {
-					sumweights = 0.
-				}
+				sumweights := 0.
 				for ik := range ys {
 					cl := m.Y.At(int(indices.At(sample, ik)), o)
+					weights[ik] = 1.
 					if isWeightDistance {
 						dist := distances.At(sample, ik)
 						weights[ik] = 1. / (epsilon + dist)
-						sumweights += weights[ik]
-
 					}
+					if m.ClassWeights != nil {
+						weights[ik] *= m.ClassWeights[o][sort.SearchFloat64s(m.Classes[o], cl)]
+					}
+					sumweights += weights[ik]
 					if clw, present := classw[cl]; present {
 						classw[cl] = clw + weights[ik]
 					} else {
@@ -25,3 +25,23 @@ func ExampleKNeighborsClassifier() {
 	// [0]
 	// [0.66666667  0.33333333]
 }
+
+func ExampleKNeighborsClassifier_balanced() {
+	X := mat.NewDense(6, 1, []float64{0, 1, 2, 3, 4, 5})
+	Y := mat.NewDense(6, 1, []float64{0, 0, 0, 0, 0, 1})
+	Xtest := mat.NewDense(1, 1, []float64{4.4})
+	Ypred := mat.NewDense(1, 1, nil)
+	Yprob := mat.NewDense(1, 2, nil)
+	for _, classWeight := range []interface{}{nil, "balanced"} {
+		neigh := NewKNeighborsClassifier(3, "uniform")
+		// with "balanced", the votes for class 0 weigh 6/(2*5) and the votes for class 1 weigh 6/(2*1)
+		neigh.ClassWeight = classWeight
+		neigh.Fit(X, Y)
+		neigh.Predict(Xtest, Ypred)
+		neigh.PredictProba(Xtest, Yprob)
+		fmt.Printf("%v %.4f\n", mat.Formatted(Ypred), mat.Formatted(Yprob))
+	}
+	// Output:
+	// [0] [0.6667  0.3333]
+	// [1] [0.2857  0.7143]
+}
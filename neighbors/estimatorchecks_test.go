@@ -0,0 +1,21 @@
+package neighbors
+
+import (
+	"testing"
+
+	"github.com/pa-m/sklearn/base/estimatorchecks"
+)
+
+func TestEstimatorChecks(t *testing.T) {
+	X, Y := estimatorchecks.ClassificationData()
+	for _, estimator := range []estimatorchecks.Estimator{NewKNeighborsClassifier(3, "uniform"), NewNearestCentroid("euclidean", 0)} {
+		if err := estimatorchecks.CheckEstimator(estimator, X, Y); err != nil {
+			t.Error(err)
+		}
+	}
+	X, Y = estimatorchecks.RegressionData()
+	if err := estimatorchecks.CheckEstimator(NewKNeighborsRegressor(3, "distance").(estimatorchecks.Estimator), X, Y); err != nil {
+		t.Error(err)
+	}
+	// NearestNeighbors.Fit takes only X, it is not a base.Transformer and is checked through the estimators using it
+}
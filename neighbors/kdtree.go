@@ -142,8 +142,10 @@ func (r *Rectangle) MaxDistanceRectangle(other *Rectangle, p float64) float64 {
 // and with other kd-trees. These do use a reasonably efficient algorithm,
 // but the kd-tree is not necessarily the best data structure for this
 // sort of calculation.
+// a *base.Dense32 data is kept in float32 in Data32 instead of Data
 type KDTree struct {
 	Data        *mat.Dense
+	Data32      *base.Dense32
 	LeafSize    int
 	Maxes, Mins []float64
 	Tree        Node
@@ -152,22 +154,25 @@ type KDTree struct {
 // NewKDTree ...
 func NewKDTree(data mat.Matrix, LeafSize int) *KDTree {
 	tr := &KDTree{
-		Data:     mat.DenseCopyOf(data),
 		LeafSize: LeafSize,
 	}
+	if data32, ok := data.(*base.Dense32); ok {
+		tr.Data32 = base.Dense32Of(data32)
+	} else {
+		tr.Data = mat.DenseCopyOf(data)
+	}
 	if tr.LeafSize < 1 {
 		tr.LeafSize = 1
 	}
 	n, m := data.Dims()
 	tr.Maxes = make([]float64, m, m)
 	tr.Mins = make([]float64, m, m)
-	copy(tr.Maxes, tr.Data.RawRowView(0))
-	copy(tr.Mins, tr.Data.RawRowView(0))
-	rawdata := tr.Data.RawMatrix()
+	mat.Row(tr.Maxes, 0, data)
+	mat.Row(tr.Mins, 0, data)
 	base.Parallelize(runtime.NumCPU(), m, func(th, start, end int) {
 		for j := start; j < end; j++ {
-			for i, rowpos := 1, rawdata.Stride; i < n; i, rowpos = i+1, rowpos+rawdata.Stride {
-				v := rawdata.Data[rowpos+j]
+			for i := 1; i < n; i++ {
+				v := tr.at(i, j)
 				if v > tr.Maxes[j] {
 					tr.Maxes[j] = v
 				}
@@ -182,6 +187,22 @@ func NewKDTree(data mat.Matrix, LeafSize int) *KDTree {
 	return tr
 }
 
+// at returns the element i,j of Data or Data32
+func (tr *KDTree) at(i, j int) float64 {
+	if tr.Data32 != nil {
+		return tr.Data32.At(i, j)
+	}
+	return tr.Data.At(i, j)
+}
+
+// rowView returns the row i of Data or Data32
+func (tr *KDTree) rowView(i int) mat.Vector {
+	if tr.Data32 != nil {
+		return tr.Data32.RowView(i)
+	}
+	return tr.Data.RowView(i)
+}
+
 func _arange(n int) (a []int) {
 	a = make([]int, n, n)
 	for i := range a {
@@ -247,10 +268,10 @@ func (tr *KDTree) _build(idx []int, maxes, mins []float64) Node {
 	lgfill := func(split float64) ([]int, []int) {
 		lessIdx, greaterIdx := make([]int, 0), make([]int, 0)
 		for _, idx1 := range idx {
-			if tr.Data.At(idx1, d) <= split {
+			if tr.at(idx1, d) <= split {
 				lessIdx = append(lessIdx, idx1)
 			}
-			if tr.Data.At(idx1, d) > split {
+			if tr.at(idx1, d) > split {
 				greaterIdx = append(greaterIdx, idx1)
 			}
 		}
@@ -260,7 +281,7 @@ func (tr *KDTree) _build(idx []int, maxes, mins []float64) Node {
 	if len(lessIdx) == 0 {
 		M := math.Inf(1)
 		for _, i := range idx {
-			if v := tr.Data.At(i, d); v < M {
+			if v := tr.at(i, d); v < M {
 				M = v
 			}
 		}
@@ -270,7 +291,7 @@ func (tr *KDTree) _build(idx []int, maxes, mins []float64) Node {
 	if len(greaterIdx) == 0 {
 		M := math.Inf(-1)
 		for _, i := range idx {
-			if v := tr.Data.At(i, d); v > M {
+			if v := tr.at(i, d); v > M {
 				M = v
 			}
 		}
@@ -279,7 +300,7 @@ func (tr *KDTree) _build(idx []int, maxes, mins []float64) Node {
 	}
 	if len(lessIdx) == 0 {
 		// # _still_ zero? all must have the same value
-		split = tr.Data.At(idx[0], d)
+		split = tr.at(idx[0], d)
 		lessIdx = _arange(len(idx) - 1)
 		greaterIdx = []int{len(idx) - 1}
 	}
@@ -366,7 +387,7 @@ func (tr *KDTree) _query(X mat.Vector, k int, eps, p float64, distanceUpperBound
 			idx := node.(*LeafNode).idx
 
 			for _, fitSample := range idx {
-				ds := MinkowskiDistanceP(X, tr.Data.RowView(fitSample), p)
+				ds := MinkowskiDistanceP(X, tr.rowView(fitSample), p)
 				if ds < distanceUpperBound {
 					if len(neighbors) == k {
 						nHeappop()
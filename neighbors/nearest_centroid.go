@@ -27,7 +27,13 @@ type NearestCentroid struct {
 // NewNearestCentroid ...
 // if Metric is "manhattan", centroids are computed using median else mean
 func NewNearestCentroid(metric string, shrinkThreshold float64) *NearestCentroid {
-	return &NearestCentroid{Metric: metric, ShrinkThreshold: shrinkThreshold}
+	return &NearestCentroid{Metric: metric, ShrinkThreshold: shrinkThreshold, NearestNeighbors: *NewNearestNeighbors()}
+}
+
+// Clone for NearestCentroid
+func (m *NearestCentroid) Clone() base.Transformer {
+	clone := *m
+	return &clone
 }
 
 // Fit ...
@@ -64,10 +70,32 @@ func (m *NearestCentroid) Fit(X, Y *mat.Dense) base.Transformer {
 			Centroids.Set(icl, feature, centroidXfeat)
 		}
 	})
+	if m.Metric != "" {
+		m.NearestNeighbors.Metric = m.Metric
+	}
 	m.NearestNeighbors.Fit(Centroids)
 	return m
 }
 
+// FitE is Fit returning an error instead of panicking
+func (m *NearestCentroid) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckFitXY("NearestCentroid.Fit", X, Y); err != nil {
+		return err
+	}
+	if _, NOutputs := Y.Dims(); NOutputs != 1 {
+		return &base.ShapeError{Op: "NearestCentroid.Fit", Msg: "NearestCentroid can't handle output Dim != 1"}
+	}
+	return base.Recover(func() { m.Fit(X, Y) })
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *NearestCentroid) PredictE(X, Y *mat.Dense) error {
+	if err := m.NearestNeighbors.checkPredict(m, "NearestCentroid.Predict", X, Y, 1, 1); err != nil {
+		return err
+	}
+	return base.Recover(func() { m.Predict(X, Y) })
+}
+
 // Predict  for NearestCentroid
 func (m *NearestCentroid) Predict(X, Y *mat.Dense) base.Transformer {
 	return m._predict(X, Y, false)
@@ -0,0 +1,151 @@
+package neighbors
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+func init() {
+	base.Register(&KNeighborsClassifier{})
+	base.Register(&KNeighborsRegressor{})
+	base.Register(&NearestCentroid{})
+}
+
+type kdNodeFields struct {
+	Leaf          bool
+	Idx           []int
+	Children      int
+	SplitDim      int
+	Split         float64
+	Less, Greater *kdNodeFields
+}
+
+type kdTreeFields struct {
+	Data        *mat.Dense
+	Data32      *base.Dense32
+	LeafSize    int
+	Maxes, Mins []float64
+	Tree        *kdNodeFields
+}
+
+func encodeKDNode(node Node) *kdNodeFields {
+	switch n := node.(type) {
+	case *LeafNode:
+		return &kdNodeFields{Leaf: true, Idx: n.idx, Children: n.children}
+	case *InnerNode:
+		return &kdNodeFields{SplitDim: n.splitDim, Split: n.split, Less: encodeKDNode(n.less), Greater: encodeKDNode(n.greater)}
+	default:
+		return nil
+	}
+}
+
+func decodeKDNode(fields *kdNodeFields) Node {
+	switch {
+	case fields == nil:
+		return nil
+	case fields.Leaf:
+		return &LeafNode{idx: fields.Idx, children: fields.Children}
+	default:
+		return &InnerNode{splitDim: fields.SplitDim, split: fields.Split, less: decodeKDNode(fields.Less), greater: decodeKDNode(fields.Greater)}
+	}
+}
+
+// GobEncode encodes KDTree including its nodes
+func (tr *KDTree) GobEncode() ([]byte, error) {
+	return base.GobEncodeFields(kdTreeFields{Data: tr.Data, Data32: tr.Data32, LeafSize: tr.LeafSize, Maxes: tr.Maxes, Mins: tr.Mins, Tree: encodeKDNode(tr.Tree)})
+}
+
+// GobDecode decodes a KDTree encoded by GobEncode
+func (tr *KDTree) GobDecode(b []byte) error {
+	var fields kdTreeFields
+	if err := base.GobDecodeFields(b, &fields); err != nil {
+		return err
+	}
+	*tr = KDTree{Data: fields.Data, Data32: fields.Data32, LeafSize: fields.LeafSize, Maxes: fields.Maxes, Mins: fields.Mins, Tree: decodeKDNode(fields.Tree)}
+	return nil
+}
+
+// Restore restores Distance from Metric and P after Load
+func (m *NearestNeighbors) Restore() error {
+	if m.X == nil && m.X32 == nil && m.SparseX == nil {
+		return nil
+	}
+	if m.P <= 0 {
+		return fmt.Errorf("NearestNeighbors: can't restore distance for P=%g", m.P)
+	}
+	m.Distance = MinkowskiDistance(m.P)
+	return nil
+}
+
+// Restore restores distances after Load
+func (m *KNeighborsClassifier) Restore() error {
+	if m.Distance == nil {
+		m.Distance = EuclideanDistance
+	}
+	return m.NearestNeighbors.Restore()
+}
+
+// isEuclideanDistance returns true for a nil or EuclideanDistance distance, the distances Restore can rebuild
+func isEuclideanDistance(distance Distance) bool {
+	return distance == nil || reflect.ValueOf(distance).Pointer() == reflect.ValueOf(EuclideanDistance).Pointer()
+}
+
+// Save writes the KNeighborsClassifier to w. it fails for a custom Distance, which can't be saved
+func (m *KNeighborsClassifier) Save(w io.Writer) error { return base.Save(w, m) }
+
+// Load reads a KNeighborsClassifier written by Save
+func (m *KNeighborsClassifier) Load(r io.Reader) error { return base.LoadInto(r, m) }
+
+// GobEncode encodes KNeighborsClassifier. it returns a *base.ParamError for a custom Distance
+func (m *KNeighborsClassifier) GobEncode() ([]byte, error) {
+	if !isEuclideanDistance(m.Distance) {
+		return nil, &base.ParamError{Estimator: m, Param: "Distance", Value: "custom", Msg: "only EuclideanDistance can be saved"}
+	}
+	type kNeighborsClassifier KNeighborsClassifier
+	return base.GobEncodeFields((*kNeighborsClassifier)(m))
+}
+
+// GobDecode decodes a KNeighborsClassifier encoded by GobEncode
+func (m *KNeighborsClassifier) GobDecode(b []byte) error {
+	type kNeighborsClassifier KNeighborsClassifier
+	return base.GobDecodeFields(b, (*kNeighborsClassifier)(m))
+}
+
+// Restore restores distances after Load
+func (m *KNeighborsRegressor) Restore() error {
+	if m.Distance == nil {
+		m.Distance = EuclideanDistance
+	}
+	return m.NearestNeighbors.Restore()
+}
+
+// Save writes the KNeighborsRegressor to w. it fails for a custom Distance, which can't be saved
+func (m *KNeighborsRegressor) Save(w io.Writer) error { return base.Save(w, m) }
+
+// Load reads a KNeighborsRegressor written by Save
+func (m *KNeighborsRegressor) Load(r io.Reader) error { return base.LoadInto(r, m) }
+
+// GobEncode encodes KNeighborsRegressor. it returns a *base.ParamError for a custom Distance
+func (m *KNeighborsRegressor) GobEncode() ([]byte, error) {
+	if !isEuclideanDistance(m.Distance) {
+		return nil, &base.ParamError{Estimator: m, Param: "Distance", Value: "custom", Msg: "only EuclideanDistance can be saved"}
+	}
+	type kNeighborsRegressor KNeighborsRegressor
+	return base.GobEncodeFields((*kNeighborsRegressor)(m))
+}
+
+// GobDecode decodes a KNeighborsRegressor encoded by GobEncode
+func (m *KNeighborsRegressor) GobDecode(b []byte) error {
+	type kNeighborsRegressor KNeighborsRegressor
+	return base.GobDecodeFields(b, (*kNeighborsRegressor)(m))
+}
+
+// Save writes the NearestCentroid to w
+func (m *NearestCentroid) Save(w io.Writer) error { return base.Save(w, m) }
+
+// Load reads a NearestCentroid written by Save
+func (m *NearestCentroid) Load(r io.Reader) error { return base.LoadInto(r, m) }
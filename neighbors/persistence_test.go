@@ -0,0 +1,77 @@
+package neighbors
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+func ExampleKNeighborsClassifier_Save() {
+	rnd := rand.New(rand.NewSource(7))
+	X := mat.NewDense(100, 2, nil)
+	Y := mat.NewDense(100, 1, nil)
+	for i := 0; i < 100; i++ {
+		X.Set(i, 0, rnd.NormFloat64())
+		X.Set(i, 1, rnd.NormFloat64())
+		if X.At(i, 0)+X.At(i, 1) > 0 {
+			Y.Set(i, 0, 1)
+		}
+	}
+	neigh := NewKNeighborsClassifier(3, "distance")
+	neigh.Algorithm = "kd_tree"
+	neigh.LeafSize = 8
+	neigh.Fit(X, Y)
+	var buf bytes.Buffer
+	if err := neigh.Save(&buf); err != nil {
+		fmt.Println(err)
+	}
+	loaded := &KNeighborsClassifier{}
+	if err := loaded.Load(&buf); err != nil {
+		fmt.Println(err)
+	}
+	Ypred, Yloaded := mat.NewDense(100, 1, nil), mat.NewDense(100, 1, nil)
+	neigh.Predict(X, Ypred)
+	loaded.Predict(X, Yloaded)
+	fmt.Println(loaded.Tree != nil, mat.Equal(Ypred, Yloaded))
+	// Output:
+	// true true
+}
+
+func TestSaveCustomDistance(t *testing.T) {
+	X := mat.NewDense(4, 1, []float64{0, 1, 2, 3})
+	Y := mat.NewDense(4, 1, []float64{0, 0, 1, 1})
+	manhattan := MinkowskiDistance(1)
+	classifier, regressor := NewKNeighborsClassifier(1, "uniform"), NewKNeighborsRegressor(1, "uniform").(*KNeighborsRegressor)
+	classifier.Distance, regressor.Distance = manhattan, manhattan
+	classifier.Fit(X, Y)
+	regressor.Fit(X, Y)
+	var paramErr *base.ParamError
+	var buf bytes.Buffer
+	if err := classifier.Save(&buf); !errors.As(err, &paramErr) || paramErr.Param != "Distance" {
+		t.Errorf("expected a *base.ParamError for a custom Distance, got %v", err)
+	}
+	if err := regressor.Save(&buf); !errors.As(err, &paramErr) || paramErr.Param != "Distance" {
+		t.Errorf("expected a *base.ParamError for a custom Distance, got %v", err)
+	}
+	// EuclideanDistance round-trips
+	regressor.Distance = EuclideanDistance
+	buf.Reset()
+	if err := regressor.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded := &KNeighborsRegressor{}
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	Ypred, Yloaded := mat.NewDense(4, 1, nil), mat.NewDense(4, 1, nil)
+	regressor.Predict(X, Ypred)
+	loaded.Predict(X, Yloaded)
+	if !mat.Equal(Ypred, Yloaded) {
+		t.Errorf("expected %g, got %g", mat.Col(nil, 0, Ypred), mat.Col(nil, 0, Yloaded))
+	}
+}
@@ -23,6 +23,8 @@ type KNeighborsRegressor struct {
 	Distance Distance
 	// Runtime members
 	Xscaled, Y *mat.Dense
+	// SampleWeight is the weight of each fitted sample, set by FitWeighted. nil weighs all samples 1
+	SampleWeight []float64
 }
 
 // NewKNeighborsRegressor returns an initialized *KNeighborsRegressor
@@ -30,8 +32,15 @@ func NewKNeighborsRegressor(K int, Weights string) base.Regressor {
 	return &KNeighborsRegressor{NearestNeighbors: *NewNearestNeighbors(), K: K, Weight: Weights}
 }
 
+// Clone for KNeighborsRegressor
+func (m *KNeighborsRegressor) Clone() base.Transformer {
+	clone := *m
+	return &clone
+}
+
 // Fit ...
 func (m *KNeighborsRegressor) Fit(X, Y *mat.Dense) base.Transformer {
+	m.SampleWeight = nil
 	m.Xscaled = mat.DenseCopyOf(X)
 	m.Y = mat.DenseCopyOf(Y)
 	if m.Distance == nil {
@@ -44,6 +53,42 @@ func (m *KNeighborsRegressor) Fit(X, Y *mat.Dense) base.Transformer {
 	return m
 }
 
+// FitE is Fit returning an error instead of panicking
+func (m *KNeighborsRegressor) FitE(X, Y *mat.Dense) error {
+	if err := checkKNeighborsFit(m, "KNeighborsRegressor.Fit", X, Y, m.K); err != nil {
+		return err
+	}
+	return base.Recover(func() { m.Fit(X, Y) })
+}
+
+// FitWeighted is FitE with the weights of the neighbors of each predicted sample multiplied by their sampleWeight
+func (m *KNeighborsRegressor) FitWeighted(X, Y *mat.Dense, sampleWeight []float64) error {
+	if err := checkKNeighborsFit(m, "KNeighborsRegressor.FitWeighted", X, Y, m.K); err != nil {
+		return err
+	}
+	if err := base.CheckSampleWeight("KNeighborsRegressor.FitWeighted", X, sampleWeight); err != nil {
+		return err
+	}
+	return base.Recover(func() {
+		m.Fit(X, Y)
+		if sampleWeight != nil {
+			m.SampleWeight = append([]float64(nil), sampleWeight...)
+		}
+	})
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *KNeighborsRegressor) PredictE(X, Y *mat.Dense) error {
+	if m.Y == nil {
+		return &base.NotFittedError{Estimator: m}
+	}
+	_, nOutputs := m.Y.Dims()
+	if err := m.NearestNeighbors.checkPredict(m, "KNeighborsRegressor.Predict", X, Y, m.K, nOutputs); err != nil {
+		return err
+	}
+	return base.Recover(func() { m.Predict(X, Y) })
+}
+
 // Predict ...
 func (m *KNeighborsRegressor) Predict(X, Y *mat.Dense) base.Regressor {
 	NFitSamples, _ := m.Xscaled.Dims()
@@ -60,19 +105,21 @@ func (m *KNeighborsRegressor) Predict(X, Y *mat.Dense) base.Regressor {
 		weights := make([]float64, m.K)
 		ys := make([]float64, m.K)
 		epsilon := 1e-15
-		for ik := range weights {
-			weights[ik] = 1.
-		}
 		for sample := start; sample < end; sample++ {
 			// sort idx to get first K nearest
 			sort.Slice(idx, func(i, j int) bool { return d2[idx[i]] < d2[idx[j]] })
 			// set Y(sample,output) to weighted average of K nearest
 			for o := 0; o < outputs; o++ {
 				for ik := range ys {
-					ys[ik] = m.Y.At(int(indices.At(sample, ik)), o)
+					neighbor := int(indices.At(sample, ik))
+					ys[ik] = m.Y.At(neighbor, o)
+					weights[ik] = 1.
 					if isWeightDistance {
 						weights[ik] = 1. / (epsilon + distances.At(sample, ik))
 					}
+					if m.SampleWeight != nil {
+						weights[ik] *= m.SampleWeight[neighbor]
+					}
 				}
 				Y.Set(sample, o, stat.Mean(ys, weights))
 			}
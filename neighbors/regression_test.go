@@ -19,3 +19,19 @@ func ExampleKNeighborsRegressor() {
 	// Output:
 	// [0.5]
 }
+
+func ExampleKNeighborsRegressor_FitWeighted() {
+	X := mat.NewDense(4, 1, []float64{0, 1, 2, 3})
+	Y := mat.NewDense(4, 1, []float64{0, 0, 1, 1})
+	neigh := NewKNeighborsRegressor(2, "uniform").(*KNeighborsRegressor)
+	// the sample at 2 weighs as 3 samples at 1
+	if err := neigh.FitWeighted(X, Y, []float64{1, 1, 3, 1}); err != nil {
+		panic(err)
+	}
+	Xtest := mat.NewDense(1, 1, []float64{1.5})
+	Ypred := mat.NewDense(1, 1, nil)
+	neigh.Predict(Xtest, Ypred)
+	fmt.Println(mat.Formatted(Ypred))
+	// Output:
+	// [0.75]
+}
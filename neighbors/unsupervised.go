@@ -1,12 +1,14 @@
 package neighbors
 
 import (
+	"fmt"
 	"math"
 	"runtime"
 	"sort"
 	"strings"
 
 	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/sparse"
 
 	"gonum.org/v1/gonum/mat"
 )
@@ -17,6 +19,8 @@ import (
 // Metric = 'cityblock', 'cosine', 'euclidean', 'l1', 'l2', 'manhattan' defaults to euclidean (= minkowski with P=2)
 // P is power for 'minkowski'
 // NJobs: number of concurrent jobs. NJobs<0 means runtime.NumCPU()  default to -1
+// a sparse X (*sparse.CSR or *sparse.CSC) is kept in SparseX instead of X and searched by brute force.
+// a *base.Dense32 X is kept in float32 in X32 instead of X, its KDTree too
 type NearestNeighbors struct {
 	Algorithm string
 	Metric    string
@@ -26,6 +30,8 @@ type NearestNeighbors struct {
 	// Runtime filled members
 	Distance func(a, b mat.Vector) float64
 	X, Y     *mat.Dense
+	X32      *base.Dense32
+	SparseX  *sparse.CSR
 	Tree     *KDTree
 }
 
@@ -50,7 +56,16 @@ func (m *NearestNeighbors) Fit(X mat.Matrix) {
 	if m.NJobs < 0 {
 		m.NJobs = runtime.NumCPU()
 	}
-	m.X = mat.DenseCopyOf(X)
+	m.X, m.X32, m.SparseX, m.Tree = nil, nil, nil, nil
+	if sparse.IsSparse(X) {
+		m.SparseX = sparse.CSRCopyOf(X)
+		return
+	}
+	if X32, ok := X.(*base.Dense32); ok {
+		m.X32 = base.Dense32Of(X32)
+	} else {
+		m.X = mat.DenseCopyOf(X)
+	}
 	useKDTree := strings.Contains(strings.ToLower(m.Algorithm), "tree") || (m.Algorithm == "auto" && r*c > 1000)
 	if useKDTree {
 		if m.LeafSize <= 0 {
@@ -60,17 +75,78 @@ func (m *NearestNeighbors) Fit(X mat.Matrix) {
 	}
 }
 
+// FitE is Fit returning an error instead of panicking. Y is ignored
+func (m *NearestNeighbors) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckXY("NearestNeighbors.Fit", X, nil); err != nil {
+		return err
+	}
+	switch m.Metric {
+	case "manhattan", "cityblock", "euclidean":
+	default:
+		if m.P <= 0 {
+			return &base.ParamError{Estimator: m, Param: "P", Value: m.P, Msg: "must be > 0"}
+		}
+	}
+	return base.Recover(func() { m.Fit(X) })
+}
+
+// fitDims returns the dimensions of the fitted X
+func (m *NearestNeighbors) fitDims() (r, c int) {
+	if m.SparseX != nil {
+		return m.SparseX.Dims()
+	}
+	if m.X32 != nil {
+		return m.X32.Dims()
+	}
+	return m.X.Dims()
+}
+
+// fitRow returns the fitted sample i
+func (m *NearestNeighbors) fitRow(i int) mat.Vector {
+	if m.X32 != nil {
+		return m.X32.RowView(i)
+	}
+	return m.X.RowView(i)
+}
+
+// checkPredict returns an error if m is not fitted or if X,Y are not suitable for predicting nOutputs outputs with K neighbors
+func (m *NearestNeighbors) checkPredict(estimator interface{}, op string, X, Y *mat.Dense, K, nOutputs int) error {
+	if m.X == nil && m.X32 == nil && m.SparseX == nil {
+		return &base.NotFittedError{Estimator: estimator}
+	}
+	if err := base.CheckXY(op, X, Y); err != nil {
+		return err
+	}
+	nFitSamples, nFeatures := m.fitDims()
+	if err := base.CheckNFeatures(op, X, nFeatures); err != nil {
+		return err
+	}
+	if K > nFitSamples {
+		return &base.ShapeError{Op: op, Msg: fmt.Sprintf("%d neighbors requested but only %d samples fitted", K, nFitSamples)}
+	}
+	if Y == nil || Y.IsZero() {
+		return &base.ShapeError{Op: op, Msg: "Y must be allocated"}
+	}
+	if _, c := Y.Dims(); c != nOutputs {
+		return &base.ShapeError{Op: op, Msg: fmt.Sprintf("Y has %d columns, expected %d", c, nOutputs)}
+	}
+	return nil
+}
+
 // KNeighbors returns distances and indices of first NNeighbors
 func (m *NearestNeighbors) KNeighbors(X mat.Matrix, NNeighbors int) (distances, indices *mat.Dense) {
 	NSamples, NFeatures := X.Dims()
 	if m.Tree != nil {
 		return m.Tree.Query(X, NNeighbors, 1e-15, m.P, math.Inf(1))
 	}
+	if m.SparseX != nil {
+		return m.sparseKNeighbors(X, NNeighbors)
+	}
 	distances = mat.NewDense(NSamples, NNeighbors, nil)
 	indices = mat.NewDense(NSamples, NNeighbors, nil)
 	base.Parallelize(m.NJobs, NSamples, func(th, start, end int) {
 		Xsample := mat.NewVecDense(NFeatures, nil)
-		NFitSamples, _ := m.X.Dims()
+		NFitSamples, _ := m.fitDims()
 		idx := make([]int, NFitSamples, NFitSamples)
 		sampleDistance := make([]float64, NFitSamples, NFitSamples)
 
@@ -79,7 +155,7 @@ func (m *NearestNeighbors) KNeighbors(X mat.Matrix, NNeighbors int) (distances,
 			mat.Row(Xsample.RawVector().Data, sample, X)
 			base.Parallelize(m.NJobs, NFitSamples, func(th, start, end int) {
 				for ifs := start; ifs < end; ifs++ {
-					sampleDistance[ifs] = m.Distance(Xsample, m.X.RowView(ifs))
+					sampleDistance[ifs] = m.Distance(Xsample, m.fitRow(ifs))
 					idx[ifs] = ifs
 				}
 			})
@@ -101,7 +177,7 @@ func (m *NearestNeighbors) KNeighbors(X mat.Matrix, NNeighbors int) (distances,
 //     n_samples_fit is the number of samples in the fitted data A[i, j] is assigned the weight of edge that connects i to j.
 func (m *NearestNeighbors) KNeighborsGraph(X *mat.Dense, NNeighbors int, mode string, includeSelf bool) (graph *mat.Dense) {
 	NSamples, _ := X.Dims()
-	NSamplesFit, _ := m.X.Dims()
+	NSamplesFit, _ := m.fitDims()
 	distances, indices := m.KNeighbors(X, NNeighbors)
 	graph = mat.NewDense(NSamples, NSamplesFit, nil)
 	var source *mat.Dense
@@ -145,7 +221,7 @@ func (m *NearestNeighbors) RadiusNeighbors(X *mat.Dense, radius float64) (distan
 	NSamples, _ := X.Dims()
 	distances = make([][]float64, NSamples)
 	indices = make([][]int, NSamples)
-	NFitSamples, _ := m.X.Dims()
+	NFitSamples, _ := m.fitDims()
 	if m.Tree == nil {
 		Mdistances, Mindices := m.KNeighbors(X, NFitSamples)
 		base.Parallelize(m.NJobs, NSamples, func(th, start, end int) {
@@ -176,3 +252,41 @@ func (m *NearestNeighbors) RadiusNeighbors(X *mat.Dense, radius float64) (distan
 	}
 	return
 }
+
+// sparseKNeighbors is KNeighbors by brute force on SparseX. euclidean distances are computed from squared norms and dot products
+func (m *NearestNeighbors) sparseKNeighbors(X mat.Matrix, NNeighbors int) (distances, indices *mat.Dense) {
+	Xs := sparse.CSROf(X)
+	NSamples, _ := Xs.Dims()
+	NFitSamples, _ := m.SparseX.Dims()
+	distances = mat.NewDense(NSamples, NNeighbors, nil)
+	indices = mat.NewDense(NSamples, NNeighbors, nil)
+	fitNorms := make([]float64, NFitSamples)
+	if m.P == 2 {
+		for ifs := range fitNorms {
+			fitNorms[ifs] = sparse.Norm2(m.SparseX.Row(ifs))
+		}
+	}
+	base.Parallelize(m.NJobs, NSamples, func(th, start, end int) {
+		idx := make([]int, NFitSamples, NFitSamples)
+		sampleDistance := make([]float64, NFitSamples, NFitSamples)
+		for sample := start; sample < end; sample++ {
+			a := Xs.Row(sample)
+			norm := sparse.Norm2(a)
+			for ifs := range sampleDistance {
+				b := m.SparseX.Row(ifs)
+				if m.P == 2 {
+					sampleDistance[ifs] = math.Sqrt(math.Max(0, norm+fitNorms[ifs]-2*sparse.Dot(a, b)))
+				} else {
+					sampleDistance[ifs] = sparse.MinkowskiDistance(a, b, m.P)
+				}
+				idx[ifs] = ifs
+			}
+			sort.SliceStable(idx, func(i, j int) bool { return sampleDistance[idx[i]] < sampleDistance[idx[j]] })
+			for ik := 0; ik < NNeighbors; ik++ {
+				indices.Set(sample, ik, float64(idx[ik]))
+				distances.Set(sample, ik, sampleDistance[idx[ik]])
+			}
+		}
+	})
+	return
+}
@@ -3,7 +3,10 @@ package neighbors
 import (
 	"fmt"
 	"math"
+	"testing"
 
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/sparse"
 	"gonum.org/v1/gonum/mat"
 )
 
@@ -102,3 +105,44 @@ func ExampleNearestNeighbors_RadiusNeighbors() {
 	// [2 1]
 
 }
+
+func TestNearestNeighborsSparse(t *testing.T) {
+	X := mat.NewDense(6, 3, []float64{-1, 0, -1, -2, 0, -1, -3, 0, 0, 0, 1, 1, 2, 1, 0, 3, 2, 0})
+	for _, metric := range []string{"euclidean", "manhattan"} {
+		dense, sp := NewNearestNeighbors(), NewNearestNeighbors()
+		dense.Metric, sp.Metric = metric, metric
+		dense.Algorithm = "brute"
+		dense.Fit(X)
+		sp.Fit(sparse.CSRCopyOf(X))
+		if sp.SparseX == nil || sp.X != nil {
+			t.Fatal("expected a sparse fit")
+		}
+		distances, indices := dense.KNeighbors(X, 3)
+		sdistances, sindices := sp.KNeighbors(sparse.CSRCopyOf(X), 3)
+		if !mat.Equal(indices, sindices) || !mat.EqualApprox(distances, sdistances, 1e-7) {
+			t.Errorf("%s: expected\n%g\n%g\ngot\n%g\n%g", metric, mat.Formatted(indices), mat.Formatted(distances), mat.Formatted(sindices), mat.Formatted(sdistances))
+		}
+	}
+}
+
+func TestNearestNeighborsDense32(t *testing.T) {
+	X := mat.NewDense(6, 3, []float64{-1, 0, -1, -2, 0, -1, -3, 0, 0, 0, 1, 1, 2, 1, 0, 3, 2, 0})
+	X32 := base.Dense32Of(X)
+	for _, algorithm := range []string{"brute", "kd_tree"} {
+		dense, m32 := NewNearestNeighbors(), NewNearestNeighbors()
+		dense.Algorithm, m32.Algorithm = algorithm, algorithm
+		dense.Fit(X)
+		m32.Fit(X32)
+		if m32.X32 == nil || m32.X != nil {
+			t.Fatal("expected a float32 fit")
+		}
+		if m32.Tree != nil && (m32.Tree.Data32 == nil || m32.Tree.Data != nil) {
+			t.Fatal("expected a float32 tree")
+		}
+		distances, indices := dense.KNeighbors(X, 3)
+		distances32, indices32 := m32.KNeighbors(X32, 3)
+		if !mat.Equal(indices, indices32) || !mat.EqualApprox(distances, distances32, 1e-7) {
+			t.Errorf("%s: expected\n%g\n%g\ngot\n%g\n%g", algorithm, mat.Formatted(indices), mat.Formatted(distances), mat.Formatted(indices32), mat.Formatted(distances32))
+		}
+	}
+}
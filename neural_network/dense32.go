@@ -0,0 +1,86 @@
+package neuralnetwork
+
+import (
+	"context"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// miniBatchX returns the rows start to end-1 of X, a *mat.Dense view or a float64 copy of the rows of a *base.Dense32
+func miniBatchX(X mat.Matrix, start, end int) *mat.Dense {
+	if X32, ok := X.(*base.Dense32); ok {
+		return X32.SliceRows(start, end).ToDense()
+	}
+	return base.MatDenseRowSlice(X.(*mat.Dense), start, end)
+}
+
+// shuffleDense32 swaps the rows of X and Y as preprocessing.Shuffler does for perm, or undoes the swaps if inverse is true
+func shuffleDense32(perm []int, X *base.Dense32, Y *mat.Dense, inverse bool) {
+	swap := func(i int) {
+		if perm[i] <= i {
+			return
+		}
+		X.SwapRows(i, perm[i])
+		yi, yj := Y.RawRowView(i), Y.RawRowView(perm[i])
+		for o := range yi {
+			yi[o], yj[o] = yj[o], yi[o]
+		}
+	}
+	if inverse {
+		for i := len(perm) - 1; i >= 0; i-- {
+			swap(i)
+		}
+		return
+	}
+	for i := range perm {
+		swap(i)
+	}
+}
+
+// FitDense32 is Fit for a float32 X. X stays in float32 and each minibatch is converted to float64.
+// the rows of X are shuffled in place during each epoch, and restored at its end
+func (regr *MLPRegressor) FitDense32(X *base.Dense32, Y *mat.Dense) base.Transformer {
+	if err := regr.fit(context.Background(), X, Y, nil); err != nil {
+		panic(err)
+	}
+	return regr
+}
+
+// PredictDense32 is Predict for a float32 X, converted to float64 by blocks of MiniBatchSize rows
+func (regr *MLPRegressor) PredictDense32(X *base.Dense32, Y *mat.Dense) base.Regressor {
+	nSamples, _ := X.Dims()
+	_, nOutputs := Y.Dims()
+	blockSize := regr.MiniBatchSize
+	if blockSize <= 0 {
+		blockSize = 200
+	}
+	for start := 0; start < nSamples; start += blockSize {
+		end := start + blockSize
+		if end > nSamples {
+			end = nSamples
+		}
+		regr.forward(X.SliceRows(start, end).ToDense(), Y.Slice(start, end, 0, nOutputs).(*mat.Dense))
+	}
+	return regr
+}
+
+// FitDense32 is Fit for a float32 X. see MLPRegressor.FitDense32
+func (regr *MLPClassifier) FitDense32(X *base.Dense32, Y *mat.Dense) base.Transformer {
+	if err := regr.fit(context.Background(), X, Y, nil); err != nil {
+		panic(err)
+	}
+	return regr
+}
+
+// PredictDense32 is Predict for a float32 X. see MLPRegressor.PredictDense32
+func (regr *MLPClassifier) PredictDense32(X *base.Dense32, Y *mat.Dense) base.Regressor {
+	regr.MLPRegressor.PredictDense32(X, Y)
+	Y.Apply(func(i, o int, y float64) float64 {
+		if y >= .5 {
+			return 1
+		}
+		return 0
+	}, Y)
+	return regr
+}
@@ -0,0 +1,64 @@
+package neuralnetwork
+
+import (
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestMLPRegressorDense32(t *testing.T) {
+	p := NewRandomProblem(300, 4, 2, "identity", "square")
+	// float32 elements, so that the minibatches of X32 are exactly the ones of X
+	p.X.Apply(func(_, _ int, v float64) float64 { return float64(float32(v)) }, p.X)
+	X32 := base.Dense32Of(p.X)
+	newMLP := func() *MLPRegressor {
+		mlp := NewMLPRegressor([]int{5}, "relu", "adam", 1e-4)
+		mlp.RandomState = base.NewRandomState(7)
+		mlp.Epochs = 5
+		mlp.MiniBatchSize = 64
+		return mlp
+	}
+	dense, m32 := newMLP(), newMLP()
+	dense.Fit(p.X, p.Y)
+	m32.FitDense32(X32, p.Y)
+	for l := range dense.Layers {
+		if !mat.Equal(dense.Layers[l].Theta, m32.Layers[l].Theta) {
+			t.Errorf("layer %d: FitDense32 theta differs from Fit theta", l)
+		}
+	}
+	if !mat.Equal(X32, p.X) {
+		t.Error("X32 is not restored after the shuffles")
+	}
+	Ydense, Y32 := mat.NewDense(300, 2, nil), mat.NewDense(300, 2, nil)
+	m32.Predict(p.X, Ydense)
+	m32.PredictDense32(X32, Y32)
+	if !mat.EqualApprox(Ydense, Y32, 1e-12) {
+		t.Error("PredictDense32 differs from Predict")
+	}
+}
+
+func TestMLPClassifierDense32(t *testing.T) {
+	p := NewRandomProblem(100, 3, 2, "logistic", "cross-entropy")
+	p.X.Apply(func(_, _ int, v float64) float64 { return float64(float32(v)) }, p.X)
+	X32 := base.Dense32Of(p.X)
+	newMLP := func() *MLPClassifier {
+		mlp := NewMLPClassifier([]int{}, "logistic", "adam", 0)
+		mlp.RandomState = base.NewRandomState(7)
+		mlp.Epochs = 5
+		mlp.ClassWeight = map[float64]float64{0: 2}
+		return mlp
+	}
+	dense, m32 := newMLP(), newMLP()
+	dense.Fit(p.X, p.Y)
+	m32.FitDense32(X32, p.Y)
+	if !mat.Equal(dense.Layers[0].Theta, m32.Layers[0].Theta) {
+		t.Error("FitDense32 theta differs from Fit theta")
+	}
+	Ydense, Y32 := mat.NewDense(100, 2, nil), mat.NewDense(100, 2, nil)
+	m32.Predict(p.X, Ydense)
+	m32.PredictDense32(X32, Y32)
+	if !mat.Equal(Ydense, Y32) {
+		t.Error("PredictDense32 differs from Predict")
+	}
+}
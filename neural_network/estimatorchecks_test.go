@@ -0,0 +1,23 @@
+package neuralnetwork
+
+import (
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/base/estimatorchecks"
+)
+
+func TestEstimatorChecks(t *testing.T) {
+	X, Y := estimatorchecks.RegressionData()
+	regr := NewMLPRegressor([]int{4}, "relu", "adam", 1e-4)
+	regr.RandomState, regr.Epochs = base.NewRandomState(7), 20
+	if err := estimatorchecks.CheckEstimator(regr, X, Y); err != nil {
+		t.Error(err)
+	}
+	X, Y = estimatorchecks.ClassificationData()
+	clf := NewMLPClassifier([]int{4}, "relu", "adam", 1e-4)
+	clf.RandomState, clf.Epochs = base.NewRandomState(7), 20
+	if err := estimatorchecks.CheckEstimator(clf, X, Y); err != nil {
+		t.Error(err)
+	}
+}
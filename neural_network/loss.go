@@ -76,6 +76,24 @@ func (crossEntropyLoss) Loss(Ytrue, Ypred, Grad *mat.Dense, nSamples int) float6
 	return J
 }
 
+// weightedLoss is loss.Loss with the loss and gradient of row i multiplied by sampleWeight.AtVec(i)
+func weightedLoss(loss LossFunctions, Ytrue, Ypred, Grad *mat.Dense, sampleWeight mat.Vector, nSamples int) float64 {
+	nRows, nOutputs := Ytrue.Dims()
+	J := 0.
+	for i := 0; i < nRows; i++ {
+		w := sampleWeight.AtVec(i)
+		var grad *mat.Dense
+		if Grad != nil {
+			grad = Grad.Slice(i, i+1, 0, nOutputs).(*mat.Dense)
+		}
+		J += w * loss.Loss(Ytrue.Slice(i, i+1, 0, nOutputs).(*mat.Dense), Ypred.Slice(i, i+1, 0, nOutputs).(*mat.Dense), grad, nSamples)
+		if grad != nil {
+			grad.Scale(w, grad)
+		}
+	}
+	return J
+}
+
 // SupportedLoss are the map[string]Losser of available matrix loss function providers
 var SupportedLoss = map[string]LossFunctions{
 	"square":        squareLoss{},
@@ -1,10 +1,11 @@
 package neuralnetwork
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math"
-	"math/rand"
+	"time"
 
 	"github.com/pa-m/sklearn/metrics"
 
@@ -33,11 +34,14 @@ type Layer struct {
 
 // NewLayer creates a randomly initialized layer
 // activation is a string or implements ActivationFunctions
+// rnd draws the initial weights, if nil they are drawn uniformly in [-.5,.5) from a base.RandomState seeded from math/rand
 func NewLayer(inputs, outputs int, activation interface{}, optimCreator base.OptimCreator, thetaSlice, gradSlice, updateSlice []float64, rnd func() float64) *Layer {
 
 	Theta := mat.NewDense(inputs, outputs, thetaSlice)
 	if rnd == nil {
-		rnd = func() float64 { return -.5 + rand.Float64() }
+		rnd = func(r *base.RandomState) func() float64 {
+			return func() float64 { return -.5 + r.Float64() }
+		}(base.CheckRandomState(nil))
 	}
 	Theta.Apply(func(feature, output int, _ float64) float64 { return rnd() }, Theta)
 	matx{Dense: Theta}.Orthonormalize()
@@ -89,13 +93,14 @@ var Regressors = []base.Regressor{&MLPRegressor{}}
 
 // MLPRegressor is a multilayer perceptron regressor
 // Activation is a string (identity,logistic,tanh,relu,paramrelu,elu) or implements ActivationFunctions
+// RandomState draws the initial weights and the shuffles of each epoch (see base.CheckRandomState)
 type MLPRegressor struct {
 	Shuffle, UseBlas        bool
 	Optimizer               base.OptimCreator
 	Activation              interface{}
 	Solver                  string
 	HiddenLayerSizes        []int
-	RandomState             *int64
+	RandomState             *base.RandomState
 	WeightDecay             float64
 	EarlyStopping           bool
 	MaxEpochWithoutProgress int
@@ -107,6 +112,9 @@ type MLPRegressor struct {
 	Loss string
 	// run values
 	thetaSlice, gradSlice, updateSlice []float64
+	randomState                        *base.RandomState
+	// weighted is true when the last column of Y passed to fitEpoch holds the sample weights
+	weighted bool
 	// Loss value after Fit
 	JFirst, J float64
 }
@@ -167,13 +175,11 @@ func (regr *MLPRegressor) inputs(prevOutputs int) (inputs int) {
 func (regr *MLPRegressor) allocLayers(nFeatures, nOutputs int, rnd func() float64) {
 	var thetaLen, thetaOffset, thetaLen1 int
 	regr.Layers = make([]*Layer, 0)
-	randFloat64 := rand.Float64
-	if regr.RandomState != nil {
-		randFloat64 = rand.New(rand.NewSource(*regr.RandomState)).Float64
+	if regr.randomState == nil {
+		regr.randomState = base.CheckRandomState(regr.RandomState)
 	}
-
-	if rnd == nil && regr.RandomState != nil {
-		rnd = func() float64 { return -.5 + 2*randFloat64() }
+	if rnd == nil {
+		rnd = func() float64 { return -.5 + 2*regr.randomState.Float64() }
 	}
 
 	prevOutputs := nFeatures
@@ -216,11 +222,62 @@ func (regr *MLPRegressor) allocLayers(nFeatures, nOutputs int, rnd func() float6
 
 // Fit fits an MLPRegressor
 func (regr *MLPRegressor) Fit(X, Y *mat.Dense) base.Transformer {
+	if err := regr.fit(context.Background(), X, Y, nil); err != nil {
+		panic(err)
+	}
+	return regr
+}
+
+// FitContext is FitE stopping between epochs when ctx is done. it reports the loss of each epoch to the
+// ProgressCallback of ctx
+func (regr *MLPRegressor) FitContext(ctx context.Context, X, Y *mat.Dense) error {
+	if err := base.CheckFitXY("MLPRegressor.Fit", X, Y); err != nil {
+		return err
+	}
+	if err := regr.checkParams(); err != nil {
+		return err
+	}
+	var err error
+	if rerr := base.Recover(func() { err = regr.fit(ctx, X, Y, nil) }); rerr != nil {
+		return rerr
+	}
+	return err
+}
+
+// FitWeighted is FitE with the loss of each sample weighted by sampleWeight
+func (regr *MLPRegressor) FitWeighted(X, Y *mat.Dense, sampleWeight []float64) error {
+	if err := base.CheckFitXY("MLPRegressor.FitWeighted", X, Y); err != nil {
+		return err
+	}
+	if err := base.CheckSampleWeight("MLPRegressor.FitWeighted", X, sampleWeight); err != nil {
+		return err
+	}
+	if err := regr.checkParams(); err != nil {
+		return err
+	}
+	var err error
+	if rerr := base.Recover(func() { err = regr.fit(context.Background(), X, Y, sampleWeight) }); rerr != nil {
+		return rerr
+	}
+	return err
+}
+
+// fit fits the layers to X, a *mat.Dense or a *base.Dense32, and Y
+func (regr *MLPRegressor) fit(ctx context.Context, X mat.Matrix, Y *mat.Dense, sampleWeight []float64) error {
+	start := time.Now()
 	nSamples, nFeatures := X.Dims()
 	_, nOutputs := Y.Dims()
-	// create layers
-	var rnd func() float64
-	regr.allocLayers(nFeatures, nOutputs, rnd)
+	// weights are appended to Y so that they are shuffled with it
+	regr.weighted = sampleWeight != nil
+	if regr.weighted {
+		Yw := mat.NewDense(nSamples, nOutputs+1, nil)
+		Yw.Slice(0, nSamples, 0, nOutputs).(*mat.Dense).Copy(Y)
+		Yw.SetCol(nOutputs, sampleWeight)
+		Y = Yw
+	}
+	// create layers, drawing initial weights and shuffles from RandomState
+	regr.randomState = base.CheckRandomState(regr.RandomState)
+	regr.allocLayers(nFeatures, nOutputs, nil)
 	// J is the loss value
 	regr.J = math.Inf(1)
 	if regr.Epochs <= 0 {
@@ -228,7 +285,9 @@ func (regr *MLPRegressor) Fit(X, Y *mat.Dense) base.Transformer {
 	}
 	switch {
 	case isGOMethodOnly(regr.Solver):
-		regr.fitGOM(X, Y)
+		if _, err := regr.fitGOM(ctx, X, Y); err != nil {
+			return err
+		}
 
 	default:
 		prevLoss := math.Inf(1)
@@ -237,7 +296,11 @@ func (regr *MLPRegressor) Fit(X, Y *mat.Dense) base.Transformer {
 			regr.MaxEpochWithoutProgress = 10
 		}
 		for epoch := 0; epoch < regr.Epochs; epoch++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
 			loss := regr.fitEpoch(X, Y, epoch)
+			base.ReportProgress(ctx, base.Progress{Estimator: regr, Epoch: epoch + 1, Epochs: regr.Epochs, Loss: loss, ValidationScore: math.NaN(), Elapsed: time.Since(start)})
 			if loss >= prevLoss {
 				NNoProgress++
 				if NNoProgress == regr.MaxEpochWithoutProgress {
@@ -252,12 +315,77 @@ func (regr *MLPRegressor) Fit(X, Y *mat.Dense) base.Transformer {
 
 		}
 	}
-	return regr
+	return nil
+}
+
+// FitE is Fit returning an error instead of panicking
+func (regr *MLPRegressor) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckFitXY("MLPRegressor.Fit", X, Y); err != nil {
+		return err
+	}
+	if err := regr.checkParams(); err != nil {
+		return err
+	}
+	return base.Recover(func() { regr.Fit(X, Y) })
+}
+
+func (regr *MLPRegressor) checkParams() error {
+	if _, ok := regr.Activation.(ActivationFunctions); !ok {
+		name, _ := regr.Activation.(string)
+		if _, ok := SupportedActivations[name]; !ok {
+			return &base.ParamError{Estimator: regr, Param: "Activation", Value: regr.Activation}
+		}
+	}
+	if _, ok := base.GOMethodCreators[regr.Solver]; !ok {
+		return &base.ParamError{Estimator: regr, Param: "Solver", Value: regr.Solver}
+	}
+	if !isGOMethodOnly(regr.Solver) && regr.Optimizer == nil {
+		return &base.ParamError{Estimator: regr, Param: "Optimizer", Value: regr.Optimizer, Msg: "Optimizer is required for solver " + regr.Solver}
+	}
+	if _, ok := SupportedLoss[regr.Loss]; !ok {
+		return &base.ParamError{Estimator: regr, Param: "Loss", Value: regr.Loss}
+	}
+	for _, size := range regr.HiddenLayerSizes {
+		if size <= 0 {
+			return &base.ParamError{Estimator: regr, Param: "HiddenLayerSizes", Value: regr.HiddenLayerSizes, Msg: "sizes must be > 0"}
+		}
+	}
+	if regr.Alpha < 0 {
+		return &base.ParamError{Estimator: regr, Param: "Alpha", Value: regr.Alpha, Msg: "must be >= 0"}
+	}
+	return nil
+}
+
+// checkPredict returns an error if regr is not fitted or if X,Y are not suitable for prediction
+func (regr *MLPRegressor) checkPredict(estimator interface{}, op string, X, Y *mat.Dense) error {
+	if len(regr.Layers) == 0 {
+		return &base.NotFittedError{Estimator: estimator}
+	}
+	if err := base.CheckFitXY(op, X, Y); err != nil {
+		return err
+	}
+	nInputs, _ := regr.Layers[0].Theta.Dims()
+	if err := base.CheckNFeatures(op, X, nInputs-1); err != nil {
+		return err
+	}
+	_, nOutputs := regr.Layers[len(regr.Layers)-1].Theta.Dims()
+	if _, c := Y.Dims(); c != nOutputs {
+		return &base.ShapeError{Op: op, Msg: fmt.Sprintf("Y has %d columns, expected %d", c, nOutputs)}
+	}
+	return nil
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (regr *MLPRegressor) PredictE(X, Y *mat.Dense) error {
+	if err := regr.checkPredict(regr, "MLPRegressor.Predict", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { regr.Predict(X, Y) })
 }
 
 // fitGOM fits with a gonum/optimize Method
 
-func (regr *MLPRegressor) fitGOM(X, Y *mat.Dense) float64 {
+func (regr *MLPRegressor) fitGOM(ctx context.Context, X mat.Matrix, Y *mat.Dense) (float64, error) {
 	epoch := 0
 
 	p := optimize.Problem{
@@ -278,24 +406,35 @@ func (regr *MLPRegressor) fitGOM(X, Y *mat.Dense) float64 {
 	method := base.GOMethodCreators[regr.Solver]()
 	settings := &optimize.Settings{}
 	settings.FuncEvaluations = regr.Epochs
+	settings.Recorder = &base.ContextRecorder{Context: ctx, Estimator: regr, Epochs: regr.Epochs}
 
 	ret, err := optimize.Minimize(p, regr.thetaSlice, settings, method)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return math.NaN(), ctxErr
+	}
 	if err != nil {
 		fmt.Println(err)
 	}
 	copy(regr.thetaSlice, ret.X)
 	regr.J = ret.F
-	return ret.F
+	return ret.F, nil
 }
 
-// fitEpoch fits one epoch
-func (regr *MLPRegressor) fitEpoch(Xfull, Yfull *mat.Dense, epoch int) float64 {
+// fitEpoch fits one epoch. the minibatches of a *base.Dense32 Xfull are converted to float64
+func (regr *MLPRegressor) fitEpoch(Xfull mat.Matrix, Yfull *mat.Dense, epoch int) float64 {
 	nSamples, _ := Xfull.Dims()
-	var shuffler preprocessing.InverseTransformer
 	if regr.Shuffle {
-		shuffler = preprocessing.NewShuffler()
-		shuffler.Fit(Xfull, Yfull).Transform(Xfull, Yfull)
-		defer shuffler.InverseTransform(Xfull, Yfull)
+		shuffler := &preprocessing.Shuffler{RandomState: regr.randomState}
+		switch X := Xfull.(type) {
+		case *base.Dense32:
+			shuffler.Fit(Yfull, Yfull)
+			shuffleDense32(shuffler.Perm, X, Yfull, false)
+			defer shuffleDense32(shuffler.Perm, X, Yfull, true)
+		default:
+			Xd := X.(*mat.Dense)
+			shuffler.Fit(Xd, Yfull).Transform(Xd, Yfull)
+			defer shuffler.InverseTransform(Xd, Yfull)
+		}
 	}
 
 	// Apply weight decay at start of epoch
@@ -320,10 +459,16 @@ func (regr *MLPRegressor) fitEpoch(Xfull, Yfull *mat.Dense, epoch int) float64 {
 	Jsum := 0.
 	for miniBatchStart < nSamples {
 		miniBatchLen := miniBatchEnd - miniBatchStart
-		X := base.MatDenseRowSlice(Xfull, miniBatchStart, miniBatchEnd)
+		X := miniBatchX(Xfull, miniBatchStart, miniBatchEnd)
 		Y := base.MatDenseRowSlice(Yfull, miniBatchStart, miniBatchEnd)
+		var W mat.Vector
+		if regr.weighted {
+			_, nCols := Y.Dims()
+			W = Y.ColView(nCols - 1)
+			Y = Y.Slice(0, miniBatchLen, 0, nCols-1).(*mat.Dense)
+		}
 
-		Jmini := regr.fitMiniBatch(X, Y, epoch, miniBatchStart, miniBatchLen, nSamples)
+		Jmini := regr.fitMiniBatch(X, Y, W, epoch, miniBatchStart, miniBatchLen, nSamples)
 		Jsum += Jmini
 
 		miniBatchStart, miniBatchEnd = miniBatchStart+miniBatchLen, miniBatchEnd+miniBatchLen
@@ -343,15 +488,15 @@ func (regr *MLPRegressor) fitEpoch(Xfull, Yfull *mat.Dense, epoch int) float64 {
 	return Jsum
 }
 
-// fitMiniBatch fit one minibatch
-func (regr *MLPRegressor) fitMiniBatch(Xmini, Ymini *mat.Dense, epoch, miniBatchStart, miniBatchLen, nSamples int) float64 {
+// fitMiniBatch fit one minibatch. W is the weight of its samples, or nil
+func (regr *MLPRegressor) fitMiniBatch(Xmini, Ymini *mat.Dense, W mat.Vector, epoch, miniBatchStart, miniBatchLen, nSamples int) float64 {
 	regr.forward(Xmini, nil)
-	Jmini := regr.backprop(Xmini, Ymini, epoch, miniBatchStart, miniBatchLen, nSamples)
+	Jmini := regr.backprop(Xmini, Ymini, W, epoch, miniBatchStart, miniBatchLen, nSamples)
 	return Jmini
 }
 
 // backprop corrects weights
-func (regr *MLPRegressor) backprop(X, Y mat.Matrix, epoch, miniBatchStart, miniBatchLen, nSamples int) (J float64) {
+func (regr *MLPRegressor) backprop(X, Y mat.Matrix, W mat.Vector, epoch, miniBatchStart, miniBatchLen, nSamples int) (J float64) {
 	//nSamples, _ := X.Dims()
 	//miniBatchPart := float64(miniBatchLen) / float64(nSamples)
 	_, nOutputs := Y.Dims()
@@ -395,7 +540,11 @@ func (regr *MLPRegressor) backprop(X, Y mat.Matrix, epoch, miniBatchStart, miniB
 				lastLoss = "cross-entropy"
 			}
 			//fmt.Printf("epoch %d %g - %g = %g\n", epoch, L.Ytrue.At(0, 0), L.Ypred.At(0, 0), L.Ytrue.At(0, 0)-L.Ypred.At(0, 0))
-			J = NewLoss(lastLoss).Loss(L.Ytrue, L.Ypred, L.Ydiff, nSamples)
+			if W == nil {
+				J = NewLoss(lastLoss).Loss(L.Ytrue, L.Ypred, L.Ydiff, nSamples)
+			} else {
+				J = weightedLoss(NewLoss(lastLoss), L.Ytrue, L.Ypred, L.Ydiff, W, nSamples)
+			}
 		} else {
 			NewLoss("square").Loss(L.Ytrue, L.Ypred, L.Ydiff, nSamples)
 		}
@@ -537,7 +686,12 @@ func (regr *MLPRegressor) Score(X, Y *mat.Dense) float64 {
 }
 
 // MLPClassifier ...
-type MLPClassifier struct{ MLPRegressor }
+// ClassWeight is nil, "balanced" or a map[float64]float64 from class labels to weights, see base.ComputeSampleWeight.
+// when Y has several columns, it is one-hot encoded and the labels are the column indices
+type MLPClassifier struct {
+	MLPRegressor
+	ClassWeight interface{}
+}
 
 // NewMLPClassifier returns a *MLPClassifier with defaults
 // activation is one of logistic,tanh,relu
@@ -552,6 +706,68 @@ func NewMLPClassifier(hiddenLayerSizes []int, activation string, solver string,
 	return regr
 }
 
+// Clone for MLPClassifier
+func (regr *MLPClassifier) Clone() base.Transformer {
+	clone := *regr
+	clone.MLPRegressor = *regr.MLPRegressor.Clone().(*MLPRegressor)
+	return &clone
+}
+
+// Fit for MLPClassifier
+func (regr *MLPClassifier) Fit(X, Y *mat.Dense) base.Transformer {
+	if err := regr.fit(context.Background(), X, Y, nil); err != nil {
+		panic(err)
+	}
+	return regr
+}
+
+// FitE is Fit returning an error instead of panicking
+func (regr *MLPClassifier) FitE(X, Y *mat.Dense) error {
+	return regr.FitContext(context.Background(), X, Y)
+}
+
+// FitContext is FitE stopping between epochs when ctx is done. see MLPRegressor.FitContext
+func (regr *MLPClassifier) FitContext(ctx context.Context, X, Y *mat.Dense) error {
+	if err := base.CheckFitXY("MLPClassifier.Fit", X, Y); err != nil {
+		return err
+	}
+	if err := regr.checkParams(); err != nil {
+		return err
+	}
+	var err error
+	if rerr := base.Recover(func() { err = regr.fit(ctx, X, Y, nil) }); rerr != nil {
+		return rerr
+	}
+	return err
+}
+
+// FitWeighted is FitE with the loss of each sample weighted by sampleWeight and by the ClassWeight of its class
+func (regr *MLPClassifier) FitWeighted(X, Y *mat.Dense, sampleWeight []float64) error {
+	if err := base.CheckFitXY("MLPClassifier.FitWeighted", X, Y); err != nil {
+		return err
+	}
+	if err := base.CheckSampleWeight("MLPClassifier.FitWeighted", X, sampleWeight); err != nil {
+		return err
+	}
+	if err := regr.checkParams(); err != nil {
+		return err
+	}
+	var err error
+	if rerr := base.Recover(func() { err = regr.fit(context.Background(), X, Y, sampleWeight) }); rerr != nil {
+		return rerr
+	}
+	return err
+}
+
+// fit fits the MLPRegressor with sampleWeight multiplied by the ClassWeight of each sample
+func (regr *MLPClassifier) fit(ctx context.Context, X mat.Matrix, Y *mat.Dense, sampleWeight []float64) error {
+	sampleWeight, err := base.ApplyClassWeight(regr.ClassWeight, Y, sampleWeight)
+	if err != nil {
+		return err
+	}
+	return regr.MLPRegressor.fit(ctx, X, Y, sampleWeight)
+}
+
 // Predict return the forward result for MLPClassifier
 func (regr *MLPClassifier) Predict(X, Y *mat.Dense) base.Regressor {
 	regr.forward(X, Y)
@@ -566,6 +782,14 @@ func (regr *MLPClassifier) Predict(X, Y *mat.Dense) base.Regressor {
 	return regr
 }
 
+// PredictE is Predict returning an error instead of panicking
+func (regr *MLPClassifier) PredictE(X, Y *mat.Dense) error {
+	if err := regr.checkPredict(regr, "MLPClassifier.Predict", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { regr.Predict(X, Y) })
+}
+
 // Transform for pipeline
 func (regr *MLPClassifier) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
 	nSamples, _ := X.Dims()
@@ -1,6 +1,8 @@
 package neuralnetwork
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
@@ -358,7 +360,7 @@ func ExampleMLPRegressor() {
 		pipeline.NamedStep{Name: "mlpregressor", Step: mlp},
 	)
 	_ = m
-	randomState := modelselection.RandomState(7)
+	randomState := base.NewRandomState(7)
 	scorer := func(Y, Ypred *mat.Dense) float64 {
 		e := metrics.MeanSquaredError(Y, Ypred, nil, "").At(0, 0)
 		return e
@@ -368,9 +370,112 @@ func ExampleMLPRegressor() {
 	res := modelselection.CrossValidate(m, X, Y,
 		nil,
 		scorer,
-		&modelselection.KFold{NSplits: 10, Shuffle: true, RandomState: &randomState}, 10)
+		&modelselection.KFold{NSplits: 10, Shuffle: true, RandomState: randomState}, 10)
 	fmt.Println(math.Sqrt(mean(res.TestScore)) < 20)
 
 	// Output:
 	// true
 }
+
+func TestMLPRegressorFitContext(t *testing.T) {
+	ds := datasets.LoadBoston()
+	X, Y := ds.X, ds.Y
+	for solver, nEpochs := range map[string]int{"adam": 3, "lbfgs": 1} {
+		mlp := NewMLPRegressor([]int{5}, "relu", solver, 0)
+		mlp.Epochs = 50
+		ctx, cancel := context.WithCancel(context.Background())
+		var epochs []int
+		ctx = base.WithProgress(ctx, base.ProgressFunc(func(p base.Progress) {
+			if p.Estimator != mlp || math.IsNaN(p.Loss) {
+				t.Errorf("%s: unexpected progress %+v", solver, p)
+			}
+			epochs = append(epochs, p.Epoch)
+			if len(epochs) == nEpochs {
+				cancel()
+			}
+		}))
+		if err := mlp.FitContext(ctx, X, Y); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: expected context.Canceled, got %v", solver, err)
+		}
+		if len(epochs) != nEpochs {
+			t.Errorf("%s: expected %d epochs before cancellation, got %v", solver, nEpochs, epochs)
+		}
+	}
+	mlp := NewMLPRegressor([]int{5}, "relu", "adam", 0)
+	mlp.Epochs = 2
+	if err := mlp.FitContext(context.Background(), X, Y); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMLPRegressorRandomState(t *testing.T) {
+	ds := datasets.LoadBoston()
+	fit := func() *mat.Dense {
+		mlp := NewMLPRegressor([]int{8}, "relu", "adam", 1e-4)
+		mlp.RandomState = base.NewRandomState(11)
+		mlp.Epochs = 3
+		mlp.Fit(ds.X, ds.Y)
+		return mlp.Layers[0].Theta
+	}
+	if !mat.Equal(fit(), fit()) {
+		t.Errorf("fits with the same RandomState differ")
+	}
+}
+
+func TestMLPRegressorFitWeighted(t *testing.T) {
+	p := NewRandomProblem(100, 3, 1, "identity", "square")
+	fit := func(sampleWeight []float64) *mat.Dense {
+		// adam updates do not depend on the scale of the gradient, sgd ones do
+		mlp := NewMLPRegressor([]int{4}, "identity", "sgd", 1e-4)
+		mlp.RandomState = base.NewRandomState(11)
+		mlp.Epochs = 3
+		if err := mlp.FitWeighted(p.X, p.Y, sampleWeight); err != nil {
+			t.Fatal(err)
+		}
+		return mlp.Layers[1].Theta
+	}
+	ones := make([]float64, 100)
+	for i := range ones {
+		ones[i] = 1
+	}
+	if !mat.EqualApprox(fit(nil), fit(ones), 1e-9) {
+		t.Errorf("fit with unit weights differs from unweighted fit")
+	}
+	ones[0] = 100
+	if mat.EqualApprox(fit(nil), fit(ones), 1e-9) {
+		t.Errorf("sample weights are ignored")
+	}
+}
+
+func TestMLPClassifierClassWeight(t *testing.T) {
+	X := mat.NewDense(8, 1, []float64{-3, -2, -1.5, -1, -.5, 0, .5, 1})
+	Y := mat.NewDense(8, 1, []float64{0, 0, 0, 0, 0, 0, 0, 1})
+	fit := func(classWeight interface{}, sampleWeight []float64) *mat.Dense {
+		mlp := NewMLPClassifier([]int{}, "logistic", "sgd", 0)
+		mlp.RandomState = base.NewRandomState(11)
+		mlp.Epochs = 3
+		mlp.ClassWeight = classWeight
+		if err := mlp.FitWeighted(X, Y, sampleWeight); err != nil {
+			t.Fatal(err)
+		}
+		return mlp.Layers[0].Theta
+	}
+	// "balanced" weighs the 7 samples of class 0 8/14 and the sample of class 1 4
+	sampleWeight := []float64{4. / 7, 4. / 7, 4. / 7, 4. / 7, 4. / 7, 4. / 7, 4. / 7, 4}
+	if balanced, weighted := fit("balanced", nil), fit(nil, sampleWeight); !mat.EqualApprox(balanced, weighted, 1e-9) {
+		t.Errorf("expected theta %g, got %g", mat.Formatted(weighted.T()), mat.Formatted(balanced.T()))
+	}
+	if mapped, weighted := fit(map[float64]float64{1: 4}, []float64{1, 1, 1, 1, 1, 1, 1, 2}), fit(nil, []float64{1, 1, 1, 1, 1, 1, 1, 8}); !mat.EqualApprox(mapped, weighted, 1e-9) {
+		t.Errorf("expected theta %g, got %g", mat.Formatted(weighted.T()), mat.Formatted(mapped.T()))
+	}
+	mlp := NewMLPClassifier([]int{}, "logistic", "sgd", 0)
+	mlp.ClassWeight = "balanced"
+	if clone := mlp.Clone().(*MLPClassifier); clone.ClassWeight != "balanced" {
+		t.Errorf("ClassWeight is lost by Clone")
+	}
+	mlp.ClassWeight = "auto"
+	var pe *base.ParamError
+	if err := mlp.FitE(X, Y); !errors.As(err, &pe) {
+		t.Errorf("expected a *base.ParamError, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package neuralnetwork
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+func init() {
+	base.Register(&MLPRegressor{})
+	base.Register(&MLPClassifier{})
+}
+
+type layerFields struct {
+	Activation string
+	Theta      *mat.Dense
+}
+
+// GobEncode encodes Activation name and Theta. runtime matrices and Optimizer are not encoded
+func (L *Layer) GobEncode() ([]byte, error) {
+	if _, ok := SupportedActivations[L.Activation]; !ok {
+		return nil, fmt.Errorf("layer activation %q can't be saved", L.Activation)
+	}
+	return base.GobEncodeFields(layerFields{Activation: L.Activation, Theta: L.Theta})
+}
+
+// GobDecode decodes a Layer encoded by GobEncode
+func (L *Layer) GobDecode(b []byte) error {
+	var fields layerFields
+	if err := base.GobDecodeFields(b, &fields); err != nil {
+		return err
+	}
+	*L = Layer{Activation: fields.Activation, Theta: fields.Theta}
+	return base.Recover(func() { L.activation = NewActivation(fields.Activation) })
+}
+
+// Restore restores Optimizer from Solver after Load
+func (regr *MLPRegressor) Restore() error {
+	if regr.Optimizer == nil && !isGOMethodOnly(regr.Solver) {
+		creator, ok := base.Solvers[regr.Solver]
+		if !ok {
+			return &base.ParamError{Estimator: regr, Param: "Solver", Value: regr.Solver}
+		}
+		regr.SetOptimizer(creator)
+	}
+	return nil
+}
+
+// Save writes the MLPRegressor to w. Activation must be a string. Optimizer is restored from Solver by Load
+func (regr *MLPRegressor) Save(w io.Writer) error { return base.Save(w, regr) }
+
+// Load reads a MLPRegressor written by Save
+func (regr *MLPRegressor) Load(r io.Reader) error { return base.LoadInto(r, regr) }
+
+// Save writes the MLPClassifier to w
+func (regr *MLPClassifier) Save(w io.Writer) error { return base.Save(w, regr) }
+
+// Load reads a MLPClassifier written by Save
+func (regr *MLPClassifier) Load(r io.Reader) error { return base.LoadInto(r, regr) }
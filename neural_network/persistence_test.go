@@ -0,0 +1,34 @@
+package neuralnetwork
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/datasets"
+	"gonum.org/v1/gonum/mat"
+)
+
+func ExampleMLPRegressor_Save() {
+	ds := datasets.LoadBoston()
+	NSamples, _ := ds.X.Dims()
+	randomState := base.NewRandomState(7)
+	mlp := NewMLPRegressor([]int{10}, "relu", "adam", 1e-4)
+	mlp.RandomState = randomState
+	mlp.Epochs = 5
+	mlp.Fit(ds.X, ds.Y)
+	var buf bytes.Buffer
+	if err := mlp.Save(&buf); err != nil {
+		fmt.Println(err)
+	}
+	loaded := &MLPRegressor{}
+	if err := loaded.Load(&buf); err != nil {
+		fmt.Println(err)
+	}
+	Ypred, Yloaded := mat.NewDense(NSamples, 1, nil), mat.NewDense(NSamples, 1, nil)
+	mlp.Predict(ds.X, Ypred)
+	loaded.Predict(ds.X, Yloaded)
+	fmt.Println(len(loaded.Layers), loaded.Optimizer != nil, mat.Equal(Ypred, Yloaded))
+	// Output:
+	// 2 true true
+}
@@ -0,0 +1,17 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/pa-m/sklearn/base/estimatorchecks"
+	lm "github.com/pa-m/sklearn/linear_model"
+	"github.com/pa-m/sklearn/preprocessing"
+)
+
+func TestEstimatorChecks(t *testing.T) {
+	X, Y := estimatorchecks.RegressionData()
+	pl := MakePipeline(preprocessing.NewStandardScaler(), lm.NewLinearRegression())
+	if err := estimatorchecks.CheckEstimator(pl, X, Y); err != nil {
+		t.Error(err)
+	}
+}
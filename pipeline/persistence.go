@@ -0,0 +1,30 @@
+package pipeline
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pa-m/sklearn/base"
+)
+
+func init() {
+	base.Register(&Pipeline{})
+}
+
+// Restore restores the runtime state of every step after Load
+func (p *Pipeline) Restore() error {
+	for _, step := range p.NamedSteps {
+		if restorer, ok := step.Step.(base.Restorer); ok {
+			if err := restorer.Restore(); err != nil {
+				return fmt.Errorf("step %s: %w", step.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Save writes the Pipeline and its steps to w. the type of every step must be registered
+func (p *Pipeline) Save(w io.Writer) error { return base.Save(w, p) }
+
+// Load reads a Pipeline written by Save
+func (p *Pipeline) Load(r io.Reader) error { return base.LoadInto(r, p) }
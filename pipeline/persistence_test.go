@@ -0,0 +1,39 @@
+package pipeline
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/pa-m/sklearn/base"
+	lm "github.com/pa-m/sklearn/linear_model"
+	"github.com/pa-m/sklearn/preprocessing"
+	"gonum.org/v1/gonum/mat"
+)
+
+func ExamplePipeline_Save() {
+	X := mat.NewDense(6, 2, []float64{1, 2, 2, 3, 3, 5, 4, 4, 5, 7, 6, 6})
+	Y := mat.NewDense(6, 1, []float64{3, 5, 8, 8, 12, 12})
+	pl := MakePipeline(preprocessing.NewStandardScaler(), preprocessing.NewPCA(), lm.NewLinearRegression())
+	pl.Fit(X, Y)
+	var buf bytes.Buffer
+	if err := pl.Save(&buf); err != nil {
+		fmt.Println(err)
+	}
+	// step types need not be known to reload a pipeline
+	loaded, err := base.Load(&buf)
+	if err != nil {
+		fmt.Println(err)
+	}
+	Ypred, Yloaded := mat.NewDense(6, 1, nil), mat.NewDense(6, 1, nil)
+	pl.Predict(X, Ypred)
+	loaded.(*Pipeline).Predict(X, Yloaded)
+	for _, step := range loaded.(*Pipeline).NamedSteps {
+		fmt.Printf("%s %T\n", step.Name, step.Step)
+	}
+	fmt.Println(mat.EqualApprox(Ypred, Yloaded, 1e-12))
+	// Output:
+	// *preprocessing.standardscaler *preprocessing.StandardScaler
+	// *preprocessing.pca *preprocessing.PCA
+	// *linearmodel.linearregression *linearmodel.LinearRegression
+	// true
+}
@@ -41,6 +41,69 @@ func (p *Pipeline) Fit(X, Y *mat.Dense) base.Transformer {
 	return p
 }
 
+// FitE is Fit returning an error instead of panicking. errors are prefixed with the failing step name
+func (p *Pipeline) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckFitXY("Pipeline.Fit", X, Y); err != nil {
+		return err
+	}
+	// NOutputs is set once every step is fitted, PredictE then reports a failed fit as not fitted
+	p.NOutputs = 0
+	Xtmp, Ytmp := X, Y
+	for _, step := range p.NamedSteps {
+		var err error
+		if fitter, ok := step.Step.(base.FitterE); ok {
+			err = fitter.FitE(Xtmp, Ytmp)
+		} else {
+			err = base.Recover(func() { step.Step.Fit(Xtmp, Ytmp) })
+		}
+		if err == nil {
+			Xtmp, Ytmp, err = transformE(step.Step, Xtmp, Ytmp)
+		}
+		if err != nil {
+			return fmt.Errorf("step %s: %w", step.Name, err)
+		}
+	}
+	_, p.NOutputs = Y.Dims()
+	return nil
+}
+
+// PredictE is Predict returning an error instead of panicking. errors are prefixed with the failing step name
+func (p *Pipeline) PredictE(X, Y *mat.Dense) error {
+	if p.NOutputs == 0 {
+		return &base.NotFittedError{Estimator: p}
+	}
+	if err := base.CheckFitXY("Pipeline.Predict", X, Y); err != nil {
+		return err
+	}
+	Xtmp, Ytmp := X, Y
+	for _, step := range p.NamedSteps {
+		var err error
+		if Xtmp, Ytmp, err = transformE(step.Step, Xtmp, Ytmp); err != nil {
+			return fmt.Errorf("step %s: %w", step.Name, err)
+		}
+	}
+	for iStep := len(p.NamedSteps) - 2; iStep >= 0; iStep-- {
+		step := p.NamedSteps[iStep]
+		inverter, ok := step.Step.(preprocessing.InverseTransformer)
+		if !ok {
+			return fmt.Errorf("step %s: %T has no InverseTransform", step.Name, step.Step)
+		}
+		if err := base.Recover(func() { _, Ytmp = inverter.InverseTransform(nil, Ytmp) }); err != nil {
+			return fmt.Errorf("step %s: %w", step.Name, err)
+		}
+	}
+	Y.Copy(Ytmp)
+	return nil
+}
+
+func transformE(step base.Transformer, X, Y *mat.Dense) (Xout, Yout *mat.Dense, err error) {
+	if transformer, ok := step.(base.TransformerE); ok {
+		return transformer.TransformE(X, Y)
+	}
+	err = base.Recover(func() { Xout, Yout = step.Transform(X, Y) })
+	return
+}
+
 // Predict ...
 func (p *Pipeline) Predict(X, Y *mat.Dense) base.Regressor {
 	Xtmp, Ytmp := X, Y
@@ -106,3 +169,57 @@ func MakePipeline(steps ...base.Transformer) *Pipeline {
 	}
 	return p
 }
+
+// GetParams returns the steps by name, and their parameters prefixed by "stepname__"
+func (p *Pipeline) GetParams() map[string]interface{} {
+	params := make(map[string]interface{})
+	for _, step := range p.NamedSteps {
+		params[step.Name] = step.Step
+		for k, v := range base.GetParams(step.Step) {
+			params[step.Name+base.ParamsSeparator+k] = v
+		}
+	}
+	return params
+}
+
+// SetParams replaces steps given by name, then sets the "stepname__Field" parameters of steps
+func (p *Pipeline) SetParams(params map[string]interface{}) error {
+	stepIndex := func(name string) int {
+		for i, step := range p.NamedSteps {
+			if step.Name == name {
+				return i
+			}
+		}
+		return -1
+	}
+	nested := make(map[string]map[string]interface{})
+	for k, v := range params {
+		if i := strings.Index(k, base.ParamsSeparator); i >= 0 {
+			name := k[:i]
+			if nested[name] == nil {
+				nested[name] = make(map[string]interface{})
+			}
+			nested[name][k[i+len(base.ParamsSeparator):]] = v
+			continue
+		}
+		i := stepIndex(k)
+		if i < 0 {
+			return &base.ParamError{Estimator: p, Param: k, Value: v, Msg: "no such step"}
+		}
+		step, ok := v.(base.Transformer)
+		if !ok {
+			return &base.ParamError{Estimator: p, Param: k, Value: v, Msg: "step must be a base.Transformer"}
+		}
+		p.NamedSteps[i].Step = step
+	}
+	for name, stepParams := range nested {
+		i := stepIndex(name)
+		if i < 0 {
+			return &base.ParamError{Estimator: p, Param: name, Value: stepParams, Msg: "no such step"}
+		}
+		if err := base.SetParams(p.NamedSteps[i].Step, stepParams); err != nil {
+			return fmt.Errorf("step %s: %w", name, err)
+		}
+	}
+	return nil
+}
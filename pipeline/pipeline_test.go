@@ -1,19 +1,23 @@
 package pipeline
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"testing"
 
 	"github.com/pa-m/sklearn/base"
 	"github.com/pa-m/sklearn/preprocessing"
 
 	"github.com/pa-m/sklearn/datasets"
+	lm "github.com/pa-m/sklearn/linear_model"
 	"github.com/pa-m/sklearn/metrics"
 	nn "github.com/pa-m/sklearn/neural_network"
 	"gonum.org/v1/gonum/mat"
 )
 
 func ExamplePipeline() {
-	RandomState := int64(7)
+	RandomState := base.NewRandomState(7)
 
 	ds := datasets.LoadBreastCancer()
 	fmt.Println("Dims", base.MatDimsString(ds.X, ds.Y))
@@ -27,7 +31,7 @@ func ExamplePipeline() {
 	poly.IncludeBias = false
 
 	m := nn.NewMLPClassifier([]int{}, "relu", "adam", 0.)
-	m.RandomState = &RandomState
+	m.RandomState = RandomState
 	m.Loss = "cross-entropy"
 	m.Epochs = 50
 	m.WeightDecay = .1
@@ -49,3 +53,61 @@ func ExamplePipeline() {
 	// accuracy>0.999 ? true
 
 }
+
+func TestPipelineErrors(t *testing.T) {
+	X := mat.NewDense(4, 2, []float64{1, 2, 2, 3, 3, 5, 4, 4})
+	Y := mat.NewDense(4, 1, []float64{1, 2, 3, 4})
+	pl := NewPipeline(
+		NamedStep{Name: "scaler", Step: &preprocessing.MinMaxScaler{FeatureRange: []float64{0}}},
+		NamedStep{Name: "regr", Step: lm.NewLinearRegression()},
+	)
+	var notFitted *base.NotFittedError
+	if err := pl.PredictE(X, mat.NewDense(4, 1, nil)); !errors.As(err, &notFitted) {
+		t.Errorf("expected NotFittedError, got %v", err)
+	}
+	var paramErr *base.ParamError
+	err := pl.FitE(X, Y)
+	if !errors.As(err, &paramErr) || !strings.HasPrefix(err.Error(), "step scaler: ") {
+		t.Errorf("expected ParamError from step scaler, got %v", err)
+	}
+	if err = pl.PredictE(X, mat.NewDense(4, 1, nil)); !errors.As(err, &notFitted) {
+		t.Errorf("expected NotFittedError after a failed fit, got %v", err)
+	}
+	pl.NamedSteps[0].Step = preprocessing.NewStandardScaler()
+	if err = pl.FitE(X, Y); err != nil {
+		t.Fatal(err)
+	}
+	if err = pl.PredictE(X, mat.NewDense(4, 1, nil)); err != nil {
+		t.Error(err)
+	}
+	// a failed refit leaves the pipeline not fitted
+	pl.NamedSteps[0].Step = &preprocessing.MinMaxScaler{FeatureRange: []float64{0}}
+	if err = pl.FitE(X, Y); err == nil {
+		t.Fatal("expected an error from step scaler")
+	}
+	if err = pl.PredictE(X, mat.NewDense(4, 1, nil)); !errors.As(err, &notFitted) {
+		t.Errorf("expected NotFittedError after a failed refit, got %v", err)
+	}
+}
+
+func TestPipelineParams(t *testing.T) {
+	pl := NewPipeline(
+		NamedStep{Name: "poly", Step: preprocessing.NewPolynomialFeatures(2)},
+		NamedStep{Name: "regr", Step: lm.NewRidge()},
+	)
+	if err := pl.SetParams(map[string]interface{}{"poly__Degree": 3, "regr__Alpha": 1, "regr__FitIntercept": false}); err != nil {
+		t.Fatal(err)
+	}
+	params := base.GetParams(pl)
+	if params["poly__Degree"] != 3 || params["regr__Alpha"] != 1. || params["regr__FitIntercept"] != false {
+		t.Errorf("unexpected params %v", params)
+	}
+	scaler := preprocessing.NewStandardScaler()
+	if err := base.SetParams(pl, map[string]interface{}{"poly": scaler}); err != nil || pl.NamedSteps[0].Step != scaler {
+		t.Errorf("step not replaced: %v", err)
+	}
+	var paramErr *base.ParamError
+	if err := pl.SetParams(map[string]interface{}{"nostep__Alpha": 1}); !errors.As(err, &paramErr) {
+		t.Errorf("expected ParamError, got %v", err)
+	}
+}
@@ -2,7 +2,6 @@ package preprocessing
 
 import (
 	"math"
-	"math/rand"
 	"sort"
 
 	"github.com/pa-m/sklearn/base"
@@ -23,6 +22,46 @@ type InverseTransformer interface {
 	InverseTransform(X, Y *mat.Dense) (Xout, Yout *mat.Dense)
 }
 
+// checkTransform returns a *base.NotFittedError if fitted is false or a *base.ShapeError if X,Y are not suitable. nFeatures<0 disables the features count check
+func checkTransform(estimator interface{}, fitted bool, op string, X, Y *mat.Dense, nFeatures int) error {
+	if !fitted {
+		return &base.NotFittedError{Estimator: estimator}
+	}
+	if err := base.CheckXY(op, X, Y); err != nil {
+		return err
+	}
+	if nFeatures >= 0 {
+		return base.CheckNFeatures(op, X, nFeatures)
+	}
+	return nil
+}
+
+// checkLabels returns a *base.ShapeError if Y is nil or empty or has not the same number of rows than a non-nil X
+func checkLabels(op string, X, Y *mat.Dense) error {
+	if Y == nil || Y.IsZero() {
+		return &base.ShapeError{Op: op, Msg: "Y is nil or empty"}
+	}
+	if X != nil && !X.IsZero() {
+		return base.CheckXY(op, X, Y)
+	}
+	return nil
+}
+
+func denseCols(m *mat.Dense) int {
+	if m == nil {
+		return -1
+	}
+	_, c := m.Dims()
+	return c
+}
+
+func polynomialNFeatures(powers [][]int) int {
+	if len(powers) == 0 {
+		return -1
+	}
+	return len(powers[0])
+}
+
 // MinMaxScaler rescale data between FeatureRange
 type MinMaxScaler struct {
 	FeatureRange                            []float
@@ -95,6 +134,26 @@ func (scaler *MinMaxScaler) PartialFit(X, Y *mat.Dense) Transformer {
 	return scaler
 }
 
+// FitE is Fit returning an error instead of panicking
+func (scaler *MinMaxScaler) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckXY("MinMaxScaler.Fit", X, Y); err != nil {
+		return err
+	}
+	if len(scaler.FeatureRange) != 2 {
+		return &base.ParamError{Estimator: scaler, Param: "FeatureRange", Value: scaler.FeatureRange, Msg: "must be [min, max]"}
+	}
+	return base.Recover(func() { scaler.Fit(X, Y) })
+}
+
+// TransformE is Transform returning an error instead of panicking
+func (scaler *MinMaxScaler) TransformE(X, Y *mat.Dense) (Xout, Yout *mat.Dense, err error) {
+	if err = checkTransform(scaler, scaler.Scale != nil, "MinMaxScaler.Transform", X, Y, denseCols(scaler.Scale)); err != nil {
+		return
+	}
+	err = base.Recover(func() { Xout, Yout = scaler.Transform(X, Y) })
+	return
+}
+
 // Transform applies scaling to X
 func (scaler *MinMaxScaler) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
 	nSamples, nFeatures := X.Dims()
@@ -175,6 +234,23 @@ func (scaler *StandardScaler) PartialFit(X, Y *mat.Dense) Transformer {
 	return scaler
 }
 
+// FitE is Fit returning an error instead of panicking
+func (scaler *StandardScaler) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckXY("StandardScaler.Fit", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { scaler.Fit(X, Y) })
+}
+
+// TransformE is Transform returning an error instead of panicking
+func (scaler *StandardScaler) TransformE(X, Y *mat.Dense) (Xout, Yout *mat.Dense, err error) {
+	if err = checkTransform(scaler, scaler.Mean != nil, "StandardScaler.Transform", X, Y, denseCols(scaler.Mean)); err != nil {
+		return
+	}
+	err = base.Recover(func() { Xout, Yout = scaler.Transform(X, Y) })
+	return
+}
+
 // Transform scales data
 func (scaler *StandardScaler) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
 	Xmat := X.RawMatrix()
@@ -337,8 +413,9 @@ func NewRobustScaler(center bool, scale bool, quantiles *QuantilePair) *RobustSc
 	}
 }
 
-// Reset ...
+// Reset clears Median and QuantileDivider
 func (scaler *RobustScaler) Reset() *RobustScaler {
+	scaler.Median, scaler.Tmp, scaler.QuantileDivider = nil, nil, nil
 	return scaler
 }
 
@@ -390,6 +467,30 @@ func (scaler *RobustScaler) PartialFit(X, Y *mat.Dense) Transformer {
 	return scaler
 }
 
+// FitE is Fit returning an error instead of panicking
+func (scaler *RobustScaler) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckXY("RobustScaler.Fit", X, Y); err != nil {
+		return err
+	}
+	if scaler.Scale && scaler.Quantiles == nil {
+		return &base.ParamError{Estimator: scaler, Param: "Quantiles", Value: scaler.Quantiles, Msg: "required when Scale is true"}
+	}
+	return base.Recover(func() { scaler.Fit(X, Y) })
+}
+
+// TransformE is Transform returning an error instead of panicking
+func (scaler *RobustScaler) TransformE(X, Y *mat.Dense) (Xout, Yout *mat.Dense, err error) {
+	nFeatures := denseCols(scaler.Median)
+	if nFeatures < 0 {
+		nFeatures = denseCols(scaler.QuantileDivider)
+	}
+	if err = checkTransform(scaler, scaler.Median != nil || scaler.QuantileDivider != nil || !(scaler.Center || scaler.Scale), "RobustScaler.Transform", X, Y, nFeatures); err != nil {
+		return
+	}
+	err = base.Recover(func() { Xout, Yout = scaler.Transform(X, Y) })
+	return
+}
+
 // Transform scales data
 func (scaler *RobustScaler) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
 	Xout = mat.DenseCopyOf(X)
@@ -594,6 +695,26 @@ func (scaler *PolynomialFeatures) Fit(X, Y *mat.Dense) Transformer {
 	return scaler
 }
 
+// FitE is Fit returning an error instead of panicking
+func (scaler *PolynomialFeatures) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckXY("PolynomialFeatures.Fit", X, Y); err != nil {
+		return err
+	}
+	if scaler.Degree < 0 {
+		return &base.ParamError{Estimator: scaler, Param: "Degree", Value: scaler.Degree, Msg: "must be >= 0"}
+	}
+	return base.Recover(func() { scaler.Fit(X, Y) })
+}
+
+// TransformE is Transform returning an error instead of panicking
+func (scaler *PolynomialFeatures) TransformE(X, Y *mat.Dense) (Xout, Yout *mat.Dense, err error) {
+	if err = checkTransform(scaler, scaler.Powers != nil, "PolynomialFeatures.Transform", X, Y, polynomialNFeatures(scaler.Powers)); err != nil {
+		return
+	}
+	err = base.Recover(func() { Xout, Yout = scaler.Transform(X, Y) })
+	return
+}
+
 // Transform returns data with polynomial features added
 func (scaler *PolynomialFeatures) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
 	nSamples, _ := X.Dims()
@@ -734,6 +855,23 @@ func (m *OneHotEncoder) Fit(X, Y *mat.Dense) Transformer {
 	return m
 }
 
+// FitE is Fit returning an error instead of panicking
+func (m *OneHotEncoder) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckXY("OneHotEncoder.Fit", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { m.Fit(X, Y) })
+}
+
+// TransformE is Transform returning an error instead of panicking
+func (m *OneHotEncoder) TransformE(X, Y *mat.Dense) (Xout, Yout *mat.Dense, err error) {
+	if err = checkTransform(m, m.Values != nil, "OneHotEncoder.Transform", X, Y, len(m.NValues)); err != nil {
+		return
+	}
+	err = base.Recover(func() { Xout, Yout = m.Transform(X, Y) })
+	return
+}
+
 // Transform transform Y labels to one hot encoded format
 func (m *OneHotEncoder) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
 	NSamples, nfeatures := X.Dims()
@@ -796,8 +934,12 @@ func (m *OneHotEncoder) InverseTransform(X, Y *mat.Dense) (Xout, Yout *mat.Dense
 
 }
 
-// Shuffler shuffles rows of X and Y
-type Shuffler struct{ Perm []int }
+// Shuffler shuffles rows of X and Y.
+// RandomState is drawn from by Fit (see base.CheckRandomState): with a seed, each Fit draws the same permutation
+type Shuffler struct {
+	RandomState *base.RandomState
+	Perm        []int
+}
 
 // NewShuffler returns a *Shuffler
 func NewShuffler() *Shuffler { return &Shuffler{} }
@@ -810,10 +952,27 @@ func (m *Shuffler) Clone() Transformer {
 
 // Fit for Shuffler
 func (m *Shuffler) Fit(X, Y *mat.Dense) Transformer {
-	m.Perm = rand.Perm(X.RawMatrix().Rows)
+	m.Perm = base.CheckRandomState(m.RandomState).Perm(X.RawMatrix().Rows)
 	return m
 }
 
+// FitE is Fit returning an error instead of panicking
+func (m *Shuffler) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckFitXY("Shuffler.Fit", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { m.Fit(X, Y) })
+}
+
+// TransformE is Transform returning an error instead of panicking
+func (m *Shuffler) TransformE(X, Y *mat.Dense) (Xout, Yout *mat.Dense, err error) {
+	if err = checkTransform(m, m.Perm != nil, "Shuffler.Transform", X, Y, -1); err != nil {
+		return
+	}
+	err = base.Recover(func() { Xout, Yout = m.Transform(X, Y) })
+	return
+}
+
 // Transform for Shuffler
 func (m *Shuffler) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
 	xmat, ymat := X.RawMatrix(), Y.RawMatrix()
@@ -885,6 +1044,23 @@ func (m *Binarizer) Fit(X, Y *mat.Dense) Transformer {
 	return m
 }
 
+// FitE is Fit returning an error instead of panicking
+func (m *Binarizer) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckXY("Binarizer.Fit", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { m.Fit(X, Y) })
+}
+
+// TransformE is Transform returning an error instead of panicking
+func (m *Binarizer) TransformE(X, Y *mat.Dense) (Xout, Yout *mat.Dense, err error) {
+	if err = checkTransform(m, true, "Binarizer.Transform", X, Y, -1); err != nil {
+		return
+	}
+	err = base.Recover(func() { Xout, Yout = m.Transform(X, Y) })
+	return
+}
+
 // Transform for Binarizer
 func (m *Binarizer) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
 	rmx := X.RawMatrix()
@@ -957,6 +1133,23 @@ func (m *MaxAbsScaler) PartialFit(X, Y *mat.Dense) base.Transformer {
 	return m
 }
 
+// FitE is Fit returning an error instead of panicking
+func (m *MaxAbsScaler) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckXY("MaxAbsScaler.Fit", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { m.Fit(X, Y) })
+}
+
+// TransformE is Transform returning an error instead of panicking
+func (m *MaxAbsScaler) TransformE(X, Y *mat.Dense) (Xout, Yout *mat.Dense, err error) {
+	if err = checkTransform(m, m.Scale != nil, "MaxAbsScaler.Transform", X, Y, len(m.Scale)); err != nil {
+		return
+	}
+	err = base.Recover(func() { Xout, Yout = m.Transform(X, Y) })
+	return
+}
+
 // Transform for MaxAbsScaler ...
 func (m *MaxAbsScaler) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
 	Xmat := X.RawMatrix()
@@ -1007,6 +1200,26 @@ func (m *Normalizer) Clone() Transformer {
 // Fit for Normalizer ...
 func (m *Normalizer) Fit(X, Y *mat.Dense) base.Transformer { return m }
 
+// FitE is Fit returning an error instead of panicking
+func (m *Normalizer) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckXY("Normalizer.Fit", X, Y); err != nil {
+		return err
+	}
+	if m.Norm != "" && m.Norm != "l1" && m.Norm != "l2" && m.Norm != "max" {
+		return &base.ParamError{Estimator: m, Param: "Norm", Value: m.Norm, Msg: "must be one of l1, l2, max"}
+	}
+	return base.Recover(func() { m.Fit(X, Y) })
+}
+
+// TransformE is Transform returning an error instead of panicking
+func (m *Normalizer) TransformE(X, Y *mat.Dense) (Xout, Yout *mat.Dense, err error) {
+	if err = checkTransform(m, true, "Normalizer.Transform", X, Y, -1); err != nil {
+		return
+	}
+	err = base.Recover(func() { Xout, Yout = m.Transform(X, Y) })
+	return
+}
+
 // Transform for Normalizer ...
 func (m *Normalizer) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
 	NSamples, NFeatures := X.Dims()
@@ -1072,6 +1285,23 @@ func (m *KernelCenterer) Fit(X, Y *mat.Dense) base.Transformer {
 	return m
 }
 
+// FitE is Fit returning an error instead of panicking
+func (m *KernelCenterer) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckXY("KernelCenterer.Fit", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { m.Fit(X, Y) })
+}
+
+// TransformE is Transform returning an error instead of panicking
+func (m *KernelCenterer) TransformE(X, Y *mat.Dense) (Xout, Yout *mat.Dense, err error) {
+	if err = checkTransform(m, m.KFitRows != nil, "KernelCenterer.Transform", X, Y, len(m.KFitRows)); err != nil {
+		return
+	}
+	err = base.Recover(func() { Xout, Yout = m.Transform(X, Y) })
+	return
+}
+
 // Transform for KernelCenterer ...
 func (m *KernelCenterer) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
 	r, _ := X.Dims()
@@ -1082,7 +1312,7 @@ func (m *KernelCenterer) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
 		}
 	})
 	Xmat := X.RawMatrix()
-	Xout = mat.NewDense(Xmat.Cols, Xmat.Cols, nil)
+	Xout = mat.NewDense(Xmat.Rows, Xmat.Cols, nil)
 	Xoutmat := Xout.RawMatrix()
 	for j, jX, jXout := 0, 0, 0; j < Xmat.Rows; j, jX, jXout = j+1, jX+Xmat.Stride, jXout+Xoutmat.Stride {
 		for i, v := range Xmat.Data[jX : jX+Xmat.Cols] {
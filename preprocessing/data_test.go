@@ -1,8 +1,10 @@
 package preprocessing
 
 import (
+	"errors"
 	"fmt"
 	_ "math"
+	"reflect"
 	"testing"
 
 	"github.com/pa-m/sklearn/base"
@@ -254,6 +256,23 @@ func ExampleShuffler() {
 	// 4	5	6	10	11	12
 }
 
+func TestShufflerRandomState(t *testing.T) {
+	m := &Shuffler{RandomState: base.NewRandomState(7)}
+	X := mat.NewDense(20, 1, nil)
+	perm := append([]int{}, m.Fit(X, X).(*Shuffler).Perm...)
+	if m.Fit(X, X); !reflect.DeepEqual(perm, m.Perm) {
+		t.Errorf("a seeded Shuffler must draw the same permutation on each Fit")
+	}
+	m.RandomState = base.CheckRandomState(base.NewRandomState(7))
+	m.Fit(X, X)
+	if !reflect.DeepEqual(perm, m.Perm) {
+		t.Errorf("a generator seeded with the same seed must draw the same first permutation")
+	}
+	if m.Fit(X, X); reflect.DeepEqual(perm, m.Perm) {
+		t.Errorf("a generator must draw a new permutation on each Fit")
+	}
+}
+
 func TestTransformer(t *testing.T) {
 	f := func(t Transformer) Transformer { return t }
 	f(NewStandardScaler())
@@ -353,3 +372,30 @@ func ExampleKernelCenterer() {
 	// ⎣0.000  0.000  0.000⎦
 
 }
+
+func TestTransformE(t *testing.T) {
+	X := mat.NewDense(3, 2, []float64{1, 2, 3, 4, 5, 6})
+	var notFitted *base.NotFittedError
+	var shapeErr *base.ShapeError
+	var paramErr *base.ParamError
+	for _, m := range []base.TransformerE{NewMinMaxScaler([]float64{0, 1}), NewStandardScaler(), NewMaxAbsScaler(), NewPolynomialFeatures(2), NewPCA(), NewImputer()} {
+		if _, _, err := m.TransformE(X, nil); !errors.As(err, &notFitted) {
+			t.Errorf("%T: expected NotFittedError, got %v", m, err)
+		}
+		if err := m.FitE(X, nil); err != nil {
+			t.Errorf("%T: %s", m, err)
+		}
+		if _, _, err := m.TransformE(mat.NewDense(3, 3, nil), nil); !errors.As(err, &shapeErr) {
+			t.Errorf("%T: expected ShapeError, got %v", m, err)
+		}
+		if _, _, err := m.TransformE(X, nil); err != nil {
+			t.Errorf("%T: %s", m, err)
+		}
+	}
+	if err := NewMinMaxScaler([]float64{0}).FitE(X, nil); !errors.As(err, &paramErr) {
+		t.Errorf("expected ParamError, got %v", err)
+	}
+	if err := (&Normalizer{Norm: "l3"}).FitE(X, nil); !errors.As(err, &paramErr) {
+		t.Errorf("expected ParamError, got %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package preprocessing
+
+import (
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/base/estimatorchecks"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestEstimatorChecks(t *testing.T) {
+	X, Y := estimatorchecks.ClassificationData()
+	shuffler := NewShuffler()
+	shuffler.RandomState = base.NewRandomState(7)
+	for _, transformer := range []estimatorchecks.Estimator{
+		NewMinMaxScaler([]float64{0, 1}), NewStandardScaler(), NewDefaultRobustScaler(), NewPolynomialFeatures(2),
+		NewOneHotEncoder(), shuffler, NewBinarizer(), NewMaxAbsScaler(), NewNormalizer(), NewKernelCenterer(), NewPCA(),
+	} {
+		if err := estimatorchecks.CheckEstimator(transformer, X, Y); err != nil {
+			t.Error(err)
+		}
+	}
+	// label transformers fit Y, X may be empty
+	for _, transformer := range []estimatorchecks.Estimator{NewLabelBinarizer(0, 1), NewMultiLabelBinarizer(), NewLabelEncoder()} {
+		if err := estimatorchecks.CheckEstimator(transformer, X, Y, estimatorchecks.Except("Shapes")...); err != nil {
+			t.Error(err)
+		}
+	}
+	// Imputer replaces NaN
+	if err := estimatorchecks.CheckEstimator(NewImputer(), X, Y, estimatorchecks.Except("NaNRejected")...); err != nil {
+		t.Error(err)
+	}
+	// a FunctionTransformer can't be saved
+	identity := func(X, Y *mat.Dense) (*mat.Dense, *mat.Dense) { return X, Y }
+	if err := estimatorchecks.CheckEstimator(NewFunctionTransformer(identity, identity), X, Y, estimatorchecks.Except("SaveLoad")...); err != nil {
+		t.Error(err)
+	}
+}
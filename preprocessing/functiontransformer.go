@@ -1,6 +1,10 @@
 package preprocessing
 
-import "gonum.org/v1/gonum/mat"
+import (
+	"github.com/pa-m/sklearn/base"
+
+	"gonum.org/v1/gonum/mat"
+)
 
 // FunctionTransformer Constructs a transformer from an arbitrary callable.
 type FunctionTransformer struct {
@@ -23,6 +27,26 @@ func (m *FunctionTransformer) Fit(X, Y *mat.Dense) Transformer {
 	return m
 }
 
+// FitE is Fit returning an error instead of panicking
+func (m *FunctionTransformer) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckXY("FunctionTransformer.Fit", X, Y); err != nil {
+		return err
+	}
+	if m.Func == nil {
+		return &base.ParamError{Estimator: m, Param: "Func", Value: m.Func, Msg: "must not be nil"}
+	}
+	return base.Recover(func() { m.Fit(X, Y) })
+}
+
+// TransformE is Transform returning an error instead of panicking
+func (m *FunctionTransformer) TransformE(X, Y *mat.Dense) (Xout, Yout *mat.Dense, err error) {
+	if err = checkTransform(m, m.Func != nil, "FunctionTransformer.Transform", X, Y, -1); err != nil {
+		return
+	}
+	err = base.Recover(func() { Xout, Yout = m.Transform(X, Y) })
+	return
+}
+
 // Transform ...
 func (m *FunctionTransformer) Transform(X, Y *mat.Dense) (X1, Y1 *mat.Dense) {
 	X1, Y1 = m.Func(X, Y)
@@ -57,6 +57,32 @@ func (m *Imputer) Fit(X, Y *mat.Dense) base.Transformer {
 	return m
 }
 
+// FitE is Fit returning an error instead of panicking
+func (m *Imputer) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckXYDims("Imputer.Fit", X, Y); err != nil {
+		return err
+	}
+	if m.Strategy != "" && m.Strategy != "mean" && m.Strategy != "median" && m.Strategy != "most_frequent" {
+		return &base.ParamError{Estimator: m, Param: "Strategy", Value: m.Strategy, Msg: "must be one of mean, median, most_frequent"}
+	}
+	return base.Recover(func() { m.Fit(X, Y) })
+}
+
+// TransformE is Transform returning an error instead of panicking
+func (m *Imputer) TransformE(X, Y *mat.Dense) (Xout, Yout *mat.Dense, err error) {
+	if m.MissingValues == nil {
+		return nil, nil, &base.NotFittedError{Estimator: m}
+	}
+	if err = base.CheckXYDims("Imputer.Transform", X, Y); err != nil {
+		return
+	}
+	if err = base.CheckNFeatures("Imputer.Transform", X, len(m.MissingValues)); err != nil {
+		return
+	}
+	err = base.Recover(func() { Xout, Yout = m.Transform(X, Y) })
+	return
+}
+
 // Transform for Imputer ...
 func (m *Imputer) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
 	Xmat := X.RawMatrix()
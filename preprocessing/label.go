@@ -1,6 +1,7 @@
 package preprocessing
 
 import (
+	"fmt"
 	"math"
 	"sort"
 
@@ -48,6 +49,29 @@ func (m *LabelBinarizer) Fit(X, Y *mat.Dense) Transformer {
 	return m
 }
 
+// FitE is Fit returning an error instead of panicking
+func (m *LabelBinarizer) FitE(X, Y *mat.Dense) error {
+	if err := checkLabels("LabelBinarizer.Fit", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { m.Fit(X, Y) })
+}
+
+// TransformE is Transform returning an error instead of panicking
+func (m *LabelBinarizer) TransformE(X, Y *mat.Dense) (Xout, Yout *mat.Dense, err error) {
+	if m.Classes == nil {
+		return nil, nil, &base.NotFittedError{Estimator: m}
+	}
+	if err = checkLabels("LabelBinarizer.Transform", X, Y); err != nil {
+		return
+	}
+	if _, c := Y.Dims(); c != len(m.Classes) {
+		return nil, nil, &base.ShapeError{Op: "LabelBinarizer.Transform", Msg: fmt.Sprintf("Y has %d columns but %d were fitted", c, len(m.Classes))}
+	}
+	err = base.Recover(func() { Xout, Yout = m.Transform(X, Y) })
+	return
+}
+
 // Transform for LabelBinarizer
 func (m *LabelBinarizer) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
 	Xout = X
@@ -163,6 +187,26 @@ func (m *MultiLabelBinarizer) Fit2(X *mat.Dense, Y interface{}) *MultiLabelBinar
 	return m
 }
 
+// FitE is Fit returning an error instead of panicking
+func (m *MultiLabelBinarizer) FitE(X, Y *mat.Dense) error {
+	if err := checkLabels("MultiLabelBinarizer.Fit", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { m.Fit(X, Y) })
+}
+
+// TransformE is Transform returning an error instead of panicking
+func (m *MultiLabelBinarizer) TransformE(X, Y *mat.Dense) (Xout, Yout *mat.Dense, err error) {
+	if m.Classes == nil {
+		return nil, nil, &base.NotFittedError{Estimator: m}
+	}
+	if err = checkLabels("MultiLabelBinarizer.Transform", X, Y); err != nil {
+		return
+	}
+	err = base.Recover(func() { Xout, Yout = m.Transform(X, Y) })
+	return
+}
+
 // Transform for MultiLabelBinarizer ...
 // Y type must be the same passed int Fit
 func (m *MultiLabelBinarizer) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
@@ -312,6 +356,29 @@ func (m *LabelEncoder) PartialFit(X, Y *mat.Dense) base.Transformer {
 	return m
 }
 
+// FitE is Fit returning an error instead of panicking
+func (m *LabelEncoder) FitE(X, Y *mat.Dense) error {
+	if err := checkLabels("LabelEncoder.Fit", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { m.Fit(X, Y) })
+}
+
+// TransformE is Transform returning an error instead of panicking
+func (m *LabelEncoder) TransformE(X, Y *mat.Dense) (Xout, Yout *mat.Dense, err error) {
+	if m.Classes == nil {
+		return nil, nil, &base.NotFittedError{Estimator: m}
+	}
+	if err = checkLabels("LabelEncoder.Transform", X, Y); err != nil {
+		return
+	}
+	if _, c := Y.Dims(); c != len(m.Classes) {
+		return nil, nil, &base.ShapeError{Op: "LabelEncoder.Transform", Msg: fmt.Sprintf("Y has %d columns but %d were fitted", c, len(m.Classes))}
+	}
+	err = base.Recover(func() { Xout, Yout = m.Transform(X, Y) })
+	return
+}
+
 // Transform for LabelEncoder ...
 func (m *LabelEncoder) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
 	Ymat := Y.RawMatrix()
@@ -13,14 +13,22 @@ type PCA struct {
 	MinVarianceRatio                       float64
 	NComponents                            int
 	SingularValues, ExplainedVarianceRatio []float64
+
+	// v holds the right singular vectors of a loaded PCA, SVD being not saved
+	v *mat.Dense
 }
 
 // NewPCA returns a *PCA
 func NewPCA() *PCA { return &PCA{} }
 
 // Clone ...
+// the clone has its own SVD, the clone of a fitted PCA keeps its right singular vectors as a loaded PCA does
 func (m *PCA) Clone() Transformer {
 	clone := *m
+	clone.SVD = mat.SVD{}
+	if m.SingularValues != nil {
+		clone.v = m.rightSingularVectors()
+	}
 	return &clone
 }
 
@@ -28,6 +36,7 @@ func (m *PCA) Clone() Transformer {
 func (m *PCA) Fit(X, Y *mat.Dense) Transformer {
 	_, c := X.Dims()
 	m.SVD.Factorize(X, mat.SVDThin)
+	m.v = nil
 	m.SingularValues = make([]float64, c)
 	m.ExplainedVarianceRatio = make([]float64, c)
 	m.SVD.Values(m.SingularValues)
@@ -49,10 +58,26 @@ func (m *PCA) Fit(X, Y *mat.Dense) Transformer {
 	return m
 }
 
+// FitE is Fit returning an error instead of panicking
+func (m *PCA) FitE(X, Y *mat.Dense) error {
+	if err := base.CheckXY("PCA.Fit", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { m.Fit(X, Y) })
+}
+
+// TransformE is Transform returning an error instead of panicking
+func (m *PCA) TransformE(X, Y *mat.Dense) (Xout, Yout *mat.Dense, err error) {
+	if err = checkTransform(m, m.SingularValues != nil, "PCA.Transform", X, Y, len(m.SingularValues)); err != nil {
+		return
+	}
+	err = base.Recover(func() { Xout, Yout = m.Transform(X, Y) })
+	return
+}
+
 // Transform Transforms X
 func (m *PCA) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
-	var v = new(mat.Dense)
-	m.SVD.VTo(v)
+	v := m.rightSingularVectors()
 	nSamples, _ := X.Dims()
 	vRows, _ := v.Dims()
 	Xout = mat.NewDense(nSamples, m.NComponents, nil)
@@ -62,6 +87,15 @@ func (m *PCA) Transform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
 	return
 }
 
+func (m *PCA) rightSingularVectors() *mat.Dense {
+	if m.v != nil {
+		return m.v
+	}
+	v := new(mat.Dense)
+	m.SVD.VTo(v)
+	return v
+}
+
 // FitTransform for PCA
 func (m *PCA) FitTransform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
 	return m.Fit(X, Y).Transform(X, Y)
@@ -73,8 +107,7 @@ func (m *PCA) InverseTransform(X, Y *mat.Dense) (Xout, Yout *mat.Dense) {
 		return X, Y
 	}
 
-	var v = new(mat.Dense)
-	m.SVD.VTo(v)
+	v := m.rightSingularVectors()
 	nSamples, _ := X.Dims()
 	_, vCols := v.Dims()
 	Xout = mat.NewDense(nSamples, vCols, nil)
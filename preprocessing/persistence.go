@@ -0,0 +1,143 @@
+package preprocessing
+
+import (
+	"io"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+// FunctionTransformer is not registered as funcs can't be saved
+func init() {
+	base.Register(&MinMaxScaler{})
+	base.Register(&StandardScaler{})
+	base.Register(&RobustScaler{})
+	base.Register(&PolynomialFeatures{})
+	base.Register(&OneHotEncoder{})
+	base.Register(&Shuffler{})
+	base.Register(&Binarizer{})
+	base.Register(&MaxAbsScaler{})
+	base.Register(&Normalizer{})
+	base.Register(&KernelCenterer{})
+	base.Register(&Imputer{})
+	base.Register(&LabelBinarizer{})
+	base.Register(&MultiLabelBinarizer{})
+	base.Register(&LabelEncoder{})
+	base.Register(&PCA{})
+}
+
+// Save writes the MinMaxScaler to w
+func (scaler *MinMaxScaler) Save(w io.Writer) error { return base.Save(w, scaler) }
+
+// Load reads a MinMaxScaler written by Save
+func (scaler *MinMaxScaler) Load(r io.Reader) error { return base.LoadInto(r, scaler) }
+
+// Save writes the StandardScaler to w
+func (scaler *StandardScaler) Save(w io.Writer) error { return base.Save(w, scaler) }
+
+// Load reads a StandardScaler written by Save
+func (scaler *StandardScaler) Load(r io.Reader) error { return base.LoadInto(r, scaler) }
+
+// Save writes the RobustScaler to w
+func (scaler *RobustScaler) Save(w io.Writer) error { return base.Save(w, scaler) }
+
+// Load reads a RobustScaler written by Save
+func (scaler *RobustScaler) Load(r io.Reader) error { return base.LoadInto(r, scaler) }
+
+// Save writes the PolynomialFeatures to w
+func (scaler *PolynomialFeatures) Save(w io.Writer) error { return base.Save(w, scaler) }
+
+// Load reads a PolynomialFeatures written by Save
+func (scaler *PolynomialFeatures) Load(r io.Reader) error { return base.LoadInto(r, scaler) }
+
+// Save writes the OneHotEncoder to w
+func (m *OneHotEncoder) Save(w io.Writer) error { return base.Save(w, m) }
+
+// Load reads an OneHotEncoder written by Save
+func (m *OneHotEncoder) Load(r io.Reader) error { return base.LoadInto(r, m) }
+
+// Save writes the Shuffler to w
+func (m *Shuffler) Save(w io.Writer) error { return base.Save(w, m) }
+
+// Load reads a Shuffler written by Save
+func (m *Shuffler) Load(r io.Reader) error { return base.LoadInto(r, m) }
+
+// Save writes the Binarizer to w
+func (m *Binarizer) Save(w io.Writer) error { return base.Save(w, m) }
+
+// Load reads a Binarizer written by Save
+func (m *Binarizer) Load(r io.Reader) error { return base.LoadInto(r, m) }
+
+// Save writes the MaxAbsScaler to w
+func (m *MaxAbsScaler) Save(w io.Writer) error { return base.Save(w, m) }
+
+// Load reads a MaxAbsScaler written by Save
+func (m *MaxAbsScaler) Load(r io.Reader) error { return base.LoadInto(r, m) }
+
+// Save writes the Normalizer to w
+func (m *Normalizer) Save(w io.Writer) error { return base.Save(w, m) }
+
+// Load reads a Normalizer written by Save
+func (m *Normalizer) Load(r io.Reader) error { return base.LoadInto(r, m) }
+
+// Save writes the KernelCenterer to w
+func (m *KernelCenterer) Save(w io.Writer) error { return base.Save(w, m) }
+
+// Load reads a KernelCenterer written by Save
+func (m *KernelCenterer) Load(r io.Reader) error { return base.LoadInto(r, m) }
+
+// Save writes the Imputer to w
+func (m *Imputer) Save(w io.Writer) error { return base.Save(w, m) }
+
+// Load reads an Imputer written by Save
+func (m *Imputer) Load(r io.Reader) error { return base.LoadInto(r, m) }
+
+// Save writes the LabelBinarizer to w
+func (m *LabelBinarizer) Save(w io.Writer) error { return base.Save(w, m) }
+
+// Load reads a LabelBinarizer written by Save
+func (m *LabelBinarizer) Load(r io.Reader) error { return base.LoadInto(r, m) }
+
+// Save writes the MultiLabelBinarizer to w
+func (m *MultiLabelBinarizer) Save(w io.Writer) error { return base.Save(w, m) }
+
+// Load reads a MultiLabelBinarizer written by Save
+func (m *MultiLabelBinarizer) Load(r io.Reader) error { return base.LoadInto(r, m) }
+
+// Save writes the LabelEncoder to w
+func (m *LabelEncoder) Save(w io.Writer) error { return base.Save(w, m) }
+
+// Load reads a LabelEncoder written by Save
+func (m *LabelEncoder) Load(r io.Reader) error { return base.LoadInto(r, m) }
+
+// Save writes the PCA to w
+func (m *PCA) Save(w io.Writer) error { return base.Save(w, m) }
+
+// Load reads a PCA written by Save
+func (m *PCA) Load(r io.Reader) error { return base.LoadInto(r, m) }
+
+type pcaFields struct {
+	MinVarianceRatio                       float64
+	NComponents                            int
+	SingularValues, ExplainedVarianceRatio []float64
+	V                                      *mat.Dense
+}
+
+// GobEncode encodes PCA with its right singular vectors instead of the SVD
+func (m *PCA) GobEncode() ([]byte, error) {
+	fields := pcaFields{MinVarianceRatio: m.MinVarianceRatio, NComponents: m.NComponents, SingularValues: m.SingularValues, ExplainedVarianceRatio: m.ExplainedVarianceRatio}
+	if m.SingularValues != nil {
+		fields.V = m.rightSingularVectors()
+	}
+	return base.GobEncodeFields(fields)
+}
+
+// GobDecode decodes a PCA encoded by GobEncode
+func (m *PCA) GobDecode(b []byte) error {
+	var fields pcaFields
+	if err := base.GobDecodeFields(b, &fields); err != nil {
+		return err
+	}
+	*m = PCA{MinVarianceRatio: fields.MinVarianceRatio, NComponents: fields.NComponents, SingularValues: fields.SingularValues, ExplainedVarianceRatio: fields.ExplainedVarianceRatio, v: fields.V}
+	return nil
+}
@@ -0,0 +1,34 @@
+package preprocessing
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestSaveLoad(t *testing.T) {
+	X := mat.NewDense(5, 3, []float64{1, 2, 3, 4, 5, 7, 7, 8, 8, 1, 0, 2, 5, 5, 1})
+	for _, m := range []Transformer{NewMinMaxScaler([]float64{0, 1}), NewStandardScaler(), NewRobustScaler(true, true, &QuantilePair{Left: .25, Right: .75}), NewPolynomialFeatures(2), NewMaxAbsScaler(), NewNormalizer(), NewBinarizer(), NewImputer(), NewPCA()} {
+		m.Fit(X, nil)
+		var buf bytes.Buffer
+		if err := base.Save(&buf, m); err != nil {
+			t.Errorf("%T: %s", m, err)
+			continue
+		}
+		loaded, err := base.Load(&buf)
+		if err != nil {
+			t.Errorf("%T: %s", m, err)
+			continue
+		}
+		Xout, _ := m.Transform(X, nil)
+		Xloaded, _ := loaded.Transform(X, nil)
+		if !mat.Equal(Xout, Xloaded) {
+			t.Errorf("%T: Transform differs after Load", m)
+		}
+	}
+	if err := base.Save(&bytes.Buffer{}, NewFunctionTransformer(nil, nil)); err == nil {
+		t.Error("expected an error saving a FunctionTransformer")
+	}
+}
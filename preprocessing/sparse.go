@@ -0,0 +1,97 @@
+package preprocessing
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/sparse"
+	"gonum.org/v1/gonum/mat"
+)
+
+// FitSparse computes Mean, Var and Scale of a sparse X, a *sparse.CSR or a *sparse.CSC.
+// it panics with a *base.ParamError if WithMean is set, as centering would make X dense
+func (scaler *StandardScaler) FitSparse(X mat.Matrix, Y *mat.Dense) Transformer {
+	if scaler.WithMean {
+		panic(&base.ParamError{Estimator: scaler, Param: "WithMean", Value: true, Msg: "must be false for a sparse X"})
+	}
+	nSamples, nFeatures := X.Dims()
+	scaler.Reset()
+	scaler.Mean = mat.NewDense(1, nFeatures, nil)
+	scaler.Var = mat.NewDense(1, nFeatures, nil)
+	scaler.Scale = mat.NewDense(1, nFeatures, nil)
+	mean, variance := scaler.Mean.RawRowView(0), scaler.Var.RawRowView(0)
+	sparse.CSROf(X).DoNonZero(func(_, j int, v float64) {
+		mean[j] += v
+		variance[j] += v * v
+	})
+	for j := range mean {
+		mean[j] /= float64(nSamples)
+		variance[j] = math.Max(0, variance[j]/float64(nSamples)-mean[j]*mean[j])
+		scale := math.Sqrt(variance[j])
+		if variance[j] == 0 {
+			scale = 1
+		}
+		scaler.Scale.Set(0, j, scale)
+	}
+	scaler.NSamplesSeen = nSamples
+	return scaler
+}
+
+// TransformSparse scales a sparse X, keeping its zeros. it panics with a *base.ParamError if WithMean is set
+func (scaler *StandardScaler) TransformSparse(X mat.Matrix, Y *mat.Dense) (Xout *sparse.CSR, Yout *mat.Dense) {
+	if scaler.WithMean {
+		panic(&base.ParamError{Estimator: scaler, Param: "WithMean", Value: true, Msg: "must be false for a sparse X"})
+	}
+	r, c := X.Dims()
+	indptr := make([]int, r+1)
+	var indices []int
+	var data []float64
+	sparse.CSROf(X).DoNonZero(func(i, j int, v float64) {
+		if scaler.WithStd {
+			v /= scaler.Scale.At(0, j)
+		}
+		indices = append(indices, j)
+		data = append(data, v)
+		indptr[i+1] = len(data)
+	})
+	for i := 1; i <= r; i++ {
+		if indptr[i] < indptr[i-1] {
+			indptr[i] = indptr[i-1]
+		}
+	}
+	return sparse.NewCSR(r, c, indptr, indices, data), Y
+}
+
+// TransformSparse is Transform returning a sparse Xout, with a single non-zero per feature in each row.
+// it panics with a *base.ValueError if X has a category not seen by Fit
+func (m *OneHotEncoder) TransformSparse(X, Y *mat.Dense) (Xout *sparse.CSR, Yout *mat.Dense) {
+	NSamples, nfeatures := X.Dims()
+	columns := 0
+	cmaps := make([]map[float64]int, nfeatures)
+	for feature := 0; feature < nfeatures; feature++ {
+		columns += m.NValues[feature]
+		cmaps[feature] = make(map[float64]int)
+		for i, v := range m.Values[feature] {
+			cmaps[feature][v] = i
+		}
+	}
+	indptr := make([]int, NSamples+1)
+	indices := make([]int, 0, NSamples*nfeatures)
+	data := make([]float64, 0, NSamples*nfeatures)
+	for sample := 0; sample < NSamples; sample++ {
+		baseColumn := 0
+		for feature := 0; feature < nfeatures; feature++ {
+			v := X.At(sample, feature)
+			index, ok := cmaps[feature][v]
+			if !ok {
+				panic(&base.ValueError{Op: "OneHotEncoder.TransformSparse", Msg: fmt.Sprintf("unknown category %g of feature %d at row %d", v, feature, sample)})
+			}
+			indices = append(indices, baseColumn+index)
+			data = append(data, 1)
+			baseColumn += m.NValues[feature]
+		}
+		indptr[sample+1] = len(data)
+	}
+	return sparse.NewCSR(NSamples, columns, indptr, indices, data), Y
+}
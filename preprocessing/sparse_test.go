@@ -0,0 +1,55 @@
+package preprocessing
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/sparse"
+	"gonum.org/v1/gonum/mat"
+)
+
+func ExampleOneHotEncoder_TransformSparse() {
+	X := mat.NewDense(4, 2, []float64{0, 1, 1, 0, 2, 2, 0, 0})
+	ohe := NewOneHotEncoder()
+	ohe.Fit(X, nil)
+	Xout, _ := ohe.TransformSparse(X, nil)
+	fmt.Println("nnz:", Xout.NNZ())
+	fmt.Printf("%g\n", mat.Formatted(Xout))
+	// Output:
+	// nnz: 8
+	// ⎡1  0  0  0  1  0⎤
+	// ⎢0  1  0  1  0  0⎥
+	// ⎢0  0  1  0  0  1⎥
+	// ⎣1  0  0  1  0  0⎦
+}
+
+func TestOneHotEncoderTransformSparseUnknown(t *testing.T) {
+	ohe := NewOneHotEncoder()
+	ohe.Fit(mat.NewDense(3, 1, []float64{0, 1, 2}), nil)
+	var valueErr *base.ValueError
+	err := base.Recover(func() { ohe.TransformSparse(mat.NewDense(2, 1, []float64{1, 3}), nil) })
+	if !errors.As(err, &valueErr) {
+		t.Errorf("expected a *base.ValueError for an unknown category, got %v", err)
+	}
+}
+
+func TestStandardScalerSparse(t *testing.T) {
+	X := mat.NewDense(4, 3, []float64{1, 0, 0, 0, 2, 0, 3, 0, 0, 0, 4, 0})
+	dense := &StandardScaler{WithStd: true}
+	Xdense, _ := dense.FitTransform(X, nil)
+	scaler := &StandardScaler{WithStd: true}
+	scaler.FitSparse(sparse.CSCCopyOf(X), nil)
+	Xs, _ := scaler.TransformSparse(sparse.CSRCopyOf(X), nil)
+	if !mat.EqualApprox(dense.Var, scaler.Var, 1e-12) || !mat.EqualApprox(Xdense, Xs, 1e-12) {
+		t.Errorf("expected\n%g\ngot\n%g", mat.Formatted(Xdense), mat.Formatted(Xs))
+	}
+	if Xs.NNZ() != 4 {
+		t.Errorf("expected 4 non-zeros, got %d", Xs.NNZ())
+	}
+	var pe *base.ParamError
+	if err := base.Recover(func() { NewStandardScaler().FitSparse(sparse.CSRCopyOf(X), nil) }); !errors.As(err, &pe) {
+		t.Errorf("expected a *base.ParamError for WithMean, got %v", err)
+	}
+}
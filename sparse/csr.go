@@ -0,0 +1,364 @@
+// Package sparse provides compressed sparse row (CSR) and column (CSC) matrices implementing mat.Matrix,
+// and the products used by the sparse code paths of the estimators
+package sparse
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// CSR is a compressed sparse row matrix: the non-zero values of row i are Data[Indptr[i]:Indptr[i+1]],
+// in the columns Indices[Indptr[i]:Indptr[i+1]], sorted in increasing order
+type CSR struct {
+	r, c    int
+	indptr  []int
+	indices []int
+	data    []float64
+}
+
+// CSC is a compressed sparse column matrix: the non-zero values of column j are Data[Indptr[j]:Indptr[j+1]],
+// in the rows Indices[Indptr[j]:Indptr[j+1]], sorted in increasing order
+type CSC struct {
+	r, c    int
+	indptr  []int
+	indices []int
+	data    []float64
+}
+
+var (
+	_ mat.Matrix = &CSR{}
+	_ mat.Matrix = &CSC{}
+)
+
+// Vector is a sparse vector of length N with values Data at the sorted positions Indices
+type Vector struct {
+	N       int
+	Indices []int
+	Data    []float64
+}
+
+// NewCSR returns a r×c CSR matrix using indptr, indices and data as its storage.
+// indptr has r+1 elements, indices and data indptr[r] elements. indices of each row must be sorted and distinct
+func NewCSR(r, c int, indptr, indices []int, data []float64) *CSR {
+	checkCompressed("NewCSR", r, c, indptr, indices, data)
+	return &CSR{r: r, c: c, indptr: indptr, indices: indices, data: data}
+}
+
+// NewCSC returns a r×c CSC matrix using indptr, indices and data as its storage.
+// indptr has c+1 elements, indices and data indptr[c] elements. indices of each column must be sorted and distinct
+func NewCSC(r, c int, indptr, indices []int, data []float64) *CSC {
+	checkCompressed("NewCSC", c, r, indptr, indices, data)
+	return &CSC{r: r, c: c, indptr: indptr, indices: indices, data: data}
+}
+
+// checkCompressed panics if indptr, indices and data are not a valid storage for n compressed vectors of length l
+func checkCompressed(op string, n, l int, indptr, indices []int, data []float64) {
+	if n < 0 || l < 0 || len(indptr) != n+1 || indptr[0] != 0 || len(indices) != indptr[n] || len(data) != indptr[n] {
+		panic(fmt.Errorf("%s: %v", op, mat.ErrShape))
+	}
+	for k := 0; k < n; k++ {
+		if indptr[k+1] < indptr[k] {
+			panic(fmt.Errorf("%s: indptr is not increasing", op))
+		}
+		for p := indptr[k]; p < indptr[k+1]; p++ {
+			if indices[p] < 0 || indices[p] >= l || (p > indptr[k] && indices[p] <= indices[p-1]) {
+				panic(fmt.Errorf("%s: indices are not sorted, distinct and in range", op))
+			}
+		}
+	}
+}
+
+// compress returns the compressed storage of the n vectors of the non-zero values of m,
+// along its rows (byRow) or its columns
+func compress(m mat.Matrix, byRow bool) (indptr, indices []int, data []float64) {
+	r, c := m.Dims()
+	n, l := c, r
+	if byRow {
+		n, l = r, c
+	}
+	indptr = make([]int, n+1)
+	at := m.At
+	if d, ok := m.(*mat.Dense); ok && byRow {
+		at = func(i, j int) float64 { return d.RawRowView(i)[j] }
+	}
+	for k := 0; k < n; k++ {
+		for p := 0; p < l; p++ {
+			var v float64
+			if byRow {
+				v = at(k, p)
+			} else {
+				v = at(p, k)
+			}
+			if v != 0 {
+				indices = append(indices, p)
+				data = append(data, v)
+			}
+		}
+		indptr[k+1] = len(data)
+	}
+	return
+}
+
+// transpose returns the compressed storage of the l vectors of length n transposed from n vectors of length l
+func transpose(n, l int, indptr, indices []int, data []float64) (tindptr, tindices []int, tdata []float64) {
+	nnz := indptr[n] - indptr[0]
+	tindptr = make([]int, l+1)
+	for p := indptr[0]; p < indptr[n]; p++ {
+		tindptr[indices[p]+1]++
+	}
+	for k := 0; k < l; k++ {
+		tindptr[k+1] += tindptr[k]
+	}
+	tindices, tdata = make([]int, nnz), make([]float64, nnz)
+	next := append([]int{}, tindptr[:l]...)
+	for k := 0; k < n; k++ {
+		for p := indptr[k]; p < indptr[k+1]; p++ {
+			q := next[indices[p]]
+			tindices[q], tdata[q] = k, data[p]
+			next[indices[p]]++
+		}
+	}
+	return
+}
+
+// CSRCopyOf returns a CSR copy of m. a *CSC is converted without scanning its zeros
+func CSRCopyOf(m mat.Matrix) *CSR {
+	r, c := m.Dims()
+	switch s := m.(type) {
+	case *CSR:
+		return s.TakeRows(nil)
+	case *CSC:
+		indptr, indices, data := transpose(c, r, s.indptr, s.indices, s.data)
+		return &CSR{r: r, c: c, indptr: indptr, indices: indices, data: data}
+	}
+	indptr, indices, data := compress(m, true)
+	return &CSR{r: r, c: c, indptr: indptr, indices: indices, data: data}
+}
+
+// CSCCopyOf returns a CSC copy of m. a *CSR is converted without scanning its zeros
+func CSCCopyOf(m mat.Matrix) *CSC {
+	r, c := m.Dims()
+	switch s := m.(type) {
+	case *CSC:
+		return CSRCopyOf(s.T()).T().(*CSC)
+	case *CSR:
+		indptr, indices, data := transpose(r, c, s.indptr[:r+1:r+1], s.indices, s.data)
+		return &CSC{r: r, c: c, indptr: indptr, indices: indices, data: data}
+	}
+	indptr, indices, data := compress(m, false)
+	return &CSC{r: r, c: c, indptr: indptr, indices: indices, data: data}
+}
+
+// CSROf returns m if it is a *CSR, else a CSR copy of m
+func CSROf(m mat.Matrix) *CSR {
+	if s, ok := m.(*CSR); ok {
+		return s
+	}
+	return CSRCopyOf(m)
+}
+
+// CSCOf returns m if it is a *CSC, else a CSC copy of m
+func CSCOf(m mat.Matrix) *CSC {
+	if s, ok := m.(*CSC); ok {
+		return s
+	}
+	return CSCCopyOf(m)
+}
+
+// IsSparse returns true if m is a *CSR or a *CSC
+func IsSparse(m mat.Matrix) bool {
+	switch m.(type) {
+	case *CSR, *CSC:
+		return true
+	}
+	return false
+}
+
+// Dims returns the dimensions of the matrix
+func (m *CSR) Dims() (r, c int) { return m.r, m.c }
+
+// At returns the element at row i, column j
+func (m *CSR) At(i, j int) float64 {
+	if uint(i) >= uint(m.r) || uint(j) >= uint(m.c) {
+		panic(mat.ErrIndexOutOfRange)
+	}
+	return vectorAt(m.indices[m.indptr[i]:m.indptr[i+1]], m.data[m.indptr[i]:m.indptr[i+1]], j)
+}
+
+// T returns the transpose of m, a *CSC sharing the storage of m
+func (m *CSR) T() mat.Matrix {
+	return &CSC{r: m.c, c: m.r, indptr: m.indptr, indices: m.indices, data: m.data}
+}
+
+// NNZ returns the number of stored values
+func (m *CSR) NNZ() int { return m.indptr[m.r] - m.indptr[0] }
+
+// Row returns row i as a Vector sharing the storage of m
+func (m *CSR) Row(i int) Vector {
+	if uint(i) >= uint(m.r) {
+		panic(mat.ErrRowAccess)
+	}
+	return Vector{N: m.c, Indices: m.indices[m.indptr[i]:m.indptr[i+1]], Data: m.data[m.indptr[i]:m.indptr[i+1]]}
+}
+
+// DoNonZero calls fn for each stored value of m, row by row
+func (m *CSR) DoNonZero(fn func(i, j int, v float64)) {
+	for i := 0; i < m.r; i++ {
+		for p := m.indptr[i]; p < m.indptr[i+1]; p++ {
+			fn(i, m.indices[p], m.data[p])
+		}
+	}
+}
+
+// SliceRows returns the rows i to k-1 of m, sharing its storage
+func (m *CSR) SliceRows(i, k int) *CSR {
+	if i < 0 || k > m.r || i > k {
+		panic(mat.ErrIndexOutOfRange)
+	}
+	return &CSR{r: k - i, c: m.c, indptr: m.indptr[i : k+1], indices: m.indices, data: m.data}
+}
+
+// TakeRows returns a copy of the rows of m in the order of rows, or of all rows if rows is nil
+func (m *CSR) TakeRows(rows []int) *CSR {
+	if rows == nil {
+		rows = make([]int, m.r)
+		for i := range rows {
+			rows[i] = i
+		}
+	}
+	out := &CSR{r: len(rows), c: m.c, indptr: make([]int, len(rows)+1)}
+	for k, i := range rows {
+		row := m.Row(i)
+		out.indices = append(out.indices, row.Indices...)
+		out.data = append(out.data, row.Data...)
+		out.indptr[k+1] = len(out.data)
+	}
+	return out
+}
+
+// ToDense returns a dense copy of m
+func (m *CSR) ToDense() *mat.Dense {
+	d := mat.NewDense(m.r, m.c, nil)
+	m.DoNonZero(func(i, j int, v float64) { d.Set(i, j, v) })
+	return d
+}
+
+// Dims returns the dimensions of the matrix
+func (m *CSC) Dims() (r, c int) { return m.r, m.c }
+
+// At returns the element at row i, column j
+func (m *CSC) At(i, j int) float64 {
+	if uint(i) >= uint(m.r) || uint(j) >= uint(m.c) {
+		panic(mat.ErrIndexOutOfRange)
+	}
+	return vectorAt(m.indices[m.indptr[j]:m.indptr[j+1]], m.data[m.indptr[j]:m.indptr[j+1]], i)
+}
+
+// T returns the transpose of m, a *CSR sharing the storage of m
+func (m *CSC) T() mat.Matrix {
+	return &CSR{r: m.c, c: m.r, indptr: m.indptr, indices: m.indices, data: m.data}
+}
+
+// NNZ returns the number of stored values
+func (m *CSC) NNZ() int { return m.indptr[m.c] - m.indptr[0] }
+
+// Col returns column j as a Vector sharing the storage of m
+func (m *CSC) Col(j int) Vector {
+	if uint(j) >= uint(m.c) {
+		panic(mat.ErrColAccess)
+	}
+	return Vector{N: m.r, Indices: m.indices[m.indptr[j]:m.indptr[j+1]], Data: m.data[m.indptr[j]:m.indptr[j+1]]}
+}
+
+// DoNonZero calls fn for each stored value of m, column by column
+func (m *CSC) DoNonZero(fn func(i, j int, v float64)) {
+	for j := 0; j < m.c; j++ {
+		for p := m.indptr[j]; p < m.indptr[j+1]; p++ {
+			fn(m.indices[p], j, m.data[p])
+		}
+	}
+}
+
+// ToDense returns a dense copy of m
+func (m *CSC) ToDense() *mat.Dense {
+	d := mat.NewDense(m.r, m.c, nil)
+	m.DoNonZero(func(i, j int, v float64) { d.Set(i, j, v) })
+	return d
+}
+
+func vectorAt(indices []int, data []float64, j int) float64 {
+	p := sort.SearchInts(indices, j)
+	if p < len(indices) && indices[p] == j {
+		return data[p]
+	}
+	return 0
+}
+
+// At returns the element i of v
+func (v Vector) At(i int) float64 {
+	if uint(i) >= uint(v.N) {
+		panic(mat.ErrIndexOutOfRange)
+	}
+	return vectorAt(v.Indices, v.Data, i)
+}
+
+type gobCompressed struct {
+	R, C            int
+	Indptr, Indices []int
+	Data            []float64
+}
+
+func gobEncode(r, c int, indptr, indices []int, data []float64) ([]byte, error) {
+	var buf bytes.Buffer
+	// rebase indptr, which may be a view of a larger matrix
+	rebased := make([]int, len(indptr))
+	for k, p := range indptr {
+		rebased[k] = p - indptr[0]
+	}
+	n := len(indptr) - 1
+	err := gob.NewEncoder(&buf).Encode(gobCompressed{R: r, C: c, Indptr: rebased, Indices: indices[indptr[0]:indptr[n]], Data: data[indptr[0]:indptr[n]]})
+	return buf.Bytes(), err
+}
+
+func gobDecode(b []byte) (g gobCompressed, err error) {
+	err = gob.NewDecoder(bytes.NewReader(b)).Decode(&g)
+	if err == nil && g.Indptr == nil {
+		// gob omits empty slices
+		g.Indptr = []int{0}
+	}
+	return
+}
+
+// GobEncode encodes m for encoding/gob
+func (m *CSR) GobEncode() ([]byte, error) {
+	return gobEncode(m.r, m.c, m.indptr, m.indices, m.data)
+}
+
+// GobDecode decodes a CSR encoded by GobEncode
+func (m *CSR) GobDecode(b []byte) error {
+	g, err := gobDecode(b)
+	if err != nil {
+		return err
+	}
+	*m = CSR{r: g.R, c: g.C, indptr: g.Indptr, indices: g.Indices, data: g.Data}
+	return nil
+}
+
+// GobEncode encodes m for encoding/gob
+func (m *CSC) GobEncode() ([]byte, error) {
+	return gobEncode(m.r, m.c, m.indptr, m.indices, m.data)
+}
+
+// GobDecode decodes a CSC encoded by GobEncode
+func (m *CSC) GobDecode(b []byte) error {
+	g, err := gobDecode(b)
+	if err != nil {
+		return err
+	}
+	*m = CSC{r: g.R, c: g.C, indptr: g.Indptr, indices: g.Indices, data: g.Data}
+	return nil
+}
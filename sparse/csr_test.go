@@ -0,0 +1,85 @@
+package sparse
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func ExampleCSRCopyOf() {
+	X := mat.NewDense(3, 4, []float64{
+		1, 0, 0, 2,
+		0, 0, 3, 0,
+		0, 4, 0, 5,
+	})
+	csr := CSRCopyOf(X)
+	fmt.Println("nnz:", csr.NNZ())
+	fmt.Printf("%g\n", mat.Formatted(csr))
+	fmt.Printf("%g\n", mat.Formatted(csr.T()))
+	fmt.Println(csr.Row(2))
+	// Output:
+	// nnz: 5
+	// ⎡1  0  0  2⎤
+	// ⎢0  0  3  0⎥
+	// ⎣0  4  0  5⎦
+	// ⎡1  0  0⎤
+	// ⎢0  0  4⎥
+	// ⎢0  3  0⎥
+	// ⎣2  0  5⎦
+	// {4 [1 3] [4 5]}
+}
+
+func TestCSRCSC(t *testing.T) {
+	X := mat.NewDense(4, 3, []float64{
+		0, 1, 0,
+		2, 0, 3,
+		0, 0, 0,
+		4, 5, 6,
+	})
+	csr := CSRCopyOf(X)
+	csc := CSCCopyOf(X)
+	for _, m := range []mat.Matrix{csr, csc, CSCCopyOf(csr), CSRCopyOf(csc), csr.ToDense(), csc.ToDense()} {
+		if !mat.Equal(X, m) {
+			t.Errorf("expected\n%g\ngot\n%g", mat.Formatted(X), mat.Formatted(m))
+		}
+	}
+	if !mat.Equal(X.Slice(1, 3, 0, 3), csr.SliceRows(1, 3)) {
+		t.Errorf("SliceRows: got\n%g", mat.Formatted(csr.SliceRows(1, 3)))
+	}
+	if !mat.Equal(X.Slice(1, 3, 0, 3), CSCCopyOf(csr.SliceRows(1, 3))) {
+		t.Errorf("CSCCopyOf(SliceRows): got\n%g", mat.Formatted(CSCCopyOf(csr.SliceRows(1, 3))))
+	}
+	taken := csr.TakeRows([]int{3, 0})
+	if !mat.Equal(mat.NewDense(2, 3, []float64{4, 5, 6, 0, 1, 0}), taken) {
+		t.Errorf("TakeRows: got\n%g", mat.Formatted(taken))
+	}
+	if csc.Col(0).At(3) != 4 {
+		t.Errorf("Col: got %v", csc.Col(0))
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic for unsorted indices")
+			}
+		}()
+		NewCSR(1, 3, []int{0, 2}, []int{2, 1}, []float64{1, 1})
+	}()
+}
+
+func TestCSRGob(t *testing.T) {
+	X := mat.NewDense(3, 3, []float64{1, 0, 2, 0, 0, 0, 0, 3, 0})
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(CSRCopyOf(X).SliceRows(1, 3)); err != nil {
+		t.Fatal(err)
+	}
+	var csr CSR
+	if err := gob.NewDecoder(&buf).Decode(&csr); err != nil {
+		t.Fatal(err)
+	}
+	if !mat.Equal(X.Slice(1, 3, 0, 3), &csr) {
+		t.Errorf("got\n%g", mat.Formatted(&csr))
+	}
+}
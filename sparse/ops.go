@@ -0,0 +1,162 @@
+package sparse
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Dot returns the dot product of the sparse vectors a and b
+func Dot(a, b Vector) float64 {
+	if a.N != b.N {
+		panic(mat.ErrShape)
+	}
+	var s float64
+	for p, q := 0, 0; p < len(a.Indices) && q < len(b.Indices); {
+		switch {
+		case a.Indices[p] < b.Indices[q]:
+			p++
+		case a.Indices[p] > b.Indices[q]:
+			q++
+		default:
+			s += a.Data[p] * b.Data[q]
+			p++
+			q++
+		}
+	}
+	return s
+}
+
+// DotDense returns the dot product of the sparse vector a and the dense vector b
+func DotDense(a Vector, b []float64) float64 {
+	if a.N != len(b) {
+		panic(mat.ErrShape)
+	}
+	var s float64
+	for p, j := range a.Indices {
+		s += a.Data[p] * b[j]
+	}
+	return s
+}
+
+// Norm2 returns the squared euclidean norm of a
+func Norm2(a Vector) float64 {
+	var s float64
+	for _, v := range a.Data {
+		s += v * v
+	}
+	return s
+}
+
+// MinkowskiDistance returns the Minkowski distance of order p between a and b. p may be math.Inf(1)
+func MinkowskiDistance(a, b Vector, p float64) float64 {
+	if a.N != b.N {
+		panic(mat.ErrShape)
+	}
+	var s float64
+	add := func(d float64) {
+		d = math.Abs(d)
+		switch {
+		case math.IsInf(p, 1):
+			s = math.Max(s, d)
+		case p == 1:
+			s += d
+		case p == 2:
+			s += d * d
+		default:
+			s += math.Pow(d, p)
+		}
+	}
+	i, k := 0, 0
+	for i < len(a.Indices) || k < len(b.Indices) {
+		switch {
+		case k == len(b.Indices) || (i < len(a.Indices) && a.Indices[i] < b.Indices[k]):
+			add(a.Data[i])
+			i++
+		case i == len(a.Indices) || a.Indices[i] > b.Indices[k]:
+			add(b.Data[k])
+			k++
+		default:
+			add(a.Data[i] - b.Data[k])
+			i++
+			k++
+		}
+	}
+	switch {
+	case math.IsInf(p, 1), p == 1:
+		return s
+	case p == 2:
+		return math.Sqrt(s)
+	default:
+		return math.Pow(s, 1/p)
+	}
+}
+
+// Mul computes a×b into dst, using the sparsity of a or b if it is a *CSR or a *CSC
+// (or the transpose of one). dst is allocated if it is empty. it falls back to dst.Mul for dense operands
+func Mul(dst *mat.Dense, a, b mat.Matrix) {
+	ar, ac := a.Dims()
+	br, bc := b.Dims()
+	if ac != br {
+		panic(mat.ErrShape)
+	}
+	if dst.IsZero() {
+		*dst = *mat.NewDense(ar, bc, nil)
+	} else if r, c := dst.Dims(); r != ar || c != bc {
+		panic(mat.ErrShape)
+	}
+	switch sa := a.(type) {
+	case *CSR:
+		// dst[i,:] = sum_p data[p] * b[indices[p],:]
+		bd := denseOf(b)
+		for i := 0; i < ar; i++ {
+			row := dst.RawRowView(i)
+			for j := range row {
+				row[j] = 0
+			}
+			for p := sa.indptr[i]; p < sa.indptr[i+1]; p++ {
+				v, brow := sa.data[p], bd.RawRowView(sa.indices[p])
+				for j := range row {
+					row[j] += v * brow[j]
+				}
+			}
+		}
+		return
+	case *CSC:
+		// dst[indices[p],:] += data[p] * b[k,:] for column k of a
+		bd := denseOf(b)
+		dst.Zero()
+		for k := 0; k < ac; k++ {
+			brow := bd.RawRowView(k)
+			for p := sa.indptr[k]; p < sa.indptr[k+1]; p++ {
+				v, row := sa.data[p], dst.RawRowView(sa.indices[p])
+				for j := range row {
+					row[j] += v * brow[j]
+				}
+			}
+		}
+		return
+	}
+	if IsSparse(b) {
+		// a×b = (bᵀ×aᵀ)ᵀ
+		var t mat.Dense
+		Mul(&t, b.T(), a.T())
+		dst.Copy(t.T())
+		return
+	}
+	dst.Mul(a, b)
+}
+
+// denseOf returns m if it is a *mat.Dense, else a dense copy of m
+func denseOf(m mat.Matrix) *mat.Dense {
+	if d, ok := m.(*mat.Dense); ok {
+		return d
+	}
+	if s, ok := m.(*CSR); ok {
+		return s.ToDense()
+	}
+	if s, ok := m.(*CSC); ok {
+		return s.ToDense()
+	}
+	return mat.DenseCopyOf(m)
+}
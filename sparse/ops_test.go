@@ -0,0 +1,70 @@
+package sparse
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+func ExampleMul() {
+	A := CSRCopyOf(mat.NewDense(2, 3, []float64{1, 0, 2, 0, 3, 0}))
+	B := mat.NewDense(3, 2, []float64{1, 2, 3, 4, 5, 6})
+	var C mat.Dense
+	Mul(&C, A, B)
+	fmt.Printf("%g\n", mat.Formatted(&C))
+	// Output:
+	// ⎡11  14⎤
+	// ⎣ 9  12⎦
+}
+
+func randomSparse(r, c int, density float64, rnd *rand.Rand) *mat.Dense {
+	X := mat.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			if rnd.Float64() < density {
+				X.Set(i, j, rnd.NormFloat64())
+			}
+		}
+	}
+	return X
+}
+
+func TestMul(t *testing.T) {
+	rnd := rand.New(rand.NewSource(7))
+	A, B := randomSparse(5, 4, .4, rnd), randomSparse(4, 3, .4, rnd)
+	var expected mat.Dense
+	expected.Mul(A, B)
+	for _, pair := range [][2]mat.Matrix{
+		{CSRCopyOf(A), B}, {CSCCopyOf(A), B}, {A, CSRCopyOf(B)}, {A, CSCCopyOf(B)},
+		{CSRCopyOf(A.T()).T(), B}, {A, B},
+	} {
+		var got mat.Dense
+		Mul(&got, pair[0], pair[1])
+		if !mat.EqualApprox(&expected, &got, 1e-12) {
+			t.Errorf("%T×%T: expected\n%g\ngot\n%g", pair[0], pair[1], mat.Formatted(&expected), mat.Formatted(&got))
+		}
+	}
+}
+
+func TestVector(t *testing.T) {
+	rnd := rand.New(rand.NewSource(7))
+	X := randomSparse(2, 6, .5, rnd)
+	csr := CSRCopyOf(X)
+	a, b := csr.Row(0), csr.Row(1)
+	if d := Dot(a, b); math.Abs(d-floats.Dot(X.RawRowView(0), X.RawRowView(1))) > 1e-12 {
+		t.Errorf("Dot: got %g", d)
+	}
+	if d := DotDense(a, X.RawRowView(1)); math.Abs(d-floats.Dot(X.RawRowView(0), X.RawRowView(1))) > 1e-12 {
+		t.Errorf("DotDense: got %g", d)
+	}
+	for _, p := range []float64{1, 2, 3, math.Inf(1)} {
+		expected := floats.Distance(X.RawRowView(0), X.RawRowView(1), p)
+		if d := MinkowskiDistance(a, b, p); math.Abs(d-expected) > 1e-12 {
+			t.Errorf("MinkowskiDistance p=%g: expected %g got %g", p, expected, d)
+		}
+	}
+}
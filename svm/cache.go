@@ -2,12 +2,10 @@ package svm
 
 import (
 	"unsafe"
-
-	"gonum.org/v1/gonum/mat"
 )
 
-func cachedKernel(X *mat.Dense, CacheSize uint, KernelFunction func(X1, X2 []float64) float64) func(i, j int) float64 {
-	m, _ := X.Dims()
+// cachedKernel returns the kernel K between m samples, caching at most CacheSize MB of values
+func cachedKernel(m int, CacheSize uint, K func(i, j int) float64) func(i, j int) float64 {
 	type DiagEntry struct {
 		bool    //presence in cache
 		float64 // cached value
@@ -25,7 +23,7 @@ func cachedKernel(X *mat.Dense, CacheSize uint, KernelFunction func(X1, X2 []flo
 		if i == j {
 			e := &KcacheDiag[i]
 			if !e.bool {
-				e.float64 = KernelFunction(X.RawRowView(i), X.RawRowView(i))
+				e.float64 = K(i, i)
 				e.bool = true
 			}
 			return e.float64
@@ -51,7 +49,7 @@ func cachedKernel(X *mat.Dense, CacheSize uint, KernelFunction func(X1, X2 []flo
 			delete(KcacheNDiag, old)
 		}
 		Ktime++
-		e := &KcacheEntry{Ktime, KernelFunction(X.RawRowView(i), X.RawRowView(j))}
+		e := &KcacheEntry{Ktime, K(i, j)}
 		KcacheNDiag[[2]int{i, j}] = e
 		return e.float64
 	}
@@ -0,0 +1,25 @@
+package svm
+
+import (
+	"testing"
+
+	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/base/estimatorchecks"
+)
+
+func TestEstimatorChecks(t *testing.T) {
+	X, Y := estimatorchecks.ClassificationData()
+	// SVC labels are -1 and 1
+	Y.Apply(func(_, _ int, y float64) float64 { return 2*y - 1 }, Y)
+	svc := NewSVC()
+	svc.RandomState, svc.MaxIter = base.NewRandomState(7), 20
+	if err := estimatorchecks.CheckEstimator(svc, X, Y); err != nil {
+		t.Error(err)
+	}
+	X, Y = estimatorchecks.RegressionData()
+	svr := NewSVR()
+	svr.RandomState, svr.MaxIter, svr.Tol = base.NewRandomState(7), 5, .3
+	if err := estimatorchecks.CheckEstimator(svr, X, Y); err != nil {
+		t.Error(err)
+	}
+}
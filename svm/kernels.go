@@ -3,6 +3,7 @@ package svm
 import (
 	"math"
 
+	"github.com/pa-m/sklearn/sparse"
 	"gonum.org/v1/gonum/floats"
 )
 
@@ -11,6 +12,12 @@ type Kernel interface {
 	Func(a, b []float64) float64
 }
 
+// SparseKernel is a Kernel usable with a sparse X
+type SparseKernel interface {
+	Kernel
+	SparseFunc(a, b sparse.Vector) float64
+}
+
 // LinearKernel is dot product
 type LinearKernel struct{}
 
@@ -23,6 +30,11 @@ func (LinearKernel) Func(a, b []float64) (sumprod float64) {
 	return
 }
 
+// SparseFunc for LinearKernel
+func (LinearKernel) SparseFunc(a, b sparse.Vector) float64 {
+	return sparse.Dot(a, b)
+}
+
 // PolynomialKernel ...
 type PolynomialKernel struct{ gamma, coef0, degree float64 }
 
@@ -31,6 +43,11 @@ func (kdata PolynomialKernel) Func(a, b []float64) (sumprod float64) {
 	return math.Pow(kdata.gamma*floats.Dot(a, b)+kdata.coef0, kdata.degree)
 }
 
+// SparseFunc for PolynomialKernel
+func (kdata PolynomialKernel) SparseFunc(a, b sparse.Vector) float64 {
+	return math.Pow(kdata.gamma*sparse.Dot(a, b)+kdata.coef0, kdata.degree)
+}
+
 // RBFKernel ...
 type RBFKernel struct{ gamma float64 }
 
@@ -44,6 +61,12 @@ func (kdata RBFKernel) Func(a, b []float64) float64 {
 	return math.Exp(-kdata.gamma * L2)
 }
 
+// SparseFunc for RBFKernel
+func (kdata RBFKernel) SparseFunc(a, b sparse.Vector) float64 {
+	L2 := math.Max(0, sparse.Norm2(a)+sparse.Norm2(b)-2*sparse.Dot(a, b))
+	return math.Exp(-kdata.gamma * L2)
+}
+
 // SigmoidKernel ...
 type SigmoidKernel struct{ gamma, coef0 float64 }
 
@@ -51,3 +74,8 @@ type SigmoidKernel struct{ gamma, coef0 float64 }
 func (kdata SigmoidKernel) Func(a, b []float64) (sumprod float64) {
 	return math.Tanh(kdata.gamma*floats.Dot(a, b) + kdata.coef0)
 }
+
+// SparseFunc for SigmoidKernel
+func (kdata SigmoidKernel) SparseFunc(a, b sparse.Vector) float64 {
+	return math.Tanh(kdata.gamma*sparse.Dot(a, b) + kdata.coef0)
+}
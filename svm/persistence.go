@@ -0,0 +1,37 @@
+package svm
+
+import (
+	"io"
+
+	"github.com/pa-m/sklearn/base"
+)
+
+func init() {
+	base.Register(&SVC{})
+	base.Register(&SVR{})
+}
+
+// Restore rebuilds the kernel function of fitted models after Load
+func (m *BaseLibSVM) Restore() error {
+	if len(m.Model) == 0 {
+		return nil
+	}
+	return base.Recover(func() {
+		K, SparseK := m.kernelFunction(), m.sparseKernelFunction()
+		for _, model := range m.Model {
+			model.KernelFunction, model.SparseKernelFunction = K, SparseK
+		}
+	})
+}
+
+// Save writes the SVC to w. Kernel must be a string or a registered Kernel type
+func (m *SVC) Save(w io.Writer) error { return base.Save(w, m) }
+
+// Load reads a SVC written by Save
+func (m *SVC) Load(r io.Reader) error { return base.LoadInto(r, m) }
+
+// Save writes the SVR to w. Kernel must be a string or a registered Kernel type
+func (m *SVR) Save(w io.Writer) error { return base.Save(w, m) }
+
+// Load reads a SVR written by Save
+func (m *SVR) Load(r io.Reader) error { return base.LoadInto(r, m) }
@@ -0,0 +1,33 @@
+package svm
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/pa-m/sklearn/base"
+	"gonum.org/v1/gonum/mat"
+)
+
+func ExampleSVC_Save() {
+	X := mat.NewDense(8, 2, []float64{0.4, -0.7, -1.5, -1., -1.4, -0.9, -1.3, -1.2, 1., 1., 1.3, 0.8, 1.2, 0.5, 1.3, 2.1})
+	Y := mat.NewDense(8, 1, []float64{-1, -1, -1, -1, 1, 1, 1, 1})
+	clf := NewSVC()
+	clf.Kernel = "rbf"
+	clf.Gamma = 2.
+	clf.MaxIter = 20
+	clf.Fit(X, Y)
+	var buf bytes.Buffer
+	if err := clf.Save(&buf); err != nil {
+		fmt.Println(err)
+	}
+	loaded, err := base.Load(&buf)
+	if err != nil {
+		fmt.Println(err)
+	}
+	Ypred, Yloaded := mat.NewDense(8, 1, nil), mat.NewDense(8, 1, nil)
+	clf.Predict(X, Ypred)
+	loaded.(*SVC).Predict(X, Yloaded)
+	fmt.Printf("%T %v\n", loaded, mat.Equal(Ypred, Yloaded))
+	// Output:
+	// *svm.SVC true
+}
@@ -1,11 +1,13 @@
 package svm
 
 import (
+	"context"
 	"fmt"
 	"math"
-	"math/rand"
+	"time"
 
 	"github.com/pa-m/sklearn/base"
+	"github.com/pa-m/sklearn/sparse"
 	"gonum.org/v1/gonum/mat"
 )
 
@@ -18,10 +20,13 @@ import (
 // https://link.springer.com/content/pdf/10.1023%2FA%3A1012474916001.pdf
 
 // Model for SVM
+// SparseX holds the support vectors instead of X when the model was fitted on a sparse X
 type Model struct {
-	X              *mat.Dense
-	Y              []float64
-	KernelFunction func(X1, X2 []float64) float64
+	X                    *mat.Dense
+	SparseX              *sparse.CSR
+	Y                    []float64
+	KernelFunction       func(X1, X2 []float64) float64
+	SparseKernelFunction func(a, b sparse.Vector) float64
 
 	B       float64
 	Alphas  []float64
@@ -45,15 +50,13 @@ type Model struct {
 // %
 // %           LIBSVM   (http://www.csie.ntu.edu.tw/~cjlin/libsvm/)
 // %           SVMLight (http://svmlight.joachims.org/)
-func svmTrain(X *mat.Dense, Y []float64, C, Epsilon float64, KernelFunction func(X1, X2 []float64) float64, Tol float64, MaxPasses int, CacheSize uint, RandomState *int64) *Model {
-	m, n := X.Dims()
+func svmTrain(fc *fitContext, m int, K func(i, j int) float64, Y, C []float64, Epsilon, Tol float64, MaxPasses int, randomState *base.RandomState) (*Model, error) {
 	alphas := make([]float64, m, m)
 	b := 0.
 	E := make([]float64, m, m)
 	eta := 0.
 	passes := 0
 	L, H := 0., 0.
-	K := cachedKernel(X, CacheSize, KernelFunction)
 	f := func(i int) float64 {
 		y := b
 		for i1 := 0; i1 < m; i1++ {
@@ -61,24 +64,22 @@ func svmTrain(X *mat.Dense, Y []float64, C, Epsilon float64, KernelFunction func
 		}
 		return y
 	}
-	randIntn := rand.Intn
-	if RandomState != nil {
-		r := rand.New(rand.NewSource(*RandomState))
-		randIntn = r.Intn
-	}
-	for passes < MaxPasses {
+	for iter := 1; passes < MaxPasses; iter++ {
+		if err := fc.iteration(iter); err != nil {
+			return nil, err
+		}
 		numChangedAlphas := 0
 		// Step 1 Find a Lagrange multiplier α 1 {that violates the Karush–Kuhn–Tucker (KKT) conditions for the optimization problem.
 		var KKTviolated bool
 		for i := 0; i < m; i++ {
 			Kii := K(i, i)
 			E[i] = f(i) - Y[i]
-			if (Y[i]*E[i] < -Epsilon && alphas[i] < C) || (Y[i]*E[i] > Epsilon && alphas[i] > 0) {
+			if (Y[i]*E[i] < -Epsilon && alphas[i] < C[i]) || (Y[i]*E[i] > Epsilon && alphas[i] > 0) {
 				KKTviolated = true
 				// Step 2 Pick a second multiplier α 2  and optimize the pair ( α 1 , α 2 )
 				// % In practice, there are many heuristics one can use to select
 				// % the i and j. In this simplified code, we select them randomly.
-				j := randIntn(m - 1)
+				j := randomState.Intn(m - 1)
 				if j >= i {
 					j++
 				}
@@ -87,9 +88,9 @@ func svmTrain(X *mat.Dense, Y []float64, C, Epsilon float64, KernelFunction func
 				alphaiold, alphajold := alphas[i], alphas[j]
 				//% Compute L and H by (10) or (11).
 				if Y[i] == Y[j] {
-					L, H = math.Max(0, alphas[j]+alphas[i]-C), math.Min(C, alphas[j]+alphas[i])
+					L, H = math.Max(0, alphas[j]+alphas[i]-C[i]), math.Min(C[j], alphas[j]+alphas[i])
 				} else {
-					L, H = math.Max(0, alphas[j]-alphas[i]), math.Min(C, C+alphas[j]-alphas[i])
+					L, H = math.Max(0, alphas[j]-alphas[i]), math.Min(C[j], C[i]+alphas[j]-alphas[i])
 				}
 				if L == H {
 					continue
@@ -116,9 +117,9 @@ func svmTrain(X *mat.Dense, Y []float64, C, Epsilon float64, KernelFunction func
 				b1 := b - E[i] - Y[i]*(alphas[i]-alphaiold)*Kii - Y[j]*(alphas[j]-alphajold)*Kij
 				b2 := b - E[j] - Y[i]*(alphas[i]-alphaiold)*Kij - Y[j]*(alphas[j]-alphajold)*Kjj
 				// % Compute b by (19).
-				if 0 < alphas[i] && alphas[i] < C {
+				if 0 < alphas[i] && alphas[i] < C[i] {
 					b = b1
-				} else if 0 < alphas[j] && alphas[j] < C {
+				} else if 0 < alphas[j] && alphas[j] < C[j] {
 					b = b2
 				} else {
 					b = (b1 + b2) / 2
@@ -148,19 +149,16 @@ func svmTrain(X *mat.Dense, Y []float64, C, Epsilon float64, KernelFunction func
 		}
 	}
 	model := &Model{
-		X:              mat.NewDense(len(idx), n, nil),
-		Y:              make([]float64, len(idx)),
-		KernelFunction: KernelFunction,
-		B:              b,
-		Alphas:         make([]float64, len(idx)),
-		Support:        idx,
+		Y:       make([]float64, len(idx)),
+		B:       b,
+		Alphas:  make([]float64, len(idx)),
+		Support: idx,
 	}
 	for ii, i := range idx {
-		model.X.SetRow(ii, X.RawRowView(i))
 		model.Y[ii] = Y[i]
 		model.Alphas[ii] = alphas[i]
 	}
-	return model
+	return model, nil
 }
 
 // %svmPredict returns a vector of predictions using a trained SVM model
@@ -169,14 +167,15 @@ func svmTrain(X *mat.Dense, Y []float64, C, Epsilon float64, KernelFunction func
 // %   trained SVM model (svmTrain). X is a mxn matrix where there each
 // %   example is a row. model is a svm model returned from svmTrain.
 // %   predictions pred is a m x 1 column of predictions of {0, 1} values.
-func svmPredict(model *Model, X, Y *mat.Dense, output int, binary bool) {
+func svmPredict(model *Model, X mat.Matrix, Y *mat.Dense, output int, binary bool) {
 	NSamples, _ := X.Dims()
+	K := model.kernel(X)
 
 	Ymat := Y.RawMatrix()
 	for i, yoff := 0, output; i < NSamples; i, yoff = i+1, yoff+Ymat.Stride {
 		prediction := 0.
 		for j := range model.Alphas {
-			prediction += model.Alphas[j] * model.Y[j] * model.KernelFunction(X.RawRowView(i), model.X.RawRowView(j))
+			prediction += model.Alphas[j] * model.Y[j] * K(i, j)
 		}
 		prediction += model.B
 		if binary {
@@ -193,6 +192,7 @@ func svmPredict(model *Model, X, Y *mat.Dense, output int, binary bool) {
 }
 
 // BaseLibSVM is a base for SVC and SVR
+// SupportVectors is nil after FitSparse, the support vectors are in the SparseX of each Model
 type BaseLibSVM struct {
 	C, Epsilon  float64
 	Kernel      interface{} // string or func(a, b []float64) float64
@@ -202,7 +202,7 @@ type BaseLibSVM struct {
 	Tol         float64
 	Shrinking   bool
 	CacheSize   uint
-	RandomState *int64
+	RandomState *base.RandomState
 
 	MaxIter        int
 	Model          []*Model
@@ -211,10 +211,12 @@ type BaseLibSVM struct {
 }
 
 // SVC struct
+// ClassWeight multiplies C for the samples of each class. it is nil, "balanced" or a map[float64]float64
+// from the -1 and 1 labels of each output to weights, see base.ComputeClassWeight
 type SVC struct {
 	BaseLibSVM
 	Probability bool
-	ClassWeight []float64
+	ClassWeight interface{}
 }
 
 // NewSVC ...
@@ -234,61 +236,277 @@ func (m *SVC) Clone() base.Transformer {
 	return &clone
 }
 
+// builtinKernel returns the Kernel named name with current Gamma, Coef0 and Degree
+func (m *BaseLibSVM) builtinKernel(name string) Kernel {
+	switch name {
+	case "linear":
+		return LinearKernel{}
+	case "poly", "polynomial":
+		return PolynomialKernel{gamma: m.Gamma, coef0: m.Coef0, degree: m.Degree}
+	case "sigmoid":
+		return SigmoidKernel{gamma: m.Gamma, coef0: m.Coef0}
+	default: //rbf
+		return RBFKernel{gamma: m.Gamma}
+	}
+}
+
+// kernelFunction returns the func implementing Kernel with current Gamma, Coef0 and Degree
+func (m *BaseLibSVM) kernelFunction() func(a, b []float64) float64 {
+	switch v := m.Kernel.(type) {
+	case func(a, b []float64) float64:
+		return v
+	case string:
+		return m.builtinKernel(v).Func
+	case Kernel:
+		return v.Func
+	default:
+		panic(fmt.Errorf("unknown kernel %#v", v))
+	}
+}
+
+// sparseKernelFunction returns the func implementing Kernel on sparse vectors, or nil if Kernel is not a SparseKernel
+func (m *BaseLibSVM) sparseKernelFunction() func(a, b sparse.Vector) float64 {
+	k := m.Kernel
+	if name, ok := k.(string); ok {
+		k = m.builtinKernel(name)
+	}
+	if sk, ok := k.(SparseKernel); ok {
+		return sk.SparseFunc
+	}
+	return nil
+}
+
+// kernel returns the kernel between the samples of X and the support vectors of model
+func (model *Model) kernel(X mat.Matrix) func(i, j int) float64 {
+	if model.SparseX != nil {
+		Xs := sparse.CSROf(X)
+		return func(i, j int) float64 { return model.SparseKernelFunction(Xs.Row(i), model.SparseX.Row(j)) }
+	}
+	Xd, ok := X.(*mat.Dense)
+	if !ok {
+		Xd = mat.DenseCopyOf(X)
+	}
+	return func(i, j int) float64 { return model.KernelFunction(Xd.RawRowView(i), model.X.RawRowView(j)) }
+}
+
 // Fit for SVC
 func (m *SVC) Fit(X, Y *mat.Dense) base.Transformer {
-	m.BaseLibSVM.fit(X, Y, svmTrain)
+	if err := m.BaseLibSVM.fit(nil, X, Y, nil, m.ClassWeight, svmTrain); err != nil {
+		panic(err)
+	}
 	return m
 }
 
-func (m *BaseLibSVM) fit(X, Y *mat.Dense, svmTrain func(X *mat.Dense, Y []float64, C, Epsilon float64, KernelFunction func(X1, X2 []float64) float64, Tol float64, MaxPasses int, CacheSize uint, RandomState *int64) *Model) {
+// FitContext is FitE stopping between SMO iterations when ctx is done.
+// iterations are reported to the ProgressCallback of ctx, without loss
+func (m *SVC) FitContext(ctx context.Context, X, Y *mat.Dense) error {
+	if err := m.BaseLibSVM.checkFit(m, "SVC.Fit", X, Y); err != nil {
+		return err
+	}
+	return m.BaseLibSVM.fitContext(ctx, m, X, Y, m.ClassWeight, svmTrain)
+}
+
+// fitContext carries the context of a fit to svmTrain and svrTrain. a nil *fitContext never stops
+type fitContext struct {
+	ctx       context.Context
+	estimator interface{}
+	start     time.Time
+}
+
+// iteration reports iteration iter and returns the context error, if any
+func (fc *fitContext) iteration(iter int) error {
+	if fc == nil {
+		return nil
+	}
+	if err := fc.ctx.Err(); err != nil {
+		return err
+	}
+	base.ReportProgress(fc.ctx, base.Progress{Estimator: fc.estimator, Epoch: iter, Loss: math.NaN(), ValidationScore: math.NaN(), Elapsed: time.Since(fc.start)})
+	return nil
+}
+
+func (m *BaseLibSVM) fitContext(ctx context.Context, estimator interface{}, X mat.Matrix, Y *mat.Dense, classWeight interface{}, train trainFunc) error {
+	var err error
+	fc := &fitContext{ctx: ctx, estimator: estimator, start: time.Now()}
+	if rerr := base.Recover(func() { err = m.fit(fc, X, Y, nil, classWeight, train) }); rerr != nil {
+		return rerr
+	}
+	return err
+}
+
+// trainFunc is the signature of svmTrain and svrTrain. K(i,j) is the kernel between the training samples i and j,
+// C[i] is the bound of the multiplier of sample i
+type trainFunc func(fc *fitContext, m int, K func(i, j int) float64, Y, C []float64, Epsilon, Tol float64, MaxPasses int, randomState *base.RandomState) (*Model, error)
+
+// fit trains a model per output of Y. X is a *mat.Dense, or a *sparse.CSR or *sparse.CSC for a SparseKernel.
+// the bound C of each sample is multiplied by its weight if sampleWeight is not nil, and by the weight of its
+// class in each output if classWeight is not nil
+func (m *BaseLibSVM) fit(fc *fitContext, X mat.Matrix, Y *mat.Dense, sampleWeight []float64, classWeight interface{}, train trainFunc) error {
 	NSamples, NFeatures := X.Dims()
 	_, Noutputs := Y.Dims()
 	if m.Gamma <= 0. {
 		m.Gamma = 1. / float64(NFeatures)
 	}
 	m.Model = make([]*Model, Noutputs)
-	var K func(a, b []float64) float64
-	switch v := m.Kernel.(type) {
-	case func(a, b []float64) float64:
-		K = v
-	case string:
-		switch v {
-		case "linear":
-			K = (LinearKernel{}).Func
-		case "poly", "polynomial":
-			K = (PolynomialKernel{gamma: m.Gamma, coef0: m.Coef0, degree: m.Degree}).Func
-		case "sigmoid":
-			K = (SigmoidKernel{gamma: m.Gamma, coef0: m.Coef0}).Func
-		default: //rbf
-			K = (RBFKernel{gamma: m.Gamma}).Func
+	var (
+		Xd       *mat.Dense
+		Xs       *sparse.CSR
+		K        = m.kernelFunction()
+		SparseK  = m.sparseKernelFunction()
+		kernelij func(i, j int) float64
+	)
+	if sparse.IsSparse(X) {
+		if SparseK == nil {
+			return &base.ParamError{Estimator: m, Param: "Kernel", Value: m.Kernel, Msg: "does not support sparse X"}
 		}
-	case Kernel:
-		K = v.Func
-	default:
-		panic(fmt.Errorf("unknown kernel %#v", v))
+		Xs = sparse.CSROf(X)
+		kernelij = func(i, j int) float64 { return SparseK(Xs.Row(i), Xs.Row(j)) }
+	} else {
+		Xd = X.(*mat.Dense)
+		kernelij = func(i, j int) float64 { return K(Xd.RawRowView(i), Xd.RawRowView(j)) }
 	}
 	if m.MaxIter <= 0 {
 		m.MaxIter = math.MaxInt32
 	}
+	C := make([][]float64, Noutputs)
+	for output := range C {
+		y := mat.NewDense(NSamples, 1, mat.Col(nil, output, Y))
+		weights, err := base.ApplyClassWeight(classWeight, y, sampleWeight)
+		if err != nil {
+			return err
+		}
+		C[output] = make([]float64, NSamples)
+		for i := range C[output] {
+			C[output][i] = m.C
+			if weights != nil {
+				C[output][i] *= weights[i]
+			}
+		}
+	}
 	m.Support = make([][]int, Noutputs)
 	m.SupportVectors = make([][][]float64, Noutputs)
+	if Xs != nil {
+		m.SupportVectors = nil
+	}
+	errs := make([]error, Noutputs)
+	randomStates := base.CheckRandomState(m.RandomState).Spawn(Noutputs)
 	base.Parallelize(-1, Noutputs, func(th, start, end int) {
 		y := make([]float64, NSamples)
 		for output := start; output < end; output++ {
 			mat.Col(y, output, Y)
-			m.Model[output] = svmTrain(X, y, m.C, m.Epsilon, K, m.Tol, m.MaxIter, m.CacheSize, m.RandomState)
+			m.Model[output], errs[output] = train(fc, NSamples, cachedKernel(NSamples, m.CacheSize, kernelij), y, C[output], m.Epsilon, m.Tol, m.MaxIter, randomStates[output])
+			if errs[output] != nil {
+				continue
+			}
 			model := m.Model[output]
+			model.KernelFunction, model.SparseKernelFunction = K, SparseK
 			m.Support[output] = model.Support
+			if Xs != nil {
+				model.SparseX = Xs.TakeRows(model.Support)
+				continue
+			}
+			model.X = mat.NewDense(len(model.Support), NFeatures, nil)
 			m.SupportVectors[output] = make([][]float64, len(model.Support))
-			for i := range model.Support {
-				m.SupportVectors[output][i] = model.X.RawRowView(i)
+			for ii, i := range model.Support {
+				model.X.SetRow(ii, Xd.RawRowView(i))
+				m.SupportVectors[output][ii] = model.X.RawRowView(ii)
 			}
 		}
 	})
+	for _, err := range errs {
+		if err != nil {
+			m.Model = nil
+			return err
+		}
+	}
+	return nil
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *SVC) FitE(X, Y *mat.Dense) error {
+	if err := m.BaseLibSVM.checkFit(m, "SVC.Fit", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { m.Fit(X, Y) })
+}
+
+// FitWeighted is FitE with the bound C of each sample multiplied by its weight
+func (m *SVC) FitWeighted(X, Y *mat.Dense, sampleWeight []float64) error {
+	if err := m.BaseLibSVM.checkFit(m, "SVC.FitWeighted", X, Y); err != nil {
+		return err
+	}
+	if err := base.CheckSampleWeight("SVC.FitWeighted", X, sampleWeight); err != nil {
+		return err
+	}
+	var err error
+	if rerr := base.Recover(func() { err = m.BaseLibSVM.fit(nil, X, Y, sampleWeight, m.ClassWeight, svmTrain) }); rerr != nil {
+		return rerr
+	}
+	return err
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *SVC) PredictE(X, Y *mat.Dense) error {
+	if err := m.BaseLibSVM.checkPredict(m, "SVC.Predict", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { m.Predict(X, Y) })
+}
+
+func (m *BaseLibSVM) checkFit(estimator interface{}, op string, X, Y *mat.Dense) error {
+	if err := base.CheckFitXY(op, X, Y); err != nil {
+		return err
+	}
+	if m.C <= 0 {
+		return &base.ParamError{Estimator: estimator, Param: "C", Value: m.C, Msg: "must be > 0"}
+	}
+	if m.Tol <= 0 {
+		return &base.ParamError{Estimator: estimator, Param: "Tol", Value: m.Tol, Msg: "must be > 0"}
+	}
+	switch v := m.Kernel.(type) {
+	case func(a, b []float64) float64, Kernel:
+	case string:
+		switch v {
+		case "linear", "poly", "polynomial", "sigmoid", "rbf", "":
+		default:
+			return &base.ParamError{Estimator: estimator, Param: "Kernel", Value: v}
+		}
+	default:
+		return &base.ParamError{Estimator: estimator, Param: "Kernel", Value: v}
+	}
+	return nil
+}
+
+func (m *BaseLibSVM) checkPredict(estimator interface{}, op string, X, Y *mat.Dense) error {
+	if len(m.Model) == 0 {
+		return &base.NotFittedError{Estimator: estimator}
+	}
+	if Y == nil {
+		return &base.ShapeError{Op: op, Msg: "Y is nil"}
+	}
+	if err := base.CheckXY(op, X, Y); err != nil {
+		return err
+	}
+	var nFeatures int
+	if m.Model[0].SparseX != nil {
+		_, nFeatures = m.Model[0].SparseX.Dims()
+	} else {
+		_, nFeatures = m.Model[0].X.Dims()
+	}
+	if nFeatures == 0 {
+		// no support vector
+		return nil
+	}
+	return base.CheckNFeatures(op, X, nFeatures)
 }
 
 // Predict for SVC
 func (m *SVC) Predict(X, Y *mat.Dense) base.Transformer {
+	m.predict(X, Y)
+	return m
+}
+
+func (m *SVC) predict(X mat.Matrix, Y *mat.Dense) {
 	_, NOutputs := Y.Dims()
 	if NOutputs == 0 {
 		NSamples, _ := X.Dims()
@@ -304,6 +522,19 @@ func (m *SVC) Predict(X, Y *mat.Dense) base.Transformer {
 
 		}
 	})
+}
+
+// FitSparse is Fit for a sparse X, a *sparse.CSR or a *sparse.CSC. Kernel must be a string or a SparseKernel
+func (m *SVC) FitSparse(X mat.Matrix, Y *mat.Dense) base.Transformer {
+	if err := m.BaseLibSVM.fit(nil, X, Y, nil, m.ClassWeight, svmTrain); err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// PredictSparse is Predict for a sparse X
+func (m *SVC) PredictSparse(X mat.Matrix, Y *mat.Dense) base.Transformer {
+	m.predict(X, Y)
 	return m
 }
 
@@ -1,15 +1,22 @@
 package svm
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"image/color"
+	"math/rand"
 	"os"
 	"os/exec"
+	"testing"
 	"time"
 
+	"github.com/pa-m/sklearn/base"
 	"github.com/pa-m/sklearn/metrics"
 	"github.com/pa-m/sklearn/preprocessing"
+	"github.com/pa-m/sklearn/sparse"
+	"gonum.org/v1/gonum/floats"
 	"gonum.org/v1/gonum/mat"
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
@@ -172,3 +179,190 @@ func ExampleSVC() {
 	// poly kernel, accuracy:1.000
 	// rbf kernel, accuracy:1.000
 }
+
+func TestSVCFitContext(t *testing.T) {
+	X := mat.NewDense(8, 2, []float64{0.4, -0.7, -1.5, -1., -1.4, -0.9, -1.3, -1.2, 1., 1., 1.3, 0.8, 1.2, 0.5, 1.3, 2.1})
+	Y := mat.NewDense(8, 1, []float64{-1, -1, -1, -1, 1, 1, 1, 1})
+	var iterations int
+	ctx := base.WithProgress(context.Background(), base.ProgressFunc(func(p base.Progress) { iterations = p.Epoch }))
+	clf := NewSVC()
+	clf.MaxIter = 5
+	if err := clf.FitContext(ctx, X, Y); err != nil || iterations == 0 {
+		t.Fatalf("unexpected FitContext result %v after %d iterations", err, iterations)
+	}
+	Ypred := mat.NewDense(8, 1, nil)
+	clf.Predict(X, Ypred)
+	// predictions are 0 or 1
+	Y01 := mat.NewDense(8, 1, []float64{0, 0, 0, 0, 1, 1, 1, 1})
+	if acc := metrics.AccuracyScore(Y01, Ypred, true, nil); acc != 1 {
+		t.Errorf("unexpected accuracy %g", acc)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	for _, m := range []base.FitterContext{NewSVC(), NewSVR()} {
+		if err := m.FitContext(cancelled, X, Y); !errors.Is(err, context.Canceled) {
+			t.Errorf("%T: expected context.Canceled, got %v", m, err)
+		}
+	}
+}
+
+func TestSVCRandomState(t *testing.T) {
+	X := mat.NewDense(9, 2, []float64{0.4, -0.7, -1.5, -1., -1.4, -0.9, -1.3, -1.2, 1., 1., 1.3, 0.8, 1.2, 0.5, 1.3, 2.1, 0, -2.7})
+	Y := mat.NewDense(9, 3, nil)
+	for sample, class := range []int{0, 0, 0, 0, 1, 1, 1, 1, 2} {
+		for output := 0; output < 3; output++ {
+			if output == class {
+				Y.Set(sample, output, 1)
+			} else {
+				Y.Set(sample, output, -1)
+			}
+		}
+	}
+	fit := func() *SVC {
+		clf := NewSVC()
+		clf.MaxIter = 5
+		clf.RandomState = base.NewRandomState(7)
+		clf.Fit(X, Y)
+		return clf
+	}
+	a, b := fit(), fit()
+	for output := range a.Model {
+		if a.Model[output].B != b.Model[output].B || !floats.Equal(a.Model[output].Alphas, b.Model[output].Alphas) {
+			t.Errorf("output %d: fits with the same RandomState differ", output)
+		}
+	}
+}
+
+func TestSVMSparse(t *testing.T) {
+	X := mat.NewDense(9, 2, []float64{0.4, -0.7, -1.5, -1., -1.4, -0.9, -1.3, -1.2, 1., 1., 1.3, 0.8, 1.2, 0.5, 1.3, 2.1, 0, -2.7})
+	Y := mat.NewDense(9, 1, []float64{-1, -1, -1, -1, 1, 1, 1, 1, -1})
+	Xs := sparse.CSRCopyOf(X)
+	for _, kernel := range []string{"linear", "poly", "rbf"} {
+		dense, sp := NewSVC(), NewSVC()
+		dense.Kernel, sp.Kernel = kernel, kernel
+		dense.RandomState, sp.RandomState = base.NewRandomState(7), base.NewRandomState(7)
+		dense.MaxIter, sp.MaxIter = 50, 50
+		dense.Fit(X, Y)
+		sp.FitSparse(Xs, Y)
+		if !floats.EqualApprox(dense.Model[0].Alphas, sp.Model[0].Alphas, 1e-6) {
+			t.Errorf("SVC %s: expected alphas %g got %g", kernel, dense.Model[0].Alphas, sp.Model[0].Alphas)
+		}
+		Ydense, Ysparse := &mat.Dense{}, &mat.Dense{}
+		dense.Predict(X, Ydense)
+		sp.PredictSparse(Xs, Ysparse)
+		if !mat.Equal(Ydense, Ysparse) {
+			t.Errorf("SVC %s: expected %g got %g", kernel, mat.Formatted(Ydense.T()), mat.Formatted(Ysparse.T()))
+		}
+
+		regr, sregr := NewSVR(), NewSVR()
+		regr.Kernel, sregr.Kernel = kernel, kernel
+		regr.RandomState, sregr.RandomState = base.NewRandomState(7), base.NewRandomState(7)
+		regr.MaxIter, sregr.MaxIter = 50, 50
+		regr.Fit(X, Y)
+		sregr.FitSparse(Xs, Y)
+		regr.Predict(X, Ydense)
+		sregr.PredictSparse(Xs, Ysparse)
+		if !mat.EqualApprox(Ydense, Ysparse, 1e-6) {
+			t.Errorf("SVR %s: expected %g got %g", kernel, mat.Formatted(Ydense.T()), mat.Formatted(Ysparse.T()))
+		}
+	}
+	clf := NewSVC()
+	clf.Kernel = func(a, b []float64) float64 { return floats.Dot(a, b) }
+	var pe *base.ParamError
+	if err := base.Recover(func() { clf.FitSparse(Xs, Y) }); !errors.As(err, &pe) {
+		t.Errorf("expected a *base.ParamError for a func kernel, got %v", err)
+	}
+}
+
+func TestSVCFitWeighted(t *testing.T) {
+	X := mat.NewDense(9, 2, []float64{0.4, -0.7, -1.5, -1., -1.4, -0.9, -1.3, -1.2, 1., 1., 1.3, 0.8, 1.2, 0.5, 1.3, 2.1, -1.4, -1.1})
+	// the last sample is mislabeled
+	Y := mat.NewDense(9, 1, []float64{-1, -1, -1, -1, 1, 1, 1, 1, 1})
+	fit := func(sampleWeight []float64) *SVC {
+		clf := NewSVC()
+		clf.Kernel = "linear"
+		clf.MaxIter = 50
+		clf.RandomState = base.NewRandomState(7)
+		if err := clf.FitWeighted(X, Y, sampleWeight); err != nil {
+			t.Fatal(err)
+		}
+		return clf
+	}
+	unweighted, ones := fit(nil), fit([]float64{1, 1, 1, 1, 1, 1, 1, 1, 1})
+	if !floats.Equal(unweighted.Model[0].Alphas, ones.Model[0].Alphas) {
+		t.Errorf("expected alphas %g with unit weights, got %g", unweighted.Model[0].Alphas, ones.Model[0].Alphas)
+	}
+	clf := fit([]float64{1, 1, 1, 1, 1, 1, 1, 1, 0})
+	for _, i := range clf.Support[0] {
+		if i == 8 {
+			t.Error("a sample with zero weight must not be a support vector")
+		}
+	}
+	Ypred := &mat.Dense{}
+	clf.Predict(X, Ypred)
+	if acc := metrics.AccuracyScore(mat.NewDense(8, 1, []float64{0, 0, 0, 0, 1, 1, 1, 1}), Ypred.Slice(0, 8, 0, 1), true, nil); acc != 1 {
+		t.Errorf("unexpected accuracy %g", acc)
+	}
+	var shapeErr *base.ShapeError
+	if err := NewSVR().FitWeighted(X, Y, []float64{1}); !errors.As(err, &shapeErr) {
+		t.Errorf("expected a *base.ShapeError, got %v", err)
+	}
+}
+
+func TestSVCClassWeight(t *testing.T) {
+	rnd := rand.New(rand.NewSource(7))
+	// 4 samples of class 1 overlapping 36 samples of class -1
+	X, Y := mat.NewDense(40, 2, nil), mat.NewDense(40, 1, nil)
+	for i := 0; i < 40; i++ {
+		y := -1.
+		if i%10 == 0 {
+			y = 1
+		}
+		X.Set(i, 0, rnd.NormFloat64()+y/2)
+		X.Set(i, 1, rnd.NormFloat64()+y/2)
+		Y.Set(i, 0, y)
+	}
+	fit := func(classWeight interface{}, sampleWeight []float64) *SVC {
+		clf := NewSVC()
+		clf.Kernel = "linear"
+		clf.MaxIter = 20
+		clf.RandomState = base.NewRandomState(7)
+		clf.ClassWeight = classWeight
+		if err := clf.FitWeighted(X, Y, sampleWeight); err != nil {
+			t.Fatal(err)
+		}
+		return clf
+	}
+	positives := func(clf *SVC) (n int) {
+		Ypred := &mat.Dense{}
+		clf.Predict(X, Ypred)
+		for i := 0; i < 40; i += 10 {
+			if Ypred.At(i, 0) > 0 {
+				n++
+			}
+		}
+		return
+	}
+	unweighted, balanced := fit(nil, nil), fit("balanced", nil)
+	if positives(balanced) <= positives(unweighted) {
+		t.Errorf("expected more of the 4 positives predicted with balanced weights, got %d, unweighted %d", positives(balanced), positives(unweighted))
+	}
+	sampleWeight := make([]float64, 40)
+	for i := range sampleWeight {
+		sampleWeight[i] = 1
+		if i%10 == 0 {
+			sampleWeight[i] = 5
+		}
+	}
+	mapped, weighted := fit(map[float64]float64{1: 5}, nil), fit(nil, sampleWeight)
+	if !floats.Equal(mapped.Model[0].Alphas, weighted.Model[0].Alphas) {
+		t.Errorf("expected alphas %g, got %g", weighted.Model[0].Alphas, mapped.Model[0].Alphas)
+	}
+	clf := NewSVC()
+	clf.ClassWeight = "auto"
+	var pe *base.ParamError
+	if err := clf.FitE(X, Y); !errors.As(err, &pe) {
+		t.Errorf("expected a *base.ParamError, got %v", err)
+	}
+}
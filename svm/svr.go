@@ -1,8 +1,8 @@
 package svm
 
 import (
+	"context"
 	"math"
-	"math/rand"
 
 	"github.com/pa-m/sklearn/base"
 	"gonum.org/v1/gonum/mat"
@@ -32,15 +32,13 @@ func (m *SVR) Clone() base.Transformer {
 	return &clone
 }
 
-func svrTrain(X *mat.Dense, Y []float64, C, Epsilon float64, KernelFunction func(X1, X2 []float64) float64, Tol float64, MaxPasses int, CacheSize uint, RandomState *int64) *Model {
-	m, n := X.Dims()
+func svrTrain(fc *fitContext, m int, K func(i, j int) float64, Y, C []float64, Epsilon, Tol float64, MaxPasses int, randomState *base.RandomState) (*Model, error) {
 	alphas := make([]float64, m, m)
 	b := 0.
 	E := make([]float64, m, m)
 	eta := 0.
 	passes := 0
 	L, H := 0., 0.
-	K := cachedKernel(X, CacheSize, KernelFunction)
 	f := func(i int) float64 {
 		y := b
 		for i1 := 0; i1 < m; i1++ {
@@ -63,25 +61,23 @@ func svrTrain(X *mat.Dense, Y []float64, C, Epsilon float64, KernelFunction func
 		}
 		return 1
 	}
-	randIntn := rand.Intn
-	if RandomState != nil {
-		r := rand.New(rand.NewSource(*RandomState))
-		randIntn = r.Intn
-	}
 
-	for passes < MaxPasses {
+	for iter := 1; passes < MaxPasses; iter++ {
+		if err := fc.iteration(iter); err != nil {
+			return nil, err
+		}
 		numChangedAlphas := 0
 		// Step 1 Find a Lagrange multiplier α 1 {that violates the Karush–Kuhn–Tucker (KKT) conditions for the optimization problem.
 		var KKTviolated bool
 		for sample := 0; sample < m; sample++ {
 			i := sample
 			E[i] = f(i) - Y[i]
-			if (E[i] < -Epsilon && alphas[i] < C) || (E[i] > Epsilon && alphas[i] > -C) {
+			if (E[i] < -Epsilon && alphas[i] < C[i]) || (E[i] > Epsilon && alphas[i] > -C[i]) {
 				KKTviolated = true
 				// Step 2 Pick a second multiplier α 2  and optimize the pair ( α 1 , α 2 )
 				// % In practice, there are many heuristics one can use to select
 				// % the i and j. In this simplified code, we select them randomly.
-				j := randIntn(m - 1)
+				j := randomState.Intn(m - 1)
 				if j >= i {
 					j++
 				}
@@ -123,7 +119,7 @@ func svrTrain(X *mat.Dense, Y []float64, C, Epsilon float64, KernelFunction func
 					}
 				}
 				//% Compute L and H by (10) or (11).
-				L, H = max(s-C, -C), min(C, C+s)
+				L, H = max(s-C[i], -C[j]), min(C[j], C[i]+s)
 				alphas[j] = min(H, max(L, alphas[j]))
 				alphas[i] = s - alphas[j]
 				Einew := E[i] + (alphas[i]-alphaiold)*Kii + (alphas[j]-alphajold)*Kij
@@ -161,33 +157,73 @@ func svrTrain(X *mat.Dense, Y []float64, C, Epsilon float64, KernelFunction func
 	}
 
 	model := &Model{
-		X:              mat.NewDense(len(idx), n, nil),
-		KernelFunction: KernelFunction,
-		B:              b,
-		Alphas:         make([]float64, len(idx)),
-		Support:        idx,
+		B:       b,
+		Alphas:  make([]float64, len(idx)),
+		Support: idx,
 	}
 	for ii, i := range idx {
-		model.X.SetRow(ii, X.RawRowView(i))
 		model.Alphas[ii] = alphas[i]
 	}
-	return model
+	return model, nil
 }
 
 // Fit for SVR
 func (m *SVR) Fit(X, Y *mat.Dense) base.Transformer {
-	m.BaseLibSVM.fit(X, Y, svrTrain)
+	if err := m.BaseLibSVM.fit(nil, X, Y, nil, nil, svrTrain); err != nil {
+		panic(err)
+	}
 	return m
 }
 
-func svrPredict(model *Model, X, Y *mat.Dense, output int) {
+// FitContext is FitE stopping between SMO iterations when ctx is done.
+// iterations are reported to the ProgressCallback of ctx, without loss
+func (m *SVR) FitContext(ctx context.Context, X, Y *mat.Dense) error {
+	if err := m.BaseLibSVM.checkFit(m, "SVR.Fit", X, Y); err != nil {
+		return err
+	}
+	return m.BaseLibSVM.fitContext(ctx, m, X, Y, nil, svrTrain)
+}
+
+// FitE is Fit returning an error instead of panicking
+func (m *SVR) FitE(X, Y *mat.Dense) error {
+	if err := m.BaseLibSVM.checkFit(m, "SVR.Fit", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { m.Fit(X, Y) })
+}
+
+// FitWeighted is FitE with the bound C of each sample multiplied by its weight
+func (m *SVR) FitWeighted(X, Y *mat.Dense, sampleWeight []float64) error {
+	if err := m.BaseLibSVM.checkFit(m, "SVR.FitWeighted", X, Y); err != nil {
+		return err
+	}
+	if err := base.CheckSampleWeight("SVR.FitWeighted", X, sampleWeight); err != nil {
+		return err
+	}
+	var err error
+	if rerr := base.Recover(func() { err = m.BaseLibSVM.fit(nil, X, Y, sampleWeight, nil, svrTrain) }); rerr != nil {
+		return rerr
+	}
+	return err
+}
+
+// PredictE is Predict returning an error instead of panicking
+func (m *SVR) PredictE(X, Y *mat.Dense) error {
+	if err := m.BaseLibSVM.checkPredict(m, "SVR.Predict", X, Y); err != nil {
+		return err
+	}
+	return base.Recover(func() { m.Predict(X, Y) })
+}
+
+func svrPredict(model *Model, X mat.Matrix, Y *mat.Dense, output int) {
 	NSamples, _ := X.Dims()
+	K := model.kernel(X)
 
 	Ymat := Y.RawMatrix()
 	for i, yoff := 0, output; i < NSamples; i, yoff = i+1, yoff+Ymat.Stride {
 		y := model.B
 		for j := range model.Alphas {
-			y += model.Alphas[j] * model.KernelFunction(X.RawRowView(i), model.X.RawRowView(j))
+			y += model.Alphas[j] * K(i, j)
 		}
 		Ymat.Data[yoff] = y
 	}
@@ -196,6 +232,11 @@ func svrPredict(model *Model, X, Y *mat.Dense, output int) {
 
 // Predict for SVR
 func (m *SVR) Predict(X, Y *mat.Dense) base.Transformer {
+	m.predict(X, Y)
+	return m
+}
+
+func (m *SVR) predict(X mat.Matrix, Y *mat.Dense) {
 	_, NOutputs := Y.Dims()
 	if NOutputs == 0 {
 		NSamples, _ := X.Dims()
@@ -210,6 +251,19 @@ func (m *SVR) Predict(X, Y *mat.Dense) base.Transformer {
 
 		}
 	})
+}
+
+// FitSparse is Fit for a sparse X, a *sparse.CSR or a *sparse.CSC. Kernel must be a string or a SparseKernel
+func (m *SVR) FitSparse(X mat.Matrix, Y *mat.Dense) base.Transformer {
+	if err := m.BaseLibSVM.fit(nil, X, Y, nil, nil, svrTrain); err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// PredictSparse is Predict for a sparse X
+func (m *SVR) PredictSparse(X mat.Matrix, Y *mat.Dense) base.Transformer {
+	m.predict(X, Y)
 	return m
 }
 
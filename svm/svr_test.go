@@ -10,6 +10,7 @@ import (
 	"sort"
 	"time"
 
+	"github.com/pa-m/sklearn/base"
 	"github.com/pa-m/sklearn/preprocessing"
 	"gonum.org/v1/gonum/mat"
 	"gonum.org/v1/plot"
@@ -67,7 +68,7 @@ func ExampleSVR() {
 		svr.Gamma = opt.gamma
 		svr.Coef0 = opt.coef0
 		svr.Degree = opt.degree
-		svr.RandomState = func() *int64 { seed := int64(5); return &seed }()
+		svr.RandomState = base.NewRandomState(5)
 		svr.Tol = math.Sqrt(Epsilon)
 
 		svr.MaxIter = 5